@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+)
+
+// runConfig 管理配置文件中的钱包，检查配置文件
+func runConfig(args []string) {
+	if len(args) == 0 {
+		printConfigUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "add-wallet":
+		// 与 tracker wallet add/remove 相同，保留以兼容旧的用法
+		runAddWallet("config add-wallet", args[1:])
+	case "remove-wallet":
+		runRemoveWallet("config remove-wallet", args[1:])
+	case "validate":
+		runValidateConfig(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, i18n.Sprintf("未知的 config 子命令: %s\n\n", args[0]))
+		printConfigUsage()
+		os.Exit(2)
+	}
+}
+
+// printConfigUsage 打印 config 子命令列表
+func printConfigUsage() {
+	fmt.Fprint(os.Stderr, i18n.T(`用法: tracker config <子命令> [参数]
+
+子命令:
+  add-wallet <地址>      添加钱包，同 tracker wallet add
+  remove-wallet <地址>   删除钱包，同 tracker wallet remove
+  validate               检查配置文件：语法、未知字段、重复的钱包、取值范围和未设置的环境变量
+`))
+}
+
+// runValidateConfig 检查配置文件并逐条打印发现的问题，有错误时以非零状态退出
+func runValidateConfig(args []string) {
+	var configFile string
+	fs := newFlagSet("config validate", i18n.T("config validate [参数]"))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径"))
+	fs.Parse(args)
+
+	// 配置中引用的环境变量可能来自 .env 文件
+	if err := initEnv(); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("检查配置失败:"), err)
+		os.Exit(1)
+	}
+	problems, err := config.CheckFile(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("检查配置失败:"), err)
+		os.Exit(1)
+	}
+
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", configFile, p)
+	}
+	if config.HasErrors(problems) {
+		os.Exit(1)
+	}
+	if len(problems) == 0 {
+		fmt.Print(i18n.Sprintf("%s: 配置检查通过\n", configFile))
+	}
+}
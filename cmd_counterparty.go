@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/tracker"
+)
+
+// runCounterparties 分析钱包最近的交易，列出往来频繁的地址和资金来源，用于发现值得加入跟踪列表的关联钱包
+func runCounterparties(args []string) {
+	var (
+		global  globalOptions
+		wallets walletOptions
+		limit   int
+		top     int
+		asJSON  bool
+	)
+	fs := newFlagSet("counterparties", i18n.T("counterparties [参数]"))
+	global.register(fs)
+	wallets.register(fs)
+	fs.IntVar(&limit, "limit", 200, i18n.T("每个钱包扫描的最近交易数量"))
+	fs.IntVar(&top, "top", 20, i18n.T("显示往来最多的前多少个地址，0表示全部"))
+	fs.BoolVar(&asJSON, "json", false, i18n.T("以JSON格式输出到标准输出"))
+	fs.Parse(args)
+
+	cfg := global.load()
+	defer global.close()
+
+	if limit <= 0 {
+		fatal("-limit 必须为正数", "limit", limit)
+	}
+	if err := applyRuntimeConfig(cfg); err != nil {
+		fatal("应用配置失败", "error", err)
+	}
+
+	walletAddrs, err := wallets.resolve(cfg)
+	if err != nil {
+		fatal(err.Error())
+	}
+	walletAddrs = solanaWallets(cfg, walletAddrs)
+	if len(walletAddrs) == 0 {
+		fatal("没有可分析的 Solana 钱包")
+	}
+	helius, err := tracker.NewHeliusService()
+	if err != nil {
+		fatal("创建 Helius 服务失败", "error", err)
+	}
+
+	tracked := make(map[string]bool)
+	for _, addr := range cfg.GetWalletAddresses() {
+		tracked[addr] = true
+	}
+
+	// 收到中断信号时取消进行中的请求
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := tracker.AnalyzeCounterparties(ctx, helius, walletAddrs, limit, tracked)
+	if err != nil {
+		fatal("分析交易对手失败", "error", err)
+	}
+	report = report.Top(top)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fatal("输出JSON失败", "error", err)
+		}
+		return
+	}
+	fmt.Print(report.String())
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/tracker"
+)
+
+// ctlTimeout 控制请求的超时
+const ctlTimeout = 10 * time.Second
+
+// runCtl 通过本地 unix socket 控制运行中的 watch 进程，无需修改配置或重启
+func runCtl(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printCtlUsage()
+		os.Exit(2)
+	}
+	action := args[0]
+
+	var (
+		configFile string
+		socket     string
+		price      float64
+		portfolio  float64
+	)
+	fs := newFlagSet("ctl "+action, i18n.Sprintf("ctl %s [参数]", action))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径，用于确定控制 socket 的位置"))
+	fs.StringVar(&socket, "socket", "", i18n.T("控制 socket 路径，默认使用配置文件中的 control_socket"))
+	switch action {
+	case "status", "pause", "resume", "refresh":
+	case "threshold":
+		fs.Float64Var(&price, "price", 0, i18n.T("单币价格/价值报警阈值（百分比）"))
+		fs.Float64Var(&portfolio, "portfolio", 0, i18n.T("组合总价值报警阈值（百分比），0表示关闭"))
+	default:
+		fmt.Fprint(os.Stderr, i18n.Sprintf("未知的 ctl 子命令: %s\n\n", action))
+		printCtlUsage()
+		os.Exit(2)
+	}
+	fs.Parse(args[1:])
+
+	var body []byte
+	if action == "threshold" {
+		var req tracker.ThresholdRequest
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "price":
+				req.Price = &price
+			case "portfolio":
+				req.Portfolio = &portfolio
+			}
+		})
+		if req.Price == nil && req.Portfolio == nil {
+			fs.Usage()
+			os.Exit(2)
+		}
+		body, _ = json.Marshal(req)
+	}
+
+	if socket == "" {
+		if err := initEnv(); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.T("控制失败:"), err)
+			os.Exit(1)
+		}
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("控制失败: %v（可使用 -socket 直接指定控制 socket）\n", err))
+			os.Exit(1)
+		}
+		socket = cfg.Settings.ControlSocket
+		i18n.SetLanguage(i18n.Detect(cfg.Settings.Language))
+	}
+
+	status, err := sendControl(socket, action, body)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("控制失败:"), err)
+		os.Exit(1)
+	}
+	if action == "refresh" {
+		fmt.Println(i18n.T("已请求立即刷新持仓"))
+	}
+	printControlStatus(status)
+}
+
+// printCtlUsage 打印 ctl 子命令列表
+func printCtlUsage() {
+	fmt.Fprint(os.Stderr, i18n.T(`用法: tracker ctl <子命令> [参数]
+
+子命令:
+  status                                  查看运行中的监控状态
+  pause                                   暂停快照、价格报警和定时刷新
+  resume                                  恢复暂停的监控
+  refresh                                 立即重新获取所有钱包的持仓
+  threshold -price <%> [-portfolio <%>]   修改报警阈值，重启后恢复为启动参数
+
+通过配置文件中 control_socket 指定的 unix socket 连接运行中的 tracker watch
+`))
+}
+
+// sendControl 通过 unix socket 发送控制请求并返回最新状态
+func sendControl(socket, action string, body []byte) (tracker.ControlStatus, error) {
+	var status tracker.ControlStatus
+	client := &http.Client{
+		Timeout: ctlTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	method := http.MethodPost
+	if action == "status" {
+		method = http.MethodGet
+	}
+	// 主机名不会被使用，连接总是发往 unix socket
+	req, err := http.NewRequest(method, "http://tracker/control/"+action, bytes.NewReader(body))
+	if err != nil {
+		return status, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return status, errors.New(i18n.Sprintf("连接 %s 失败，tracker watch 是否在运行: %v", socket, err))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return status, fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return status, fmt.Errorf("%s", strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return status, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return status, nil
+}
+
+// printControlStatus 打印监控状态
+func printControlStatus(status tracker.ControlStatus) {
+	state := i18n.T("运行中")
+	if status.Paused {
+		state = i18n.T("已暂停")
+	}
+	fmt.Print(i18n.Sprintf("状态: %s\n", state))
+	fmt.Print(i18n.Sprintf("快照间隔: %s\n", status.Interval))
+	fmt.Print(i18n.Sprintf("单币报警阈值: %g%%\n", status.AlertThreshold))
+	if status.PortfolioThreshold > 0 {
+		fmt.Print(i18n.Sprintf("组合报警阈值: %g%%\n", status.PortfolioThreshold))
+	} else {
+		fmt.Println(i18n.T("组合报警阈值: 已关闭"))
+	}
+	if status.LastSnapshot != nil {
+		fmt.Print(i18n.Sprintf("最近快照: %s\n", status.LastSnapshot.Local().Format("2006-01-02 15:04:05")))
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/tracker"
+)
+
+// runReport 根据存储的快照生成指定时间范围的区间报告
+func runReport(args []string) {
+	var (
+		global    globalOptions
+		overrides overrideOptions
+		since     time.Duration
+	)
+	fs := newFlagSet("report", i18n.T("report [参数]"))
+	global.register(fs)
+	overrides.registerStore(fs)
+	fs.DurationVar(&since, "since", 24*time.Hour, i18n.T("报告覆盖的时间范围（如 24h），从当前时间往前推算"))
+	fs.Parse(args)
+
+	cfg := global.load()
+	defer global.close()
+
+	if err := overrides.apply(cfg); err != nil {
+		fatal("运行参数无效", "error", err)
+	}
+	if since <= 0 {
+		fatal("-since 必须为正数", "since", since)
+	}
+	if cfg.Settings.SQLitePath == "" {
+		fatal("report 需要快照数据库，请在配置文件中设置 sqlite_path 或使用 -db 参数")
+	}
+
+	store, err := tracker.NewSQLiteStore(cfg.Settings.SQLitePath)
+	if err != nil {
+		fatal("打开快照数据库失败", "error", err)
+	}
+	defer store.Close()
+
+	until := time.Now()
+	report, err := tracker.BuildHistoryReport(context.Background(), store, until.Add(-since), until)
+	if err != nil {
+		fatal("生成区间报告失败", "error", err)
+	}
+	fmt.Println(report.String())
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/tracker"
+)
+
+// runSnapshot 获取一次当前持仓和价格，输出报告后退出
+func runSnapshot(args []string) {
+	var (
+		global    globalOptions
+		wallets   walletOptions
+		overrides overrideOptions
+		strict    bool
+		asJSON    bool
+	)
+	fs := newFlagSet("snapshot", i18n.T("snapshot [参数]"))
+	global.register(fs)
+	wallets.register(fs)
+	overrides.registerFetch(fs)
+	fs.BoolVar(&strict, "strict", false, i18n.T("任一钱包获取失败时以非零状态退出"))
+	fs.BoolVar(&asJSON, "json", false, i18n.T("以JSON格式输出到标准输出"))
+	fs.Parse(args)
+
+	cfg := global.load()
+	defer global.close()
+
+	if err := overrides.apply(cfg); err != nil {
+		fatal("运行参数无效", "error", err)
+	}
+	initTracker(cfg, false)
+	defer initTracing(cfg)()
+
+	walletAddrs, err := wallets.resolve(cfg)
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	// 收到中断信号时取消进行中的请求
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	tokens, err := fetchTokens(ctx, walletAddrs, cfg, strict)
+	if err != nil {
+		fatal("获取代币数据失败", "error", err)
+	}
+	validTokens, err := updateTokenPrices(ctx, tokens, nil)
+	if err != nil {
+		fatal("更新价格失败", "error", err)
+	}
+	if cfg.Settings.IncludeNFTs {
+		if err := tracker.RefreshNFTPortfolio(ctx, walletAddrs, cfg.Settings.NFTCollections, validTokens); err != nil {
+			logger.Error("NFT估值失败", "error", err)
+		}
+	}
+	if err := tracker.RefreshPerpPositions(ctx, cfg.Settings.Derivatives.HyperliquidAccounts, nil); err != nil {
+		logger.Error("获取合约仓位失败", "error", err)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(tracker.NewSnapshotEvent(validTokens, time.Now())); err != nil {
+			fatal("输出JSON失败", "error", err)
+		}
+		return
+	}
+	fmt.Println(tracker.GenerateReport(validTokens))
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+)
+
+// runWallet 管理配置文件中的钱包，修改前将原文件备份为 <文件名>.bak
+func runWallet(args []string) {
+	if len(args) == 0 {
+		printWalletUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "add":
+		runAddWallet("wallet add", args[1:])
+	case "remove":
+		runRemoveWallet("wallet remove", args[1:])
+	case "list":
+		runListWallets(args[1:])
+	case "label":
+		runLabelWallet(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, i18n.Sprintf("未知的 wallet 子命令: %s\n\n", args[0]))
+		printWalletUsage()
+		os.Exit(2)
+	}
+}
+
+// printWalletUsage 打印 wallet 子命令列表
+func printWalletUsage() {
+	fmt.Fprint(os.Stderr, i18n.T(`用法: tracker wallet <子命令> [参数]
+
+子命令:
+  add <地址>            添加钱包，可用 -label/-group/-tags/-chain 设置属性
+  remove <地址>         删除钱包
+  list                  列出配置文件中的钱包
+  label <地址> <标签>   修改钱包的标签，标签为 "" 时删除
+
+修改前会将原配置文件备份为 <文件名>.bak
+`))
+}
+
+// runAddWallet 向配置文件添加钱包，name 为调用的子命令名称
+func runAddWallet(name string, args []string) {
+	var (
+		configFile string
+		wallet     config.WalletConfig
+		tags       string
+	)
+	fs := newFlagSet(name, i18n.Sprintf("%s <地址> [参数]", name))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径"))
+	fs.StringVar(&wallet.Label, "label", "", i18n.T("钱包标签"))
+	fs.StringVar(&wallet.Group, "group", "", i18n.T("所属分组"))
+	fs.StringVar(&tags, "tags", "", i18n.T("逗号分隔的标签"))
+	fs.StringVar(&wallet.Chain, "chain", "", i18n.T("所在的链（solana/ethereum/base），为空时根据地址推断"))
+	wallet.Address = parseWithAddress(fs, args)
+
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			wallet.Tags = append(wallet.Tags, tag)
+		}
+	}
+
+	if err := config.AddWallet(configFile, wallet); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("添加钱包失败:"), err)
+		os.Exit(1)
+	}
+	fmt.Print(i18n.Sprintf("已添加钱包 %s 到 %s\n", wallet.Address, configFile))
+}
+
+// runRemoveWallet 从配置文件删除钱包，name 为调用的子命令名称
+func runRemoveWallet(name string, args []string) {
+	var configFile string
+	fs := newFlagSet(name, i18n.Sprintf("%s <地址> [参数]", name))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径"))
+	address := parseWithAddress(fs, args)
+
+	if err := config.RemoveWallet(configFile, address); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("删除钱包失败:"), err)
+		os.Exit(1)
+	}
+	fmt.Print(i18n.Sprintf("已从 %s 删除钱包 %s\n", configFile, address))
+}
+
+// runListWallets 列出配置文件中的钱包
+func runListWallets(args []string) {
+	var configFile string
+	fs := newFlagSet("wallet list", i18n.T("wallet list [参数]"))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径"))
+	fs.Parse(args)
+
+	wallets, err := config.ListWallets(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("读取钱包失败:"), err)
+		os.Exit(1)
+	}
+	if len(wallets) == 0 {
+		fmt.Print(i18n.Sprintf("%s 中没有钱包\n", configFile))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join([]string{i18n.T("地址"), i18n.T("链"), i18n.T("标签"), i18n.T("分组"), i18n.T("标记")}, "\t"))
+	for _, wallet := range wallets {
+		chain := wallet.Chain
+		if chain == "" {
+			chain = config.DetectChain(wallet.Address)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wallet.Address, chain, wallet.Label, wallet.Group, strings.Join(wallet.Tags, ","))
+	}
+	w.Flush()
+}
+
+// runLabelWallet 修改配置文件中钱包的标签
+func runLabelWallet(args []string) {
+	var configFile string
+	fs := newFlagSet("wallet label", i18n.T("wallet label <地址> <标签> [参数]"))
+	fs.StringVar(&configFile, "config", defaultConfigFile(), i18n.T("钱包配置文件路径"))
+	positional := parsePositional(fs, args)
+	if len(positional) != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	address, label := positional[0], positional[1]
+
+	if err := config.SetWalletLabel(configFile, address, label); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("修改钱包标签失败:"), err)
+		os.Exit(1)
+	}
+	if label == "" {
+		fmt.Print(i18n.Sprintf("已删除钱包 %s 的标签\n", address))
+		return
+	}
+	fmt.Print(i18n.Sprintf("已将钱包 %s 的标签设为 %s\n", address, label))
+}
+
+// parseWithAddress 解析参数并返回钱包地址，地址可以写在参数之前或之后
+func parseWithAddress(fs *flag.FlagSet, args []string) string {
+	var address string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		address, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if address == "" {
+		address = fs.Arg(0)
+	}
+	if address == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return address
+}
+
+// parsePositional 解析参数并返回所有位置参数，位置参数和选项可以交替出现
+func parsePositional(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,787 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/tracker"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// runWatch 持续监控钱包：定时快照、报警和定时刷新代币列表
+func runWatch(args []string) {
+	var (
+		global             globalOptions
+		wallets            walletOptions
+		overrides          overrideOptions
+		serveAddr          string
+		portfolioThreshold float64
+		resetBaseline      bool
+		strict             bool
+		useTUI             bool
+		output             string
+	)
+	fs := newFlagSet("watch", i18n.T("watch [参数]"))
+	global.register(fs)
+	wallets.register(fs)
+	overrides.registerFetch(fs)
+	overrides.registerMonitor(fs)
+	overrides.registerStore(fs)
+	fs.StringVar(&serveAddr, "serve", "", i18n.T("HTTP查询服务监听地址（如 :8080），为空则不启动"))
+	fs.Float64Var(&portfolioThreshold, "portfolio-threshold", 5.0, i18n.T("组合总价值报警阈值（百分比），0表示关闭"))
+	fs.BoolVar(&resetBaseline, "reset-baseline", false, i18n.T("丢弃已保存的盈亏基准，以本次启动的持仓重新锚定"))
+	fs.BoolVar(&strict, "strict", false, i18n.T("启动时任一钱包获取失败则以非零状态退出"))
+	fs.BoolVar(&useTUI, "tui", false, i18n.T("使用终端仪表盘代替文本报告"))
+	fs.StringVar(&output, "output", "table", i18n.T("文本报告格式: table 每次输出完整持仓表，diff 只输出与上次相比的变化"))
+	fs.Parse(args)
+
+	cfg := global.load()
+	defer global.close()
+
+	if err := overrides.apply(cfg); err != nil {
+		fatal("运行参数无效", "error", err)
+	}
+	var diff *tracker.DiffReporter
+	switch output {
+	case "table":
+	case "diff":
+		diff = tracker.NewDiffReporter()
+	default:
+		fatal("-output 无效，可选 table/diff", "output", output)
+	}
+	redisClient := initTracker(cfg, resetBaseline)
+	defer initTracing(cfg)()
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
+
+	walletAddrs, err := wallets.resolve(cfg)
+	if err != nil {
+		fatal(err.Error())
+	}
+
+	// 创建上下文以便优雅退出
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 获取最新数据
+	tokens, err := fetchTokens(ctx, walletAddrs, cfg, strict)
+	if err != nil {
+		fatal("获取代币数据失败", "error", err)
+	}
+
+	// 更新价格
+	validTokens, err := updateTokenPrices(ctx, tokens, nil)
+	if err != nil {
+		fatal("更新价格失败", "error", err)
+	}
+
+	// 获取NFT估值
+	if cfg.Settings.IncludeNFTs {
+		if err := tracker.RefreshNFTPortfolio(ctx, walletAddrs, cfg.Settings.NFTCollections, validTokens); err != nil {
+			logger.Error("NFT估值失败", "error", err)
+		}
+	}
+
+	// 获取永续合约仓位
+	if err := tracker.RefreshPerpPositions(ctx, cfg.Settings.Derivatives.HyperliquidAccounts, nil); err != nil {
+		logger.Error("获取合约仓位失败", "error", err)
+	}
+
+	// 生成初始报告
+	if !useTUI {
+		printReport(validTokens, diff)
+	}
+
+	// 创建中断信号通道
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// 收到 SIGHUP 时重置盈亏基准
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupChan:
+				tracker.ResetBaseline()
+			}
+		}
+	}()
+
+	// 创建并启动监控器
+	monitor := tracker.NewTokenMonitor(cfg.Settings.MonitorInterval, func(tokens []*tracker.TokenData) {
+		if !useTUI {
+			printReport(tokens, diff)
+		}
+	})
+	monitor.SetQuiet(useTUI)
+	monitor.SetOutputRotation(cfg.Settings.LogRotation.CSVRotateConfig(), cfg.Settings.LogRotation.RotateConfig())
+
+	monitor.SetPortfolioAlertThreshold(portfolioThreshold)
+	monitor.SetAlertCooldown(cfg.Settings.AlertCooldown)
+	// 涨跌榜和变化率列的窗口以及最短快照间隔参与推算历史快照缓冲区容量，需在 SetAlertWindows 之前设置
+	monitor.SetAdaptiveInterval(adaptiveInterval(cfg.Settings))
+	monitor.SetTopMovers(cfg.Settings.TopMovers.Windows, cfg.Settings.TopMovers.Count)
+	monitor.SetChangeColumns(cfg.Settings.ChangeColumns)
+	monitor.SetAlertWindows(cfg.Settings.AlertWindows, cfg.Settings.HistorySize)
+	monitor.SetPositionReduceThreshold(cfg.Settings.PositionReduceThreshold)
+	monitor.SetAirdropAlertValue(cfg.Settings.AirdropAlertValue)
+	monitor.SetTrailingStop(cfg.Settings.TrailingStopPct)
+	monitor.SetLiquidationAlert(cfg.Settings.Derivatives.LiquidationAlertPct)
+	monitor.SetImpermanentLossAlert(cfg.Settings.ImpermanentLossPct)
+	monitor.SetLiquidityAlerts(cfg.Settings.Liquidity.MinUSD, cfg.Settings.Liquidity.DropPct)
+	monitor.SetBuiltinAlerts(*cfg.Settings.BuiltinAlerts)
+	monitor.SetHealthLimits(cfg.Settings.HealthLimits())
+	if err := monitor.SetSeverityConfig(severityFromConfig(cfg.Settings.Severity)); err != nil {
+		fatal("配置报警级别失败", "error", err)
+	}
+	rules, err := rulesFromConfig(cfg.Rules)
+	if err != nil {
+		fatal("加载报警规则失败", "error", err)
+	}
+	monitor.SetRules(rules)
+
+	// 恢复上次退出时保存的历史快照和持仓，避免重启后报警窗口从零开始
+	if state := cfg.Settings.State; state.MaxAge > 0 {
+		if err := monitor.LoadState(state.Path, state.MaxAge); err != nil && !os.IsNotExist(err) {
+			logger.Warn("恢复监控状态失败", "path", state.Path, "error", err)
+		}
+	}
+
+	// CoinGecko 交叉验证：偏离过大的价格降低可信度，可选持续偏离报警
+	if coingecko := cfg.Settings.CoinGecko; coingecko.CrossCheck {
+		monitor.SetSecondaryPriceService(tracker.PlainPrices(tracker.NewCoinGeckoPriceService(coingecko)))
+		var window time.Duration
+		if coingecko.DivergenceAlert {
+			window = coingecko.DivergenceWindow
+		}
+		monitor.SetDivergenceAlert(coingecko.DivergenceThreshold, window)
+	}
+
+	// 注册通知渠道
+	if webhookURL := os.Getenv("DISCORD_WEBHOOK_URL"); webhookURL != "" {
+		monitor.Notifiers().RegisterNamed("discord", tracker.NewDiscordNotifier(webhookURL))
+	}
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		webhook := tracker.NewWebhookNotifier(webhookURL, os.Getenv("WEBHOOK_SECRET"))
+		monitor.Notifiers().RegisterNamed("webhook", webhook)
+		if os.Getenv("WEBHOOK_SNAPSHOTS") == "true" {
+			webhook.ForwardSnapshots(ctx, monitor)
+		}
+	}
+	var eventBus *tracker.EventBusNotifier
+	if bus := cfg.Settings.EventBus; bus.Driver != "" {
+		publisher, err := tracker.NewEventPublisher(bus)
+		if err != nil {
+			fatal("连接消息总线失败", "driver", bus.Driver, "error", err)
+		}
+		eventBus = tracker.NewEventBusNotifier(publisher, bus.SnapshotTopic, bus.AlertTopic)
+		monitor.Notifiers().RegisterNamed("eventbus", eventBus)
+		eventBus.ForwardSnapshots(ctx, monitor)
+	}
+
+	// 聪明钱钱包的兑换交易作为跟单信号发送到 webhook/消息总线
+	var copyTrader *tracker.CopyTrader
+	if copyCfg := cfg.Settings.CopyTrading; copyCfg.Enabled() {
+		var sinks []tracker.SignalSink
+		if copyCfg.Webhook != "" {
+			sinks = append(sinks, tracker.NewWebhookNotifier(os.ExpandEnv(copyCfg.Webhook), os.Getenv("SIGNAL_WEBHOOK_SECRET")))
+		}
+		if copyCfg.Topic != "" {
+			sinks = append(sinks, eventBus.SignalSink(copyCfg.Topic))
+		}
+		helius, err := tracker.NewHeliusService()
+		if err != nil {
+			logger.Warn("无法创建 Helius 服务，跟单信号只使用 webhook 推送的交易", "error", err)
+		}
+		smart := smartMoneyWallets(cfg, walletAddrs)
+		copyTrader = tracker.NewCopyTrader(helius, sinks...)
+		copyTrader.SetWallets(smart)
+		copyTrader.SetMinValue(copyCfg.MinValue)
+		logger.Info("跟单信号已启用", "tag", copyCfg.Tag, "wallets", len(smart))
+	}
+	if redisClient != nil {
+		if channel := cfg.Settings.Redis.AlertChannel; channel != "" {
+			monitor.Notifiers().RegisterNamed("redis", tracker.NewRedisNotifier(redisClient, channel))
+		}
+		if cfg.Settings.Redis.DedupeAlerts {
+			monitor.SetAlertCoordinator(redisClient)
+		}
+	}
+	var emailNotifier *tracker.EmailNotifier
+	if emailCfg, ok, err := tracker.EmailConfigFromEnv(); err != nil {
+		fatal("读取SMTP配置失败", "error", err)
+	} else if ok {
+		emailNotifier, err = tracker.NewEmailNotifier(emailCfg)
+		if err != nil {
+			fatal("创建邮件通知失败", "error", err)
+		}
+		monitor.Notifiers().RegisterNamed("email", emailNotifier)
+	}
+	if webhookURL := os.Getenv("SLACK_WEBHOOK_URL"); webhookURL != "" {
+		slack := tracker.NewSlackNotifier(webhookURL)
+		monitor.Notifiers().RegisterNamed("slack", slack)
+		if at := os.Getenv("SLACK_SUMMARY_TIME"); at != "" {
+			if err := slack.RunDailySummary(ctx, monitor, at); err != nil {
+				fatal("配置Slack每日汇总失败", "error", err)
+			}
+		}
+	}
+
+	// 打开快照数据库
+	if cfg.Settings.SQLitePath != "" {
+		store, err := tracker.NewSQLiteStore(cfg.Settings.SQLitePath)
+		if err != nil {
+			fatal("打开快照数据库失败", "error", err)
+		}
+		defer store.Close()
+		monitor.SetStore(store)
+
+		// 每日/每周汇总
+		if summaries := cfg.Settings.Summaries; summaries.Daily || summaries.Weekly {
+			weekday, _ := config.ParseWeekday(summaries.Weekday)
+			if err := monitor.RunSummaries(ctx, summaries.Time, weekday, summaries.Daily, summaries.Weekly); err != nil {
+				fatal("配置汇总报告失败", "error", err)
+			}
+		}
+	}
+
+	// 将快照写入时序数据库
+	var seriesWriter *tracker.TimeSeriesWriter
+	if timeseries := cfg.Settings.TimeSeries; timeseries.Driver != "" {
+		sink, err := tracker.NewTimeSeriesSink(timeseries)
+		if err != nil {
+			fatal("连接时序数据库失败", "driver", timeseries.Driver, "error", err)
+		}
+		seriesWriter = tracker.NewTimeSeriesWriter(sink, timeseries)
+		seriesWriter.Run(ctx, monitor)
+	}
+
+	// 按天导出快照的 Parquet 文件
+	var parquetExporter *tracker.ParquetExporter
+	if cfg.Settings.Parquet.Enabled {
+		parquetExporter, err = tracker.NewParquetExporter(cfg.Settings.Parquet.Dir, cfg.Settings.Parquet.FlushInterval)
+		if err != nil {
+			fatal("创建Parquet导出失败", "error", err)
+		}
+		parquetExporter.Run(ctx, monitor)
+	}
+
+	// 同步持仓表和每日汇总到 Google Sheets
+	var sheetsSync *tracker.SheetsSync
+	if sheets := cfg.Settings.Sheets; sheets.SpreadsheetID != "" {
+		client, err := tracker.NewSheetsClient(sheets.SpreadsheetID, os.ExpandEnv(sheets.CredentialsFile))
+		if err != nil {
+			fatal("创建Google Sheets同步失败", "error", err)
+		}
+		sheetsSync = tracker.NewSheetsSync(client, sheets.HoldingsSheet, sheets.SummarySheet, sheets.Interval)
+		sheetsSync.Run(ctx, monitor)
+	}
+
+	// 价值最高的代币在快照之间以更短的间隔检查价格报警，直接请求 Jupiter 而不经过价格缓存
+	var fastPrices *tracker.FastPriceWatch
+	if fast := cfg.Settings.FastPrices; fast.Top > 0 {
+		fastPrices = tracker.NewFastPriceWatch(tracker.NewJupiterPriceService(), fast.Top, fast.Interval)
+		fastPrices.Run(ctx, monitor)
+	}
+
+	// 更新监控器数据
+	monitor.UpdateTokens(validTokens)
+	writeHTMLReport(monitor, cfg.Settings.DataDir)
+
+	// 启动监控
+	monitor.Start(ctx)
+
+	// 配置了备用RPC端点时定期检查各端点的健康状态
+	if len(cfg.Settings.RPC.Endpoints) > 0 {
+		tracker.StartRPCHealthCheck(ctx, cfg.Settings.RPC.HealthCheckInterval)
+	}
+
+	// 启动HTTP查询服务
+	var server *tracker.Server
+	if serveAddr != "" {
+		server = tracker.NewServer(serveAddr, monitor)
+		server.Start()
+	}
+
+	// 当前配置和钱包列表，配置文件重新加载时更新
+	var stateMu sync.RWMutex
+	currentState := func() (*config.Config, []string) {
+		stateMu.RLock()
+		defer stateMu.RUnlock()
+		return cfg, walletAddrs
+	}
+	refresh := newRefreshQueue()
+
+	// 运行时控制接口：本地 unix socket 供 tracker ctl 使用，HTTP 服务上的接口需要设置令牌
+	control := tracker.NewControlHandler(monitor, "", refresh.requestAll)
+	var controlSocket *tracker.ControlSocket
+	if path := cfg.Settings.ControlSocket; path != "-" {
+		controlSocket, err = tracker.ListenControlSocket(path, control)
+		if err != nil {
+			logger.Warn("无法启动控制 socket，tracker ctl 不可用", "error", err)
+		}
+	}
+	if server != nil {
+		token := os.Getenv("TRACKER_CONTROL_TOKEN")
+		if token != "" {
+			server.Handle("/control/", tracker.NewControlHandler(monitor, token, refresh.requestAll))
+		} else {
+			logger.Info("未设置 TRACKER_CONTROL_TOKEN，HTTP 服务不提供控制接口")
+		}
+		if cfg.Settings.DebugPprof {
+			if err := server.EnablePprof(token); err != nil {
+				logger.Warn("debug_pprof 需要设置 TRACKER_CONTROL_TOKEN，未启用 pprof 端点")
+			} else {
+				logger.Info("pprof 端点已启用", "path", "/debug/pprof/")
+			}
+		}
+	}
+
+	// 接收 Helius webhook 推送的交易，发出活动报警并增量更新相关钱包
+	var heliusHook *tracker.HeliusWebhookHandler
+	if hookCfg := cfg.Settings.HeliusWebhook; hookCfg.Enabled {
+		if server == nil {
+			logger.Warn("Helius webhook 需要使用 -serve 启动HTTP服务，已忽略")
+		} else {
+			heliusHook = tracker.NewHeliusWebhookHandler(monitor, os.Getenv("HELIUS_WEBHOOK_AUTH"), func(active []string) {
+				logger.Info("收到钱包交易推送，更新相关钱包", "wallets", len(active))
+				refresh.request(active)
+			})
+			heliusHook.SetWallets(solanaWallets(cfg, walletAddrs))
+			if copyTrader != nil {
+				heliusHook.SetCopyTrader(copyTrader)
+			}
+			server.Handle(hookCfg.Path, heliusHook)
+			logger.Info("Helius webhook 已启用", "path", hookCfg.Path)
+		}
+	}
+
+	// 订阅 Solana 钱包账户变化，链上余额变化后立即更新代币列表
+	var stream *tracker.AccountStream
+	if cfg.Settings.RealtimeUpdates {
+		stream, err = tracker.NewAccountStream()
+		if err != nil {
+			logger.Warn("无法创建WebSocket订阅，使用定时轮询", "error", err)
+		} else {
+			stream.SetWallets(solanaWallets(cfg, walletAddrs))
+			go stream.Run(ctx, func(changed []string) {
+				logger.Info("检测到链上账户变化，更新代币列表", "wallets", len(changed))
+				refresh.request(changed)
+			})
+		}
+	}
+
+	// switchConfig 切换到新的配置，钱包列表变化时立即重新获取
+	switchConfig := func(newCfg *config.Config) {
+		stateMu.Lock()
+		oldAddrs := walletAddrs
+		// 沿用已加载的代币元数据缓存
+		newCfg.SetMetadataCache(cfg.MetadataCache())
+		cfg = newCfg
+		walletAddrs = wallets.reload(newCfg, walletAddrs)
+		changed := !sameWallets(oldAddrs, walletAddrs)
+		stateMu.Unlock()
+
+		// 钱包标签可能变化，每次都重新计算聪明钱钱包
+		if copyTrader != nil {
+			copyTrader.SetWallets(smartMoneyWallets(newCfg, walletAddrs))
+			copyTrader.SetMinValue(newCfg.Settings.CopyTrading.MinValue)
+		}
+
+		if changed {
+			tracker.PruneWalletStatuses(walletAddrs)
+			if stream != nil {
+				stream.SetWallets(solanaWallets(newCfg, walletAddrs))
+			}
+			if heliusHook != nil {
+				heliusHook.SetWallets(solanaWallets(newCfg, walletAddrs))
+			}
+			logger.Info("钱包列表已变化，立即更新代币列表", "wallets", len(walletAddrs))
+			refresh.requestAll()
+		}
+	}
+
+	// 监听配置文件变化，热更新钱包、代币、阈值和过滤规则；只使用环境变量配置时不监听
+	if global.configFile != "" {
+		err = config.Watch(ctx, global.configFile, func(newCfg *config.Config) {
+			if err := overrides.apply(newCfg); err != nil {
+				logger.Error("重新加载的配置无效，继续使用旧配置", "error", err)
+				return
+			}
+			global.override(&newCfg.Settings)
+			if err := global.setupLogging(newCfg.Settings); err != nil {
+				logger.Error("重新加载的日志配置无效，继续使用旧配置", "error", err)
+				return
+			}
+			if err := applyRuntimeConfig(newCfg); err != nil {
+				logger.Error("应用重新加载的配置失败", "error", err)
+				return
+			}
+			monitor.SetAlertCooldown(newCfg.Settings.AlertCooldown)
+			monitor.SetAdaptiveInterval(adaptiveInterval(newCfg.Settings))
+			monitor.SetTopMovers(newCfg.Settings.TopMovers.Windows, newCfg.Settings.TopMovers.Count)
+			monitor.SetChangeColumns(newCfg.Settings.ChangeColumns)
+			monitor.SetAlertWindows(newCfg.Settings.AlertWindows, newCfg.Settings.HistorySize)
+			monitor.SetPositionReduceThreshold(newCfg.Settings.PositionReduceThreshold)
+			monitor.SetAirdropAlertValue(newCfg.Settings.AirdropAlertValue)
+			monitor.SetTrailingStop(newCfg.Settings.TrailingStopPct)
+			monitor.SetLiquidationAlert(newCfg.Settings.Derivatives.LiquidationAlertPct)
+			monitor.SetImpermanentLossAlert(newCfg.Settings.ImpermanentLossPct)
+			monitor.SetLiquidityAlerts(newCfg.Settings.Liquidity.MinUSD, newCfg.Settings.Liquidity.DropPct)
+			monitor.SetBuiltinAlerts(*newCfg.Settings.BuiltinAlerts)
+			monitor.SetHealthLimits(newCfg.Settings.HealthLimits())
+			if err := monitor.SetSeverityConfig(severityFromConfig(newCfg.Settings.Severity)); err != nil {
+				logger.Error("重新加载的报警级别设置无效，继续使用旧设置", "error", err)
+			}
+			if rules, err := rulesFromConfig(newCfg.Rules); err != nil {
+				logger.Error("重新加载的报警规则无效，继续使用旧规则", "error", err)
+			} else {
+				monitor.SetRules(rules)
+			}
+			monitor.SetOutputRotation(newCfg.Settings.LogRotation.CSVRotateConfig(), newCfg.Settings.LogRotation.RotateConfig())
+			switchConfig(newCfg)
+		})
+		if err != nil {
+			logger.Warn("无法监听配置文件变化", "error", err)
+		}
+	}
+
+	// 定期重新解析 .sol 域名，域名转让后跟踪新所有者的地址
+	go func() {
+		refreshCfg, _ := currentState()
+		ticker := time.NewTicker(refreshCfg.Settings.SNS.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			tracker.ExpireSNSCache()
+			current, _ := currentState()
+			next := *current
+			next.Wallets = append([]config.WalletConfig(nil), current.Wallets...)
+			changed, err := resolveWalletDomains(&next)
+			if err != nil {
+				logger.Error("重新解析钱包域名失败", "error", err)
+				continue
+			}
+			if !changed && !tracker.IsSNSDomain(wallets.walletAddr) {
+				continue
+			}
+			if err := applyRuntimeConfig(&next); err != nil {
+				logger.Error("应用重新解析的钱包地址失败", "error", err)
+				continue
+			}
+			switchConfig(&next)
+		}
+	}()
+
+	// 创建定时更新代币列表的goroutine
+	go func() {
+		refreshCfg, _ := currentState()
+		ticker := time.NewTicker(refreshCfg.Settings.RefreshInterval)
+		defer ticker.Stop()
+		logger.Info("开始定时更新代币列表", "interval", refreshCfg.Settings.RefreshInterval)
+
+		// 各钱包最近一次获取的代币，增量更新时与新获取的钱包合并
+		lastTokens := tokens
+
+		// updateData 更新代币列表，changed 为nil时获取所有钱包，否则只重新获取这些钱包。
+		// strict 只作用于启动时的首次获取，后台刷新中获取失败的钱包只记录日志
+		updateData := func(changed []string) {
+			cfg, walletAddrs := currentState()
+
+			// 一次刷新中的获取和价格更新归到同一个 trace 下
+			ctx, span := tracker.StartSpan(ctx, "refresh", attribute.Bool("refresh.incremental", changed != nil))
+			defer span.End()
+
+			var tokens map[string][]*tracker.TokenData
+			if changed == nil {
+				logger.Debug("执行定时更新")
+				fetched, err := fetchTokens(ctx, walletAddrs, cfg, false)
+				if err != nil {
+					logger.Error("更新代币数据失败", "error", err)
+					return
+				}
+				tokens = fetched
+			} else {
+				changed = trackedWallets(changed, walletAddrs)
+				if len(changed) == 0 {
+					return
+				}
+				logger.Debug("执行增量更新", "wallets", changed)
+				fetched, err := fetchTokens(ctx, changed, cfg, false)
+				if err != nil {
+					logger.Error("更新代币数据失败", "error", err)
+					return
+				}
+				tokens = mergeWalletTokens(lastTokens, fetched, walletAddrs)
+			}
+			lastTokens = tokens
+
+			validTokens, err := updateTokenPrices(ctx, tokens, monitor)
+			if err != nil {
+				logger.Error("更新价格失败", "error", err)
+				return
+			}
+
+			// 更新监控器数据
+			monitor.UpdateTokens(validTokens)
+			writeHTMLReport(monitor, cfg.Settings.DataDir)
+
+			if cfg.Settings.IncludeNFTs {
+				if err := tracker.RefreshNFTPortfolio(ctx, walletAddrs, cfg.Settings.NFTCollections, validTokens); err != nil {
+					logger.Error("NFT估值失败", "error", err)
+				}
+			}
+			if err := tracker.RefreshPerpPositions(ctx, cfg.Settings.Derivatives.HyperliquidAccounts, monitor); err != nil {
+				logger.Error("获取合约仓位失败", "error", err)
+			}
+			// 没有 Helius webhook 推送时轮询本次刷新的聪明钱钱包的新交易
+			if copyTrader != nil && heliusHook == nil {
+				polled := walletAddrs
+				if changed != nil {
+					polled = changed
+				}
+				copyTrader.Poll(ctx, polled)
+			}
+			logger.Debug("定时更新完成", "tokens", len(validTokens))
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("停止定时更新")
+				return
+			case <-ticker.C:
+				// 暂停期间不定时刷新，收到立即刷新请求时照常执行
+				if monitor.Paused() {
+					continue
+				}
+				// WebSocket订阅正常时由账户变化触发更新，断开后恢复轮询
+				if stream != nil && stream.Connected() {
+					logger.Debug("WebSocket订阅正常，跳过定时轮询")
+					continue
+				}
+				updateData(nil)
+			case <-refresh.notify:
+				all, changed := refresh.take()
+				if all {
+					changed = nil
+				}
+				updateData(changed)
+			}
+		}
+	}()
+
+	// 启动终端仪表盘，退出仪表盘时结束程序
+	if useTUI {
+		go func() {
+			if err := tracker.NewDashboard(monitor).Run(ctx); err != nil {
+				logger.Error("仪表盘运行失败", "error", err)
+				fmt.Fprintln(os.Stderr, i18n.T("仪表盘运行失败:"), err)
+			}
+			sigChan <- syscall.SIGINT
+		}()
+	}
+
+	// 等待中断信号
+	<-sigChan
+
+	// 取消进行中的获取和价格更新
+	cancel()
+
+	// 优雅退出
+	if controlSocket != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := controlSocket.Shutdown(shutdownCtx); err != nil {
+			logger.Error("关闭控制 socket 失败", "error", err)
+		}
+		shutdownCancel()
+	}
+	if server != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("关闭HTTP服务失败", "error", err)
+		}
+		shutdownCancel()
+	}
+	monitor.Stop()
+	finalCfg, _ := currentState()
+	if state := finalCfg.Settings.State; state.MaxAge > 0 {
+		if err := monitor.SaveState(state.Path); err != nil {
+			logger.Error("保存监控状态失败", "error", err)
+		}
+	}
+
+	// 发送尚未发出的报警邮件
+	if emailNotifier != nil {
+		if err := emailNotifier.Flush(); err != nil {
+			logger.Error("发送报警邮件失败", "error", err)
+		}
+	}
+
+	// 关闭消息总线
+	if eventBus != nil {
+		if err := eventBus.Close(); err != nil {
+			logger.Error("关闭消息总线失败", "error", err)
+		}
+	}
+
+	// 写入剩余的时序数据
+	if seriesWriter != nil {
+		if err := seriesWriter.Close(); err != nil {
+			logger.Error("关闭时序数据库失败", "error", err)
+		}
+	}
+
+	// 完成当天的 Parquet 文件
+	if parquetExporter != nil {
+		parquetExporter.Close()
+	}
+
+	// 同步最后的持仓表
+	if sheetsSync != nil {
+		sheetsSync.Close()
+	}
+	if fastPrices != nil {
+		fastPrices.Close()
+	}
+
+	logger.Info("程序执行完成")
+}
+
+// adaptiveInterval 返回自适应快照间隔的上下限和波动阈值，未启用时上下限为0
+func adaptiveInterval(s config.Settings) (time.Duration, time.Duration, float64) {
+	if !s.AdaptiveInterval.Enabled {
+		return 0, 0, 0
+	}
+	shortest, longest := s.AdaptiveBounds()
+	return shortest, longest, s.AdaptiveInterval.Threshold
+}
+
+// writeHTMLReport 将当前持仓写入数据目录下的 index.html
+func writeHTMLReport(monitor *tracker.TokenMonitor, dataDir string) {
+	if err := monitor.WriteHTMLReport(filepath.Join(dataDir, "index.html")); err != nil {
+		logger.Error("写入HTML报告失败", "error", err)
+	}
+}
+
+// solanaWallets 返回需要订阅账户变化的 Solana 钱包
+func solanaWallets(cfg *config.Config, walletAddrs []string) []string {
+	var wallets []string
+	for _, addr := range walletAddrs {
+		if cfg.WalletChain(addr) == config.ChainSolana {
+			wallets = append(wallets, addr)
+		}
+	}
+	return wallets
+}
+
+// smartMoneyWallets 返回带有跟单信号标签（或属于该分组）的 Solana 钱包
+func smartMoneyWallets(cfg *config.Config, walletAddrs []string) []string {
+	tracked := make(map[string]bool, len(walletAddrs))
+	for _, addr := range walletAddrs {
+		tracked[addr] = true
+	}
+	var wallets []string
+	for _, w := range cfg.Wallets {
+		if tracked[w.Address] && w.InGroup(cfg.Settings.CopyTrading.Tag) && cfg.WalletChain(w.Address) == config.ChainSolana {
+			wallets = append(wallets, w.Address)
+		}
+	}
+	return wallets
+}
+
+// refreshQueue 合并待更新的钱包，由定时更新的goroutine统一处理
+type refreshQueue struct {
+	mu      sync.Mutex
+	all     bool
+	wallets map[string]bool
+	notify  chan struct{}
+}
+
+func newRefreshQueue() *refreshQueue {
+	return &refreshQueue{wallets: make(map[string]bool), notify: make(chan struct{}, 1)}
+}
+
+// request 请求重新获取指定钱包
+func (q *refreshQueue) request(walletAddrs []string) {
+	q.mu.Lock()
+	for _, addr := range walletAddrs {
+		q.wallets[addr] = true
+	}
+	q.mu.Unlock()
+	q.signal()
+}
+
+// requestAll 请求重新获取所有钱包
+func (q *refreshQueue) requestAll() {
+	q.mu.Lock()
+	q.all = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *refreshQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take 取出待更新的钱包并清空队列
+func (q *refreshQueue) take() (bool, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	all := q.all
+	wallets := make([]string, 0, len(q.wallets))
+	for addr := range q.wallets {
+		wallets = append(wallets, addr)
+	}
+	q.all = false
+	q.wallets = make(map[string]bool)
+	return all, wallets
+}
+
+// trackedWallets 返回 walletAddrs 中仍在跟踪的钱包
+func trackedWallets(walletAddrs, tracked []string) []string {
+	set := make(map[string]bool, len(tracked))
+	for _, addr := range tracked {
+		set[addr] = true
+	}
+	var result []string
+	for _, addr := range walletAddrs {
+		if set[addr] {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
+
+// mergeWalletTokens 用新获取的钱包代币替换上一次的结果，只保留仍在跟踪的钱包
+func mergeWalletTokens(previous, fetched map[string][]*tracker.TokenData, walletAddrs []string) map[string][]*tracker.TokenData {
+	merged := make(map[string][]*tracker.TokenData, len(walletAddrs))
+	for _, addr := range walletAddrs {
+		if tokens, ok := fetched[addr]; ok {
+			merged[addr] = tokens
+		} else if tokens, ok := previous[addr]; ok {
+			merged[addr] = tokens
+		}
+	}
+	return merged
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateAddress 按所在的链检查钱包地址格式：Solana 地址为 base58 编码的32字节公钥或 .sol 域名，
+// EVM 地址为 0x 加40位十六进制，交易所账户为交易所名称；chain 为空时根据地址推断
+func ValidateAddress(address, chain string) error {
+	if address == "" {
+		return fmt.Errorf("地址不能为空")
+	}
+	if chain == "" {
+		chain = DetectChain(address)
+	}
+
+	switch chain {
+	case ChainExchange:
+		if !IsExchange(address) {
+			return fmt.Errorf("不是支持的交易所（可选 binance/okx/coinbase）")
+		}
+	case ChainEthereum, ChainBase:
+		if len(address) != 42 || !strings.HasPrefix(address, "0x") || strings.Trim(address[2:], "0123456789abcdefABCDEF") != "" {
+			return fmt.Errorf("不是有效的 EVM 地址（应为 0x 加40位十六进制）")
+		}
+	default:
+		if strings.HasSuffix(strings.ToLower(address), ".sol") {
+			return validateSNSDomain(address)
+		}
+		key, err := base58.Decode(address)
+		if err != nil {
+			return fmt.Errorf("不是有效的 Solana 地址（包含 base58 以外的字符）")
+		}
+		if len(key) != 32 {
+			return fmt.Errorf("不是有效的 Solana 地址（解码后为 %d 字节，应为32字节）", len(key))
+		}
+	}
+	return nil
+}
+
+// validateSNSDomain 检查 .sol 域名格式：name.sol 或 sub.name.sol
+func validateSNSDomain(domain string) error {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), ".sol"), ".")
+	if len(labels) > 2 {
+		return fmt.Errorf("不是有效的 .sol 域名（最多支持一级子域名）")
+	}
+	for _, label := range labels {
+		if label == "" || strings.ContainsAny(label, " \t/") {
+			return fmt.Errorf("不是有效的 .sol 域名")
+		}
+	}
+	return nil
+}
+
+// walletSourceLines 返回配置文件中各钱包地址所在的行号和该行内容（地址 -> 行），无法解析时返回nil
+func walletSourceLines(data []byte) map[string]sourceLine {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "wallets" || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		result := make(map[string]sourceLine)
+		for _, item := range root.Content[i+1].Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(item.Content); j += 2 {
+				key, value := item.Content[j], item.Content[j+1]
+				if key.Value != "address" || value.Line < 1 || value.Line > len(lines) {
+					continue
+				}
+				if _, ok := result[value.Value]; !ok {
+					result[value.Value] = sourceLine{number: value.Line, text: strings.TrimSpace(lines[value.Line-1])}
+				}
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// sourceLine 配置文件中的一行
+type sourceLine struct {
+	number int
+	text   string
+}
+
+// validateWallets 检查钱包的链和地址格式；skipInvalid 为 true 时跳过无效的钱包并输出警告，否则返回第一个错误，
+// 错误中包含钱包在配置文件中的行号和该行内容（钱包来自环境变量时没有行号）
+func (c *Config) validateWallets(lines map[string]sourceLine, skipInvalid bool) error {
+	valid := c.Wallets[:0]
+	for i, w := range c.Wallets {
+		var err error
+		if w.Chain != "" && !validChains[w.Chain] {
+			err = fmt.Errorf("chain 无效: %s", w.Chain)
+		} else if addrErr := ValidateAddress(w.Address, w.Chain); addrErr != nil {
+			err = fmt.Errorf("地址 %q %v", w.Address, addrErr)
+		}
+		if err == nil {
+			valid = append(valid, w)
+			continue
+		}
+
+		location := fmt.Sprintf("wallets[%d]", i)
+		if line, ok := lines[w.Address]; ok {
+			location += fmt.Sprintf("（第 %d 行: %s）", line.number, line.text)
+		}
+		if !skipInvalid {
+			return fmt.Errorf("%s 无效: %v", location, err)
+		}
+		logger.Warn("跳过无效的钱包", "wallet", location, "error", err)
+	}
+	c.Wallets = valid
+	return nil
+}
@@ -1,33 +1,60 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
+
 	"gopkg.in/yaml.v3"
 )
 
-// TokenMetadataCache 代币元数据缓存
+// TokenMetadataCache 代币元数据缓存，设置了文件路径时保存到磁盘，重启后无需重新获取
 type TokenMetadataCache struct {
 	data  map[string]*TokenMetadata
 	mutex sync.RWMutex
+	path  string        // 缓存文件路径，为空表示只缓存在内存中
+	ttl   time.Duration // 元数据的有效期
+	dirty bool          // 是否有尚未保存的更新
 }
 
 // TokenMetadata 代币元数据
 type TokenMetadata struct {
-	Symbol    string
-	Name      string
-	Decimals  int
-	Price     float64
-	UpdatedAt time.Time
+	Symbol    string    `json:"symbol,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Decimals  int       `json:"decimals,omitempty"`
+	Price     float64   `json:"-"`
+	Source    string    `json:"source,omitempty"` // 元数据来源：das / metaplex
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // WalletConfig 存储单个钱包的配置
 type WalletConfig struct {
-	Address string `yaml:"address"`
-	Label   string `yaml:"label"`
+	Address string   `yaml:"address"` // 钱包地址，也可以是 .sol 域名（Bonfida SNS），加载时解析为所有者地址；binance/okx/coinbase 表示交易所现货账户
+	Label   string   `yaml:"label"`
+	Domain  string   `yaml:"-"`     // 地址由 .sol 域名解析而来时为原域名
+	Chain   string   `yaml:"chain"` // solana/ethereum/base，为空时根据地址推断
+	Group   string   `yaml:"group"` // 所属分组，如 trading、cold-storage
+	Tags    []string `yaml:"tags"`  // 标签，可用于 -group 筛选
+}
+
+// InGroup 判断钱包是否属于指定分组或带有该标签
+func (w WalletConfig) InGroup(name string) bool {
+	if w.Group == name {
+		return true
+	}
+	for _, tag := range w.Tags {
+		if tag == name {
+			return true
+		}
+	}
+	return false
 }
 
 // TokenConfig 存储代币配置
@@ -36,23 +63,1129 @@ type TokenConfig struct {
 	Symbol  string `yaml:"symbol"`
 	Name    string `yaml:"name"`
 	Decimal int    `yaml:"decimal"`
+	Chain   string `yaml:"chain"` // 代币所在的链，为空表示 solana
+}
+
+// 支持的链
+const (
+	ChainSolana   = "solana"
+	ChainEthereum = "ethereum"
+	ChainBase     = "base"
+)
+
+var validChains = map[string]bool{
+	ChainSolana:   true,
+	ChainEthereum: true,
+	ChainBase:     true,
+}
+
+// ChainExchange 中心化交易所：钱包地址为交易所名称的现货账户，以及交易所中没有对应链上代币的资产
+const ChainExchange = "exchange"
+
+// 支持的中心化交易所，API 密钥通过环境变量提供，只需要读取权限
+const (
+	ExchangeBinance  = "binance"
+	ExchangeOKX      = "okx"
+	ExchangeCoinbase = "coinbase"
+)
+
+// ExchangeCredentialEnvs 各交易所 API 凭证所需的环境变量
+var ExchangeCredentialEnvs = map[string][]string{
+	ExchangeBinance:  {"BINANCE_API_KEY", "BINANCE_API_SECRET"},
+	ExchangeOKX:      {"OKX_API_KEY", "OKX_API_SECRET", "OKX_API_PASSPHRASE"},
+	ExchangeCoinbase: {"COINBASE_API_KEY", "COINBASE_API_SECRET"},
+}
+
+// ExchangeAssetPrefix 交易所中没有对应链上代币的资产标识前缀，如 cex:BTC
+const ExchangeAssetPrefix = "cex:"
+
+// IsExchange 判断钱包地址是否为交易所账户
+func IsExchange(address string) bool {
+	_, ok := ExchangeCredentialEnvs[strings.ToLower(address)]
+	return ok
+}
+
+// DetectChain 根据地址格式推断所在的链：0x开头的地址视为以太坊，交易所名称和 cex: 开头的资产视为交易所，其余视为 solana
+func DetectChain(address string) string {
+	if len(address) == 42 && strings.HasPrefix(address, "0x") {
+		return ChainEthereum
+	}
+	if IsExchange(address) || strings.HasPrefix(address, ExchangeAssetPrefix) {
+		return ChainExchange
+	}
+	return ChainSolana
+}
+
+// Settings 存储运行参数
+type Settings struct {
+	MonitorInterval         time.Duration     `yaml:"monitor_interval"`          // 监控快照间隔
+	RefreshInterval         time.Duration     `yaml:"refresh_interval"`          // 代币列表刷新间隔
+	MaxConcurrentWallets    int               `yaml:"max_concurrent_wallets"`    // 并发获取的钱包数量
+	WalletFetchDelay        time.Duration     `yaml:"wallet_fetch_delay"`        // 相邻两个钱包开始获取之间的最小间隔，负数表示不限制
+	PriceBatchSize          int               `yaml:"price_batch_size"`          // 价格查询的批量大小
+	PriceSources            []string          `yaml:"price_sources"`             // 并发查询的价格数据源: jupiter/dexscreener/birdeye
+	FallbackPriceSources    []string          `yaml:"fallback_price_sources"`    // 主数据源缺失价格时使用的备用数据源
+	PreferredPriceSources   []string          `yaml:"preferred_price_sources"`   // 优先使用的数据源（如 pyth），有可信价格时直接采用
+	PythFeeds               map[string]string `yaml:"pyth_feeds"`                // 额外的 mint 地址到 Pyth 价格源ID映射
+	CoinGecko               CoinGecko         `yaml:"coingecko"`                 // CoinGecko 价格源和交叉验证设置
+	SQLitePath              string            `yaml:"sqlite_path"`               // 快照数据库路径，为空则写入CSV
+	TimeSeries              TimeSeries        `yaml:"timeseries"`                // 写入 InfluxDB/TimescaleDB 的时序数据输出
+	Parquet                 Parquet           `yaml:"parquet"`                   // 按天导出快照的 Parquet 文件
+	Sheets                  Sheets            `yaml:"sheets"`                    // 同步持仓表和每日汇总到 Google Sheets
+	EventBus                EventBus          `yaml:"event_bus"`                 // 发布快照和报警事件的 Kafka/NATS 消息总线
+	Redis                   Redis             `yaml:"redis"`                     // 多个实例共享价格缓存、发布报警和报警去重
+	Tracing                 Tracing           `yaml:"tracing"`                   // OpenTelemetry 追踪设置
+	Health                  Health            `yaml:"health"`                    // 健康检查端点的判定阈值
+	TopMovers               TopMovers         `yaml:"top_movers"`                // 报告中的涨跌榜
+	ChangeColumns           []time.Duration   `yaml:"change_columns"`            // 报告中价格变化率列的时间窗口，默认 1m、5m、1h，设为 [] 表示不显示
+	ReportColor             string            `yaml:"report_color"`              // 报告中的涨跌颜色: auto/always/never，auto 在输出到终端且未设置 NO_COLOR 时启用
+	AlertCooldown           time.Duration     `yaml:"alert_cooldown"`            // 同一报警的抑制时长，负数表示不抑制
+	AlertWindows            []time.Duration   `yaml:"alert_windows"`             // 报警检查的时间窗口
+	BuiltinAlerts           *bool             `yaml:"builtin_alerts"`            // 是否启用内置的单币价格/价值变化报警，默认 true；只使用 rules 时可关闭
+	HistorySize             int               `yaml:"history_size"`              // 历史快照缓冲区容量，0表示根据最长窗口自动推算
+	MaxTokenAccounts        int               `yaml:"max_token_accounts"`        // 单个钱包分页获取的代币账户数量上限
+	MetadataCachePath       string            `yaml:"metadata_cache_path"`       // 代币元数据（符号、名称、精度）的缓存文件，为空表示只缓存在内存中
+	MetadataCacheTTL        time.Duration     `yaml:"metadata_cache_ttl"`        // 缓存的代币元数据的有效期
+	IncludeNFTs             bool              `yaml:"include_nfts"`              // 是否获取并估值NFT（会增加API调用）
+	NFTCollections          map[string]string `yaml:"nft_collections"`           // NFT集合地址到 Magic Eden 集合符号的映射，用于查询地板价
+	IncludeDeFi             bool              `yaml:"include_defi"`              // 是否识别借贷存款和LP仓位并计入持仓（会增加RPC调用）
+	ImpermanentLossPct      float64           `yaml:"impermanent_loss_pct"`      // LP仓位相对持有存入资产不动的损失超过该比例（%）时报警，0表示关闭
+	PositionReduceThreshold float64           `yaml:"position_reduce_threshold"` // 减仓报警阈值（百分比），0表示只在清仓时报警
+	AirdropAlertValue       float64           `yaml:"airdrop_alert_value"`       // 钱包没有花费却出现的新代币价值达到该值（美元）时按疑似空投报警，负数表示按普通新代币报警
+	TrailingStopPct         float64           `yaml:"trailing_stop_pct"`         // 价格从开始监控以来的最高价回撤超过该比例（%）时报警，0表示关闭
+	LogLevel                string            `yaml:"log_level"`                 // 日志级别: debug/info/warn/alert/error，为空时使用 LOG_LEVEL 环境变量
+	LogFormat               string            `yaml:"log_format"`                // 日志格式: text/json
+	Language                string            `yaml:"language"`                  // 日志、报告和报警的语言: zh/en，为空时根据 LANG 环境变量选择
+	LogRotation             LogRotation       `yaml:"log_rotation"`              // 日志和监控输出文件的轮转设置
+	LogFile                 string            `yaml:"log_file"`                  // 日志文件路径，"-" 表示只输出到标准错误（适合容器）
+	DataDir                 string            `yaml:"data_dir"`                  // 监控CSV、报警日志、HTML报告、盈亏基准和默认状态/元数据缓存文件所在目录
+	PriceCache              PriceCache        `yaml:"price_cache"`               // 价格缓存设置
+	StalePriceMaxAge        time.Duration     `yaml:"stale_price_max_age"`       // 数据源缺失价格时沿用上次价格的最长时间，负数表示不沿用
+	HTTP                    HTTPSettings      `yaml:"http"`                      // 共享HTTP客户端设置
+	CircuitBreaker          CircuitBreaker    `yaml:"circuit_breaker"`           // Helius/Jupiter 熔断设置
+	RPC                     RPC               `yaml:"rpc"`                       // 获取代币账户的备用 Solana RPC 端点
+	RealtimeUpdates         bool              `yaml:"realtime_updates"`          // 通过 WebSocket 订阅 Solana 钱包账户变化，连接正常时不再定时轮询
+	HeliusWebhook           HeliusWebhook     `yaml:"helius_webhook"`            // 接收 Helius 交易推送的设置
+	Liquidity               Liquidity         `yaml:"liquidity"`                 // 流动性和市场深度设置
+	TokenSafety             TokenSafety       `yaml:"token_safety"`              // 代币安全检查设置
+	Summaries               Summaries         `yaml:"summaries"`                 // 每日/每周汇总报告设置
+	Stablecoins             Stablecoins       `yaml:"stablecoins"`               // 稳定币估值和脱锚监控设置
+	Severity                Severity          `yaml:"severity"`                  // 报警级别、按级别路由和静默时段
+	State                   State             `yaml:"state"`                     // 退出时保存、启动时恢复的监控状态
+	SNS                     SNS               `yaml:"sns"`                       // 钱包 .sol 域名的解析缓存和重新解析间隔
+	SkipInvalidWallets      bool              `yaml:"skip_invalid_wallets"`      // 跳过地址格式无效的钱包并输出警告，默认加载配置失败
+
+	FastPrices       FastPrices       `yaml:"fast_prices"`       // 以更短的间隔轮询价值最高的代币的价格，价格报警不必等待下一次快照
+	AdaptiveInterval AdaptiveInterval `yaml:"adaptive_interval"` // 根据波动自动调整快照间隔
+	ControlSocket    string           `yaml:"control_socket"`    // tracker ctl 使用的 unix socket 路径，默认为数据目录下的 control.sock，"-" 表示不监听
+	DebugPprof       bool             `yaml:"debug_pprof"`       // 在 -serve 的HTTP服务上提供 /debug/pprof/ 分析端点，需设置 TRACKER_CONTROL_TOKEN，默认关闭
+
+	Derivatives Derivatives `yaml:"derivatives"` // 永续合约仓位跟踪和强平报警
+
+	CopyTrading CopyTrading `yaml:"copy_trading"` // 聪明钱钱包的跟单信号输出
+}
+
+// CopyTrading 跟单信号设置：带有标签的钱包的兑换交易以 trade_signal 事件发送到 webhook 和/或消息总线，
+// 交易来自 Helius webhook 推送，未启用时在每次刷新后轮询；webhook 和 topic 都为空表示不输出
+type CopyTrading struct {
+	Tag      string  `yaml:"tag"`       // 带有该标签或属于该分组的钱包视为聪明钱
+	Webhook  string  `yaml:"webhook"`   // 接收信号的 webhook 地址，支持 ${ENV}；设置 SIGNAL_WEBHOOK_SECRET 环境变量时附带 HMAC 签名
+	Topic    string  `yaml:"topic"`     // 通过 event_bus 发布信号的 topic/subject
+	MinValue float64 `yaml:"min_value"` // 估算成交价值低于该值（美元）的信号不发送，价值未知的信号始终发送
+}
+
+// Enabled 返回是否输出跟单信号
+func (c CopyTrading) Enabled() bool {
+	return c.Webhook != "" || c.Topic != ""
+}
+
+// Derivatives 永续合约仓位设置：仓位单独显示在报告的合约部分，不计入组合总价值
+type Derivatives struct {
+	HyperliquidAccounts []string `yaml:"hyperliquid_accounts"`  // 要跟踪的 Hyperliquid 账户地址（0x开头）
+	LiquidationAlertPct float64  `yaml:"liquidation_alert_pct"` // 标记价格距强平价小于该比例（%）时报警，负数表示不报警
+}
+
+// AdaptiveInterval 自适应快照间隔：波动超过阈值时缩短间隔，平静时逐步延长，始终在 min 和 max 之间
+type AdaptiveInterval struct {
+	Enabled   bool          `yaml:"enabled"`   // 是否启用，关闭时固定使用 monitor_interval
+	Min       time.Duration `yaml:"min"`       // 最短间隔，0表示 monitor_interval 的1/4
+	Max       time.Duration `yaml:"max"`       // 最长间隔，0表示 monitor_interval 的3倍
+	Threshold float64       `yaml:"threshold"` // 按价值加权的价格波动（%，折算到一个 monitor_interval）超过该值时缩短间隔
+}
+
+// FastPrices 快速价格轮询设置：价值最高的若干代币在快照之间也检查价格报警
+type FastPrices struct {
+	Top      int           `yaml:"top"`      // 轮询价值最高的多少个代币（不含稳定币），0表示关闭
+	Interval time.Duration `yaml:"interval"` // 轮询间隔，需小于 monitor_interval
+}
+
+// SNS 钱包 .sol 域名（Bonfida SNS）解析设置
+type SNS struct {
+	CachePath       string        `yaml:"cache_path"`       // 解析结果缓存文件，默认为数据目录下的 sns.json
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // 重新解析域名的间隔，用于发现域名转让给新的所有者
+}
+
+// Stablecoins 稳定币设置：默认按 $1 估值，同时监控实际市场价格，偏离超过范围时报警
+type Stablecoins struct {
+	Mints           []string `yaml:"mints"`            // 视为稳定币的 mint 地址，为空时使用 USDC/USDT/PYUSD
+	PegBand         float64  `yaml:"peg_band"`         // 脱锚报警范围（%），负数表示不报警
+	MarketValuation bool     `yaml:"market_valuation"` // 按实际市场价格估值，而不是按 $1
+}
+
+// CoinGecko CoinGecko 价格源设置，可作为价格数据源（名称 coingecko）或 Jupiter 的交叉验证数据源
+type CoinGecko struct {
+	IDs                 map[string]string `yaml:"ids"`                  // 额外的 mint 地址到 CoinGecko 币种ID映射
+	ContractLookup      bool              `yaml:"contract_lookup"`      // 未映射币种ID的 Solana 代币按合约地址查询
+	CrossCheck          bool              `yaml:"cross_check"`          // 作为交叉验证数据源，偏离过大的价格降低可信度
+	DivergenceThreshold float64           `yaml:"divergence_threshold"` // 交叉验证偏离阈值（%）
+	DivergenceAlert     bool              `yaml:"divergence_alert"`     // 偏离在窗口内持续超过阈值且不断扩大时报警
+	DivergenceWindow    time.Duration     `yaml:"divergence_window"`    // 偏离报警的观察窗口
+}
+
+// TimeSeries 时序数据库输出设置：每个快照写入组合总值和各代币的价格、数量、价值，用于在 Grafana 中查看长期走势
+type TimeSeries struct {
+	Driver        string        `yaml:"driver"`         // influxdb / timescale，为空表示不输出
+	URL           string        `yaml:"url"`            // InfluxDB 地址或 TimescaleDB 连接串（postgres://...），支持 ${ENV} 环境变量
+	Database      string        `yaml:"database"`       // InfluxDB 1.x 的数据库名
+	Org           string        `yaml:"org"`            // InfluxDB 2.x 的组织
+	Bucket        string        `yaml:"bucket"`         // InfluxDB 2.x 的 bucket，设置后使用 2.x 写入接口
+	Token         string        `yaml:"token"`          // InfluxDB 的API令牌，支持 ${ENV} 环境变量
+	BatchSize     int           `yaml:"batch_size"`     // 累积多少个数据点后立即写入
+	FlushInterval time.Duration `yaml:"flush_interval"` // 未达到批量大小时的最长写入间隔
+	MaxRetries    int           `yaml:"max_retries"`    // 写入失败的重试次数，负数表示不重试
+	MaxBuffer     int           `yaml:"max_buffer"`     // 数据库不可用时最多缓存的数据点，超过后丢弃最旧的
+}
+
+// Parquet 按天写入快照 Parquet 文件的设置，用于 DuckDB/pandas 等工具分析长期数据
+type Parquet struct {
+	Enabled       bool          `yaml:"enabled"`        // 是否导出
+	Dir           string        `yaml:"dir"`            // 输出目录，默认为数据目录下的 parquet
+	FlushInterval time.Duration `yaml:"flush_interval"` // 将缓存的行写入文件的间隔
+}
+
+// Sheets Google Sheets 同步设置：定时将当前持仓表写入一个工作表，每天结束时向另一个工作表追加一行当天的汇总
+type Sheets struct {
+	SpreadsheetID   string        `yaml:"spreadsheet_id"`   // 表格ID（URL 中 /d/ 与 /edit 之间的部分），为空表示不同步
+	CredentialsFile string        `yaml:"credentials_file"` // 服务账号密钥文件（JSON），支持 ${ENV} 环境变量；表格需共享给该服务账号
+	HoldingsSheet   string        `yaml:"holdings_sheet"`   // 写入持仓表的工作表，不存在时自动创建
+	SummarySheet    string        `yaml:"summary_sheet"`    // 追加每日汇总的工作表，不存在时自动创建
+	Interval        time.Duration `yaml:"interval"`         // 同步持仓表的间隔
+}
+
+// 支持的时序数据库
+const (
+	TimeSeriesInfluxDB  = "influxdb"
+	TimeSeriesTimescale = "timescale"
+)
+
+// EventBus 消息总线设置：快照和报警事件以JSON发布到 Kafka topic 或 NATS subject，报警作为名为 eventbus 的通知渠道
+type EventBus struct {
+	Driver        string   `yaml:"driver"`         // kafka / nats，为空表示不发布
+	Brokers       []string `yaml:"brokers"`        // Kafka broker 地址（host:port）或 NATS 服务器地址（nats://...），支持 ${ENV} 环境变量
+	SnapshotTopic string   `yaml:"snapshot_topic"` // 快照事件的 topic/subject
+	AlertTopic    string   `yaml:"alert_topic"`    // 报警事件的 topic/subject
+}
+
+// Tracing OpenTelemetry 追踪设置：钱包获取、DAS/RPC 请求和价格批次的 span 通过 OTLP/HTTP 导出
+type Tracing struct {
+	Enabled     bool    `yaml:"enabled"`      // 是否启用
+	Endpoint    string  `yaml:"endpoint"`     // OTLP/HTTP 接收地址（host:port），为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT 环境变量或 localhost:4318
+	Insecure    bool    `yaml:"insecure"`     // 使用 HTTP 而不是 HTTPS 连接
+	ServiceName string  `yaml:"service_name"` // 上报的服务名
+	SampleRatio float64 `yaml:"sample_ratio"` // 采样比例，范围 (0, 1]
+}
+
+// Health /healthz 和 /readyz 的判定阈值，0表示根据监控和刷新间隔自动推算
+type Health struct {
+	MaxSnapshotAge time.Duration `yaml:"max_snapshot_age"` // 快照循环超过该时长没有完成时 /healthz 返回503，默认为5个监控间隔
+	MaxFetchAge    time.Duration `yaml:"max_fetch_age"`    // 超过该时长没有成功获取钱包持仓时 /readyz 返回503，默认为3个刷新间隔，负数表示不检查
+}
+
+// HealthLimits 返回实际使用的快照和获取时长上限；启用实时更新时钱包没有变化就不会重新获取，默认不检查获取时长
+func (s Settings) HealthLimits() (maxSnapshotAge, maxFetchAge time.Duration) {
+	maxSnapshotAge = s.Health.MaxSnapshotAge
+	if maxSnapshotAge == 0 {
+		maxSnapshotAge = 5 * s.MonitorInterval
+		if s.AdaptiveInterval.Enabled {
+			_, longest := s.AdaptiveBounds()
+			maxSnapshotAge = 5 * longest
+		}
+	}
+	maxFetchAge = s.Health.MaxFetchAge
+	if maxFetchAge == 0 {
+		maxFetchAge = 3 * s.RefreshInterval
+		if s.RealtimeUpdates {
+			maxFetchAge = -1
+		}
+	}
+	return maxSnapshotAge, maxFetchAge
+}
+
+// AdaptiveBounds 返回自适应快照间隔实际使用的最短和最长间隔，未设置时根据 monitor_interval 推算
+func (s Settings) AdaptiveBounds() (shortest, longest time.Duration) {
+	shortest, longest = s.AdaptiveInterval.Min, s.AdaptiveInterval.Max
+	if shortest == 0 {
+		shortest = s.MonitorInterval / 4
+	}
+	if longest == 0 {
+		longest = 3 * s.MonitorInterval
+	}
+	return shortest, longest
+}
+
+// TopMovers 报告中按时间窗口统计的价格涨跌榜
+type TopMovers struct {
+	Windows []time.Duration `yaml:"windows"` // 统计涨跌幅的时间窗口，默认 5m、1h、24h
+	Count   int             `yaml:"count"`   // 每个榜单显示的代币数，默认5，负数表示不显示涨跌榜
+}
+
+// Redis 多个实例协作的 Redis 设置
+type Redis struct {
+	URL          string `yaml:"url"`           // redis://[:password@]host:6379/0，支持 ${ENV} 环境变量，为空表示不使用
+	KeyPrefix    string `yaml:"key_prefix"`    // 键名前缀，多套部署共用一个 Redis 时用于区分
+	PriceCache   bool   `yaml:"price_cache"`   // 作为多个实例共享的价格缓存，新鲜期与 price_cache 相同
+	AlertChannel string `yaml:"alert_channel"` // 发布报警事件的频道，作为名为 redis 的通知渠道，为空表示不发布
+	DedupeAlerts bool   `yaml:"dedupe_alerts"` // 多个实例跟踪相同钱包时，同一报警只由最先触发的实例发送
+}
+
+// 支持的消息总线
+const (
+	EventBusKafka = "kafka"
+	EventBusNATS  = "nats"
+)
+
+// Summaries 每日/每周汇总报告设置，汇总基于快照数据库，通过已配置的通知渠道发送
+type Summaries struct {
+	Daily   bool   `yaml:"daily"`   // 是否发送每日汇总
+	Weekly  bool   `yaml:"weekly"`  // 是否发送每周汇总
+	Time    string `yaml:"time"`    // 发送时间（HH:MM，本地时间），汇总截止到该时间
+	Weekday string `yaml:"weekday"` // 每周汇总的发送日，如 monday
+}
+
+// ParseWeekday 解析星期名称（如 monday、Mon），不区分大小写
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("未知的星期: %s", name)
+}
+
+// TokenSafety 代币安全检查设置：检查新出现代币的增发权限、冻结权限和LP锁定情况，在报告和报警中标记高风险代币
+type TokenSafety struct {
+	Enabled         bool          `yaml:"enabled"`          // 是否启用
+	RiskThreshold   int           `yaml:"risk_threshold"`   // 风险分（0-100）达到该值的代币视为高风险
+	RecheckInterval time.Duration `yaml:"recheck_interval"` // 检查结果的有效期，过期后重新检查
+	Trusted         []string      `yaml:"trusted"`          // 不做检查的代币mint地址（主流稳定币已内置）
+}
+
+// Liquidity 持仓代币的流动性和市场深度设置，用于报告展示和撤池预警
+type Liquidity struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // 流动性数据的缓存时长，负数表示不获取流动性
+	MinUSD          float64       `yaml:"min_usd"`          // 持仓代币流动性跌破该值（美元）时报警，0表示关闭
+	DropPct         float64       `yaml:"drop_pct"`         // 两次检查间流动性下降超过该比例（百分比）时报警，0表示关闭
+}
+
+// HeliusWebhook 接收 Helius 增强交易 webhook 的设置，需要同时使用 -serve 启动HTTP服务
+type HeliusWebhook struct {
+	Enabled bool   `yaml:"enabled"` // 是否启用
+	Path    string `yaml:"path"`    // 接收推送的路径
+}
+
+// CircuitBreaker 外部API熔断设置：连续失败达到阈值后暂停请求，冷却后再探测
+type CircuitBreaker struct {
+	FailureThreshold int           `yaml:"failure_threshold"` // 连续失败次数阈值，负数表示关闭熔断
+	Cooldown         time.Duration `yaml:"cooldown"`          // 熔断后等待多久发送探测请求
+}
+
+// RPC 备用 Solana RPC 端点设置：Helius 端点总是排在第一个，连续失败的端点暂时跳过，恢复后重新使用
+type RPC struct {
+	Endpoints           []string      `yaml:"endpoints"`             // 备用端点地址（如 Triton、公共RPC），可包含API密钥，支持 ${ENV} 环境变量
+	Strategy            string        `yaml:"strategy"`              // failover：使用第一个可用端点；round_robin：轮流使用可用端点
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"` // 主动健康检查间隔，负数表示只根据请求结果判断
+}
+
+// 报告颜色模式
+const (
+	ReportColorAuto   = "auto"
+	ReportColorAlways = "always"
+	ReportColorNever  = "never"
+)
+
+// RPC 端点选择策略
+const (
+	RPCStrategyFailover   = "failover"
+	RPCStrategyRoundRobin = "round_robin"
+)
+
+// HTTPSettings 访问 Helius、Jupiter 等API时共用的HTTP客户端设置
+type HTTPSettings struct {
+	Timeout             time.Duration      `yaml:"timeout"`                 // 数据API请求的超时时间
+	NotifyTimeout       time.Duration      `yaml:"notify_timeout"`          // 通知渠道请求的超时时间
+	MaxIdleConnsPerHost int                `yaml:"max_idle_conns_per_host"` // 每个主机保留的空闲连接数
+	RateLimits          map[string]float64 `yaml:"rate_limits"`             // 主机名到每秒最大请求数的映射，0表示不限流
+	RateLimitBursts     map[string]int     `yaml:"rate_limit_bursts"`       // 主机名到空闲后允许突发的请求数的映射，未列出的主机为1
+}
+
+// PriceCache 价格缓存设置，减少对价格API的重复查询
+type PriceCache struct {
+	TTL               time.Duration `yaml:"ttl"`                 // 价格的新鲜期，负数表示关闭缓存
+	LongTailTTL       time.Duration `yaml:"long_tail_ttl"`       // 长尾代币（流动性低或没有价格）的新鲜期
+	StaleTTL          time.Duration `yaml:"stale_ttl"`           // 过期后先返回旧价格并在后台刷新的时长
+	LongTailLiquidity float64       `yaml:"long_tail_liquidity"` // 流动性低于该值（美元）的代币视为长尾代币
+}
+
+// LogRotation 日志文件和监控输出（CSV、报警日志）的轮转设置
+type LogRotation struct {
+	MaxSizeMB  int           `yaml:"max_size_mb"` // 单个文件的最大大小（MB），超过后轮转
+	MaxAge     time.Duration `yaml:"max_age"`     // 单个文件的最长写入时间（如 24h），0表示不按时间轮转
+	MaxBackups int           `yaml:"max_backups"` // 保留的备份数量，0表示全部保留
+	Compress   bool          `yaml:"compress"`    // 是否用 gzip 压缩备份
+	DailyCSV   *bool         `yaml:"daily_csv"`   // 监控CSV（monitor.csv、portfolio.csv）是否在每天零点轮转，默认 true
+}
+
+// RotateConfig 转换为文件轮转设置
+func (r LogRotation) RotateConfig() logging.RotateConfig {
+	return logging.RotateConfig{
+		MaxSize:    int64(r.MaxSizeMB) * 1024 * 1024,
+		MaxAge:     r.MaxAge,
+		MaxBackups: r.MaxBackups,
+		Compress:   r.Compress,
+	}
+}
+
+// CSVRotateConfig 转换为监控CSV的轮转设置，默认在大小和时长限制之外按天轮转
+func (r LogRotation) CSVRotateConfig() logging.RotateConfig {
+	cfg := r.RotateConfig()
+	cfg.Daily = r.DailyCSV == nil || *r.DailyCSV
+	return cfg
+}
+
+// 支持的价格数据源
+var validPriceSources = map[string]bool{
+	"jupiter":     true,
+	"dexscreener": true,
+	"birdeye":     true,
+	"pyth":        true,
+	"coingecko":   true,
+}
+
+// 运行参数默认值
+const (
+	DefaultMonitorInterval      = 20 * time.Second
+	DefaultRefreshInterval      = 5 * time.Minute
+	DefaultMaxConcurrentWallets = 3
+	DefaultWalletFetchDelay     = 500 * time.Millisecond
+	DefaultPriceBatchSize       = 100
+	DefaultAlertCooldown        = 5 * time.Minute
+	DefaultTopMoversCount       = 5
+	DefaultSeverityWarnPct      = 10.0
+	DefaultDataDir              = "reports"
+	DefaultLogFile              = "wallet-tracker.log"
+	DefaultStalePriceMaxAge     = 30 * time.Minute
+	DefaultMetadataCacheTTL     = 7 * 24 * time.Hour
+	DefaultStateMaxAge          = time.Hour
+	DefaultSeverityCriticalPct  = 25.0
+	DefaultMaxTokenAccounts     = 10000
+	DefaultLogMaxSizeMB         = 100
+	DefaultLogMaxBackups        = 5
+	DefaultPriceCacheTTL        = 10 * time.Second
+	DefaultLongTailTTL          = 5 * time.Minute
+	DefaultPriceStaleTTL        = 10 * time.Minute
+	DefaultLongTailLiquidity    = 50000
+	DefaultHTTPTimeout          = 30 * time.Second
+	DefaultNotifyTimeout        = 10 * time.Second
+	DefaultMaxIdleConnsPerHost  = 10
+	DefaultBreakerThreshold     = 5
+	DefaultBreakerCooldown      = time.Minute
+	DefaultRPCHealthCheck       = 30 * time.Second
+	DefaultHeliusWebhookPath    = "/webhooks/helius"
+	DefaultLiquidityRefresh     = time.Minute
+	DefaultRiskThreshold        = 50
+	DefaultSafetyRecheck        = 24 * time.Hour
+	DefaultSummaryTime          = "00:00"
+	DefaultDivergenceThreshold  = 5.0
+	DefaultPegBand              = 0.5
+	DefaultDivergenceWindow     = 5 * time.Minute
+	DefaultSummaryWeekday       = "monday"
+	DefaultTimeSeriesBatch      = 500
+	DefaultSnapshotTopic        = "wallet-tracker.snapshots"
+	DefaultRedisKeyPrefix       = "wallet-tracker"
+	DefaultTracingServiceName   = "wallet-tracker"
+	DefaultAlertTopic           = "wallet-tracker.alerts"
+	DefaultTimeSeriesFlush      = 10 * time.Second
+	DefaultTimeSeriesRetries    = 3
+	DefaultTimeSeriesBuffer     = 50000
+	DefaultParquetFlush         = 10 * time.Minute
+	DefaultSheetsHoldings       = "Holdings"
+	DefaultSheetsSummary        = "Daily"
+	DefaultSheetsInterval       = 5 * time.Minute
+	DefaultSNSRefresh           = time.Hour
+	DefaultFastPriceInterval    = 5 * time.Second
+	DefaultAdaptiveThreshold    = 1.0
+	DefaultLiquidationAlertPct  = 10.0
+	DefaultAirdropAlertValue    = 10.0
+	DefaultCopyTradingTag       = "smart_money"
+)
+
+// DefaultStablecoins 默认视为稳定币的 mint 地址（USDC、USDT、PYUSD）
+var DefaultStablecoins = []string{
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB",
+	"2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo",
+}
+
+// DefaultRateLimits 各API主机的默认限流（每秒请求数），按免费额度设置
+var DefaultRateLimits = map[string]float64{
+	"mainnet.helius-rpc.com": 10,
+	"api.helius.xyz":         10,
+	"api.jup.ag":             10,
+	"api.dexscreener.com":    5,
+	"api.rugcheck.xyz":       2,
+	"api.coingecko.com":      0.5,
+}
+
+// TradeConfig 手动录入的交易记录
+type TradeConfig struct {
+	Mint   string    `yaml:"mint"`
+	Side   string    `yaml:"side"` // buy / sell
+	Amount float64   `yaml:"amount"`
+	Price  float64   `yaml:"price"` // 成交单价（美元）
+	Time   time.Time `yaml:"time"`
+	Wallet string    `yaml:"wallet"`
+}
+
+// PriceTargetConfig 单个代币的绝对价格目标
+type PriceTargetConfig struct {
+	Mint       string  `yaml:"mint"`
+	AlertAbove float64 `yaml:"alert_above"` // 价格涨到该值及以上时报警（美元），0表示不设置
+	AlertBelow float64 `yaml:"alert_below"` // 价格跌到该值及以下时报警（美元），0表示不设置
+	Repeat     bool    `yaml:"repeat"`      // 价格回到目标另一侧后重新生效，默认只触发一次
+}
+
+// NotifierNames 报警规则可指定的通知渠道名称
+var NotifierNames = map[string]bool{
+	"discord":  true,
+	"webhook":  true,
+	"email":    true,
+	"slack":    true,
+	"eventbus": true,
+	"redis":    true,
+}
+
+// AlertRule 自定义报警规则，when 为表达式，如 "token.symbol == 'BONK' && change_5m < -10 && value > 500"
+type AlertRule struct {
+	Name     string   `yaml:"name"`
+	When     string   `yaml:"when"`
+	Message  string   `yaml:"message"`  // 报警文本，为空时只显示规则名称
+	Notify   []string `yaml:"notify"`   // 发送到的通知渠道: discord/webhook/email/slack/eventbus/redis，为空时按级别路由
+	Severity string   `yaml:"severity"` // 报警级别: info/warn/critical，默认 warn
+}
+
+// severityLevels 支持的报警级别
+var severityLevels = map[string]bool{"info": true, "warn": true, "critical": true}
+
+// State 监控状态持久化设置：退出时保存历史快照、代币列表和各钱包持仓，重启后恢复
+type State struct {
+	Path   string        `yaml:"path"`    // 状态文件路径
+	MaxAge time.Duration `yaml:"max_age"` // 超过该时长的状态不再恢复，负数表示不保存也不恢复
+}
+
+// Severity 报警级别划分、按级别路由和静默时段设置
+type Severity struct {
+	WarnPct     float64             `yaml:"warn_pct"`     // 价格/价值等变化幅度达到该比例（%）时为 warn
+	CriticalPct float64             `yaml:"critical_pct"` // 变化幅度达到该比例（%）时为 critical
+	Routes      map[string][]string `yaml:"routes"`       // 各级别发送到的通知渠道，如 critical: [slack, email]，未配置的级别发送到所有渠道
+	QuietHours  QuietHours          `yaml:"quiet_hours"`  // 静默时段
+}
+
+// QuietHours 静默时段（本地时间），期间只发送 critical 报警，其余报警在结束后合并为一条汇总发送
+type QuietHours struct {
+	Start string `yaml:"start"` // 开始时间 HH:MM，为空表示不静默
+	End   string `yaml:"end"`   // 结束时间 HH:MM，可早于开始时间表示跨零点
+}
+
+// Validate 校验报警级别设置
+func (s *Severity) Validate() error {
+	if s.WarnPct < 0 || s.CriticalPct < s.WarnPct {
+		return fmt.Errorf("severity.warn_pct 不能为负数且不能大于 critical_pct: %v / %v", s.WarnPct, s.CriticalPct)
+	}
+	for level, names := range s.Routes {
+		if !severityLevels[level] {
+			return fmt.Errorf("severity.routes 的级别无效: %s（可选 info/warn/critical）", level)
+		}
+		for _, name := range names {
+			if !NotifierNames[name] {
+				return fmt.Errorf("severity.routes.%s 的通知渠道无效: %s", level, name)
+			}
+		}
+	}
+	if (s.QuietHours.Start == "") != (s.QuietHours.End == "") {
+		return fmt.Errorf("severity.quiet_hours 需要同时设置 start 和 end")
+	}
+	for _, clock := range []string{s.QuietHours.Start, s.QuietHours.End} {
+		if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+			return fmt.Errorf("severity.quiet_hours 时间格式无效（应为 HH:MM）: %s", clock)
+		}
+	}
+	return nil
+}
+
+// Filters 代币过滤规则，在价格更新后应用
+type Filters struct {
+	MinValue          float64  `yaml:"min_value"`           // 最小价值（美元），0表示不过滤
+	MinLiquidity      float64  `yaml:"min_liquidity"`       // 最小流动性（美元），流动性未知的代币不受限制
+	Blacklist         []string `yaml:"blacklist"`           // 始终隐藏的 mint 地址
+	Whitelist         []string `yaml:"whitelist"`           // 非空时只显示其中的 mint 地址
+	HideLowConfidence *bool    `yaml:"hide_low_confidence"` // 隐藏低可信度价格的代币，默认 true
+	MaxTokens         int      `yaml:"max_tokens"`          // 按价值保留的最多代币数量
+	HideSpam          *bool    `yaml:"hide_spam"`           // 隐藏名称带有网址等诈骗特征或未经买入转入的粉尘代币，默认 true
+}
+
+// DefaultMaxTokens 默认保留的代币数量
+const DefaultMaxTokens = 50
+
+// ApplyDefaults 为未设置的过滤规则填充默认值
+func (f *Filters) ApplyDefaults() {
+	if f.HideLowConfidence == nil {
+		hide := true
+		f.HideLowConfidence = &hide
+	}
+	if f.MaxTokens == 0 {
+		f.MaxTokens = DefaultMaxTokens
+	}
+	if f.HideSpam == nil {
+		hide := true
+		f.HideSpam = &hide
+	}
+}
+
+// Validate 校验过滤规则
+func (f *Filters) Validate() error {
+	if f.MinValue < 0 {
+		return fmt.Errorf("filters.min_value 不能为负数: %v", f.MinValue)
+	}
+	if f.MinLiquidity < 0 {
+		return fmt.Errorf("filters.min_liquidity 不能为负数: %v", f.MinLiquidity)
+	}
+	if f.MaxTokens < 1 {
+		return fmt.Errorf("filters.max_tokens 不能小于1: %d", f.MaxTokens)
+	}
+	return nil
 }
 
 // Config 存储所有配置
 type Config struct {
-	Wallets []WalletConfig `yaml:"wallets"`
-	Tokens  []TokenConfig  `yaml:"tokens"`
-	cache   *TokenMetadataCache
+	Wallets      []WalletConfig      `yaml:"wallets"`
+	Tokens       []TokenConfig       `yaml:"tokens"`
+	MinValue     float64             `yaml:"min_value"` // 已废弃，请使用 filters.min_value
+	Filters      Filters             `yaml:"filters"`
+	Settings     Settings            `yaml:"settings"`
+	Trades       []TradeConfig       `yaml:"trades"`
+	PriceTargets []PriceTargetConfig `yaml:"price_targets"`
+	Rules        []AlertRule         `yaml:"rules"`
+	cache        *TokenMetadataCache
 }
 
-// NewTokenMetadataCache 创建新的代币元数据缓存
+// ApplyDefaults 为未设置的运行参数填充默认值
+func (s *Settings) ApplyDefaults() {
+	if s.DataDir == "" {
+		s.DataDir = DefaultDataDir
+	}
+	if s.LogFile == "" {
+		s.LogFile = DefaultLogFile
+	}
+	if s.MonitorInterval == 0 {
+		s.MonitorInterval = DefaultMonitorInterval
+	}
+	if s.RefreshInterval == 0 {
+		s.RefreshInterval = DefaultRefreshInterval
+	}
+	if s.MaxConcurrentWallets == 0 {
+		s.MaxConcurrentWallets = DefaultMaxConcurrentWallets
+	}
+	if s.WalletFetchDelay == 0 {
+		s.WalletFetchDelay = DefaultWalletFetchDelay
+	}
+	if s.PriceBatchSize == 0 {
+		s.PriceBatchSize = DefaultPriceBatchSize
+	}
+	if s.MaxTokenAccounts == 0 {
+		s.MaxTokenAccounts = DefaultMaxTokenAccounts
+	}
+	if s.BuiltinAlerts == nil {
+		enabled := true
+		s.BuiltinAlerts = &enabled
+	}
+	if s.AlertCooldown == 0 {
+		s.AlertCooldown = DefaultAlertCooldown
+	}
+	if len(s.AlertWindows) == 0 {
+		s.AlertWindows = []time.Duration{30 * time.Second, time.Minute, 5 * time.Minute}
+	}
+	if len(s.TopMovers.Windows) == 0 {
+		s.TopMovers.Windows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+	}
+	if s.TopMovers.Count == 0 {
+		s.TopMovers.Count = DefaultTopMoversCount
+	}
+	// 显式设为空列表时不显示变化率列
+	if s.ChangeColumns == nil {
+		s.ChangeColumns = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+	}
+	if s.ReportColor == "" {
+		s.ReportColor = ReportColorAuto
+	}
+	if len(s.PriceSources) == 0 {
+		s.PriceSources = []string{"jupiter", "dexscreener"}
+	}
+	if s.LogRotation.MaxSizeMB == 0 {
+		s.LogRotation.MaxSizeMB = DefaultLogMaxSizeMB
+	}
+	if s.LogRotation.MaxBackups == 0 {
+		s.LogRotation.MaxBackups = DefaultLogMaxBackups
+	}
+	if s.LogRotation.DailyCSV == nil {
+		daily := true
+		s.LogRotation.DailyCSV = &daily
+	}
+	if s.PriceCache.TTL == 0 {
+		s.PriceCache.TTL = DefaultPriceCacheTTL
+	}
+	if s.PriceCache.LongTailTTL == 0 {
+		s.PriceCache.LongTailTTL = DefaultLongTailTTL
+	}
+	if s.PriceCache.StaleTTL == 0 {
+		s.PriceCache.StaleTTL = DefaultPriceStaleTTL
+	}
+	if s.PriceCache.LongTailLiquidity == 0 {
+		s.PriceCache.LongTailLiquidity = DefaultLongTailLiquidity
+	}
+	if s.HTTP.Timeout == 0 {
+		s.HTTP.Timeout = DefaultHTTPTimeout
+	}
+	if s.HTTP.NotifyTimeout == 0 {
+		s.HTTP.NotifyTimeout = DefaultNotifyTimeout
+	}
+	if s.HTTP.MaxIdleConnsPerHost == 0 {
+		s.HTTP.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if s.HeliusWebhook.Path == "" {
+		s.HeliusWebhook.Path = DefaultHeliusWebhookPath
+	}
+	if s.CircuitBreaker.FailureThreshold == 0 {
+		s.CircuitBreaker.FailureThreshold = DefaultBreakerThreshold
+	}
+	if s.CoinGecko.DivergenceThreshold == 0 {
+		s.CoinGecko.DivergenceThreshold = DefaultDivergenceThreshold
+	}
+	if s.CoinGecko.DivergenceWindow == 0 {
+		s.CoinGecko.DivergenceWindow = DefaultDivergenceWindow
+	}
+	if s.MetadataCachePath == "" {
+		s.MetadataCachePath = filepath.Join(s.DataDir, "token_metadata.json")
+	}
+	if s.MetadataCacheTTL == 0 {
+		s.MetadataCacheTTL = DefaultMetadataCacheTTL
+	}
+	if s.StalePriceMaxAge == 0 {
+		s.StalePriceMaxAge = DefaultStalePriceMaxAge
+	}
+	if s.State.Path == "" {
+		s.State.Path = filepath.Join(s.DataDir, "state.json")
+	}
+	if s.Parquet.Dir == "" {
+		s.Parquet.Dir = filepath.Join(s.DataDir, "parquet")
+	}
+	if s.Parquet.FlushInterval == 0 {
+		s.Parquet.FlushInterval = DefaultParquetFlush
+	}
+	if s.Sheets.HoldingsSheet == "" {
+		s.Sheets.HoldingsSheet = DefaultSheetsHoldings
+	}
+	if s.Sheets.SummarySheet == "" {
+		s.Sheets.SummarySheet = DefaultSheetsSummary
+	}
+	if s.Sheets.Interval == 0 {
+		s.Sheets.Interval = DefaultSheetsInterval
+	}
+	if s.SNS.CachePath == "" {
+		s.SNS.CachePath = filepath.Join(s.DataDir, "sns.json")
+	}
+	if s.ControlSocket == "" {
+		s.ControlSocket = filepath.Join(s.DataDir, "control.sock")
+	}
+	if s.SNS.RefreshInterval == 0 {
+		s.SNS.RefreshInterval = DefaultSNSRefresh
+	}
+	if s.FastPrices.Interval == 0 {
+		s.FastPrices.Interval = DefaultFastPriceInterval
+	}
+	if s.AdaptiveInterval.Threshold == 0 {
+		s.AdaptiveInterval.Threshold = DefaultAdaptiveThreshold
+	}
+	if s.Derivatives.LiquidationAlertPct == 0 {
+		s.Derivatives.LiquidationAlertPct = DefaultLiquidationAlertPct
+	}
+	if s.AirdropAlertValue == 0 {
+		s.AirdropAlertValue = DefaultAirdropAlertValue
+	}
+	if s.CopyTrading.Tag == "" {
+		s.CopyTrading.Tag = DefaultCopyTradingTag
+	}
+	if s.State.MaxAge == 0 {
+		s.State.MaxAge = DefaultStateMaxAge
+	}
+	if s.Severity.WarnPct == 0 {
+		s.Severity.WarnPct = DefaultSeverityWarnPct
+	}
+	if s.Severity.CriticalPct == 0 {
+		s.Severity.CriticalPct = DefaultSeverityCriticalPct
+	}
+	if s.Stablecoins.Mints == nil {
+		s.Stablecoins.Mints = append([]string(nil), DefaultStablecoins...)
+	}
+	if s.Stablecoins.PegBand == 0 {
+		s.Stablecoins.PegBand = DefaultPegBand
+	}
+	if s.Tracing.ServiceName == "" {
+		s.Tracing.ServiceName = DefaultTracingServiceName
+	}
+	if s.Tracing.SampleRatio == 0 {
+		s.Tracing.SampleRatio = 1
+	}
+	if s.Redis.KeyPrefix == "" {
+		s.Redis.KeyPrefix = DefaultRedisKeyPrefix
+	}
+	if s.EventBus.SnapshotTopic == "" {
+		s.EventBus.SnapshotTopic = DefaultSnapshotTopic
+	}
+	if s.EventBus.AlertTopic == "" {
+		s.EventBus.AlertTopic = DefaultAlertTopic
+	}
+	if s.TimeSeries.BatchSize == 0 {
+		s.TimeSeries.BatchSize = DefaultTimeSeriesBatch
+	}
+	if s.TimeSeries.FlushInterval == 0 {
+		s.TimeSeries.FlushInterval = DefaultTimeSeriesFlush
+	}
+	if s.TimeSeries.MaxRetries == 0 {
+		s.TimeSeries.MaxRetries = DefaultTimeSeriesRetries
+	}
+	if s.TimeSeries.MaxBuffer == 0 {
+		s.TimeSeries.MaxBuffer = DefaultTimeSeriesBuffer
+	}
+	if s.Summaries.Time == "" {
+		s.Summaries.Time = DefaultSummaryTime
+	}
+	if s.Summaries.Weekday == "" {
+		s.Summaries.Weekday = DefaultSummaryWeekday
+	}
+	if s.TokenSafety.RiskThreshold == 0 {
+		s.TokenSafety.RiskThreshold = DefaultRiskThreshold
+	}
+	if s.TokenSafety.RecheckInterval == 0 {
+		s.TokenSafety.RecheckInterval = DefaultSafetyRecheck
+	}
+	if s.Liquidity.RefreshInterval == 0 {
+		s.Liquidity.RefreshInterval = DefaultLiquidityRefresh
+	}
+	if s.CircuitBreaker.Cooldown == 0 {
+		s.CircuitBreaker.Cooldown = DefaultBreakerCooldown
+	}
+	if s.RPC.Strategy == "" {
+		s.RPC.Strategy = RPCStrategyFailover
+	}
+	if s.RPC.HealthCheckInterval == 0 {
+		s.RPC.HealthCheckInterval = DefaultRPCHealthCheck
+	}
+	// 配置中没有列出的主机使用默认限流
+	rateLimits := make(map[string]float64, len(DefaultRateLimits)+len(s.HTTP.RateLimits))
+	for host, rps := range DefaultRateLimits {
+		rateLimits[host] = rps
+	}
+	for host, rps := range s.HTTP.RateLimits {
+		rateLimits[host] = rps
+	}
+	s.HTTP.RateLimits = rateLimits
+}
+
+// Validate 校验运行参数
+func (s *Settings) Validate() error {
+	if s.MonitorInterval <= 0 {
+		return fmt.Errorf("monitor_interval 必须为正数: %v", s.MonitorInterval)
+	}
+	if s.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval 必须为正数: %v", s.RefreshInterval)
+	}
+	if s.MaxConcurrentWallets < 1 {
+		return fmt.Errorf("max_concurrent_wallets 不能小于1: %d", s.MaxConcurrentWallets)
+	}
+	if s.MaxTokenAccounts < 1 {
+		return fmt.Errorf("max_token_accounts 不能小于1: %d", s.MaxTokenAccounts)
+	}
+	if s.PriceBatchSize < 1 {
+		return fmt.Errorf("price_batch_size 不能小于1: %d", s.PriceBatchSize)
+	}
+	for _, window := range s.AlertWindows {
+		if window <= 0 {
+			return fmt.Errorf("alert_windows 中的窗口必须为正数: %v", window)
+		}
+	}
+	for _, window := range s.TopMovers.Windows {
+		if window <= 0 {
+			return fmt.Errorf("top_movers.windows 中的窗口必须为正数: %v", window)
+		}
+	}
+	for _, window := range s.ChangeColumns {
+		if window <= 0 {
+			return fmt.Errorf("change_columns 中的窗口必须为正数: %v", window)
+		}
+	}
+	switch s.ReportColor {
+	case ReportColorAuto, ReportColorAlways, ReportColorNever:
+	default:
+		return fmt.Errorf("report_color 无效: %s（可选 auto/always/never）", s.ReportColor)
+	}
+	if s.PositionReduceThreshold < 0 || s.PositionReduceThreshold > 100 {
+		return fmt.Errorf("position_reduce_threshold 必须在0到100之间: %v", s.PositionReduceThreshold)
+	}
+	if s.TrailingStopPct < 0 || s.TrailingStopPct >= 100 {
+		return fmt.Errorf("trailing_stop_pct 必须在0到100之间: %v", s.TrailingStopPct)
+	}
+	if s.ImpermanentLossPct < 0 || s.ImpermanentLossPct >= 100 {
+		return fmt.Errorf("impermanent_loss_pct 必须在0到100之间: %v", s.ImpermanentLossPct)
+	}
+	if s.MetadataCacheTTL < 0 {
+		return fmt.Errorf("metadata_cache_ttl 不能为负数: %v", s.MetadataCacheTTL)
+	}
+	if s.HistorySize < 0 {
+		return fmt.Errorf("history_size 不能为负数: %d", s.HistorySize)
+	}
+	if _, err := logging.ParseLevel(s.LogLevel); err != nil {
+		return fmt.Errorf("log_level 无效: %v", err)
+	}
+	if !logging.ValidFormat(s.LogFormat) {
+		return fmt.Errorf("未知的日志格式: %s", s.LogFormat)
+	}
+	if !i18n.Valid(s.Language) {
+		return fmt.Errorf("不支持的语言: %s（可选 zh、en）", s.Language)
+	}
+	if s.PriceCache.LongTailTTL < 0 || s.PriceCache.StaleTTL < 0 || s.PriceCache.LongTailLiquidity < 0 {
+		return fmt.Errorf("price_cache 中的 long_tail_ttl、stale_ttl 和 long_tail_liquidity 不能为负数")
+	}
+	if s.HTTP.Timeout < 0 || s.HTTP.NotifyTimeout < 0 || s.HTTP.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("http 中的超时时间和连接数不能为负数")
+	}
+	for host, rps := range s.HTTP.RateLimits {
+		if rps < 0 {
+			return fmt.Errorf("http.rate_limits 中 %s 的请求数不能为负数: %v", host, rps)
+		}
+	}
+	for host, burst := range s.HTTP.RateLimitBursts {
+		if burst < 0 {
+			return fmt.Errorf("http.rate_limit_bursts 中 %s 的突发数不能为负数: %d", host, burst)
+		}
+	}
+	if !strings.HasPrefix(s.HeliusWebhook.Path, "/") {
+		return fmt.Errorf("helius_webhook.path 必须以 / 开头: %s", s.HeliusWebhook.Path)
+	}
+	if s.CircuitBreaker.Cooldown < 0 {
+		return fmt.Errorf("circuit_breaker.cooldown 不能为负数: %v", s.CircuitBreaker.Cooldown)
+	}
+	if s.RPC.Strategy != RPCStrategyFailover && s.RPC.Strategy != RPCStrategyRoundRobin {
+		return fmt.Errorf("rpc.strategy 无效: %s（可选 failover/round_robin）", s.RPC.Strategy)
+	}
+	for _, endpoint := range s.RPC.Endpoints {
+		expanded := os.ExpandEnv(endpoint)
+		if !strings.HasPrefix(expanded, "http://") && !strings.HasPrefix(expanded, "https://") {
+			return fmt.Errorf("rpc.endpoints 中的地址必须以 http:// 或 https:// 开头: %s", endpoint)
+		}
+	}
+	if s.CoinGecko.DivergenceThreshold < 0 || s.CoinGecko.DivergenceWindow < 0 {
+		return fmt.Errorf("coingecko 中的 divergence_threshold 和 divergence_window 不能为负数")
+	}
+	if err := s.Severity.Validate(); err != nil {
+		return err
+	}
+	if _, err := time.Parse("15:04", s.Summaries.Time); err != nil {
+		return fmt.Errorf("summaries.time 格式无效（应为 HH:MM）: %s", s.Summaries.Time)
+	}
+	if _, err := ParseWeekday(s.Summaries.Weekday); err != nil {
+		return fmt.Errorf("summaries.weekday 无效: %v", err)
+	}
+	if (s.Summaries.Daily || s.Summaries.Weekly) && s.SQLitePath == "" {
+		return fmt.Errorf("summaries 需要配置 sqlite_path 保存快照")
+	}
+	if s.Parquet.FlushInterval < 0 {
+		return fmt.Errorf("parquet.flush_interval 不能为负数: %v", s.Parquet.FlushInterval)
+	}
+	if s.Sheets.SpreadsheetID != "" && s.Sheets.CredentialsFile == "" {
+		return fmt.Errorf("sheets 需要配置 credentials_file（服务账号密钥文件）")
+	}
+	if s.Sheets.Interval < 0 {
+		return fmt.Errorf("sheets.interval 不能为负数: %v", s.Sheets.Interval)
+	}
+	if s.SNS.RefreshInterval < 0 {
+		return fmt.Errorf("sns.refresh_interval 不能为负数: %v", s.SNS.RefreshInterval)
+	}
+	if s.AdaptiveInterval.Enabled {
+		shortest, longest := s.AdaptiveBounds()
+		if shortest <= 0 || shortest > s.MonitorInterval || longest < s.MonitorInterval {
+			return fmt.Errorf("adaptive_interval 需满足 0 < min <= monitor_interval <= max: min=%v, monitor_interval=%v, max=%v", shortest, s.MonitorInterval, longest)
+		}
+		if s.AdaptiveInterval.Threshold <= 0 {
+			return fmt.Errorf("adaptive_interval.threshold 必须为正数: %v", s.AdaptiveInterval.Threshold)
+		}
+	}
+	if s.FastPrices.Top < 0 {
+		return fmt.Errorf("fast_prices.top 不能为负数: %d", s.FastPrices.Top)
+	}
+	if s.FastPrices.Top > 0 && (s.FastPrices.Interval <= 0 || s.FastPrices.Interval >= s.MonitorInterval) {
+		return fmt.Errorf("fast_prices.interval 必须为正数且小于 monitor_interval（%v）: %v", s.MonitorInterval, s.FastPrices.Interval)
+	}
+	for _, account := range s.Derivatives.HyperliquidAccounts {
+		if err := ValidateAddress(account, ChainEthereum); err != nil {
+			return fmt.Errorf("derivatives.hyperliquid_accounts 中的 %q %v", account, err)
+		}
+	}
+	if s.Derivatives.LiquidationAlertPct >= 100 {
+		return fmt.Errorf("derivatives.liquidation_alert_pct 必须小于100: %v", s.Derivatives.LiquidationAlertPct)
+	}
+	if s.CopyTrading.Topic != "" && s.EventBus.Driver == "" {
+		return fmt.Errorf("copy_trading.topic 需要配置 event_bus.driver")
+	}
+	if s.CopyTrading.MinValue < 0 {
+		return fmt.Errorf("copy_trading.min_value 不能为负数: %v", s.CopyTrading.MinValue)
+	}
+	if s.Sheets.HoldingsSheet == s.Sheets.SummarySheet {
+		return fmt.Errorf("sheets.holdings_sheet 和 summary_sheet 不能是同一个工作表: %s", s.Sheets.HoldingsSheet)
+	}
+	if err := s.TimeSeries.Validate(); err != nil {
+		return err
+	}
+	if s.Tracing.SampleRatio <= 0 || s.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio 必须在0到1之间: %v", s.Tracing.SampleRatio)
+	}
+	if s.Health.MaxSnapshotAge < 0 {
+		return fmt.Errorf("health.max_snapshot_age 不能为负数: %v", s.Health.MaxSnapshotAge)
+	}
+	if s.Redis.URL == "" && (s.Redis.PriceCache || s.Redis.AlertChannel != "" || s.Redis.DedupeAlerts) {
+		return fmt.Errorf("redis 中的 price_cache、alert_channel 和 dedupe_alerts 需要设置 redis.url")
+	}
+	if s.Redis.PriceCache && s.PriceCache.TTL <= 0 {
+		return fmt.Errorf("redis.price_cache 需要启用 price_cache（ttl 为正数）")
+	}
+	if s.EventBus.Driver != "" {
+		if s.EventBus.Driver != EventBusKafka && s.EventBus.Driver != EventBusNATS {
+			return fmt.Errorf("event_bus.driver 无效: %s（可选 kafka/nats）", s.EventBus.Driver)
+		}
+		if len(s.EventBus.Brokers) == 0 {
+			return fmt.Errorf("event_bus.brokers 不能为空")
+		}
+	}
+	if s.TokenSafety.RiskThreshold < 0 || s.TokenSafety.RiskThreshold > 100 {
+		return fmt.Errorf("token_safety.risk_threshold 必须在0到100之间: %d", s.TokenSafety.RiskThreshold)
+	}
+	if s.TokenSafety.RecheckInterval < 0 {
+		return fmt.Errorf("token_safety.recheck_interval 不能为负数: %v", s.TokenSafety.RecheckInterval)
+	}
+	if s.Liquidity.MinUSD < 0 {
+		return fmt.Errorf("liquidity.min_usd 不能为负数: %v", s.Liquidity.MinUSD)
+	}
+	if s.Liquidity.DropPct < 0 || s.Liquidity.DropPct > 100 {
+		return fmt.Errorf("liquidity.drop_pct 必须在0到100之间: %v", s.Liquidity.DropPct)
+	}
+	for host, rps := range s.HTTP.RateLimits {
+		if rps < 0 {
+			return fmt.Errorf("http.rate_limits 中 %s 的请求频率不能为负数: %v", host, rps)
+		}
+	}
+	if s.LogRotation.MaxSizeMB < 0 || s.LogRotation.MaxAge < 0 || s.LogRotation.MaxBackups < 0 {
+		return fmt.Errorf("log_rotation 中的参数不能为负数")
+	}
+	sources := append(append([]string{}, s.PriceSources...), s.FallbackPriceSources...)
+	for _, source := range append(sources, s.PreferredPriceSources...) {
+		if !validPriceSources[source] {
+			return fmt.Errorf("未知的价格数据源: %s", source)
+		}
+	}
+	return nil
+}
+
+// Validate 校验时序数据库输出设置
+func (t *TimeSeries) Validate() error {
+	switch t.Driver {
+	case "":
+		return nil
+	case TimeSeriesInfluxDB:
+		if t.Database == "" && t.Bucket == "" {
+			return fmt.Errorf("timeseries 使用 influxdb 时需要设置 database（1.x）或 bucket（2.x）")
+		}
+		if t.Bucket != "" && t.Org == "" {
+			return fmt.Errorf("timeseries.bucket 需要同时设置 org")
+		}
+	case TimeSeriesTimescale:
+	default:
+		return fmt.Errorf("timeseries.driver 无效: %s（可选 influxdb/timescale）", t.Driver)
+	}
+	if t.URL == "" {
+		return fmt.Errorf("timeseries.url 不能为空")
+	}
+	if t.BatchSize < 1 {
+		return fmt.Errorf("timeseries.batch_size 不能小于1: %d", t.BatchSize)
+	}
+	if t.FlushInterval <= 0 {
+		return fmt.Errorf("timeseries.flush_interval 必须为正数: %v", t.FlushInterval)
+	}
+	if t.MaxBuffer < t.BatchSize {
+		return fmt.Errorf("timeseries.max_buffer 不能小于 batch_size: %d", t.MaxBuffer)
+	}
+	return nil
+}
+
+// NewTokenMetadataCache 创建只保存在内存中的代币元数据缓存
 func NewTokenMetadataCache() *TokenMetadataCache {
 	return &TokenMetadataCache{
 		data: make(map[string]*TokenMetadata),
+		ttl:  DefaultMetadataCacheTTL,
 	}
 }
 
-// Get 获取缓存的代币元数据
+// LoadTokenMetadataCache 创建保存到文件的代币元数据缓存并加载文件中未过期的记录，文件不存在时返回空缓存
+func LoadTokenMetadataCache(path string, ttl time.Duration) (*TokenMetadataCache, error) {
+	c := NewTokenMetadataCache()
+	c.path = path
+	if ttl > 0 {
+		c.ttl = ttl
+	}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("读取元数据缓存失败: %v", err)
+	}
+	var cached map[string]*TokenMetadata
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return c, fmt.Errorf("解析元数据缓存失败: %v", err)
+	}
+	for mint, metadata := range cached {
+		if metadata != nil && time.Since(metadata.UpdatedAt) <= c.ttl {
+			c.data[mint] = metadata
+		}
+	}
+	return c, nil
+}
+
+// Get 获取缓存的代币元数据，符号、名称和精度不会变化，有效期较长
 func (c *TokenMetadataCache) Get(mint string) (*TokenMetadata, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
@@ -60,31 +1193,140 @@ func (c *TokenMetadataCache) Get(mint string) (*TokenMetadata, bool) {
 	if !ok {
 		return nil, false
 	}
-	// 检查缓存是否过期（1分钟）
-	if time.Since(metadata.UpdatedAt) > 2*time.Minute {
+	if time.Since(metadata.UpdatedAt) > c.ttl {
 		return nil, false
 	}
 	return metadata, true
 }
 
-// Set 设置代币元数据缓存
+// Set 设置代币元数据缓存，内容不变时只在接近过期时更新时间，避免每次刷新都重写文件
 func (c *TokenMetadataCache) Set(mint string, metadata *TokenMetadata) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	if old, ok := c.data[mint]; ok && old.Symbol == metadata.Symbol && old.Name == metadata.Name &&
+		old.Decimals == metadata.Decimals && time.Since(old.UpdatedAt) < c.ttl/2 {
+		return
+	}
 	metadata.UpdatedAt = time.Now()
 	c.data[mint] = metadata
+	c.dirty = true
 }
 
-// LoadConfig 从YAML文件加载配置
-func LoadConfig(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
+// Len 返回缓存的代币数量
+func (c *TokenMetadataCache) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.data)
+}
+
+// Save 有更新时将缓存写入文件（先写临时文件再重命名），未设置文件路径时不做任何事
+func (c *TokenMetadataCache) Save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.data)
 	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+		return fmt.Errorf("序列化元数据缓存失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("创建元数据缓存目录失败: %v", err)
 	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存元数据缓存失败: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("保存元数据缓存失败: %v", err)
+	}
+	c.dirty = false
+	return nil
+}
 
+// LoadConfig 从YAML文件加载配置并应用环境变量覆盖（见 ApplyEnv）；filename 为空时只使用环境变量
+func LoadConfig(filename string) (*Config, error) {
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	var walletLines map[string]sourceLine
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %v", err)
+		}
+		walletLines = walletSourceLines(data)
+	}
+	if err := ApplyEnv(&config); err != nil {
+		return nil, err
+	}
+
+	if config.MinValue < 0 {
+		return nil, fmt.Errorf("min_value 不能为负数: %v", config.MinValue)
+	}
+	// 兼容旧的顶层 min_value
+	if config.Filters.MinValue == 0 {
+		config.Filters.MinValue = config.MinValue
+	}
+	config.Filters.ApplyDefaults()
+	if err := config.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.validateWallets(walletLines, config.Settings.SkipInvalidWallets); err != nil {
+		return nil, err
+	}
+	for _, t := range config.Tokens {
+		if t.Chain != "" && !validChains[t.Chain] {
+			return nil, fmt.Errorf("代币 %s 的 chain 无效: %s", t.Address, t.Chain)
+		}
+	}
+
+	for i, trade := range config.Trades {
+		if trade.Side != "buy" && trade.Side != "sell" {
+			return nil, fmt.Errorf("trades[%d] 的 side 必须为 buy 或 sell: %s", i, trade.Side)
+		}
+		if trade.Mint == "" || trade.Amount <= 0 || trade.Price < 0 {
+			return nil, fmt.Errorf("trades[%d] 缺少 mint 或数量/价格无效", i)
+		}
+	}
+
+	ruleNames := make(map[string]bool, len(config.Rules))
+	for i, rule := range config.Rules {
+		if rule.Name == "" || rule.When == "" {
+			return nil, fmt.Errorf("rules[%d] 缺少 name 或 when", i)
+		}
+		if ruleNames[rule.Name] {
+			return nil, fmt.Errorf("报警规则名称重复: %s", rule.Name)
+		}
+		ruleNames[rule.Name] = true
+		for _, name := range rule.Notify {
+			if !NotifierNames[name] {
+				return nil, fmt.Errorf("报警规则 %s 的通知渠道无效: %s（可选 discord/webhook/email/slack/eventbus/redis）", rule.Name, name)
+			}
+		}
+		if rule.Severity != "" && !severityLevels[rule.Severity] {
+			return nil, fmt.Errorf("报警规则 %s 的级别无效: %s（可选 info/warn/critical）", rule.Name, rule.Severity)
+		}
+	}
+
+	for i, target := range config.PriceTargets {
+		if target.Mint == "" {
+			return nil, fmt.Errorf("price_targets[%d] 缺少 mint", i)
+		}
+		if target.AlertAbove < 0 || target.AlertBelow < 0 {
+			return nil, fmt.Errorf("price_targets[%d] 的目标价格不能为负数", i)
+		}
+		if target.AlertAbove == 0 && target.AlertBelow == 0 {
+			return nil, fmt.Errorf("price_targets[%d] 需设置 alert_above 或 alert_below", i)
+		}
+	}
+
+	config.Settings.ApplyDefaults()
+	if err := config.Settings.Validate(); err != nil {
+		return nil, err
 	}
 
 	config.cache = NewTokenMetadataCache()
@@ -105,14 +1347,12 @@ func SaveConfig(filename string, config *Config) error {
 	return nil
 }
 
-// GetTokenMetadata 获取代币元数据（先从缓存获取，如果没有则返回配置中的信息）
+// GetTokenMetadata 获取代币元数据：配置中 tokens 的条目优先（用户手动指定的符号不会被缓存覆盖），
+// 配置中未填写的字段和未配置的代币使用缓存中的信息
 func (c *Config) GetTokenMetadata(mint string) *TokenMetadata {
-	// 先从缓存中查找
-	if metadata, ok := c.cache.Get(mint); ok {
-		return metadata
-	}
+	cached, _ := c.cache.Get(mint)
 
-	// 如果缓存中没有，从配置中查找
+	// 配置中的信息不写入缓存，修改配置后立即生效
 	for _, token := range c.Tokens {
 		if token.Address == mint {
 			metadata := &TokenMetadata{
@@ -120,12 +1360,22 @@ func (c *Config) GetTokenMetadata(mint string) *TokenMetadata {
 				Name:     token.Name,
 				Decimals: token.Decimal,
 			}
-			c.cache.Set(mint, metadata)
+			if cached != nil {
+				if metadata.Symbol == "" {
+					metadata.Symbol = cached.Symbol
+				}
+				if metadata.Name == "" {
+					metadata.Name = cached.Name
+				}
+				if metadata.Decimals == 0 {
+					metadata.Decimals = cached.Decimals
+				}
+			}
 			return metadata
 		}
 	}
 
-	return nil
+	return cached
 }
 
 // SetTokenMetadata 设置代币元数据到缓存
@@ -133,6 +1383,21 @@ func (c *Config) SetTokenMetadata(mint string, metadata *TokenMetadata) {
 	c.cache.Set(mint, metadata)
 }
 
+// SetMetadataCache 使用指定的元数据缓存，重新加载配置时沿用已有的缓存
+func (c *Config) SetMetadataCache(cache *TokenMetadataCache) {
+	c.cache = cache
+}
+
+// MetadataCache 返回代币元数据缓存
+func (c *Config) MetadataCache() *TokenMetadataCache {
+	return c.cache
+}
+
+// SaveTokenMetadata 将有更新的代币元数据缓存写入文件
+func (c *Config) SaveTokenMetadata() error {
+	return c.cache.Save()
+}
+
 // GetWalletAddresses 获取所有钱包地址
 func (c *Config) GetWalletAddresses() []string {
 	addresses := make([]string, len(c.Wallets))
@@ -142,6 +1407,42 @@ func (c *Config) GetWalletAddresses() []string {
 	return addresses
 }
 
+// GroupWalletAddresses 获取属于指定分组或带有该标签的钱包地址
+func (c *Config) GroupWalletAddresses(name string) []string {
+	var addresses []string
+	for _, w := range c.Wallets {
+		if w.InGroup(name) {
+			addresses = append(addresses, w.Address)
+		}
+	}
+	return addresses
+}
+
+// WalletChain 返回钱包所在的链，未配置时根据地址推断
+func (c *Config) WalletChain(address string) string {
+	for _, w := range c.Wallets {
+		if w.Address == address && w.Chain != "" {
+			return w.Chain
+		}
+	}
+	return DetectChain(address)
+}
+
+// ChainTokens 返回配置中指定链上的代币，未设置 chain 的代币属于 solana
+func (c *Config) ChainTokens(chain string) []TokenConfig {
+	var tokens []TokenConfig
+	for _, token := range c.Tokens {
+		tokenChain := token.Chain
+		if tokenChain == "" {
+			tokenChain = ChainSolana
+		}
+		if tokenChain == chain {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
 // GetToken 获取代币配置（兼容旧方法）
 func (c *Config) GetToken(address string) *TokenConfig {
 	for _, token := range c.Tokens {
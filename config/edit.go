@@ -0,0 +1,241 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddWallet 向配置文件添加钱包，保留文件中的注释和其他内容
+func AddWallet(filename string, wallet WalletConfig) error {
+	if wallet.Address == "" {
+		return fmt.Errorf("钱包地址不能为空")
+	}
+	if wallet.Chain != "" && !validChains[wallet.Chain] {
+		return fmt.Errorf("钱包 %s 的 chain 无效: %s", wallet.Address, wallet.Chain)
+	}
+	if err := ValidateAddress(wallet.Address, wallet.Chain); err != nil {
+		return fmt.Errorf("钱包地址 %s %v", wallet.Address, err)
+	}
+
+	doc, err := readDocument(filename)
+	if err != nil {
+		return err
+	}
+	wallets, err := walletsNode(doc)
+	if err != nil {
+		return err
+	}
+	if i := walletIndex(wallets, wallet.Address); i >= 0 {
+		return fmt.Errorf("钱包已存在: %s（第 %d 行）", wallet.Address, wallets.Content[i].Line)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(wallet); err != nil {
+		return fmt.Errorf("编码钱包配置失败: %v", err)
+	}
+	dropEmptyFields(&node)
+	wallets.Content = append(wallets.Content, &node)
+	return writeDocument(filename, doc)
+}
+
+// RemoveWallet 从配置文件删除钱包，保留文件中的注释和其他内容
+func RemoveWallet(filename string, address string) error {
+	doc, err := readDocument(filename)
+	if err != nil {
+		return err
+	}
+	wallets, err := walletsNode(doc)
+	if err != nil {
+		return err
+	}
+	i := walletIndex(wallets, address)
+	if i < 0 {
+		return fmt.Errorf("钱包不存在: %s", address)
+	}
+	wallets.Content = append(wallets.Content[:i], wallets.Content[i+1:]...)
+	return writeDocument(filename, doc)
+}
+
+// SetWalletLabel 修改配置文件中钱包的标签，label 为空时删除标签，保留文件中的注释和其他内容
+func SetWalletLabel(filename string, address, label string) error {
+	doc, err := readDocument(filename)
+	if err != nil {
+		return err
+	}
+	wallets, err := walletsNode(doc)
+	if err != nil {
+		return err
+	}
+	i := walletIndex(wallets, address)
+	if i < 0 {
+		return fmt.Errorf("钱包不存在: %s", address)
+	}
+	item := wallets.Content[i]
+	if item.Kind != yaml.MappingNode {
+		return fmt.Errorf("配置文件格式无效: 钱包 %s 必须是映射", address)
+	}
+
+	for j := 0; j+1 < len(item.Content); j += 2 {
+		if item.Content[j].Value != "label" {
+			continue
+		}
+		if label == "" {
+			item.Content = append(item.Content[:j], item.Content[j+2:]...)
+		} else {
+			item.Content[j+1].SetString(label)
+		}
+		return writeDocument(filename, doc)
+	}
+	if label != "" {
+		// 标签紧跟在地址之后，与新增的钱包条目字段顺序一致
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "label"}
+		value := &yaml.Node{}
+		value.SetString(label)
+		at := min(2, len(item.Content))
+		item.Content = append(item.Content[:at], append([]*yaml.Node{key, value}, item.Content[at:]...)...)
+	}
+	return writeDocument(filename, doc)
+}
+
+// ListWallets 返回配置文件中按原样列出的钱包，不解析域名也不应用环境变量覆盖
+func ListWallets(filename string) ([]WalletConfig, error) {
+	doc, err := readDocument(filename)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := walletsNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	var result []WalletConfig
+	if err := wallets.Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析钱包列表失败: %v", err)
+	}
+	return result, nil
+}
+
+// readDocument 读取配置文件的YAML节点树
+func readDocument(filename string) (*yaml.Node, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	if doc.Kind == 0 {
+		// 空文件
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置文件格式无效: 顶层必须是映射")
+	}
+	return &doc, nil
+}
+
+// writeDocument 将节点树写回配置文件，写入前确认结果仍可被正常加载，并将原文件备份为 <文件名>.bak
+func writeDocument(filename string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("编码配置文件失败: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("编码配置文件失败: %v", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(buf.Bytes(), &cfg); err != nil {
+		return fmt.Errorf("生成的配置文件无效: %v", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("读取配置文件信息失败: %v", err)
+	}
+	previous, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := os.WriteFile(filename+".bak", previous, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("备份配置文件失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免写入中断损坏配置
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// walletsNode 返回 wallets 列表节点，不存在时创建
+func walletsNode(doc *yaml.Node) (*yaml.Node, error) {
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "wallets" {
+			continue
+		}
+		value := root.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			value.Kind, value.Tag, value.Value = yaml.SequenceNode, "!!seq", ""
+		}
+		if value.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("配置文件格式无效: wallets 必须是列表")
+		}
+		return value, nil
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "wallets"}
+	value := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+	return value, nil
+}
+
+// walletIndex 返回地址在 wallets 列表中的位置，不存在时返回-1
+func walletIndex(wallets *yaml.Node, address string) int {
+	for i, item := range wallets.Content {
+		var w WalletConfig
+		if err := item.Decode(&w); err == nil && sameAddress(w.Address, address) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameAddress 判断两个钱包地址是否相同：EVM 地址和 .sol 域名不区分大小写，Solana 地址区分大小写
+func sameAddress(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if DetectChain(a) != ChainSolana || strings.HasSuffix(strings.ToLower(a), ".sol") {
+		return strings.EqualFold(a, b)
+	}
+	return false
+}
+
+// dropEmptyFields 删除映射节点中的空值字段，保持新增条目简洁
+func dropEmptyFields(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	content := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if (value.Kind == yaml.ScalarNode && value.Value == "") ||
+			(value.Kind == yaml.SequenceNode && len(value.Content) == 0) {
+			continue
+		}
+		content = append(content, node.Content[i], value)
+	}
+	node.Content = content
+}
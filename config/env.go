@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀
+const EnvPrefix = "WALLET_TRACKER_"
+
+// envSeparator 环境变量名中嵌套配置项之间的分隔符
+const envSeparator = "__"
+
+// ApplyEnv 用环境变量覆盖配置项，便于在容器中不挂载配置文件运行。
+// 变量名为 EnvPrefix 加上大写的 YAML 键路径，层级之间用双下划线分隔，例如：
+//
+//	WALLET_TRACKER_SETTINGS__MONITOR_INTERVAL=30s
+//	WALLET_TRACKER_SETTINGS__REDIS__URL=redis://redis:6379/0
+//	WALLET_TRACKER_WALLETS='[{address: "...", label: main}]'
+//
+// 字符串配置项直接使用变量值，其他类型（数字、时长、布尔、列表、映射和整个分段）按 YAML 解析并整体替换
+func ApplyEnv(config *Config) error {
+	return applyEnv(reflect.ValueOf(config).Elem(), EnvPrefix)
+}
+
+// applyEnv 递归覆盖结构体的各个配置项
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		name := prefix + strings.ToUpper(key)
+		fv := v.Field(i)
+
+		if value, ok := os.LookupEnv(name); ok {
+			if err := setFromEnv(fv, value); err != nil {
+				return fmt.Errorf("环境变量 %s 无效: %v", name, err)
+			}
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := applyEnv(fv, name+envSeparator); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setFromEnv 将环境变量的值写入配置项
+func setFromEnv(fv reflect.Value, value string) error {
+	if fv.Kind() == reflect.String {
+		fv.SetString(value)
+		return nil
+	}
+	// 先清空，避免映射与原有内容合并
+	fv.Set(reflect.Zero(fv.Type()))
+	return yaml.Unmarshal([]byte(value), fv.Addr().Interface())
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"wallet-tracker/internal/i18n"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem 配置检查发现的一个问题
+type Problem struct {
+	Line    int  // 所在行号，无法定位时为0
+	Warning bool // 警告不影响加载，但配置可能不会按预期生效
+	Message string
+}
+
+// String 返回带级别和行号的问题描述
+func (p Problem) String() string {
+	level := i18n.T("错误")
+	if p.Warning {
+		level = i18n.T("警告")
+	}
+	if p.Line > 0 {
+		return i18n.Sprintf("%s 第 %d 行: %s", level, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", level, p.Message)
+}
+
+// HasErrors 判断问题列表中是否有错误（而不只是警告）
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFile 检查配置文件并返回发现的所有问题：YAML 语法、未知字段（拼写错误）、字段类型、重复的钱包、
+// 引用但未设置的环境变量，以及加载配置时的取值范围检查；文件无法读取时返回错误
+func CheckFile(filename string) ([]Problem, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{syntaxProblem(err)}, nil
+	}
+
+	problems, typeErrors := checkFields(data)
+	problems = append(problems, checkDuplicateWallets(&doc)...)
+	problems = append(problems, checkEnvReferences(&doc)...)
+
+	// 字段类型错误时 LoadConfig 会因同样的原因失败，不再重复报告
+	if !typeErrors {
+		if cfg, err := LoadConfig(filename); err != nil {
+			problems = append(problems, Problem{Message: err.Error()})
+		} else {
+			problems = append(problems, checkLoaded(cfg)...)
+		}
+	}
+
+	// 按行号排列，无法定位到行的问题放在最后
+	sort.SliceStable(problems, func(i, j int) bool {
+		li, lj := problems[i].Line, problems[j].Line
+		return li != 0 && (lj == 0 || li < lj)
+	})
+	return problems, nil
+}
+
+// yamlLinePattern yaml 错误信息中的行号前缀，如 "line 12: ..."
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// syntaxProblem 将 YAML 语法错误转换为带行号的问题
+func syntaxProblem(err error) Problem {
+	message := err.Error()
+	if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Problem{Line: line, Message: i18n.Sprintf("YAML 语法错误: %s", m[2])}
+	}
+	return Problem{Message: i18n.Sprintf("YAML 语法错误: %s", strings.TrimPrefix(message, "yaml: "))}
+}
+
+// unknownFieldPattern yaml 报告未知字段的信息，如 "field wallet not found in type config.Config"
+var unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type (\S+)$`)
+
+// checkFields 以严格模式解析配置，报告未知字段和类型不匹配的字段；第二个返回值表示是否有类型错误
+func checkFields(data []byte) ([]Problem, bool) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg Config
+	err := dec.Decode(&cfg)
+	var typeErr *yaml.TypeError
+	if err == nil || !errors.As(err, &typeErr) {
+		return nil, false
+	}
+
+	var problems []Problem
+	typeErrors := false
+	for _, message := range typeErr.Errors {
+		var line int
+		if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			message = m[2]
+		}
+		if m := unknownFieldPattern.FindStringSubmatch(message); m != nil {
+			text := i18n.Sprintf("未知的字段 %s，该字段会被忽略", m[1])
+			if suggestion := closestField(m[1], knownFields[m[2]]); suggestion != "" {
+				text += i18n.Sprintf("（是否应为 %s？）", suggestion)
+			}
+			problems = append(problems, Problem{Line: line, Message: text})
+			continue
+		}
+		typeErrors = true
+		problems = append(problems, Problem{Line: line, Message: i18n.Sprintf("字段类型错误: %s", message)})
+	}
+	return problems, typeErrors
+}
+
+// knownFields 配置中各结构体类型（如 config.Settings）的 yaml 字段名
+var knownFields = collectFields(reflect.TypeOf(Config{}), make(map[string][]string))
+
+// collectFields 递归收集结构体及其字段类型的 yaml 字段名
+func collectFields(t reflect.Type, fields map[string][]string) map[string][]string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() != reflect.TypeOf(Config{}).PkgPath() {
+		return fields
+	}
+	if _, ok := fields[t.String()]; ok {
+		return fields
+	}
+	fields[t.String()] = nil
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[t.String()] = append(fields[t.String()], name)
+		collectFields(field.Type, fields)
+	}
+	return fields
+}
+
+// closestField 返回与未知字段拼写最接近的已知字段，差异过大时返回空字符串
+func closestField(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+// checkDuplicateWallets 报告 wallets 中重复的地址（EVM 地址不区分大小写）
+func checkDuplicateWallets(doc *yaml.Node) []Problem {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := doc.Content[0]
+	var problems []Problem
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "wallets" || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		seen := make(map[string]int)
+		for _, item := range root.Content[i+1].Content {
+			var w WalletConfig
+			if err := item.Decode(&w); err != nil || w.Address == "" {
+				continue
+			}
+			key := w.Address
+			if DetectChain(key) != ChainSolana || strings.HasSuffix(strings.ToLower(key), ".sol") {
+				key = strings.ToLower(key)
+			}
+			if first, ok := seen[key]; ok {
+				problems = append(problems, Problem{
+					Line:    item.Line,
+					Message: i18n.Sprintf("钱包 %s 重复（与第 %d 行相同），持仓会被重复计算", w.Address, first),
+				})
+				continue
+			}
+			seen[key] = item.Line
+		}
+	}
+	return problems
+}
+
+// envReferencePattern 配置值中的环境变量引用，与 os.ExpandEnv 一致支持 ${NAME} 和 $NAME
+var envReferencePattern = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// checkEnvReferences 报告配置值中引用但未设置（或为空）的环境变量
+func checkEnvReferences(doc *yaml.Node) []Problem {
+	var problems []Problem
+	reported := make(map[string]bool)
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.ScalarNode {
+			for _, m := range envReferencePattern.FindAllStringSubmatch(node.Value, -1) {
+				name := m[1] + m[2]
+				if os.Getenv(name) != "" || reported[name] {
+					continue
+				}
+				reported[name] = true
+				problems = append(problems, Problem{
+					Line:    node.Line,
+					Warning: true,
+					Message: i18n.Sprintf("引用的环境变量 %s 未设置，替换后为空", name),
+				})
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return problems
+}
+
+// checkLoaded 检查加载后的配置中不会导致加载失败、但通常是配置错误的情况
+func checkLoaded(cfg *Config) []Problem {
+	var problems []Problem
+	if len(cfg.Wallets) == 0 {
+		problems = append(problems, Problem{Warning: true, Message: i18n.T("没有配置钱包，需使用 -wallet 指定要处理的钱包")})
+	}
+	for _, w := range cfg.Wallets {
+		if !IsExchange(w.Address) {
+			continue
+		}
+		for _, env := range ExchangeCredentialEnvs[strings.ToLower(w.Address)] {
+			if os.Getenv(env) == "" {
+				problems = append(problems, Problem{Warning: true, Message: i18n.Sprintf("未设置 %s 环境变量，无法获取交易所 %s 的余额", env, w.Address)})
+			}
+		}
+	}
+	for _, w := range cfg.Wallets {
+		if cfg.WalletChain(w.Address) != ChainSolana {
+			continue
+		}
+		if os.Getenv("HELIUS_RPC_ENDPOINT") == "" || os.Getenv("HELIUS_API_KEY") == "" {
+			problems = append(problems, Problem{Warning: true, Message: i18n.T("未设置 HELIUS_RPC_ENDPOINT 或 HELIUS_API_KEY 环境变量，无法获取 Solana 钱包持仓")})
+		}
+		break
+	}
+	return problems
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// writeConfig 将配置内容写入临时文件并返回路径
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wallets.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return path
+}
+
+func TestCheckFile(t *testing.T) {
+	const wallet = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	t.Setenv("HELIUS_RPC_ENDPOINT", "https://rpc.example.com")
+	t.Setenv("HELIUS_API_KEY", "test")
+	t.Setenv("TRACKER_TEST_UNSET", "")
+
+	tests := []struct {
+		name        string
+		content     string
+		wantLine    int
+		wantWarning bool
+		wantMessage string
+		wantErrors  bool
+	}{
+		{
+			name:        "YAML 语法错误",
+			content:     "wallets:\n  - address: " + wallet + "\n settings: [\n",
+			wantLine:    2,
+			wantMessage: "YAML 语法错误",
+			wantErrors:  true,
+		},
+		{
+			name:        "未知字段提示拼写最接近的字段",
+			content:     "wallets:\n  - address: " + wallet + "\nsettings:\n  monitor_intervl: 30s\n",
+			wantLine:    4,
+			wantMessage: "未知的字段 monitor_intervl，该字段会被忽略（是否应为 monitor_interval？）",
+			wantErrors:  true,
+		},
+		{
+			name:        "重复的钱包",
+			content:     "wallets:\n  - address: " + wallet + "\n  - address: " + wallet + "\n",
+			wantLine:    3,
+			wantMessage: "钱包 " + wallet + " 重复（与第 2 行相同）",
+			wantErrors:  true,
+		},
+		{
+			name:        "未设置的环境变量是警告",
+			content:     "wallets:\n  - address: " + wallet + "\nsettings:\n  copy_trading:\n    webhook: ${TRACKER_TEST_UNSET}\n",
+			wantLine:    5,
+			wantWarning: true,
+			wantMessage: "引用的环境变量 TRACKER_TEST_UNSET 未设置",
+		},
+		{
+			name:        "没有钱包是警告",
+			content:     "settings:\n  monitor_interval: 30s\n",
+			wantWarning: true,
+			wantMessage: "没有配置钱包",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := CheckFile(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatalf("CheckFile 失败: %v", err)
+			}
+			if got := HasErrors(problems); got != tt.wantErrors {
+				t.Errorf("HasErrors = %v, 期望 %v, 问题: %v", got, tt.wantErrors, problems)
+			}
+			for _, p := range problems {
+				if strings.Contains(p.Message, tt.wantMessage) {
+					if p.Line != tt.wantLine || p.Warning != tt.wantWarning {
+						t.Errorf("问题 %q 的行号/级别 = %d/%v, 期望 %d/%v", p.Message, p.Line, p.Warning, tt.wantLine, tt.wantWarning)
+					}
+					return
+				}
+			}
+			t.Errorf("没有包含 %q 的问题, 得到 %v", tt.wantMessage, problems)
+		})
+	}
+}
+
+func TestCheckFileEnglish(t *testing.T) {
+	i18n.SetLanguage(i18n.English)
+	t.Cleanup(func() { i18n.SetLanguage(i18n.Chinese) })
+
+	const wallet = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	content := "wallets:\n  - address: " + wallet + "\n  - address: " + wallet + "\n"
+	problems, err := CheckFile(writeConfig(t, content))
+	if err != nil {
+		t.Fatalf("CheckFile 失败: %v", err)
+	}
+	want := "error line 3: wallet " + wallet + " is duplicated (same as line 2), holdings will be counted twice"
+	for _, p := range problems {
+		if p.String() == want {
+			return
+		}
+	}
+	t.Errorf("没有问题输出为 %q, 得到 %v", want, problems)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"wallet-tracker/internal/logging"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var logger = logging.For("config")
+
+// reloadDebounce 文件变化后等待的时间，合并编辑器保存时的多次写入
+const reloadDebounce = 500 * time.Millisecond
+
+// Watch 监听配置文件变化，重新加载成功后调用 onChange；加载失败时保留旧配置并记录日志
+func Watch(ctx context.Context, filename string, onChange func(*Config)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建文件监听失败: %v", err)
+	}
+
+	// 监听所在目录，兼容编辑器通过重命名替换文件的保存方式
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		watcher.Close()
+		return fmt.Errorf("解析配置文件路径失败: %v", err)
+	}
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("监听配置目录失败: %v", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != absPath {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("配置文件监听错误", "error", err)
+			case <-debounce:
+				debounce = nil
+				cfg, err := LoadConfig(filename)
+				if err != nil {
+					logger.Error("重新加载配置失败，继续使用旧配置", "error", err)
+					continue
+				}
+				logger.Info("配置文件已重新加载", "file", filename)
+				onChange(cfg)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,723 @@
+package i18n
+
+// english 英文消息目录；格式字符串的译文需保持与原文相同的占位符顺序
+var english = map[string]string{
+	// 报警标题
+	"代币价格报警":   "Token price alert",
+	"代币价值报警":   "Token value alert",
+	"持仓数量变化":   "Position size changed",
+	"强平风险":     "Liquidation risk",
+	"无常损失报警":   "Impermanent loss alert",
+	"疑似空投":     "Possible airdrop",
+	"组合价值报警":   "Portfolio value alert",
+	"数据源偏离报警":  "Price source divergence",
+	"新代币买入":    "New token bought",
+	"持仓减少":     "Position reduced",
+	"钱包交易活动":   "Wallet activity",
+	"流动性报警":    "Liquidity alert",
+	"高风险代币":    "High-risk token",
+	"组合汇总":     "Portfolio summary",
+	"稳定币脱锚":    "Stablecoin depeg",
+	"稳定币恢复锚定":  "Stablecoin peg restored",
+	"价格目标":     "Price target",
+	"回撤报警":     "Trailing stop",
+	"规则报警":     "Rule alert",
+	"静默时段报警汇总": "Quiet hours alert digest",
+	"数据不完整":    "Incomplete data",
+	"每日汇总":     "Daily summary",
+	"每周汇总":     "Weekly summary",
+
+	// 报警内容
+	"组合价值报警 - %s内总价值变化率: %.2f%% (从 $%.2f 到 $%.2f)": "Portfolio value alert - total value change over %s: %.2f%% (from $%.2f to $%.2f)",
+	"代币价格报警 - %s (%s)\n" +
+		"时间窗口: %s\n" +
+		"价格变化: %.2f%%\n" +
+		"当前价格: $%.8f\n" +
+		"历史价格: $%.8f\n" +
+		"当前价值: $%.2f\n" +
+		"持有钱包: %s": "Token price alert - %s (%s)\n" +
+		"Window: %s\n" +
+		"Price change: %.2f%%\n" +
+		"Current price: $%.8f\n" +
+		"Previous price: $%.8f\n" +
+		"Current value: $%.2f\n" +
+		"Held by: %s",
+	"代币价值报警 - %s (%s) %s内价值变化率: %.2f%% (从 $%.2f 到 $%.2f, 持有钱包: %s)":                             "Token value alert - %s (%s) value change over %s: %.2f%% (from $%.2f to $%.2f, held by: %s)",
+	"数据源偏离报警 - %s (%s) %s内价格偏离持续超过 %.2f%% 且不断扩大 (从 %.2f%% 到 %.2f%%, 主价格: $%.8f, 交叉验证价格: $%.8f)": "Price source divergence - %s (%s) over %s the price divergence stayed above %.2f%% and kept widening (from %.2f%% to %.2f%%, primary price: $%.8f, cross-check price: $%.8f)",
+	"新代币买入 - 钱包 %s 买入 %s (%s), 数量 %.4f, 价值 %s":                                                  "New token bought - wallet %s bought %s (%s), amount %.4f, value %s",
+	"疑似空投 - 钱包 %s 收到未经买入的 %s (%s), 数量 %.4f, 价值 $%.2f":                                           "Possible airdrop - wallet %s received unsolicited %s (%s), amount %.4f, value $%.2f",
+	"持仓%s - 钱包 %s 的 %s (%s) 数量从 %.4f 减少到 %.4f (-%.2f%%), 估计价值变化 %s":                             "Position %s - wallet %s %s (%s) amount reduced from %.4f to %.4f (-%.2f%%), estimated value change %s",
+
+	"持仓数量变化 - %s (%s) %s内数量从 %.4f 变为 %.4f (%+.2f%%)，价值从 $%.2f 到 $%.2f（价格影响 %+.2f，数量影响 %+.2f），持有钱包: %s": "Position size changed - %s (%s) amount over %s changed from %.4f to %.4f (%+.2f%%), value from $%.2f to $%.2f (price effect %+.2f, amount effect %+.2f), held by: %s",
+
+	"强平风险 - 账户 %s 的 %s %s仓 标记价格 $%.4f 距强平价 $%.4f 仅 %.2f%%（阈值 %.2f%%），仓位价值 $%.2f，未实现盈亏 %+.2f": "Liquidation risk - account %s %s %s position mark price $%.4f vs liquidation price $%.4f, only %.2f%% away (threshold %.2f%%), position value $%.2f, unrealized PnL %+.2f",
+
+	"无常损失报警 - 钱包 %s 的 %s %s LP 相对持有不动 %.2f%%（阈值 %.2f%%），当前价值 $%.2f，持有不动价值 $%.2f": "Impermanent loss - wallet %s %s %s LP is %.2f%% versus holding (threshold %.2f%%), current value $%.2f, hold value $%.2f",
+
+	"减仓":         "reduced",
+	"清仓":         "closed",
+	"未知":         "unknown",
+	"跌破下限 $%.0f": "fell below floor $%.0f",
+	"下降 %.2f%%":  "dropped %.2f%%",
+	"流动性报警 - %s (%s) 流动性%s: $%.0f -> $%.0f，持仓价值 $%.2f":                 "Liquidity alert - %s (%s) liquidity %s: $%.0f -> $%.0f, position value $%.2f",
+	"稳定币脱锚 - %s (%s) 市场价格 $%.4f，偏离 %+.2f%% 超出 ±%.2f%%，持仓 %.2f":         "Stablecoin depeg - %s (%s) market price $%.4f, deviation %+.2f%% outside ±%.2f%%, holding %.2f",
+	"稳定币恢复锚定 - %s (%s) 市场价格 $%.4f，偏离 %+.2f%%":                          "Stablecoin peg restored - %s (%s) market price $%.4f, deviation %+.2f%%",
+	"回撤报警 - %s (%s) 从最高价 $%.6f 回撤 %.2f%% 至 $%.6f，超过 %.2f%%，持仓价值 $%.2f": "Trailing stop - %s (%s) fell from peak $%.6f by %.2f%% to $%.6f, exceeding %.2f%%, position value $%.2f",
+	"高风险代币 - %s (%s) 风险分 %d: %s，持仓价值 $%.2f":                            "High-risk token - %s (%s) risk score %d: %s, position value $%.2f",
+	"跌破":        "fell below",
+	"突破":        "rose above",
+	"一次性目标，已停用": "one-shot target, now disabled",
+	"价格回到目标另一侧后重新生效":                                 "re-arms once the price crosses back",
+	"价格目标 - %s (%s) %s $%.6f，当前价格 $%.6f，持仓 %.2f（%s）": "Price target - %s (%s) %s $%.6f, current price $%.6f, holding %.2f (%s)",
+	"钱包活动 - 钱包 %s: %s, 交易 %s":                        "Wallet activity - wallet %s: %s, transaction %s",
+	"数据不完整: 钱包 %s 获取失败，组合总值 $%.2f 不包含该钱包的最新持仓\n%s\n当前共 %d 个钱包数据不完整": "Incomplete data: failed to fetch wallet %s, portfolio total $%.2f excludes its latest holdings\n%s\n%d wallet(s) currently incomplete",
+	"，沿用旧数据":    ", using previous data",
+	"（上次成功 %s）": " (last success %s)",
+	"规则报警 [":    "Rule alert [",
+	" - 组合":     " - portfolio",
+	"\n条件: ":    "\nCondition: ",
+	"\n当前价格: $%.8f  当前价值: $%.2f  持有钱包: %s": "\nCurrent price: $%.8f  Current value: $%.2f  Held by: %s",
+	"\n组合价值: $%.2f":                       "\nPortfolio value: $%.2f",
+	"静默时段内共有 %d 条报警:":                     "%d alert(s) during quiet hours:",
+	"\n... 另有 %d 条":                       "\n... and %d more",
+	"开盘: $%.2f  收盘: $%.2f  变化: %+.2f%%\n": "Open: $%.2f  Close: $%.2f  Change: %+.2f%%\n",
+	"最高: $%.2f  最低: $%.2f  快照数: %d":       "High: $%.2f  Low: $%.2f  Snapshots: %d",
+	"\n涨幅最大: %s %+.2f%% ($%.6f -> $%.6f)": "\nTop gainer: %s %+.2f%% ($%.6f -> $%.6f)",
+	"\n跌幅最大: %s %+.2f%% ($%.6f -> $%.6f)": "\nTop loser: %s %+.2f%% ($%.6f -> $%.6f)",
+	"级别":                      "Severity",
+	"变化":                      "Change",
+	"窗口":                      "Window",
+	"钱包":                      "Wallet",
+	"每日组合汇总":                  "Daily portfolio summary",
+	"*总价值:* $%.2f\n*代币数:* %d": "*Total value:* $%.2f\n*Tokens:* %d",
+	"未实现盈亏: $%+.2f (%+.2f%%)": "Unrealized PnL: $%+.2f (%+.2f%%)",
+	"每日组合汇总: $%.2f":           "Daily portfolio summary: $%.2f",
+	"[wallet-tracker] {{len .Alerts}} 条报警{{if eq (len .Alerts) 1}}: {{(index .Alerts 0).Symbol}}{{end}}": "[wallet-tracker] {{len .Alerts}} alert(s){{if eq (len .Alerts) 1}}: {{(index .Alerts 0).Symbol}}{{end}}",
+	`{{range .Alerts}}[{{.Timestamp.Format "2006-01-02 15:04:05"}}] {{.Message}}
+{{if .MintAddr}}{{tokenURL .MintAddr}}
+{{end}}
+{{end}}共 {{len .Alerts}} 条报警，汇总时间 {{.Start.Format "15:04:05"}} - {{.End.Format "15:04:05"}}
+`: `{{range .Alerts}}[{{.Timestamp.Format "2006-01-02 15:04:05"}}] {{.Message}}
+{{if .MintAddr}}{{tokenURL .MintAddr}}
+{{end}}
+{{end}}{{len .Alerts}} alert(s), collected {{.Start.Format "15:04:05"}} - {{.End.Format "15:04:05"}}
+`,
+
+	// 报告
+	"代币":                              "Token",
+	"价格":                              "Price",
+	"价值":                              "Value",
+	"数量":                              "Amount",
+	"流动性":                             "Liquidity",
+	"质押":                              "Staked",
+	"占比":                              "Share",
+	"盈亏":                              "PnL",
+	"总值":                              "Total",
+	"无":                               "none",
+	"其他":                              "Other",
+	"新增":                              "new",
+	"已清仓":                             "closed",
+	"过期 ":                             "stale ",
+	"风险分":                             "Risk",
+	"风险项":                             "Risks",
+	"可增发":                             "mintable",
+	"可冻结":                             "freezable",
+	"LP锁定":                            "LP locked ",
+	"分组":                              "Group",
+	"钱包数":                             "Wallets",
+	"(未分组)":                           "(ungrouped)",
+	"起始价格":                            "Start price",
+	"结束价格":                            "End price",
+	"价格变化":                            "Price chg",
+	"结束价值":                            "End value",
+	"平均成本":                            "Avg cost",
+	"当前价格":                            "Price",
+	"已实现":                             "Realized",
+	"未实现":                             "Unrealized",
+	"NFT集合":                           "NFT collection",
+	"压缩":                              "Compressed",
+	"地板价(SOL)":                        "Floor (SOL)",
+	"估值":                              "Value",
+	"(无集合)":                           "(no collection)",
+	"NFT估值: $%.2f":                    "NFT value: $%.2f",
+	" (%d 个NFT无地板价)":                  " (%d NFT(s) without floor price)",
+	"合约仓位":                            "Perp positions",
+	"DeFi仓位":                          "DeFi positions",
+	"无常损失":                            "IL",
+	"协议":                              "Protocol",
+	"类型":                              "Type",
+	"借贷":                              "Lending",
+	"存款/底层资产":                         "Deposits/underlying",
+	"借款":                              "Borrowed",
+	"净值":                              "Net value",
+	" (%d 项资产无价格)":                    " (%d asset(s) without price)",
+	"账户":                              "Account",
+	"合约":                              "Contract",
+	"方向":                              "Side",
+	"开仓价":                             "Entry",
+	"标记价":                             "Mark",
+	"强平价":                             "Liq. price",
+	"距强平":                             "To liq.",
+	"杠杆":                              "Leverage",
+	"未实现盈亏":                           "Unrealized PnL",
+	"地址":                              "Address",
+	"交易数":                             "Txs",
+	"转入SOL":                           "SOL in",
+	"转出SOL":                           "SOL out",
+	"资助钱包":                            "Funded",
+	"最近往来":                            "Last seen",
+	"备注":                              "Note",
+	"已跟踪":                             "tracked",
+	"多":                               "long",
+	"空":                               "short",
+	"总值: $%.2f [%s]\n":                "Total: $%.2f [%s]\n",
+	"其中质押: $%.2f\n":                   "Staked: $%.2f\n",
+	"其中DeFi: $%.2f\n":                 "In DeFi: $%.2f\n",
+	"持仓无变化":                           "No holding changes",
+	"数量变化":                            "Amount change",
+	"价值变化":                            "Value change",
+	"总值: $%.2f (%+.2f, %+.2f%%) [%s]": "Total: $%.2f (%+.2f, %+.2f%%) [%s]",
+	"合约账户权益: $%.2f，未实现盈亏: %+.2f":   "Perp account equity: $%.2f, unrealized PnL: %+.2f",
+	"基准中已不在持仓的代币: %d个\n":           "Baseline tokens no longer held: %d\n",
+	"\n详细代币报告\n":                   "\nDetailed token report\n",
+	"  DeFi: %.8f ($%.2f)\n":       "  DeFi: %.8f ($%.2f)\n",
+	"时间: ":                         "Time: ",
+	"代币 #%d: %s\n":                 "Token #%d: %s\n",
+	"  Mint地址: %s\n":               "  Mint: %s\n",
+	"  价格: $%.8f\n":                "  Price: $%.8f\n",
+	"  市场价格: $%.6f (偏离 %+.2f%%)\n": "  Market price: $%.6f (deviation %+.2f%%)\n",
+	"  数量: %.8f\n":                 "  Amount: %.8f\n",
+	"  价值: $%.2f\n":                "  Value: $%.2f\n",
+	"  可信度: %s\n":                  "  Confidence: %s\n",
+	"  价格过期: 数据源未返回，沿用 %s 前的价格\n": "  Stale price: not returned by sources, using the price from %s ago\n",
+	"  流动性: $%.2f\n":                                  "  Liquidity: $%.2f\n",
+	"DeFi存款: $%.2f，借款: $%.2f，净值: $%.2f":               "DeFi deposits: $%.2f, borrowed: $%.2f, net: $%.2f",
+	"交易对手分析: %d 个钱包，扫描 %d 笔交易":                        "Counterparty analysis: %d wallet(s), %d transaction(s) scanned",
+	"没有发现直接转账往来的地址":                                   "No direct transfer counterparties found",
+	"资金来源（扫描范围内最早转入 SOL 的地址）":                         "Funding sources (earliest SOL sender within the scanned range)",
+	"  深度(±2%%): 买入 $%.2f / 卖出 $%.2f\n":               "  Depth (±2%%): buy $%.2f / sell $%.2f\n",
+	"  风险分: %d (%s)\n":                                "  Risk score: %d (%s)\n",
+	"  质押: %.8f ($%.2f)\n":                            "  Staked: %.8f ($%.2f)\n",
+	"  兑换率: 1 %s = %.6f SOL\n":                        "  Exchange rate: 1 %s = %.6f SOL\n",
+	"  盈亏: %s\n":                                      "  PnL: %s\n",
+	"总资产价值: $%.2f\n":                                  "Total value: $%.2f\n",
+	"* 价格过期（数据源未返回，沿用上次价格）: ":                         "* Stale prices (not returned by sources, using last price): ",
+	"\n⚠ 数据不完整: %d 个钱包获取失败，总值可能偏低\n":                  "\n⚠ Incomplete data: %d wallet(s) failed to fetch, total may be understated\n",
+	"数据不完整: %d 个钱包获取失败，总值可能偏低":                        "Incomplete data: %d wallet(s) failed to fetch, total may be understated",
+	"\n钱包 %s (%s) 总值: $%.2f\n":                        "\nWallet %s (%s) total: $%.2f\n",
+	"... 其余 %d 个代币\n":                                 "... %d more token(s)\n",
+	"\n⚠ 高风险代币\n%-16s %8s %14s  %s\n":                 "\n⚠ High-risk tokens\n%-16s %8s %14s  %s\n",
+	"风险分 %d: %s":                                      "risk score %d: %s",
+	"高度集中":                                            "highly concentrated",
+	"适度集中":                                            "moderately concentrated",
+	"分散":                                              "diversified",
+	"\n资产配置 (Allocation)\n":                           "\nAllocation\n",
+	"集中度: HHI %.0f (%s)，等效持仓数 %.1f\n":                 "Concentration: HHI %.0f (%s), effective holdings %.1f\n",
+	"前%d %.2f%%":                                      "top %d %.2f%%",
+	"持仓占比: ":                                          "Exposure: ",
+	"稳定币: $%.2f (%.2f%%)  波动资产: $%.2f (%.2f%%)\n":     "Stablecoins: $%.2f (%.2f%%)  Volatile: $%.2f (%.2f%%)\n",
+	"\n区间报告: %s 至 %s (%d 个快照)\n":                      "\nRange report: %s to %s (%d snapshots)\n",
+	"总价值: $%.2f -> $%.2f (%+.2f%%)\n":                 "Total value: $%.2f -> $%.2f (%+.2f%%)\n",
+	"区间最低: $%.2f  区间最高: $%.2f\n":                      "Low: $%.2f  High: $%.2f\n",
+	"$%.2f (%.4f%%/s | 总变化: %.4f%% | 间隔: %.1fs) [%s]": "$%.2f (%.4f%%/s | total change: %.4f%% | interval: %.1fs) [%s]",
+	"钱包资产报告":                                          "Wallet portfolio report",
+	"生成时间":                                            "generated",
+	"暂无持仓":                                            "No holdings",
+	"最近24小时总价值":                                       "Total value, last 24 hours",
+	"快照数据不足":                                          "Not enough snapshots",
+	"Mint地址":                                          "Mint",
+	"价格(USD)":                                         "Price (USD)",
+	"价值(USD)":                                         "Value (USD)",
+	"变化额(USD)":                                        "Change (USD)",
+	"变化率(%)":                                          "Change (%)",
+	"盈亏(USD)":                                         "PnL (USD)",
+	"盈亏率(%)":                                          "PnL (%)",
+	"时间戳":                                             "Timestamp",
+	"总价值(USD)":                                        "Total value (USD)",
+	"代币数":                                             "Tokens",
+	"\n涨跌榜 (Top movers)\n":                            "\nTop movers\n",
+	"  无价格变化\n":                                       "  no price changes\n",
+	"涨幅":                                              "Gainers",
+	"跌幅":                                              "Losers",
+	"日期":                                              "Date",
+	"开盘":                                              "Open",
+	"收盘":                                              "Close",
+	"最高":                                              "High",
+	"最低":                                              "Low",
+	"快照数":                                             "Snapshots",
+	"占比(%)":                                           "Share (%)",
+	"可信度":                                             "Confidence",
+	"总价值":                                             "Total value",
+	"更新时间":                                            "Updated",
+
+	// 健康检查
+	"快照循环已 %s 没有完成":   "snapshot loop has not completed for %s",
+	"尚未成功获取钱包持仓":      "wallet holdings not fetched yet",
+	"钱包持仓已 %s 没有成功获取": "wallet holdings not fetched successfully for %s",
+	"尚未生成快照":          "no snapshot taken yet",
+	"快照已 %s 没有更新":     "snapshot not updated for %s",
+
+	// 日志
+	"发现未完成的Parquet文件（上次异常退出），其中的数据无法读取": "found unfinished Parquet files (previous run exited abnormally), their data cannot be read",
+	"完成Parquet文件失败":                                       "failed to finish Parquet file",
+	"写入Parquet文件失败":                                       "failed to write Parquet file",
+	"开始写入Parquet文件":                                       "writing Parquet file",
+	"退出时仍有汇总行未写入Google Sheets":                            "daily summary rows still unwritten to Google Sheets at exit",
+	"准备Google Sheets工作表失败，稍后重试":                           "failed to prepare Google Sheets worksheets, will retry",
+	"同步持仓表到Google Sheets失败，稍后重试":                          "failed to sync holdings to Google Sheets, will retry",
+	"已同步持仓表到Google Sheets":                                "synced holdings to Google Sheets",
+	"追加每日汇总到Google Sheets失败，稍后重试":                         "failed to append daily summary to Google Sheets, will retry",
+	"重新解析域名失败，沿用上次的解析结果":                                  "failed to re-resolve domain, keeping previous resolution",
+	"域名所有者已变化":                                            "domain owner changed",
+	"已解析钱包域名":                                             "resolved wallet domain",
+	"保存域名解析缓存失败":                                          "failed to save domain resolution cache",
+	"加载域名解析缓存失败":                                          "failed to load domain resolution cache",
+	"重新解析钱包域名失败":                                          "failed to re-resolve wallet domains",
+	"应用重新解析的钱包地址失败":                                       "failed to apply re-resolved wallet addresses",
+	"监控已暂停":                                               "monitor paused",
+	"监控已恢复":                                               "monitor resumed",
+	"报警阈值已修改":                                             "alert thresholds changed",
+	"收到立即刷新请求":                                            "received refresh request",
+	"控制接口认证失败":                                            "control API authentication failed",
+	"控制 socket 已启动":                                       "control socket started",
+	"控制 socket 异常退出":                                      "control socket exited unexpectedly",
+	"无法启动控制 socket，tracker ctl 不可用":                       "cannot start control socket, tracker ctl unavailable",
+	"未设置 TRACKER_CONTROL_TOKEN，HTTP 服务不提供控制接口":            "TRACKER_CONTROL_TOKEN not set, HTTP server does not expose control API",
+	"关闭控制 socket 失败":                                      "failed to close control socket",
+	"调试接口认证失败":                                            "debug API authentication failed",
+	"debug_pprof 需要设置 TRACKER_CONTROL_TOKEN，未启用 pprof 端点": "debug_pprof requires TRACKER_CONTROL_TOKEN, pprof endpoints not enabled",
+	"pprof 端点已启用":                                         "pprof endpoints enabled",
+	"调整快照间隔":                                              "adjusted snapshot interval",
+	"快速价格轮询失败":                                            "fast price poll failed",
+	"快速价格轮询触发报警":                                          "fast price poll triggered alert",
+	"跳过无效的钱包":                                             "skipping invalid wallet",
+	"已追加每日汇总到Google Sheets":                               "appended daily summary to Google Sheets",
+	"文件表头已变化，轮转旧文件":                                       "file header changed, rotating old file",
+	"API熔断中，使用上一次获取的代币列表":                                 "circuit open, using last fetched token list",
+	"CSV报告保存路径":                                           "CSV report path",
+	"DAS API分页中断，返回已获取的代币":                                "DAS API pagination interrupted, returning tokens fetched so far",
+	"DAS API分页获取完成":                                       "DAS API pagination finished",
+	"DAS API获取代币完成":                                       "DAS API token fetch finished",
+	"DAS API获取分页失败，返回已获取的代币":                              "DAS API page fetch failed, returning tokens fetched so far",
+	"DAS API获取失败，将使用RPC数据作为备选":                            "DAS API fetch failed, falling back to RPC data",
+	"DAS API获取超时，将使用RPC数据作为备选":                            "DAS API fetch timed out, falling back to RPC data",
+	"DAS获取代币元数据失败":                                        "failed to fetch token metadata via DAS",
+	"DAS资产数量达到上限，停止分页":                                    "DAS asset limit reached, stopping pagination",
+	"HTTP服务已启动":                                           "HTTP server started",
+	"HTTP服务异常退出":                                          "HTTP server exited unexpectedly",
+	"Helius webhook 已启用":                                  "Helius webhook enabled",
+	"无法创建 Helius 服务，跟单信号只使用 webhook 推送的交易":                "cannot create Helius service, copy-trading signals only use transactions pushed by webhook",
+	"跟单信号已启用":                                             "copy-trading signals enabled",
+	"发送跟单信号":                                              "copy-trading signal sent",
+	"Helius webhook 认证失败":                                 "Helius webhook authentication failed",
+	"Helius webhook 需要使用 -serve 启动HTTP服务，已忽略":             "Helius webhook requires -serve to start the HTTP server, ignored",
+	"Jupiter 要求的等待时间过长，放弃本批次":                             "Jupiter requested too long a wait, skipping this batch",
+	"NATS已重新连接":                                           "NATS reconnected",
+	"NATS连接断开":                                            "NATS disconnected",
+	"NFT估值失败":                                             "NFT valuation failed",
+	"NFT估值完成":                                             "NFT valuation finished",
+	"NFT数量达到上限，停止分页":                                      "NFT limit reached, stopping pagination",
+	"RPC代币数据":                                             "RPC token data",
+	"RPC端点健康检查失败":                                         "RPC endpoint health check failed",
+	"RPC端点请求失败，尝试下一个端点":                                   "RPC endpoint request failed, trying next endpoint",
+	"RPC获取SOL余额失败":                                        "failed to fetch SOL balance via RPC",
+	"RPC获取代币账户分页失败，返回已获取的账户":                              "RPC token account page fetch failed, returning accounts fetched so far",
+	"RPC获取代币账户完成":                                         "RPC token account fetch finished",
+	"RPC获取失败":                                             "RPC fetch failed",
+	"Redis价格缓存":                                           "Redis price cache",
+	"WebSocket 推送失败":                                      "WebSocket push failed",
+	"WebSocket 握手失败":                                      "WebSocket handshake failed",
+	"WebSocket订阅成功，实时更新钱包余额":                              "WebSocket subscribed, wallet balances update in real time",
+	"WebSocket订阅断开，改为定时轮询":                                "WebSocket subscription lost, falling back to polling",
+	"WebSocket订阅正常，跳过定时轮询":                                "WebSocket subscription healthy, skipping scheduled poll",
+	"上次价格已超过最长沿用时间，隐藏代币":                                  "last price exceeded maximum stale age, hiding token",
+	"从Birdeye获取价格完成":                                      "Birdeye price fetch finished",
+	"从CoinGecko获取价格完成":                                    "CoinGecko price fetch finished",
+	"从DexScreener获取价格完成":                                  "DexScreener price fetch finished",
+	"从Jupiter获取价格完成":                                      "Jupiter price fetch finished",
+	"从Pyth获取价格完成":                                         "Pyth price fetch finished",
+	"从交叉验证数据源获取价格失败":                                      "failed to fetch prices from cross-check source",
+	"从分组加载钱包地址":                                           "loaded wallet addresses from group",
+	"从存储查询历史快照失败":                                         "failed to query snapshot history from store",
+	"从存储查询组合历史失败":                                         "failed to query portfolio history from store",
+	"从配置文件加载钱包地址":                                         "loaded wallet addresses from config file",
+	"代币价值计算":                                              "token value computed",
+	"代币价格显著变化":                                            "significant token price change",
+	"代币报告":                                                "token report",
+	"代币账户数量达到上限，停止分页":                                     "token account limit reached, stopping pagination",
+	"仪表盘运行失败":                                             "dashboard failed",
+	"价格与交叉验证数据源偏离过大，降低可信度":                                "price diverges from cross-check source, lowering confidence",
+	"价格数据源不可用，使用过期的缓存价格":                                  "price source unavailable, using stale cached prices",
+	"价格无效":                                                "invalid price",
+	"价格更新完成":                                              "price update finished",
+	"价格缓存":                                                "price cache",
+	"价格聚合完成":                                              "price aggregation finished",
+	"价格超出合理范围":                                            "price out of reasonable range",
+	"优先价格数据源获取失败":                                         "primary price source failed",
+	"使用元数据回填代币":                                           "backfilling token from metadata",
+	"使用命令行指定的钱包地址":                                        "using wallet addresses from command line",
+	"使用备用RPC端点":                                           "using fallback RPC endpoint",
+	"保存代币元数据缓存失败":                                         "failed to save token metadata cache",
+	"保存快照到存储失败":                                           "failed to save snapshot to store",
+	"保存盈亏基准失败":                                            "failed to save PnL baseline",
+	"保存监控状态失败":                                            "failed to save monitor state",
+	"停止定时更新":                                              "stopping scheduled updates",
+	"关闭HTTP服务失败":                                          "failed to shut down HTTP server",
+	"关闭时序数据库失败":                                           "failed to close time-series database",
+	"关闭消息总线失败":                                            "failed to close event bus",
+	"写入CSV报告失败":                                           "failed to write CSV report",
+	"写入HTML报告失败":                                          "failed to write HTML report",
+	"写入HTTP响应失败":                                          "failed to write HTTP response",
+	"写入Redis价格缓存失败":                                       "failed to write Redis price cache",
+	"写入报警日志失败":                                            "failed to write alert log",
+	"写入时序数据库失败":                                           "failed to write to time-series database",
+	"写入时序数据库失败，稍后重试":                                      "failed to write to time-series database, retrying later",
+	"写入组合CSV失败":                                           "failed to write portfolio CSV",
+	"列出备份文件失败":                                            "failed to list backup files",
+	"创建CSV文件失败":                                           "failed to create CSV file",
+	"创建RPC代币数据":                                           "created RPC token data",
+	"创建hypertable失败，按普通表写入":                               "failed to create hypertable, writing to a regular table",
+	"创建数据目录失败":                                            "failed to create reports directory",
+	"创建报警日志文件失败":                                          "failed to create alert log file",
+	"创建组合CSV文件失败":                                         "failed to create portfolio CSV file",
+	"删除旧备份失败":                                             "failed to remove old backup",
+	"加载代币元数据缓存失败":                                         "failed to load token metadata cache",
+	"加载盈亏基准失败":                                            "failed to load PnL baseline",
+	"历史快照缓冲区容量":                                           "snapshot history capacity",
+	"压缩备份文件失败":                                            "failed to compress backup file",
+	"发布快照事件失败":                                            "failed to publish snapshot event",
+	"发现高风险代币":                                             "high-risk token detected",
+	"发送Slack每日汇总失败":                                       "failed to send Slack daily summary",
+	"发送快照到webhook失败":                                      "failed to send snapshot to webhook",
+	"发送报警通知失败":                                            "failed to send alert notification",
+	"发送报警邮件失败":                                            "failed to send alert email",
+	"发送追踪数据失败":                                            "failed to send tracing data",
+	"合并RPC和DAS API数据":                                     "merging RPC and DAS API data",
+	"后台刷新价格失败":                                            "background price refresh failed",
+	"处理DAS代币数据":                                           "processing DAS token data",
+	"处理Jupiter价格批次":                                       "processing Jupiter price batch",
+	"处理RPC代币数据":                                           "processing RPC token data",
+	"处理钱包时发生panic":                                        "panic while processing wallet",
+	"备用价格数据源获取失败":                                         "fallback price source failed",
+	"备用价格数据源补充价格":                                         "fallback price source filled in prices",
+	"多实例报警去重失败，照常发送":                                      "cross-instance alert dedupe failed, sending anyway",
+	"完成处理钱包代币信息":                                          "finished processing wallet tokens",
+	"定时更新完成":                                              "scheduled update finished",
+	"导出追踪数据失败":                                            "failed to export tracing data",
+	"已保存监控状态":                                             "saved monitor state",
+	"已创建盈亏基准":                                             "created PnL baseline",
+	"已加载代币元数据缓存":                                          "loaded token metadata cache",
+	"已加载盈亏基准":                                             "loaded PnL baseline",
+	"已发送报警摘要邮件":                                           "sent alert digest email",
+	"已发送静默时段报警汇总":                                         "sent quiet hours alert digest",
+	"已恢复监控状态":                                             "restored monitor state",
+	"已跳过钱包":                                               "skipped wallet",
+	"应用重新加载的配置失败":                                         "failed to apply reloaded config",
+	"开始处理钱包地址":                                            "processing wallet address",
+	"开始定时更新代币列表":                                          "starting scheduled token list updates",
+	"开始并发获取钱包代币信息":                                        "fetching wallet tokens concurrently",
+	"开始执行程序":                                              "starting",
+	"开始更新所有代币价格":                                          "updating all token prices",
+	"开始获取钱包代币列表":                                          "fetching wallet token lists",
+	"恢复监控状态失败":                                            "failed to restore monitor state",
+	"执行增量更新":                                              "running incremental update",
+	"执行定时更新":                                              "running scheduled update",
+	"批次处理失败":                                              "batch failed",
+	"报警处于冷却期，已抑制":                                         "alert suppressed during cooldown",
+	"报警已由其他实例发送":                                          "alert already sent by another instance",
+	"报警日志保存路径":                                            "alert log path",
+	"报警规则求值失败":                                            "failed to evaluate alert rule",
+	"收到 Helius webhook":                                   "received Helius webhook",
+	"收到账户变化通知":                                            "received account change notification",
+	"收到钱包交易推送，更新相关钱包":                                     "received wallet transaction push, updating affected wallets",
+	"数据源未返回价格，沿用上次价格":                                     "price not returned by sources, using last price",
+	"无法创建WebSocket订阅，使用定时轮询":                              "cannot create WebSocket subscription, using polling",
+	"无法监听配置文件变化":                                          "cannot watch config file for changes",
+	"无法解析代币余额":                                            "cannot parse token balance",
+	"无法解析代币数量":                                            "cannot parse token amount",
+	"时序数据库拒绝写入，丢弃该批数据点":                                   "time-series database rejected write, dropping batch",
+	"时序数据库缓存已满，丢弃最旧的数据点":                                  "time-series buffer full, dropping oldest points",
+	"更新代币数据失败":                                            "failed to update token data",
+	"更新价格失败":                                              "failed to update prices",
+	"未找到价格":                                               "price not found",
+	"查询未知代币元数据完成":                                         "finished looking up unknown token metadata",
+	"查询组合K线失败":                                            "failed to query portfolio candles",
+	"检查代币价格变化":                                            "checking token price changes",
+	"检查价格变化":                                              "checking price changes",
+	"检测到链上账户变化，更新代币列表":                                    "on-chain account change detected, updating token list",
+	"汇总周期内没有快照，跳过":                                        "no snapshots in summary period, skipping",
+	"添加SOL余额":                                             "added SOL balance",
+	"添加新的价格快照":                                            "added price snapshot",
+	"熔断器关闭，API已恢复":                                        "circuit closed, API recovered",
+	"熔断器半开，发送探测请求":                                        "circuit half-open, sending probe request",
+	"熔断器打开，暂停请求":                                          "circuit open, pausing requests",
+	"生成HTML报告失败":                                          "failed to generate HTML report",
+	"生成汇总报告失败":                                            "failed to generate summary report",
+	"盈亏基准已重置":                                             "PnL baseline reset",
+	"监控状态已过期，忽略":                                          "monitor state expired, ignoring",
+	"程序执行完成":                                              "finished",
+	"组合价值变化主要由单个代币引起，已由单币报警覆盖":   "portfolio change driven by a single token, already covered by token alert",
+	"组合价值序列保存路径":                 "portfolio series path",
+	"缺少 Helius 配置，代币安全检查不读取链上权限": "Helius not configured, token safety checks skip on-chain authorities",
+	"获取 RugCheck 报告失败":           "failed to fetch RugCheck report",
+	"获取Metaplex元数据失败":            "failed to fetch Metaplex metadata",
+	"获取NFT分页失败，返回已获取的NFT":        "NFT page fetch failed, returning NFTs fetched so far",
+	"获取Token-2022代币账户失败":         "failed to fetch Token-2022 accounts",
+	"获取Token-2022转账手续费失败":        "failed to fetch Token-2022 transfer fee",
+	"获取代币余额失败":                   "failed to fetch token balance",
+	"获取价格失败":                     "failed to fetch prices",
+	"获取到代币价格":                    "got token price",
+	"获取流动性失败":                    "failed to fetch liquidity",
+	"获取质押账户失败":                   "failed to fetch stake accounts",
+	"获取质押账户完成":                   "stake account fetch finished",
+	"获取钱包NFT失败":                  "failed to fetch wallet NFTs",
+	"获取钱包代币失败":                   "failed to fetch wallet tokens",
+	"获取钱包代币完成":                   "wallet token fetch finished",
+	"获取合约仓位失败":                   "failed to fetch perp positions",
+	"获取合约仓位完成":                   "perp position fetch finished",
+	"获取DeFi仓位失败":                 "failed to fetch DeFi positions",
+	"获取DeFi仓位完成":                 "DeFi position fetch finished",
+	"获取LP存入交易失败":                 "failed to fetch LP deposit transactions",
+	"获取钱包交易失败":                   "failed to fetch wallet transactions",
+	"发送跟单信号失败":                   "failed to send copy-trading signal",
+	"获取聪明钱交易失败":                  "failed to fetch smart money transactions",
+	"开始获取交易所余额":                  "fetching exchange balances",
+	"获取交易所余额完成":                  "exchange balance fetch finished",
+	"获取币安报价失败":                   "failed to fetch Binance prices",
+	"获取Coinbase报价失败":             "failed to fetch Coinbase price",
+	"获取集合地板价失败":                  "failed to fetch collection floor price",
+	"被过滤代币合计价值":                  "total value of filtered tokens",
+	"解析 Helius webhook 失败":       "failed to parse Helius webhook",
+	"解析价格失败":                     "failed to parse price",
+	"读取Redis价格缓存失败，直接查询数据源":      "failed to read Redis price cache, querying sources directly",
+	"读取代币权限失败":                   "failed to read token authorities",
+	"过滤规则隐藏代币":                   "token hidden by filter",
+	"退出时仍有数据点未写入":                "unwritten points remain at exit",
+	"配置文件已重新加载":                  "config reloaded",
+	"配置文件监听错误":                   "config watcher error",
+	"重新加载的报警级别设置无效，继续使用旧设置":      "reloaded severity settings invalid, keeping previous settings",
+	"重新加载的报警规则无效，继续使用旧规则":        "reloaded alert rules invalid, keeping previous rules",
+	"重新加载的日志配置无效，继续使用旧配置":        "reloaded logging config invalid, keeping previous config",
+	"重新加载的配置无效，继续使用旧配置":          "reloaded config invalid, keeping previous config",
+	"重新加载配置失败，继续使用旧配置":           "failed to reload config, keeping previous config",
+	"重置盈亏基准，将使用下一次价格更新的结果作为新基准":  "resetting PnL baseline, next price update becomes the new baseline",
+	"重试获取价格":                     "retrying price fetch",
+	"钱包列表已变化，立即更新代币列表":           "wallet list changed, updating token list now",
+	"钱包数据已恢复":                    "wallet data recovered",
+	"价格数据源获取失败":                  "price source failed",
+
+	// 启动失败
+	"创建Parquet导出失败":            "failed to create Parquet exporter",
+	"创建Google Sheets同步失败":      "failed to create Google Sheets sync",
+	"-since 必须为正数":             "-since must be positive",
+	"-limit 必须为正数":             "-limit must be positive",
+	"没有可分析的 Solana 钱包":         "no Solana wallets to analyze",
+	"-output 无效，可选 table/diff": "invalid -output, expected table/diff",
+	"report 需要快照数据库，请在配置文件中设置 sqlite_path 或使用 -db 参数": "report requires a snapshot database, set sqlite_path in the config file or use -db",
+	"创建价格服务失败":                  "failed to create price service",
+	"创建 Helius 服务失败":            "failed to create Helius service",
+	"分析交易对手失败":                  "failed to analyze counterparties",
+	"创建邮件通知失败":                  "failed to create email notifier",
+	"初始化环境失败":                   "failed to initialize environment",
+	"初始化追踪失败":                   "failed to initialize tracing",
+	"加载报警规则失败":                  "failed to load alert rules",
+	"加载配置文件失败":                  "failed to load config file",
+	"应用配置失败":                    "failed to apply config",
+	"打开快照数据库失败":                 "failed to open snapshot database",
+	"日志配置无效":                    "invalid logging config",
+	"生成区间报告失败":                  "failed to generate range report",
+	"获取代币数据失败":                  "failed to fetch token data",
+	"读取SMTP配置失败":                "failed to read SMTP config",
+	"输出JSON失败":                  "failed to write JSON",
+	"运行参数无效":                    "invalid arguments",
+	"连接Redis失败":                 "failed to connect to Redis",
+	"连接时序数据库失败":                 "failed to connect to time-series database",
+	"连接消息总线失败":                  "failed to connect to event bus",
+	"配置Slack每日汇总失败":             "failed to configure Slack daily summary",
+	"配置报警级别失败":                  "failed to configure alert severity",
+	"配置汇总报告失败":                  "failed to configure summary reports",
+	"钱包获取失败，strict 模式下退出":       "wallet fetch failed, exiting in strict mode",
+	"%d 个钱包获取失败，strict 模式下退出\n": "%d wallet(s) failed to fetch, exiting in strict mode\n",
+
+	// wallet 子命令
+	"未知的 wallet 子命令: %s\n\n": "unknown wallet subcommand: %s\n\n",
+	"添加钱包失败:":                "failed to add wallet:",
+	"删除钱包失败:":                "failed to remove wallet:",
+	"读取钱包失败:":                "failed to read wallets:",
+	"修改钱包标签失败:":              "failed to set wallet label:",
+	"已添加钱包 %s 到 %s\n":        "Added wallet %s to %s\n",
+	"已从 %s 删除钱包 %s\n":        "Removed wallet %[2]s from %[1]s\n",
+	"%s 中没有钱包\n":             "No wallets in %s\n",
+	"已删除钱包 %s 的标签\n":         "Removed label of wallet %s\n",
+	"已将钱包 %s 的标签设为 %s\n":     "Set label of wallet %s to %s\n",
+	"链":                      "Chain",
+	"标签":                     "Label",
+	"标记":                     "Tags",
+
+	// ctl 子命令
+	"未知的 ctl 子命令: %s\n\n": "unknown ctl subcommand: %s\n\n",
+	"控制失败:":               "control failed:",
+	"控制失败: %v（可使用 -socket 直接指定控制 socket）\n": "control failed: %v (use -socket to specify the control socket directly)\n",
+	"连接 %s 失败，tracker watch 是否在运行: %v":      "failed to connect to %s, is tracker watch running: %v",
+	"已请求立即刷新持仓":                             "Requested an immediate holdings refresh",
+	"运行中":                                   "running",
+	"已暂停":                                   "paused",
+	"状态: %s\n":                              "Status: %s\n",
+	"快照间隔: %s\n":                            "Snapshot interval: %s\n",
+	"单币报警阈值: %g%%\n":                        "Token alert threshold: %g%%\n",
+	"组合报警阈值: %g%%\n":                        "Portfolio alert threshold: %g%%\n",
+	"组合报警阈值: 已关闭":                           "Portfolio alert threshold: off",
+	"最近快照: %s\n":                            "Last snapshot: %s\n",
+
+	// config 子命令
+	"未知的 config 子命令: %s\n\n": "unknown config subcommand: %s\n\n",
+	"检查配置失败:":                "failed to check config:",
+	"%s: 配置检查通过\n":           "%s: config OK\n",
+	"错误":                     "error",
+	"警告":                     "warning",
+	"%s 第 %d 行: %s":          "%s line %d: %s",
+	"YAML 语法错误: %s":          "YAML syntax error: %s",
+	"未知的字段 %s，该字段会被忽略":                                               "unknown field %s, it will be ignored",
+	"（是否应为 %s？）":                                                     " (did you mean %s?)",
+	"字段类型错误: %s":                                                     "wrong field type: %s",
+	"钱包 %s 重复（与第 %d 行相同），持仓会被重复计算":                                   "wallet %s is duplicated (same as line %d), holdings will be counted twice",
+	"引用的环境变量 %s 未设置，替换后为空":                                           "referenced environment variable %s is not set, it expands to empty",
+	"没有配置钱包，需使用 -wallet 指定要处理的钱包":                                    "no wallets configured, use -wallet to choose the wallet to process",
+	"未设置 %s 环境变量，无法获取交易所 %s 的余额":                                     "%s environment variable not set, cannot fetch balances of exchange %s",
+	"未设置 HELIUS_RPC_ENDPOINT 或 HELIUS_API_KEY 环境变量，无法获取 Solana 钱包持仓": "HELIUS_RPC_ENDPOINT or HELIUS_API_KEY environment variable not set, cannot fetch Solana wallet holdings",
+
+	// 终端仪表盘
+	"变化率":    "Change",
+	"变化率%/s": "Change%/s",
+	"走势":     "Trend",
+	"降序":     "desc",
+	"升序":     "asc",
+	" 总值: $%.2f | 代币数: %d | 更新: %s | 排序: %s(%s) | v/p/c/s 排序  q 退出 ": " Total: $%.2f | Tokens: %d | Updated: %s | Sort: %s(%s) | v/p/c/s sort  q quit ",
+
+	// 用法和参数说明
+	"未知的子命令: %s\n\n":              "unknown subcommand: %s\n\n",
+	"用法: tracker %s\n\n参数:\n":     "Usage: tracker %s\n\nFlags:\n",
+	"无法创建日志文件:":                   "cannot create log file:",
+	"仪表盘运行失败:":                    "dashboard failed:",
+	"watch [参数]":                  "watch [flags]",
+	"snapshot [参数]":               "snapshot [flags]",
+	"report [参数]":                 "report [flags]",
+	"counterparties [参数]":         "counterparties [flags]",
+	"config validate [参数]":        "config validate [flags]",
+	"ctl %s [参数]":                 "ctl %s [flags]",
+	"%s <地址> [参数]":                "%s <address> [flags]",
+	"wallet list [参数]":            "wallet list [flags]",
+	"wallet label <地址> <标签> [参数]": "wallet label <address> <label> [flags]",
+	"钱包配置文件路径":                    "path to the wallet config file",
+	"钱包配置文件路径，为空表示只从 %s* 环境变量读取配置":                         "path to the wallet config file, empty to read config only from %s* environment variables",
+	"钱包配置文件路径，用于确定控制 socket 的位置":                           "path to the wallet config file, used to locate the control socket",
+	"日志级别（debug/info/warn/alert/error），覆盖配置文件中的 log_level": "log level (debug/info/warn/alert/error), overrides log_level in the config file",
+	"日志格式（text/json），覆盖配置文件中的 log_format":                  "log format (text/json), overrides log_format in the config file",
+	"报告不使用颜色（适合CI和日志），覆盖配置文件中的 report_color":               "disable colors in reports (for CI and logs), overrides report_color in the config file",
+	"要分析的钱包地址或 .sol 域名":                                    "wallet address or .sol domain to analyze",
+	"是否处理配置文件中的所有钱包":                                       "process all wallets in the config file",
+	"只处理属于该分组或带有该标签的钱包":                                    "only process wallets in this group or with this tag",
+	"报告中显示代币的最小价值（美元），覆盖配置文件中的 filters.min_value":          "minimum token value (USD) shown in reports, overrides filters.min_value in the config file",
+	"并发获取的钱包数量，覆盖配置文件中的 max_concurrent_wallets":            "number of wallets fetched concurrently, overrides max_concurrent_wallets in the config file",
+	"价格查询的批量大小，覆盖配置文件中的 price_batch_size":                  "price query batch size, overrides price_batch_size in the config file",
+	"监控快照间隔（如 20s），覆盖配置文件中的 monitor_interval":              "monitor snapshot interval (e.g. 20s), overrides monitor_interval in the config file",
+	"代币列表刷新间隔（如 5m），覆盖配置文件中的 refresh_interval":             "token list refresh interval (e.g. 5m), overrides refresh_interval in the config file",
+	"快照SQLite数据库路径，覆盖配置文件中的 sqlite_path":                   "snapshot SQLite database path, overrides sqlite_path in the config file",
+	"HTTP查询服务监听地址（如 :8080），为空则不启动":                         "HTTP query server listen address (e.g. :8080), empty to disable",
+	"组合总价值报警阈值（百分比），0表示关闭":                                 "portfolio value alert threshold (percent), 0 to disable",
+	"丢弃已保存的盈亏基准，以本次启动的持仓重新锚定":                              "discard the saved P&L baseline and re-anchor on holdings at this start",
+	"启动时任一钱包获取失败则以非零状态退出":                                  "exit with non-zero status if any wallet fails to fetch at startup",
+	"任一钱包获取失败时以非零状态退出":                                     "exit with non-zero status if any wallet fails to fetch",
+	"使用终端仪表盘代替文本报告":                                        "use the terminal dashboard instead of text reports",
+	"文本报告格式: table 每次输出完整持仓表，diff 只输出与上次相比的变化":             "text report format: table prints the full holdings table each time, diff prints only changes since the last report",
+	"以JSON格式输出到标准输出":                                       "write JSON to standard output",
+	"报告覆盖的时间范围（如 24h），从当前时间往前推算":                           "time range covered by the report (e.g. 24h), counted back from now",
+	"每个钱包扫描的最近交易数量":                                        "number of recent transactions scanned per wallet",
+	"显示往来最多的前多少个地址，0表示全部":                                  "number of top counterparties to show, 0 for all",
+	"控制 socket 路径，默认使用配置文件中的 control_socket":               "control socket path, defaults to control_socket in the config file",
+	"单币价格/价值报警阈值（百分比）":                                     "token price/value alert threshold (percent)",
+	"钱包标签":    "wallet label",
+	"所属分组":    "wallet group",
+	"逗号分隔的标签": "comma-separated tags",
+	"所在的链（solana/ethereum/base），为空时根据地址推断": "chain (solana/ethereum/base), inferred from the address when empty",
+	`用法: tracker <子命令> [参数]
+
+子命令:
+  watch      持续监控钱包、报警并定时刷新（默认）
+  snapshot   获取一次当前持仓后退出，-json 输出JSON
+  report     根据存储的快照生成区间报告，如 -since 24h
+  wallet     管理配置文件中的钱包: add / remove / list / label
+  config     检查配置文件: validate
+  ctl        控制运行中的 watch: status / pause / resume / refresh / threshold
+  counterparties
+             分析钱包最近的交易，列出往来频繁的地址和资金来源，如 -all -limit 500
+
+使用 tracker <子命令> -h 查看各子命令的参数
+`: `Usage: tracker <subcommand> [flags]
+
+Subcommands:
+  watch      continuously monitor wallets, alert and refresh periodically (default)
+  snapshot   fetch current holdings once and exit, -json for JSON output
+  report     build a period report from stored snapshots, e.g. -since 24h
+  wallet     manage wallets in the config file: add / remove / list / label
+  config     check the config file: validate
+  ctl        control a running watch: status / pause / resume / refresh / threshold
+  counterparties
+             analyze recent transactions and list frequent counterparties and funding sources, e.g. -all -limit 500
+
+Run tracker <subcommand> -h to see the flags of each subcommand
+`,
+	`用法: tracker config <子命令> [参数]
+
+子命令:
+  add-wallet <地址>      添加钱包，同 tracker wallet add
+  remove-wallet <地址>   删除钱包，同 tracker wallet remove
+  validate               检查配置文件：语法、未知字段、重复的钱包、取值范围和未设置的环境变量
+`: `Usage: tracker config <subcommand> [flags]
+
+Subcommands:
+  add-wallet <address>      add a wallet, same as tracker wallet add
+  remove-wallet <address>   remove a wallet, same as tracker wallet remove
+  validate                  check the config file: syntax, unknown fields, duplicate wallets, value ranges and unset environment variables
+`,
+	`用法: tracker ctl <子命令> [参数]
+
+子命令:
+  status                                  查看运行中的监控状态
+  pause                                   暂停快照、价格报警和定时刷新
+  resume                                  恢复暂停的监控
+  refresh                                 立即重新获取所有钱包的持仓
+  threshold -price <%> [-portfolio <%>]   修改报警阈值，重启后恢复为启动参数
+
+通过配置文件中 control_socket 指定的 unix socket 连接运行中的 tracker watch
+`: `Usage: tracker ctl <subcommand> [flags]
+
+Subcommands:
+  status                                  show the state of the running monitor
+  pause                                   pause snapshots, price alerts and periodic refresh
+  resume                                  resume a paused monitor
+  refresh                                 refetch holdings of all wallets now
+  threshold -price <%> [-portfolio <%>]   change alert thresholds, reset to startup flags on restart
+
+Connects to the running tracker watch through the unix socket set by control_socket in the config file
+`,
+	`用法: tracker wallet <子命令> [参数]
+
+子命令:
+  add <地址>            添加钱包，可用 -label/-group/-tags/-chain 设置属性
+  remove <地址>         删除钱包
+  list                  列出配置文件中的钱包
+  label <地址> <标签>   修改钱包的标签，标签为 "" 时删除
+
+修改前会将原配置文件备份为 <文件名>.bak
+`: `Usage: tracker wallet <subcommand> [flags]
+
+Subcommands:
+  add <address>             add a wallet, -label/-group/-tags/-chain set its attributes
+  remove <address>          remove a wallet
+  list                      list wallets in the config file
+  label <address> <label>   change the wallet label, "" removes it
+
+The original config file is backed up as <file>.bak before changes
+`,
+}
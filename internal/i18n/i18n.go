@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// catalogs 各语言的消息目录，以中文原文为键；中文不需要目录
+var catalogs = map[string]map[string]string{
+	English: english,
+}
+
+// active 当前语言及其消息目录
+type active struct {
+	lang    string
+	catalog map[string]string // 为nil表示直接输出中文原文
+}
+
+// current 当前使用的语言，未设置时为中文
+var current atomic.Pointer[active]
+
+// Valid 判断语言代码是否受支持，空字符串表示自动检测
+func Valid(lang string) bool {
+	switch normalize(lang) {
+	case "", Chinese, English:
+		return true
+	}
+	return false
+}
+
+// Detect 返回要使用的语言：配置优先，其次是 LC_ALL、LC_MESSAGES、LANG 环境变量，都未设置时为中文。
+// C/POSIX 语言环境不表示语言偏好，跳过
+func Detect(configured string) string {
+	if lang := normalize(configured); lang != "" {
+		return lang
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		switch lang := normalize(os.Getenv(env)); lang {
+		case "", "c", "posix":
+			continue
+		case English:
+			return English
+		default:
+			return Chinese
+		}
+	}
+	return Chinese
+}
+
+// SetLanguage 切换输出语言，不支持的语言按中文处理
+func SetLanguage(lang string) {
+	lang = normalize(lang)
+	catalog, ok := catalogs[lang]
+	if !ok {
+		lang = Chinese
+	}
+	current.Store(&active{lang: lang, catalog: catalog})
+}
+
+// Language 返回当前的输出语言
+func Language() string {
+	if a := current.Load(); a != nil {
+		return a.lang
+	}
+	return Chinese
+}
+
+// T 翻译消息，目录中没有的消息原样返回
+func T(msg string) string {
+	a := current.Load()
+	if a == nil || a.catalog == nil {
+		return msg
+	}
+	if translated, ok := a.catalog[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf 翻译格式字符串后格式化，译文需保持与原文相同的占位符顺序
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// normalize 将 en_US.UTF-8、zh-CN 等语言环境名转换为语言代码
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// LevelAlert 报警级别，介于 WARN 和 ERROR 之间
+const LevelAlert = slog.Level(6)
+
+// 支持的输出格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// level 全局日志级别，可在运行中调整
+var level slog.LevelVar
+
+// base 当前使用的底层处理器
+var base atomic.Pointer[slog.Handler]
+
+func init() {
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, handlerOptions())
+	base.Store(&h)
+}
+
+// Options 日志配置
+type Options struct {
+	Level  slog.Level
+	Format string    // text / json
+	Output io.Writer // 日志输出目标
+}
+
+// Setup 根据配置初始化日志处理器，并接管标准库 log 包的输出
+func Setup(opts Options) error {
+	var h slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		h = slog.NewTextHandler(opts.Output, handlerOptions())
+	case FormatJSON:
+		h = slog.NewJSONHandler(opts.Output, handlerOptions())
+	default:
+		return fmt.Errorf("未知的日志格式: %s", opts.Format)
+	}
+	level.Set(opts.Level)
+	base.Store(&h)
+	slog.SetDefault(slog.New(&dynamicHandler{}))
+	return nil
+}
+
+// SetLevel 调整全局日志级别
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level 返回当前的全局日志级别
+func Level() slog.Level {
+	return level.Level()
+}
+
+// Enabled 判断指定级别的日志是否会输出
+func Enabled(l slog.Level) bool {
+	return l >= level.Level()
+}
+
+// ParseLevel 解析日志级别名称：DEBUG/INFO/WARN/ALERT/ERROR，不区分大小写
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "", "INFO":
+		return slog.LevelInfo, nil
+	case "WARN", "WARNING":
+		return slog.LevelWarn, nil
+	case "ALERT":
+		return LevelAlert, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("未知的日志级别: %s", name)
+}
+
+// ValidFormat 判断日志格式是否受支持
+func ValidFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "", FormatText, FormatJSON:
+		return true
+	}
+	return false
+}
+
+// For 返回带有 module 字段的模块日志记录器，在 Setup 之前创建也会使用最新的配置
+func For(module string) *slog.Logger {
+	return slog.New(&dynamicHandler{}).With("module", module)
+}
+
+// handlerOptions 处理器选项：共享全局级别，并将自定义级别显示为名称
+func handlerOptions() *slog.HandlerOptions {
+	return &slog.HandlerOptions{
+		Level: &level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				if l, ok := a.Value.Any().(slog.Level); ok && l == LevelAlert {
+					a.Value = slog.StringValue("ALERT")
+				}
+			}
+			return a
+		},
+	}
+}
+
+// dynamicHandler 每次输出时委托给当前的底层处理器
+type dynamicHandler struct {
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (d *dynamicHandler) current() slog.Handler {
+	h := *base.Load()
+	for _, op := range d.ops {
+		h = op(h)
+	}
+	return h
+}
+
+func (d *dynamicHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return (*base.Load()).Enabled(ctx, l)
+}
+
+// Handle 按当前语言翻译日志消息后输出
+func (d *dynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Message = i18n.T(r.Message)
+	return d.current().Handle(ctx, r)
+}
+
+func (d *dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (d *dynamicHandler) WithGroup(name string) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (d *dynamicHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(d.ops), len(d.ops)+1)
+	copy(ops, d.ops)
+	return &dynamicHandler{ops: append(ops, op)}
+}
@@ -0,0 +1,314 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 轮转备份文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var rotateLog = For("rotate")
+
+// RotateConfig 文件轮转设置
+type RotateConfig struct {
+	MaxSize    int64         // 单个文件的最大字节数，0表示不按大小轮转
+	MaxAge     time.Duration // 单个文件的最长写入时间，0表示不按时间轮转
+	MaxBackups int           // 保留的备份数量，0表示全部保留
+	Compress   bool          // 是否用 gzip 压缩备份
+	Daily      bool          // 是否在本地时间跨天后轮转，使每个文件只包含一天的数据
+}
+
+// RotatingFile 按大小和时间自动轮转的追加写入文件，可作为日志和报告的输出目标
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	header   string // 每个新文件开头写入的内容，如CSV表头
+	cfg      RotateConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	day      time.Time // 当前文件内容所属的日期（本地时间零点），用于按天轮转
+
+	millMu sync.Mutex // 串行化备份压缩和清理
+}
+
+// OpenRotatingFile 打开（或创建）轮转文件，新文件会先写入 header；
+// 已有文件的表头与 header 不同（如列或语言发生变化）时先轮转，避免新旧格式的数据混在同一个文件中
+func OpenRotatingFile(path string, cfg RotateConfig, header string) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, header: header, cfg: cfg}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	if !r.headerMatches() {
+		rotateLog.Info("文件表头已变化，轮转旧文件", "file", path)
+		if err := r.rotate(); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// SetConfig 更新轮转设置，从下一次写入开始生效
+func (r *RotatingFile) SetConfig(cfg RotateConfig) {
+	r.mu.Lock()
+	r.cfg = cfg
+	r.mu.Unlock()
+}
+
+// Write 写入数据，写入前超过大小或时间限制时先轮转
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, fmt.Errorf("文件已关闭: %s", r.path)
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// WriteString 写入字符串
+func (r *RotatingFile) WriteString(s string) (int, error) {
+	return r.Write([]byte(s))
+}
+
+// Rotate 立即轮转当前文件
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate()
+}
+
+// Close 关闭文件
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// shouldRotate 判断写入 n 字节前是否需要轮转；只有表头的文件不会轮转
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.size <= int64(len(r.header)) {
+		return false
+	}
+	if r.cfg.MaxSize > 0 && r.size+n > r.cfg.MaxSize {
+		return true
+	}
+	if r.cfg.Daily && !startOfDay(time.Now()).Equal(r.day) {
+		return true
+	}
+	return r.cfg.MaxAge > 0 && time.Since(r.openedAt) >= r.cfg.MaxAge
+}
+
+// headerMatches 判断已有文件开头是否为当前表头，没有表头或文件为空时视为匹配
+func (r *RotatingFile) headerMatches() bool {
+	if r.header == "" || r.size == 0 {
+		return true
+	}
+	file, err := os.Open(r.path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+	buf := make([]byte, len(r.header))
+	if _, err := io.ReadFull(file, buf); err != nil {
+		return false
+	}
+	return string(buf) == r.header
+}
+
+// startOfDay 返回本地时间当天零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// open 以追加模式打开文件，新文件写入表头
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("读取文件信息失败: %v", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	// 已有内容的文件按最后修改时间确定所属日期，重启后跨天的旧文件在下次写入时轮转
+	r.day = startOfDay(r.openedAt)
+	if r.size > 0 {
+		r.day = startOfDay(info.ModTime())
+	}
+	if r.size == 0 && r.header != "" {
+		n, err := file.WriteString(r.header)
+		r.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("写入表头失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// rotate 将当前文件重命名为带时间戳的备份并打开新文件，然后在后台压缩和清理旧备份
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			return fmt.Errorf("关闭文件失败: %v", err)
+		}
+		r.file = nil
+	}
+
+	// 同一毫秒内多次轮转时顺延时间戳，避免覆盖已有备份
+	stamp := time.Now()
+	backup := r.backupName(stamp)
+	for r.backupExists(backup) {
+		stamp = stamp.Add(time.Millisecond)
+		backup = r.backupName(stamp)
+	}
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("重命名备份文件失败: %v", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	cfg := r.cfg
+	go r.mill(backup, cfg)
+	return nil
+}
+
+// backupName 生成备份文件名，如 reports/monitor-2006-01-02T15-04-05.000.csv
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir, name := filepath.Split(r.path)
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext)
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext))
+}
+
+// backupExists 判断备份文件（包括压缩后的）是否已存在
+func (r *RotatingFile) backupExists(backup string) bool {
+	for _, name := range []string{backup, backup + ".gz"} {
+		if _, err := os.Stat(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mill 压缩新的备份并删除超出数量的旧备份
+func (r *RotatingFile) mill(backup string, cfg RotateConfig) {
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	if cfg.Compress {
+		if err := compressFile(backup); err != nil {
+			rotateLog.Warn("压缩备份文件失败", "file", backup, "error", err)
+		}
+	}
+	if cfg.MaxBackups <= 0 {
+		return
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		rotateLog.Warn("列出备份文件失败", "file", r.path, "error", err)
+		return
+	}
+	for len(backups) > cfg.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			rotateLog.Warn("删除旧备份失败", "file", backups[0], "error", err)
+		}
+		backups = backups[1:]
+	}
+}
+
+// backups 列出当前文件的所有备份，按时间从旧到新排列
+func (r *RotatingFile) backups() ([]string, error) {
+	dir, name := filepath.Split(r.path)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		fileName := entry.Name()
+		stamp := strings.TrimSuffix(strings.TrimSuffix(fileName, ".gz"), ext)
+		if entry.IsDir() || !strings.HasPrefix(stamp, prefix) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(stamp, prefix)); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, fileName))
+	}
+	// 时间戳格式按字典序即为时间顺序
+	sort.Slice(backups, func(i, j int) bool {
+		return strings.TrimSuffix(backups[i], ".gz") < strings.TrimSuffix(backups[j], ".gz")
+	})
+	return backups, nil
+}
+
+// compressFile 将文件压缩为 .gz 并删除原文件
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,296 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	accountStreamDebounce    = 3 * time.Second  // 合并短时间内的多次账户变化
+	accountStreamPing        = 30 * time.Second // 心跳间隔，避免空闲连接被服务端关闭
+	accountStreamReadTimeout = 90 * time.Second // 超过该时间没有收到任何消息视为连接断开
+	accountStreamMaxBackoff  = time.Minute      // 重连的最大等待时间
+)
+
+// AccountStream 通过 Solana WebSocket 订阅钱包的 SOL 余额和代币账户变化，
+// 连接断开期间 Connected 返回false，调用方应退回到定时轮询
+type AccountStream struct {
+	endpoint string
+
+	mu      sync.Mutex
+	wallets []string
+
+	connected atomic.Bool
+	reset     chan struct{} // 钱包列表变化时通知当前连接重新订阅
+}
+
+// NewAccountStream 使用环境变量 HELIUS_WS_ENDPOINT 创建账户订阅，未设置时根据 HELIUS_RPC_ENDPOINT 推算
+func NewAccountStream() (*AccountStream, error) {
+	apiKey := os.Getenv("HELIUS_API_KEY")
+	endpoint := os.Getenv("HELIUS_WS_ENDPOINT")
+	if endpoint == "" {
+		rpc := os.Getenv("HELIUS_RPC_ENDPOINT")
+		if rpc == "" || apiKey == "" {
+			return nil, fmt.Errorf("缺少 Helius WebSocket 配置")
+		}
+		endpoint = strings.Replace(strings.Replace(rpc, "https://", "wss://", 1), "http://", "ws://", 1)
+	}
+	if apiKey != "" && !strings.Contains(endpoint, "api-key=") {
+		endpoint = fmt.Sprintf("%s/?api-key=%s", strings.TrimSuffix(endpoint, "/"), apiKey)
+	}
+	return NewAccountStreamWithEndpoint(endpoint), nil
+}
+
+// NewAccountStreamWithEndpoint 使用指定的 WebSocket 端点创建账户订阅
+func NewAccountStreamWithEndpoint(endpoint string) *AccountStream {
+	return &AccountStream{endpoint: endpoint, reset: make(chan struct{}, 1)}
+}
+
+// SetWallets 设置订阅的 Solana 钱包，变化时断开当前连接并重新订阅
+func (s *AccountStream) SetWallets(walletAddrs []string) {
+	s.mu.Lock()
+	s.wallets = append([]string(nil), walletAddrs...)
+	s.mu.Unlock()
+
+	select {
+	case s.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Connected 返回订阅是否正常，断开期间应使用轮询
+func (s *AccountStream) Connected() bool {
+	return s.connected.Load()
+}
+
+// Run 保持订阅直到 ctx 取消，账户变化经合并后通过 onChange 回调；
+// 每次（重新）连接成功后会对所有钱包回调一次，补上断开期间的变化
+func (s *AccountStream) Run(ctx context.Context, onChange func(walletAddrs []string)) {
+	events := make(chan string, 64)
+	go s.debounce(ctx, events, onChange)
+
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := s.session(ctx, events)
+		s.connected.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			// 钱包列表变化，立即重新连接
+			backoff = time.Second
+			continue
+		}
+		if time.Since(started) > accountStreamMaxBackoff {
+			backoff = time.Second
+		}
+		walletLog.Warn("WebSocket订阅断开，改为定时轮询", "error", err, "retry", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > accountStreamMaxBackoff {
+			backoff = accountStreamMaxBackoff
+		}
+	}
+}
+
+// debounce 合并 accountStreamDebounce 内的账户变化后再回调
+func (s *AccountStream) debounce(ctx context.Context, events <-chan string, onChange func([]string)) {
+	pending := make(map[string]bool)
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case wallet := <-events:
+			pending[wallet] = true
+			if timer == nil {
+				timer = time.After(accountStreamDebounce)
+			}
+		case <-timer:
+			wallets := make([]string, 0, len(pending))
+			for wallet := range pending {
+				wallets = append(wallets, wallet)
+			}
+			pending = make(map[string]bool)
+			timer = nil
+			onChange(wallets)
+		}
+	}
+}
+
+// streamMessage 订阅响应和通知中需要的字段
+type streamMessage struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Method string `json:"method"`
+	Params struct {
+		Subscription int `json:"subscription"`
+	} `json:"params"`
+}
+
+// session 建立一次连接并处理通知；钱包列表变化时返回nil，连接出错时返回错误
+func (s *AccountStream) session(ctx context.Context, events chan<- string) error {
+	s.mu.Lock()
+	wallets := s.wallets
+	s.mu.Unlock()
+
+	// 清除连接前积压的重新订阅信号
+	select {
+	case <-s.reset:
+	default:
+	}
+
+	if len(wallets) == 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.reset:
+			return nil
+		}
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	// ctx 取消或钱包列表变化时关闭连接，使读取立即返回
+	var resetting atomic.Bool
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(accountStreamPing)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-s.reset:
+				resetting.Store(true)
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(accountStreamReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(accountStreamReadTimeout))
+	})
+
+	// 请求ID对应的钱包，订阅确认后转为订阅ID对应的钱包
+	requests := make(map[int]string)
+	subscriptions := make(map[int]string)
+	for _, wallet := range wallets {
+		for _, req := range subscribeRequests(wallet, len(requests)+1) {
+			if err := conn.WriteJSON(req); err != nil {
+				return fmt.Errorf("发送订阅请求失败: %v", err)
+			}
+			requests[req["id"].(int)] = wallet
+		}
+	}
+	pending := len(requests)
+
+	emit := func(wallet string) {
+		select {
+		case events <- wallet:
+		case <-ctx.Done():
+		}
+	}
+
+	for {
+		var msg streamMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if resetting.Load() {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("读取消息失败: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(accountStreamReadTimeout))
+
+		switch {
+		case msg.Method != "":
+			if wallet, ok := subscriptions[msg.Params.Subscription]; ok {
+				walletLog.Debug("收到账户变化通知", "wallet", wallet, "method", msg.Method)
+				emit(wallet)
+			}
+		case msg.Error != nil:
+			return fmt.Errorf("订阅失败: %s", msg.Error.Message)
+		default:
+			wallet, ok := requests[msg.ID]
+			if !ok {
+				continue
+			}
+			var subID int
+			if err := json.Unmarshal(msg.Result, &subID); err != nil {
+				return fmt.Errorf("解析订阅响应失败: %v", err)
+			}
+			subscriptions[subID] = wallet
+			delete(requests, msg.ID)
+
+			pending--
+			if pending == 0 {
+				s.connected.Store(true)
+				walletLog.Info("WebSocket订阅成功，实时更新钱包余额", "wallets", len(wallets), "subscriptions", len(subscriptions))
+				for _, wallet := range wallets {
+					emit(wallet)
+				}
+			}
+		}
+	}
+}
+
+// subscribeRequests 生成单个钱包的订阅请求：SOL余额、SPL Token 和 Token-2022 代币账户
+func subscribeRequests(wallet string, firstID int) []map[string]interface{} {
+	// 代币账户中 owner 字段位于偏移32处
+	ownerFilter := map[string]interface{}{
+		"memcmp": map[string]interface{}{"offset": 32, "bytes": wallet},
+	}
+	options := func(filters ...interface{}) map[string]interface{} {
+		opts := map[string]interface{}{"encoding": "base64", "commitment": "confirmed"}
+		if len(filters) > 0 {
+			opts["filters"] = filters
+		}
+		return opts
+	}
+	request := func(id int, method string, params ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+	}
+
+	return []map[string]interface{}{
+		request(firstID, "accountSubscribe", wallet, options()),
+		request(firstID+1, "programSubscribe", tokenProgramID,
+			options(map[string]interface{}{"dataSize": 165}, ownerFilter)),
+		// Token-2022 账户可能带有扩展，不按大小过滤
+		request(firstID+2, "programSubscribe", token2022ProgramID, options(ownerFilter)),
+	}
+}
@@ -0,0 +1,125 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	adaptiveShrink = 0.5  // 波动超过阈值时间隔缩短为原来的比例
+	adaptiveGrow   = 1.25 // 平静时间隔延长为原来的比例
+	adaptiveQuiet  = 0.5  // 波动低于阈值的该比例时视为平静
+)
+
+// adaptiveInterval 根据组合波动调整快照间隔：波动超过阈值时缩短一半，
+// 平静时逐步延长，介于两者之间时保持不变；min 为0表示关闭，固定使用监控间隔
+type adaptiveInterval struct {
+	mu        sync.Mutex
+	min       time.Duration
+	max       time.Duration
+	threshold float64       // 按价值加权的价格波动阈值（%，折算到一个监控间隔）
+	current   time.Duration // 当前使用的快照间隔
+}
+
+// SetAdaptiveInterval 设置自适应快照间隔的上下限和波动阈值（%），min 为0时关闭；
+// 最短间隔参与推算历史快照缓冲区容量，需在 SetAlertWindows 之前设置
+func (m *TokenMonitor) SetAdaptiveInterval(min, max time.Duration, threshold float64) {
+	a := &m.adaptive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.min, a.max, a.threshold = min, max, threshold
+	if min <= 0 {
+		a.current = 0
+		return
+	}
+	if a.current == 0 {
+		a.current = m.interval
+	}
+	a.current = clampDuration(a.current, min, max)
+}
+
+// currentInterval 返回下一次快照前等待的时长
+func (m *TokenMonitor) currentInterval() time.Duration {
+	m.adaptive.mu.Lock()
+	defer m.adaptive.mu.Unlock()
+	if m.adaptive.current > 0 {
+		return m.adaptive.current
+	}
+	return m.interval
+}
+
+// shortestInterval 返回快照间隔可能的最小值，用于推算历史快照缓冲区容量
+func (m *TokenMonitor) shortestInterval() time.Duration {
+	m.adaptive.mu.Lock()
+	defer m.adaptive.mu.Unlock()
+	if m.adaptive.min > 0 && m.adaptive.min < m.interval {
+		return m.adaptive.min
+	}
+	return m.interval
+}
+
+// longestInterval 返回快照间隔可能的最大值，用作查找历史快照的时间容差
+func (m *TokenMonitor) longestInterval() time.Duration {
+	m.adaptive.mu.Lock()
+	defer m.adaptive.mu.Unlock()
+	if m.adaptive.min > 0 && m.adaptive.max > m.interval {
+		return m.adaptive.max
+	}
+	return m.interval
+}
+
+// adjustInterval 根据相邻两次快照之间按价值加权的价格波动调整快照间隔
+func (m *TokenMonitor) adjustInterval(previous, current *PriceSnapshot) {
+	if previous == nil || current == nil {
+		return
+	}
+	elapsed := current.Timestamp.Sub(previous.Timestamp)
+	if elapsed <= 0 {
+		return
+	}
+
+	var weighted, total float64
+	for mintAddr, token := range current.TokenData {
+		old, ok := previous.TokenData[mintAddr]
+		if !ok || old.Price <= 0 || token.Value <= 0 || isStablecoin(token) {
+			continue
+		}
+		weighted += abs(token.Price-old.Price) / old.Price * 100 * token.Value
+		total += token.Value
+	}
+	if total <= 0 {
+		return
+	}
+	// 折算到一个监控间隔，使阈值与当前间隔无关
+	volatility := weighted / total * float64(m.interval) / float64(elapsed)
+
+	a := &m.adaptive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.min <= 0 {
+		return
+	}
+	next := a.current
+	switch {
+	case volatility >= a.threshold:
+		next = time.Duration(float64(a.current) * adaptiveShrink)
+	case volatility < a.threshold*adaptiveQuiet:
+		next = time.Duration(float64(a.current) * adaptiveGrow)
+	}
+	next = clampDuration(next.Round(100*time.Millisecond), a.min, a.max)
+	if next != a.current {
+		monitorLog.Info("调整快照间隔", "interval", next, "previous", a.current, "volatility_pct", volatility)
+		a.current = next
+	}
+}
+
+// clampDuration 将时长限制在 [min, max] 之间
+func clampDuration(d, min, max time.Duration) time.Duration {
+	if d < min {
+		return min
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
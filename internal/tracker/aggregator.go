@@ -0,0 +1,354 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"wallet-tracker/config"
+)
+
+// priceDisagreementPct 各数据源价格偏离中位数超过该比例时降低可信度
+const priceDisagreementPct = 10.0
+
+// QuotePriceService 返回带来源和可信度信息的价格数据源
+type QuotePriceService interface {
+	GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error)
+}
+
+// plainPriceService 将只返回价格的 PriceService 适配为 QuotePriceService
+type plainPriceService struct {
+	service    PriceService
+	source     PriceSource
+	confidence string
+}
+
+// AdaptPriceService 将 PriceService 适配为 QuotePriceService，所有价格使用同一可信度
+func AdaptPriceService(service PriceService, source PriceSource, confidence string) QuotePriceService {
+	return &plainPriceService{service: service, source: source, confidence: confidence}
+}
+
+// GetTokenPrices 获取价格并补充来源和可信度
+func (p *plainPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	raw, err := p.service.GetTokenPrices(ctx, mintAddrs)
+	prices := make(map[string]*TokenPrice, len(raw))
+	now := time.Now()
+	for mintAddr, price := range raw {
+		if price < minPriceUSD || price > maxPriceUSD {
+			continue
+		}
+		prices[mintAddr] = &TokenPrice{
+			Price:           price,
+			Source:          p.source,
+			Timestamp:       now,
+			ConfidenceLevel: p.confidence,
+		}
+	}
+	return prices, err
+}
+
+// PriceAggregator 并发查询多个价格数据源并合并结果
+type PriceAggregator struct {
+	sources   []QuotePriceService
+	preferred []QuotePriceService // 优先使用的数据源（如预言机），有可信价格时直接采用
+	fallbacks []QuotePriceService // 仅用于主数据源缺失价格的代币
+}
+
+// NewPriceAggregator 创建价格聚合器，sources 按优先级排列
+func NewPriceAggregator(sources ...QuotePriceService) *PriceAggregator {
+	return &PriceAggregator{sources: sources}
+}
+
+// AddSource 添加价格数据源
+func (a *PriceAggregator) AddSource(source QuotePriceService) {
+	a.sources = append(a.sources, source)
+}
+
+// AddPreferred 添加优先数据源，其返回的非低可信度价格直接覆盖聚合结果
+func (a *PriceAggregator) AddPreferred(source QuotePriceService) {
+	a.preferred = append(a.preferred, source)
+}
+
+// AddFallback 添加备用价格数据源，只查询主数据源没有价格的代币
+func (a *PriceAggregator) AddFallback(source QuotePriceService) {
+	a.fallbacks = append(a.fallbacks, source)
+}
+
+// NewDefaultPriceAggregator 创建默认的价格聚合器（Jupiter + DexScreener）
+func NewDefaultPriceAggregator() *PriceAggregator {
+	return NewPriceAggregator(
+		NewJupiterPriceService(),
+		NewDexScreenerPriceService(),
+	)
+}
+
+// newPriceSourceByName 根据名称创建价格数据源
+func newPriceSourceByName(name string, settings config.Settings) (QuotePriceService, error) {
+	switch name {
+	case "pyth":
+		return NewPythPriceService(settings.PythFeeds), nil
+	case "coingecko":
+		return NewCoinGeckoPriceService(settings.CoinGecko), nil
+	case "jupiter":
+		return NewJupiterPriceService(), nil
+	case "dexscreener":
+		return NewDexScreenerPriceService(), nil
+	case "birdeye":
+		birdeye, err := NewBirdeyePriceService()
+		if err != nil {
+			return nil, err
+		}
+		return AdaptPriceService(birdeye, PriceSourceBirdeye, "medium"), nil
+	default:
+		return nil, fmt.Errorf("未知的价格数据源: %s", name)
+	}
+}
+
+// NewPriceAggregatorFromConfig 根据运行参数中的数据源配置创建价格聚合器
+func NewPriceAggregatorFromConfig(settings config.Settings) (*PriceAggregator, error) {
+	a := NewPriceAggregator()
+	for _, name := range settings.PriceSources {
+		source, err := newPriceSourceByName(name, settings)
+		if err != nil {
+			return nil, err
+		}
+		a.AddSource(source)
+	}
+	for _, name := range settings.PreferredPriceSources {
+		source, err := newPriceSourceByName(name, settings)
+		if err != nil {
+			return nil, err
+		}
+		a.AddPreferred(source)
+	}
+	for _, name := range settings.FallbackPriceSources {
+		source, err := newPriceSourceByName(name, settings)
+		if err != nil {
+			return nil, err
+		}
+		a.AddFallback(source)
+	}
+	if len(a.sources) == 0 {
+		return nil, fmt.Errorf("至少需要配置一个价格数据源")
+	}
+	return a, nil
+}
+
+var (
+	priceServiceMu sync.RWMutex
+	priceService   QuotePriceService
+)
+
+// SetPriceService 设置 UpdateTokenPrices 使用的价格服务
+func SetPriceService(service QuotePriceService) {
+	priceServiceMu.Lock()
+	defer priceServiceMu.Unlock()
+	priceService = service
+}
+
+// currentPriceService 返回当前价格服务，未设置时使用默认聚合器
+func currentPriceService() QuotePriceService {
+	priceServiceMu.RLock()
+	service := priceService
+	priceServiceMu.RUnlock()
+	if service != nil {
+		return service
+	}
+	return NewDefaultPriceAggregator()
+}
+
+// GetTokenPrices 并发查询所有数据源，按可信度加权取中位数；单个数据源失败时自动使用其余数据源
+func (a *PriceAggregator) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	if len(mintAddrs) == 0 {
+		return make(map[string]*TokenPrice), nil
+	}
+
+	results := make([]map[string]*TokenPrice, len(a.sources))
+	errs := make([]error, len(a.sources))
+
+	var wg sync.WaitGroup
+	for i, source := range a.sources {
+		wg.Add(1)
+		go func(i int, source QuotePriceService) {
+			defer wg.Done()
+			results[i], errs[i] = source.GetTokenPrices(ctx, mintAddrs)
+		}(i, source)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 按代币汇总各数据源的报价
+	quotes := make(map[string][]*TokenPrice)
+	var failed int
+	var lastErr error
+	for i, prices := range results {
+		if errs[i] != nil {
+			// 熔断期间每次快照都会失败，只在调试级别记录
+			level := slog.LevelWarn
+			if errors.Is(errs[i], ErrCircuitOpen) {
+				level = slog.LevelDebug
+			}
+			priceLog.Log(ctx, level, "价格数据源获取失败", "source", fmt.Sprintf("%T", a.sources[i]), "error", errs[i])
+			lastErr = errs[i]
+			if len(prices) == 0 {
+				failed++
+				continue
+			}
+		}
+		for mintAddr, price := range prices {
+			if price != nil && price.Price > 0 {
+				quotes[mintAddr] = append(quotes[mintAddr], price)
+			}
+		}
+	}
+
+	if failed == len(a.sources) {
+		return nil, fmt.Errorf("所有价格数据源均失败: %v", lastErr)
+	}
+
+	prices := make(map[string]*TokenPrice, len(quotes))
+	for mintAddr, mintQuotes := range quotes {
+		prices[mintAddr] = reconcilePrices(mintQuotes)
+	}
+
+	// 优先数据源（如预言机）的可信价格直接覆盖聚合结果
+	for _, source := range a.preferred {
+		preferredPrices, err := source.GetTokenPrices(ctx, mintAddrs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			priceLog.Warn("优先价格数据源获取失败", "source", fmt.Sprintf("%T", source), "error", err)
+		}
+		for mintAddr, price := range preferredPrices {
+			if price != nil && price.Price > 0 && price.ConfidenceLevel != "low" {
+				// 保留聚合结果中的流动性信息
+				if existing, ok := prices[mintAddr]; ok && price.Liquidity == 0 {
+					preferred := *price
+					preferred.Liquidity = existing.Liquidity
+					price = &preferred
+				}
+				prices[mintAddr] = price
+			}
+		}
+	}
+
+	// 主数据源缺失或只有低可信度价格的代币，依次尝试备用数据源
+	for _, fallback := range a.fallbacks {
+		var missing []string
+		for _, mintAddr := range mintAddrs {
+			if price, ok := prices[mintAddr]; !ok || price.ConfidenceLevel == "low" {
+				missing = append(missing, mintAddr)
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+
+		fallbackPrices, err := fallback.GetTokenPrices(ctx, missing)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			priceLog.Warn("备用价格数据源获取失败", "source", fmt.Sprintf("%T", fallback), "error", err)
+		}
+		for mintAddr, price := range fallbackPrices {
+			if price != nil && price.Price > 0 {
+				prices[mintAddr] = price
+			}
+		}
+		priceLog.Info("备用价格数据源补充价格", "priced", len(fallbackPrices), "missing", len(missing))
+	}
+
+	priceLog.Info("价格聚合完成", "priced", len(prices), "requested", len(mintAddrs), "sources_ok", len(a.sources)-failed, "sources", len(a.sources))
+	return prices, nil
+}
+
+// confidenceWeight 可信度对应的权重
+func confidenceWeight(level string) float64 {
+	switch level {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// downgradeConfidence 将可信度降低一级
+func downgradeConfidence(level string) string {
+	switch level {
+	case "high":
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// reconcilePrices 合并同一代币的多个报价：优先忽略低可信度报价，按可信度加权取中位数
+func reconcilePrices(quotes []*TokenPrice) *TokenPrice {
+	if len(quotes) == 1 {
+		return quotes[0]
+	}
+
+	// 有非低可信度报价时忽略低可信度报价
+	usable := make([]*TokenPrice, 0, len(quotes))
+	for _, q := range quotes {
+		if q.ConfidenceLevel != "low" {
+			usable = append(usable, q)
+		}
+	}
+	if len(usable) == 0 {
+		usable = quotes
+	}
+
+	sort.Slice(usable, func(i, j int) bool {
+		return usable[i].Price < usable[j].Price
+	})
+
+	// 加权中位数
+	var totalWeight float64
+	for _, q := range usable {
+		totalWeight += confidenceWeight(q.ConfidenceLevel)
+	}
+	median := usable[len(usable)-1]
+	var cumulative float64
+	for _, q := range usable {
+		cumulative += confidenceWeight(q.ConfidenceLevel)
+		if cumulative >= totalWeight/2 {
+			median = q
+			break
+		}
+	}
+
+	result := *median
+	if len(usable) > 1 {
+		result.Source = PriceSourceAggregated
+	}
+	// 流动性取各数据源中的最大值
+	for _, q := range quotes {
+		if q.Liquidity > result.Liquidity {
+			result.Liquidity = q.Liquidity
+		}
+	}
+
+	// 各数据源分歧过大时降低可信度
+	for _, q := range usable {
+		if abs(q.Price-median.Price)/median.Price*100 > priceDisagreementPct {
+			result.ConfidenceLevel = downgradeConfidence(result.ConfidenceLevel)
+			break
+		}
+	}
+
+	return &result
+}
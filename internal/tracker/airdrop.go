@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	spamDustValue = 1.0  // 未经买入转入、价值低于该值（美元）的代币视为粉尘垃圾代币
+	solFeeDust    = 0.01 // 低于该数量的 SOL 减少视为交易手续费，不算作买入花费
+)
+
+// spamNamePatterns 名称或符号中出现时视为诈骗代币的片段（通常引导用户访问钓鱼网站领取）
+var spamNamePatterns = []string{
+	"http", "www.", "t.me/", ".com", ".io", ".xyz", ".net", ".org", ".app",
+	"claim", "visit", "voucher",
+}
+
+var (
+	unsolicitedMu    sync.RWMutex
+	unsolicitedMints = make(map[string]bool) // 未经买入转入钱包的 mint 地址
+)
+
+// markUnsolicited 记录未经买入转入钱包的代币
+func markUnsolicited(mintAddr string) {
+	unsolicitedMu.Lock()
+	defer unsolicitedMu.Unlock()
+	unsolicitedMints[mintAddr] = true
+}
+
+// isUnsolicited 返回代币是否未经买入转入
+func isUnsolicited(mintAddr string) bool {
+	unsolicitedMu.RLock()
+	defer unsolicitedMu.RUnlock()
+	return unsolicitedMints[mintAddr]
+}
+
+// hasSpamName 返回代币名称或符号是否带有网址、领取等诈骗特征
+func hasSpamName(token *TokenData) bool {
+	text := strings.ToLower(token.Symbol + " " + token.Name)
+	for _, pattern := range spamNamePatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSpamToken 返回代币是否为垃圾代币：名称带有诈骗特征，或未经买入转入且价值只是粉尘
+func isSpamToken(token *TokenData) bool {
+	if hasSpamName(token) {
+		return true
+	}
+	return isUnsolicited(token.MintAddr) && token.Value < spamDustValue
+}
+
+// walletSpent 返回两次刷新之间钱包是否有代币减少（忽略手续费级别的 SOL 减少），
+// 没有任何花费时新出现的代币视为空投
+func walletSpent(previous, current map[string]*TokenData) bool {
+	for mintAddr, before := range previous {
+		after := 0.0
+		if token, ok := current[mintAddr]; ok {
+			after = token.Amount
+		}
+		spent := before.Amount - after
+		if mintAddr == nativeSOLMint || mintAddr == wrappedSOLMint {
+			if spent >= solFeeDust {
+				return true
+			}
+			continue
+		}
+		if spent > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAirdropAlertValue 设置空投报警阈值：未经买入转入的新代币价值达到 value（美元）时报警，负数表示不报警
+func (m *TokenMonitor) SetAirdropAlertValue(value float64) {
+	m.holdings.mu.Lock()
+	defer m.holdings.mu.Unlock()
+	m.holdings.airdropValue = value
+}
+
+// alertAirdrop 对未经买入转入的新代币发出疑似空投报警，价值未达到阈值或价格未知时不报警
+func (m *TokenMonitor) alertAirdrop(wallet string, token *TokenData, now time.Time) {
+	price := m.holdings.lastPrices[token.MintAddr]
+	value := token.Amount * price
+	if price <= 0 || value < m.holdings.airdropValue {
+		return
+	}
+
+	m.emitAlert(Alert{
+		Type:     AlertTypeAirdrop,
+		Wallet:   wallet,
+		MintAddr: token.MintAddr,
+		Chain:    token.Chain,
+		Symbol:   token.Symbol,
+		NewValue: value,
+		Message: i18n.Sprintf("疑似空投 - 钱包 %s 收到未经买入的 %s (%s), 数量 %.4f, 价值 $%.2f",
+			WalletLabel(wallet), displaySymbol(token), token.MintAddr, token.Amount, value),
+		Timestamp: now,
+	})
+}
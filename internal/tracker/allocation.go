@@ -0,0 +1,157 @@
+package tracker
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	stablecoinPegTolerance = 0.02 // 按符号识别的稳定币价格需在 $1 上下2%以内，避免仿冒代币
+
+	hhiModerate     = 1500 // HHI 高于该值视为适度集中
+	hhiConcentrated = 2500 // HHI 高于该值视为高度集中
+)
+
+// allocationTopN 报告中统计的前N大持仓
+var allocationTopN = []int{1, 3, 5, 10}
+
+// stablecoinMints 主流稳定币的 mint 地址
+var stablecoinMints = map[string]bool{
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": true, // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": true, // USDT
+	"2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo": true, // PYUSD
+	"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48":   true, // USDC (Ethereum)
+	"0xdAC17F958D2ee523a2206206994597C13D831ec7":   true, // USDT (Ethereum)
+	"0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913":   true, // USDC (Base)
+}
+
+// stablecoinSymbols 按符号识别的稳定币，需同时满足价格锚定
+var stablecoinSymbols = map[string]bool{
+	"USDC": true, "USDT": true, "PYUSD": true, "DAI": true, "USDS": true,
+	"USDE": true, "FDUSD": true, "USDG": true, "USD1": true,
+}
+
+// isStablecoin 判断代币是否为稳定币
+func isStablecoin(token *TokenData) bool {
+	if stablecoinMints[token.MintAddr] || configuredStablecoin(token.MintAddr) {
+		return true
+	}
+	return stablecoinSymbols[strings.ToUpper(token.Symbol)] &&
+		math.Abs(token.Price-1) <= stablecoinPegTolerance
+}
+
+// AllocationEntry 单个代币的配置占比
+type AllocationEntry struct {
+	Symbol string  `json:"symbol"`
+	Mint   string  `json:"mint"`
+	Value  float64 `json:"value"`
+	Pct    float64 `json:"pct"`
+	Stable bool    `json:"stable"`
+}
+
+// TopExposure 前N大持仓合计占比
+type TopExposure struct {
+	N   int     `json:"n"`
+	Pct float64 `json:"pct"`
+}
+
+// Allocation 组合的配置和集中度分析
+type Allocation struct {
+	TotalValue        float64           `json:"total_value"`
+	Holdings          []AllocationEntry `json:"holdings"`           // 按价值降序
+	HHI               float64           `json:"hhi"`                // 赫芬达尔指数（0-10000），越高越集中
+	EffectiveHoldings float64           `json:"effective_holdings"` // 等效持仓数（10000/HHI）
+	Concentration     string            `json:"concentration"`      // 集中度等级
+	TopN              []TopExposure     `json:"top_n"`
+	StableValue       float64           `json:"stable_value"`
+	VolatileValue     float64           `json:"volatile_value"`
+	StablePct         float64           `json:"stable_pct"`
+}
+
+// AnalyzeAllocation 计算组合的配置占比、HHI集中度、前N大持仓占比和稳定币/波动资产比例
+func AnalyzeAllocation(tokens []*TokenData) *Allocation {
+	alloc := &Allocation{Holdings: make([]AllocationEntry, 0, len(tokens))}
+	for _, token := range tokens {
+		if token.Value <= 0 {
+			continue
+		}
+		stable := isStablecoin(token)
+		alloc.TotalValue += token.Value
+		if stable {
+			alloc.StableValue += token.Value
+		} else {
+			alloc.VolatileValue += token.Value
+		}
+		alloc.Holdings = append(alloc.Holdings, AllocationEntry{
+			Symbol: displaySymbol(token),
+			Mint:   token.MintAddr,
+			Value:  token.Value,
+			Stable: stable,
+		})
+	}
+	if alloc.TotalValue <= 0 {
+		return alloc
+	}
+
+	sort.Slice(alloc.Holdings, func(i, j int) bool {
+		return alloc.Holdings[i].Value > alloc.Holdings[j].Value
+	})
+	for i := range alloc.Holdings {
+		pct := alloc.Holdings[i].Value / alloc.TotalValue * 100
+		alloc.Holdings[i].Pct = pct
+		alloc.HHI += pct * pct
+	}
+	alloc.EffectiveHoldings = 10000 / alloc.HHI
+	alloc.Concentration = concentrationLevel(alloc.HHI)
+	alloc.StablePct = alloc.StableValue / alloc.TotalValue * 100
+
+	for _, n := range allocationTopN {
+		var pct float64
+		for i := 0; i < n && i < len(alloc.Holdings); i++ {
+			pct += alloc.Holdings[i].Pct
+		}
+		alloc.TopN = append(alloc.TopN, TopExposure{N: n, Pct: pct})
+		if n >= len(alloc.Holdings) {
+			break
+		}
+	}
+	return alloc
+}
+
+// concentrationLevel 按 HHI 划分集中度等级
+func concentrationLevel(hhi float64) string {
+	switch {
+	case hhi > hhiConcentrated:
+		return "高度集中"
+	case hhi > hhiModerate:
+		return "适度集中"
+	default:
+		return "分散"
+	}
+}
+
+// generateAllocationSection 生成资产配置部分
+func generateAllocationSection(tokens []*TokenData) string {
+	alloc := AnalyzeAllocation(tokens)
+	if alloc.TotalValue <= 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T("\n资产配置 (Allocation)\n"))
+	sb.WriteString(strings.Repeat("-", 60) + "\n")
+	sb.WriteString(i18n.Sprintf("集中度: HHI %.0f (%s)，等效持仓数 %.1f\n",
+		alloc.HHI, i18n.T(alloc.Concentration), alloc.EffectiveHoldings))
+
+	exposures := make([]string, 0, len(alloc.TopN))
+	for _, top := range alloc.TopN {
+		exposures = append(exposures, i18n.Sprintf("前%d %.2f%%", top.N, top.Pct))
+	}
+	sb.WriteString(i18n.T("持仓占比: ") + strings.Join(exposures, " / ") + "\n")
+	sb.WriteString(i18n.Sprintf("稳定币: $%.2f (%.2f%%)  波动资产: $%.2f (%.2f%%)\n",
+		alloc.StableValue, alloc.StablePct, alloc.VolatileValue, 100-alloc.StablePct))
+	return sb.String()
+}
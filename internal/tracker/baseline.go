@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BaselineToken 基准快照中单个代币的数据
+type BaselineToken struct {
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+	Value  float64 `json:"value"`
+}
+
+// Baseline 用于计算未实现盈亏的基准快照
+type Baseline struct {
+	CreatedAt  time.Time                 `json:"created_at"`
+	TotalValue float64                   `json:"total_value"`
+	Tokens     map[string]*BaselineToken `json:"tokens"` // mint地址 -> 基准数据
+}
+
+var (
+	baselineMu   sync.Mutex
+	baseline     *Baseline
+	baselinePath string
+)
+
+// NewBaseline 根据当前代币列表创建基准快照
+func NewBaseline(tokens []*TokenData) *Baseline {
+	b := &Baseline{
+		CreatedAt: time.Now(),
+		Tokens:    make(map[string]*BaselineToken),
+	}
+	for _, token := range tokens {
+		if token.Price <= 0 {
+			continue
+		}
+		b.Tokens[token.MintAddr] = &BaselineToken{
+			Symbol: token.Symbol,
+			Amount: token.Amount,
+			Price:  token.Price,
+			Value:  token.Value,
+		}
+		b.TotalValue += token.Value
+	}
+	return b
+}
+
+// LoadBaseline 从JSON文件加载基准快照
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("解析基准文件失败: %v", err)
+	}
+	if b.Tokens == nil {
+		b.Tokens = make(map[string]*BaselineToken)
+	}
+	return &b, nil
+}
+
+// SaveBaseline 保存基准快照到JSON文件
+func SaveBaseline(path string, b *Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化基准失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建基准目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存基准文件失败: %v", err)
+	}
+	return nil
+}
+
+// InitBaseline 设置基准文件路径并尝试加载已有基准；reset 为 true 时丢弃已有基准
+func InitBaseline(path string, reset bool) {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+
+	baselinePath = path
+	baseline = nil
+	if reset {
+		monitorLog.Info("重置盈亏基准，将使用下一次价格更新的结果作为新基准")
+		return
+	}
+
+	b, err := LoadBaseline(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			monitorLog.Error("加载盈亏基准失败", "error", err)
+		}
+		return
+	}
+	baseline = b
+	monitorLog.Info("已加载盈亏基准", "created_at", b.CreatedAt, "tokens", len(b.Tokens), "value", b.TotalValue)
+}
+
+// ResetBaseline 丢弃当前基准，下一次价格更新时重新锚定
+func ResetBaseline() {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+	baseline = nil
+	monitorLog.Info("盈亏基准已重置")
+}
+
+// applyBaseline 根据基准计算每个代币的未实现盈亏；基准不存在时以当前代币创建基准
+func applyBaseline(tokens []*TokenData) {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+
+	if baselinePath == "" {
+		return
+	}
+
+	if baseline == nil {
+		baseline = NewBaseline(tokens)
+		if err := SaveBaseline(baselinePath, baseline); err != nil {
+			monitorLog.Error("保存盈亏基准失败", "error", err)
+		} else {
+			monitorLog.Info("已创建盈亏基准", "tokens", len(baseline.Tokens), "value", baseline.TotalValue)
+		}
+	}
+
+	for _, token := range tokens {
+		base, ok := baseline.Tokens[token.MintAddr]
+		if !ok || base.Price <= 0 {
+			// 基准之后才出现的代币没有成本
+			token.HasBasis = false
+			token.PnL = 0
+			token.PnLPct = 0
+			continue
+		}
+		token.HasBasis = true
+		token.PnL = token.Amount * (token.Price - base.Price)
+		token.PnLPct = (token.Price/base.Price - 1) * 100
+	}
+}
+
+// missingBaselineTokens 返回基准中存在但当前持仓中已消失的代币数量
+func missingBaselineTokens(tokens []*TokenData) int {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+
+	if baseline == nil {
+		return 0
+	}
+	current := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		current[token.MintAddr] = true
+	}
+	missing := 0
+	for mintAddr := range baseline.Tokens {
+		if !current[mintAddr] {
+			missing++
+		}
+	}
+	return missing
+}
+
+// summarizePnL 汇总有成本的代币的未实现盈亏
+func summarizePnL(tokens []*TokenData) (pnl float64, pnlPct float64, ok bool) {
+	var basisValue float64
+	for _, token := range tokens {
+		if !token.HasBasis {
+			continue
+		}
+		ok = true
+		pnl += token.PnL
+		basisValue += token.Value - token.PnL
+	}
+	if basisValue > 0 {
+		pnlPct = pnl / basisValue * 100
+	}
+	return pnl, pnlPct, ok
+}
+
+// formatPnL 格式化盈亏显示，没有成本时显示 N/A
+func formatPnL(token *TokenData) string {
+	if !token.HasBasis {
+		return "N/A"
+	}
+	return fmt.Sprintf("%+.2f (%+.2f%%)", token.PnL, token.PnLPct)
+}
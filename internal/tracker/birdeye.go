@@ -0,0 +1,117 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	birdeyeAPIEndpoint = "https://public-api.birdeye.so/defi/multi_price"
+	birdeyeBatchSize   = 100 // Birdeye multi_price 单次最多查询100个地址
+	birdeyeDefaultRPS  = 1.0 // 免费额度下的默认请求频率
+)
+
+// BirdeyePriceService Birdeye价格服务，实现 PriceService 接口
+type BirdeyePriceService struct {
+	client    *http.Client
+	baseURL   string
+	apiKey    string
+	batchSize int
+	limiter   *rateLimiter
+}
+
+// NewBirdeyePriceService 使用环境变量 BIRDEYE_API_KEY / BIRDEYE_RPS 创建 Birdeye 价格服务
+func NewBirdeyePriceService() (*BirdeyePriceService, error) {
+	apiKey := os.Getenv("BIRDEYE_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("缺少 Birdeye API 配置")
+	}
+
+	rps := birdeyeDefaultRPS
+	if v := os.Getenv("BIRDEYE_RPS"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("BIRDEYE_RPS 无效: %s", v)
+		}
+		rps = parsed
+	}
+
+	return NewBirdeyePriceServiceWithConfig(birdeyeAPIEndpoint, apiKey, rps, nil), nil
+}
+
+// NewBirdeyePriceServiceWithConfig 使用指定的端点、API密钥、请求频率和HTTP客户端创建 Birdeye 价格服务
+func NewBirdeyePriceServiceWithConfig(baseURL, apiKey string, rps float64, client *http.Client) *BirdeyePriceService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &BirdeyePriceService{
+		client:    client,
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		batchSize: birdeyeBatchSize,
+		limiter:   newRateLimiter(rps, 1),
+	}
+}
+
+// GetTokenPrices 批量获取代币价格
+func (s *BirdeyePriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	mintAddrs = solanaMints(mintAddrs)
+
+	for i := 0; i < len(mintAddrs); i += s.batchSize {
+		end := i + s.batchSize
+		if end > len(mintAddrs) {
+			end = len(mintAddrs)
+		}
+		batch := mintAddrs[i:end]
+
+		if err := s.limiter.Wait(ctx); err != nil {
+			return prices, err
+		}
+
+		url := fmt.Sprintf("%s?list_address=%s", s.baseURL, strings.Join(batch, ","))
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return prices, fmt.Errorf("创建请求失败: %v", err)
+		}
+		req.Header.Set("X-API-KEY", s.apiKey)
+		req.Header.Set("x-chain", "solana")
+
+		resp, err := s.client.Do(req)
+		observeSourceResponse("birdeye", resp, err)
+		if err != nil {
+			return prices, fmt.Errorf("请求失败: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return prices, fmt.Errorf("Birdeye 返回状态码 %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Success bool `json:"success"`
+			Data    map[string]*struct {
+				Value float64 `json:"value"`
+			} `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return prices, fmt.Errorf("解析响应失败: %v", err)
+		}
+
+		for mintAddr, data := range result.Data {
+			if data == nil || data.Value < minPriceUSD || data.Value > maxPriceUSD {
+				continue
+			}
+			prices[mintAddr] = data.Value
+		}
+	}
+
+	priceLog.Info("从Birdeye获取价格完成", "priced", len(prices), "requested", len(mintAddrs))
+	return prices, nil
+}
@@ -0,0 +1,55 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+
+	"wallet-tracker/config"
+
+	"github.com/portto/solana-go-sdk/client"
+)
+
+// ChainClient 获取某条链上钱包持仓的客户端
+type ChainClient interface {
+	// Chain 返回链名称
+	Chain() string
+	// FetchTokens 获取钱包在该链上的代币持仓
+	FetchTokens(ctx context.Context, walletAddr string) ([]*TokenData, error)
+}
+
+// solanaChainClient 使用 Helius RPC/DAS 获取 Solana 持仓
+type solanaChainClient struct {
+	rpc *client.Client
+	cfg *config.Config
+}
+
+// Chain 返回链名称
+func (s *solanaChainClient) Chain() string {
+	return config.ChainSolana
+}
+
+// FetchTokens 获取钱包在 Solana 上的代币持仓
+func (s *solanaChainClient) FetchTokens(ctx context.Context, walletAddr string) ([]*TokenData, error) {
+	return FetchWalletTokens(ctx, walletAddr, s.rpc, s.cfg)
+}
+
+// newChainClient 获取钱包持仓时创建链客户端的函数，测试中替换为模拟客户端
+var newChainClient = NewChainClient
+
+// NewChainClient 根据链名称创建对应的客户端
+func NewChainClient(chain string, rpc *client.Client, cfg *config.Config) (ChainClient, error) {
+	switch chain {
+	case "", config.ChainSolana:
+		return &solanaChainClient{rpc: rpc, cfg: cfg}, nil
+	case config.ChainEthereum, config.ChainBase:
+		var tokens []config.TokenConfig
+		if cfg != nil {
+			tokens = cfg.ChainTokens(chain)
+		}
+		return NewEVMChainClient(chain, tokens)
+	case config.ChainExchange:
+		return NewExchangeClient(), nil
+	default:
+		return nil, fmt.Errorf("不支持的链: %s", chain)
+	}
+}
@@ -0,0 +1,161 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 熔断器默认设置
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = time.Minute
+)
+
+// ErrCircuitOpen 熔断器打开期间直接拒绝请求
+var ErrCircuitOpen = errors.New("熔断器已打开")
+
+// breakerState 熔断器状态
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // 正常放行
+	breakerOpen                         // 拒绝所有请求
+	breakerHalfOpen                     // 冷却结束，放行一个探测请求
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker 外部API熔断器：连续失败达到阈值后打开，冷却期内直接拒绝请求，
+// 冷却结束后放行一个探测请求，成功则恢复，失败则重新打开
+type CircuitBreaker struct {
+	name string
+
+	mu        sync.Mutex
+	threshold int           // 连续失败次数阈值，0表示不熔断
+	cooldown  time.Duration // 打开后等待多久开始探测
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool // 半开状态下是否已有探测请求在进行
+}
+
+// 各外部API共用的熔断器
+var (
+	heliusBreaker  = NewCircuitBreaker("helius", defaultBreakerThreshold, defaultBreakerCooldown)
+	jupiterBreaker = NewCircuitBreaker("jupiter", defaultBreakerThreshold, defaultBreakerCooldown)
+)
+
+// NewCircuitBreaker 创建熔断器，threshold<=0 表示不熔断
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{name: name, threshold: threshold, cooldown: cooldown}
+}
+
+// SetCircuitBreakerConfig 设置 Helius 和 Jupiter 熔断器的失败阈值和冷却时间，threshold<=0 表示关闭熔断
+func SetCircuitBreakerConfig(threshold int, cooldown time.Duration) {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	for _, b := range []*CircuitBreaker{heliusBreaker, jupiterBreaker} {
+		b.configure(threshold, cooldown)
+	}
+}
+
+func (b *CircuitBreaker) configure(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold = threshold
+	b.cooldown = cooldown
+	if threshold <= 0 {
+		b.state, b.failures, b.probing = breakerClosed, 0, false
+	}
+}
+
+// Allow 判断是否放行请求；半开状态下只放行一个探测请求
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.state = breakerHalfOpen
+		b.probing = false
+		fallthrough
+	case breakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.probing = true
+		httpLog.Info("熔断器半开，发送探测请求", "api", b.name)
+	}
+	return nil
+}
+
+// Record 记录请求结果，只在状态变化时输出日志
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		if b.state != breakerClosed {
+			httpLog.Info("熔断器关闭，API已恢复", "api", b.name)
+		}
+		b.state, b.failures, b.probing = breakerClosed, 0, false
+		return
+	}
+
+	b.failures++
+	if b.threshold <= 0 {
+		return
+	}
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state != breakerOpen {
+			httpLog.Warn("熔断器打开，暂停请求", "api", b.name, "failures", b.failures, "cooldown", b.cooldown)
+		}
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// State 返回熔断器当前状态
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
+
+// Do 经过熔断器发送请求；网络错误、429和5xx响应计为失败，调用方取消的请求不计入
+func (b *CircuitBreaker) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := b.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// 请求被取消，释放探测机会但不改变状态
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+	case err != nil:
+		b.Record(false)
+	default:
+		b.Record(resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500)
+	}
+	return resp, err
+}
@@ -0,0 +1,230 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"wallet-tracker/config"
+)
+
+const (
+	coinGeckoAPIEndpoint = "https://api.coingecko.com/api/v3"
+	coinGeckoBatchSize   = 30              // 按合约地址查询时单次最多查询的地址数
+	coinGeckoMaxStale    = 5 * time.Minute // 超过该时间未更新的价格视为低可信度
+)
+
+// defaultCoinGeckoIDs 主流代币 mint 地址到 CoinGecko 币种ID的映射
+var defaultCoinGeckoIDs = map[string]string{
+	"So11111111111111111111111111111111111111111":  "solana",                  // SOL（原生余额）
+	"So11111111111111111111111111111111111111112":  "solana",                  // wSOL
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": "usd-coin",                // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": "tether",                  // USDT
+	"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN":  "jupiter-exchange-solana", // JUP
+	"jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL":  "jito-governance-token",   // JTO
+	"HZ1JovNiVvGrGNiiYvEozEVgZ58xaU3RKwX8eACQBCt3": "pyth-network",            // PYTH
+	"DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263": "bonk",                    // BONK
+	"EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm": "dogwifcoin",              // WIF
+	"mSoLzYCxHdYgdzU16g5QSh3i5K3z3KZK7ytfqcJm7So":  "msol",                    // mSOL
+	"J1toso1uCk3RLmjorhTtrVwY9HJ7X8V9yYac6Y7kGCPn": "jito-staked-sol",         // JitoSOL
+}
+
+// CoinGeckoPriceService 基于 CoinGecko 的价格服务：主流代币按币种ID查询，
+// 其余 Solana 代币可按合约地址查询
+type CoinGeckoPriceService struct {
+	client         *http.Client
+	baseURL        string
+	apiKey         string
+	ids            map[string]string // mint地址 -> CoinGecko 币种ID
+	contractLookup bool
+}
+
+// NewCoinGeckoPriceService 使用环境变量 COINGECKO_API_KEY（可选）创建 CoinGecko 价格服务
+func NewCoinGeckoPriceService(cfg config.CoinGecko) *CoinGeckoPriceService {
+	baseURL := coinGeckoAPIEndpoint
+	if endpoint := os.Getenv("COINGECKO_API_ENDPOINT"); endpoint != "" {
+		baseURL = strings.TrimSuffix(endpoint, "/")
+	}
+	return NewCoinGeckoPriceServiceWithConfig(baseURL, os.Getenv("COINGECKO_API_KEY"), cfg, nil)
+}
+
+// NewCoinGeckoPriceServiceWithConfig 使用指定的端点、API密钥和HTTP客户端创建 CoinGecko 价格服务，client 为nil时使用共享客户端
+func NewCoinGeckoPriceServiceWithConfig(baseURL, apiKey string, cfg config.CoinGecko, client *http.Client) *CoinGeckoPriceService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+
+	ids := make(map[string]string, len(defaultCoinGeckoIDs)+len(cfg.IDs))
+	for mint, id := range defaultCoinGeckoIDs {
+		ids[mint] = id
+	}
+	for mint, id := range cfg.IDs {
+		ids[mint] = id
+	}
+
+	return &CoinGeckoPriceService{
+		client:         client,
+		baseURL:        baseURL,
+		apiKey:         apiKey,
+		ids:            ids,
+		contractLookup: cfg.ContractLookup,
+	}
+}
+
+// coinGeckoQuote CoinGecko 价格接口返回的单个报价
+type coinGeckoQuote struct {
+	USD           float64 `json:"usd"`
+	LastUpdatedAt int64   `json:"last_updated_at"`
+}
+
+// GetTokenPrices 获取代币价格：已映射币种ID的代币按ID查询，开启合约查询时其余 Solana 代币按合约地址查询
+func (s *CoinGeckoPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	prices := make(map[string]*TokenPrice)
+
+	// 币种ID -> 使用该ID的mint地址
+	idMints := make(map[string][]string)
+	var contracts []string
+	for _, mintAddr := range mintAddrs {
+		if id, ok := s.ids[mintAddr]; ok {
+			idMints[id] = append(idMints[id], mintAddr)
+		} else if s.contractLookup && config.DetectChain(mintAddr) == config.ChainSolana {
+			contracts = append(contracts, mintAddr)
+		}
+	}
+
+	var lastErr error
+	if len(idMints) > 0 {
+		ids := make([]string, 0, len(idMints))
+		for id := range idMints {
+			ids = append(ids, id)
+		}
+		query := url.Values{}
+		query.Set("ids", strings.Join(ids, ","))
+		quotes, err := s.fetch(ctx, "/simple/price", query)
+		if err != nil {
+			lastErr = err
+		}
+		for id, quote := range quotes {
+			for _, mintAddr := range idMints[id] {
+				s.addPrice(prices, mintAddr, quote)
+			}
+		}
+	}
+
+	for i := 0; i < len(contracts); i += coinGeckoBatchSize {
+		end := i + coinGeckoBatchSize
+		if end > len(contracts) {
+			end = len(contracts)
+		}
+		batch := contracts[i:end]
+		query := url.Values{}
+		query.Set("contract_addresses", strings.Join(batch, ","))
+		quotes, err := s.fetch(ctx, "/simple/token_price/solana", query)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		// 返回的合约地址可能是小写
+		for _, mintAddr := range batch {
+			quote, ok := quotes[mintAddr]
+			if !ok {
+				quote, ok = quotes[strings.ToLower(mintAddr)]
+			}
+			if ok {
+				s.addPrice(prices, mintAddr, quote)
+			}
+		}
+	}
+
+	priceLog.Info("从CoinGecko获取价格完成", "priced", len(prices), "requested", len(mintAddrs))
+	return prices, lastErr
+}
+
+// fetch 请求 CoinGecko 价格接口
+func (s *CoinGeckoPriceService) fetch(ctx context.Context, path string, query url.Values) (map[string]coinGeckoQuote, error) {
+	query.Set("vs_currencies", "usd")
+	query.Set("include_last_updated_at", "true")
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	if s.apiKey != "" {
+		// Pro 端点使用 x-cg-pro-api-key，公共端点使用 Demo 密钥
+		if strings.Contains(s.baseURL, "pro-api.") {
+			req.Header.Set("x-cg-pro-api-key", s.apiKey)
+		} else {
+			req.Header.Set("x-cg-demo-api-key", s.apiKey)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	observeSourceResponse("coingecko", resp, err)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CoinGecko 返回状态码 %d", resp.StatusCode)
+	}
+
+	var quotes map[string]coinGeckoQuote
+	if err := json.NewDecoder(resp.Body).Decode(&quotes); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return quotes, nil
+}
+
+// addPrice 校验报价并按更新时间估算可信度
+func (s *CoinGeckoPriceService) addPrice(prices map[string]*TokenPrice, mintAddr string, quote coinGeckoQuote) {
+	if quote.USD < minPriceUSD || quote.USD > maxPriceUSD {
+		return
+	}
+	updated := time.Now()
+	confidence := "high"
+	if quote.LastUpdatedAt > 0 {
+		updated = time.Unix(quote.LastUpdatedAt, 0)
+		if time.Since(updated) > coinGeckoMaxStale {
+			confidence = "low"
+		}
+	}
+	// 按合约地址查到的长尾代币报价通常来自少数交易对
+	if _, mapped := s.ids[mintAddr]; !mapped && confidence == "high" {
+		confidence = "medium"
+	}
+	prices[mintAddr] = &TokenPrice{
+		Price:           quote.USD,
+		Source:          PriceSourceCoinGecko,
+		Timestamp:       updated,
+		ConfidenceLevel: confidence,
+	}
+}
+
+// quotePrices 将 QuotePriceService 适配为只返回价格的 PriceService
+type quotePrices struct {
+	service QuotePriceService
+}
+
+// PlainPrices 将 QuotePriceService 适配为 PriceService，用于交叉验证等只需要价格的场景
+func PlainPrices(service QuotePriceService) PriceService {
+	return &quotePrices{service: service}
+}
+
+// GetTokenPrices 获取价格，忽略来源和可信度
+func (q *quotePrices) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]float64, error) {
+	quotes, err := q.service.GetTokenPrices(ctx, mintAddrs)
+	prices := make(map[string]float64, len(quotes))
+	for mintAddr, quote := range quotes {
+		if quote != nil && quote.Price > 0 {
+			prices[mintAddr] = quote.Price
+		}
+	}
+	return prices, err
+}
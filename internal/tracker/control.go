@@ -0,0 +1,214 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// controlMaxBody 控制请求体上限
+const controlMaxBody = 1 << 16
+
+// ControlStatus 运行时控制接口返回的监控状态
+type ControlStatus struct {
+	Paused             bool       `json:"paused"`
+	AlertThreshold     float64    `json:"alert_threshold"`     // 单币价格/价值报警阈值（%）
+	PortfolioThreshold float64    `json:"portfolio_threshold"` // 组合总价值报警阈值（%），0表示关闭
+	Interval           string     `json:"interval"`            // 当前的快照间隔
+	LastSnapshot       *time.Time `json:"last_snapshot,omitempty"`
+}
+
+// ThresholdRequest 修改报警阈值的请求，未设置的字段保持不变
+type ThresholdRequest struct {
+	Price     *float64 `json:"price,omitempty"`
+	Portfolio *float64 `json:"portfolio,omitempty"`
+}
+
+// Pause 暂停快照和价格报警，钱包持仓不再定时刷新，直到调用 Resume
+func (m *TokenMonitor) Pause() {
+	if !m.paused.Swap(true) {
+		monitorLog.Info("监控已暂停")
+	}
+}
+
+// Resume 恢复暂停的快照和价格报警
+func (m *TokenMonitor) Resume() {
+	if m.paused.Swap(false) {
+		monitorLog.Info("监控已恢复")
+	}
+}
+
+// Paused 返回监控是否处于暂停状态
+func (m *TokenMonitor) Paused() bool {
+	return m.paused.Load()
+}
+
+// ControlStatus 返回运行时控制接口展示的状态
+func (m *TokenMonitor) ControlStatus() ControlStatus {
+	price, portfolio := m.alertThresholds()
+	return ControlStatus{
+		Paused:             m.Paused(),
+		AlertThreshold:     price,
+		PortfolioThreshold: portfolio,
+		Interval:           m.currentInterval().String(),
+		LastSnapshot:       optionalTime(m.lastSnapshot()),
+	}
+}
+
+// ControlHandler 运行时控制接口：暂停/恢复监控、立即刷新持仓和修改报警阈值，
+// 路由为 <前缀>/status、/pause、/resume、/refresh、/threshold
+type ControlHandler struct {
+	monitor   *TokenMonitor
+	token     string // 请求需在 Authorization 头中附带 "Bearer <token>"，为空表示不校验（unix socket 由文件权限保护）
+	onRefresh func()
+}
+
+// NewControlHandler 创建运行时控制接口，onRefresh 在收到刷新请求时调用
+func NewControlHandler(monitor *TokenMonitor, token string, onRefresh func()) *ControlHandler {
+	return &ControlHandler{monitor: monitor, token: token, onRefresh: onRefresh}
+}
+
+// ServeHTTP 处理控制请求，除 status 外只接受 POST，成功时返回最新状态
+func (h *ControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" && !validBearer(r, h.token) {
+		serverLog.Warn("控制接口认证失败", "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	action := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if action == "status" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, h.monitor.ControlStatus())
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch action {
+	case "pause":
+		h.monitor.Pause()
+	case "resume":
+		h.monitor.Resume()
+	case "refresh":
+		serverLog.Info("收到立即刷新请求")
+		if h.onRefresh != nil {
+			h.onRefresh()
+		}
+	case "threshold":
+		var req ThresholdRequest
+		body, err := io.ReadAll(io.LimitReader(r.Body, controlMaxBody))
+		if err == nil {
+			err = json.Unmarshal(body, &req)
+		}
+		if err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.monitor.setThresholds(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, h.monitor.ControlStatus())
+}
+
+// setThresholds 修改报警阈值，阈值不能为负数，单币阈值不能为0
+func (m *TokenMonitor) setThresholds(req ThresholdRequest) error {
+	if req.Price != nil && *req.Price <= 0 {
+		return fmt.Errorf("价格报警阈值必须为正数: %v", *req.Price)
+	}
+	if req.Portfolio != nil && *req.Portfolio < 0 {
+		return fmt.Errorf("组合报警阈值不能为负数: %v", *req.Portfolio)
+	}
+	if req.Price != nil {
+		m.SetAlertThreshold(*req.Price)
+	}
+	if req.Portfolio != nil {
+		m.SetPortfolioAlertThreshold(*req.Portfolio)
+	}
+	price, portfolio := m.alertThresholds()
+	monitorLog.Info("报警阈值已修改", "price_pct", price, "portfolio_pct", portfolio)
+	return nil
+}
+
+// ControlSocket 在 unix socket 上提供运行时控制接口，供 tracker ctl 使用
+type ControlSocket struct {
+	path     string
+	listener net.Listener
+	server   *http.Server
+}
+
+// ListenControlSocket 在 path 上监听 unix socket，已存在的旧 socket 文件会被替换；
+// socket 文件权限为 0600，只有运行进程的用户可以连接
+func ListenControlSocket(path string, handler http.Handler) (*ControlSocket, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建控制 socket 目录失败: %v", err)
+	}
+	// 目标路径上已有的文件不是 socket 时不覆盖
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket == 0 {
+		return nil, fmt.Errorf("控制 socket 路径已被其他文件占用: %s", path)
+	}
+
+	// 先在只有当前用户可以访问的临时目录（0700）中创建 socket 并设置权限，再移动到目标路径，
+	// 避免 socket 在设置权限前以 umask 权限暴露给其他用户
+	tmpDir, err := os.MkdirTemp(dir, ".control-")
+	if err != nil {
+		return nil, fmt.Errorf("创建控制 socket 临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPath := filepath.Join(tmpDir, "control.sock")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("监听控制 socket 失败: %v", err)
+	}
+	// socket 文件移动后由 Shutdown 删除
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("设置控制 socket 权限失败: %v", err)
+	}
+	// 上次异常退出时残留的 socket 文件被直接替换
+	if err := os.Rename(tmpPath, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("移动控制 socket 失败: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/control/", handler)
+	s := &ControlSocket{
+		path:     path,
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+	}
+	go func() {
+		serverLog.Info("控制 socket 已启动", "path", path)
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			serverLog.Error("控制 socket 异常退出", "error", err)
+		}
+	}()
+	return s, nil
+}
+
+// Shutdown 关闭控制 socket 并删除 socket 文件
+func (s *ControlSocket) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+	os.Remove(s.path)
+	return err
+}
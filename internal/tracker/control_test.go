@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestControlHandlerAuth(t *testing.T) {
+	const token = "secret"
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+		wantPaused bool
+	}{
+		{name: "没有令牌", wantStatus: http.StatusUnauthorized},
+		{name: "令牌错误", auth: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "缺少 Bearer 前缀", auth: token, wantStatus: http.StatusUnauthorized},
+		{name: "令牌正确", auth: "Bearer " + token, wantStatus: http.StatusOK, wantPaused: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMonitor(t, time.Minute)
+			server := httptest.NewServer(NewControlHandler(m, token, nil))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/control/pause", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d", resp.StatusCode, tt.wantStatus)
+			}
+			if m.Paused() != tt.wantPaused {
+				t.Errorf("暂停状态 = %v, 期望 %v", m.Paused(), tt.wantPaused)
+			}
+		})
+	}
+}
+
+func TestListenControlSocket(t *testing.T) {
+	m := newTestMonitor(t, time.Minute)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "control.sock")
+
+	// 上次异常退出残留的 socket 文件
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("创建残留 socket 失败: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	socket, err := ListenControlSocket(path, NewControlHandler(m, "", nil))
+	if err != nil {
+		t.Fatalf("ListenControlSocket 失败: %v", err)
+	}
+	defer socket.Shutdown(context.Background())
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatalf("socket 文件不存在: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket 权限 = %o, 期望 600", perm)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("目录中残留了临时文件: %v", entries)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://tracker/control/status")
+	if err != nil {
+		t.Fatalf("通过 socket 请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("状态码 = %d, 期望 %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestListenControlSocketKeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.sock")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListenControlSocket(path, http.NotFoundHandler()); err == nil {
+		t.Fatal("路径上是普通文件时应返回错误")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("普通文件被修改: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,241 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	copyTradePollLimit = 20   // 轮询时每个钱包最多获取的兑换交易数量
+	copyTradeSeenSize  = 1000 // 记录最近处理过的交易签名数量，webhook 重试和轮询重叠时不重复发送
+)
+
+// TradeSignal 跟单信号：聪明钱钱包的一笔买入或卖出
+type TradeSignal struct {
+	Wallet      string    `json:"wallet"`
+	WalletLabel string    `json:"wallet_label,omitempty"`
+	Mint        string    `json:"mint"`       // 买入或卖出的代币
+	Side        TradeSide `json:"side"`       // buy / sell
+	Amount      float64   `json:"amount"`     // 代币数量
+	QuoteMint   string    `json:"quote_mint"` // 付出（买入）或得到（卖出）的代币
+	QuoteAmount float64   `json:"quote_amount"`
+	ValueUSD    float64   `json:"value_usd,omitempty"` // 按最近价格估算的成交价值，价格未知时为0
+	Signature   string    `json:"signature"`
+	Source      string    `json:"source,omitempty"` // 交易来源，如 JUPITER、RAYDIUM
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// SignalSink 接收跟单信号的输出渠道
+type SignalSink interface {
+	PublishSignal(ctx context.Context, signal TradeSignal) error
+}
+
+// CopyTrader 从聪明钱钱包的兑换交易中提取跟单信号并发送到 webhook/消息总线；
+// 交易来自 Helius webhook 推送（Observe），未启用 webhook 时在每次刷新后轮询（Poll）
+type CopyTrader struct {
+	helius *HeliusService // 为nil时不轮询
+	sinks  []SignalSink
+
+	mu       sync.Mutex
+	wallets  map[string]bool
+	minValue float64
+	lastSeen map[string]string // 钱包地址 -> 轮询到的最新交易签名
+	seen     map[string]bool   // 钱包|签名 -> 已处理
+	order    []string          // seen 的插入顺序
+}
+
+// NewCopyTrader 创建跟单信号输出
+func NewCopyTrader(helius *HeliusService, sinks ...SignalSink) *CopyTrader {
+	return &CopyTrader{
+		helius:   helius,
+		sinks:    sinks,
+		wallets:  make(map[string]bool),
+		lastSeen: make(map[string]string),
+		seen:     make(map[string]bool),
+	}
+}
+
+// SetWallets 设置聪明钱钱包，只为这些钱包的交易生成信号
+func (t *CopyTrader) SetWallets(walletAddrs []string) {
+	wallets := make(map[string]bool, len(walletAddrs))
+	for _, addr := range walletAddrs {
+		wallets[addr] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.wallets = wallets
+}
+
+// SetMinValue 设置信号的最小成交价值（美元），价值未知的信号始终发送
+func (t *CopyTrader) SetMinValue(value float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.minValue = value
+}
+
+// Observe 处理一笔推送的交易，为涉及的聪明钱钱包发送信号
+func (t *CopyTrader) Observe(tx *WalletTransaction) {
+	t.mu.Lock()
+	var signals []TradeSignal
+	for wallet := range t.wallets {
+		if signal, ok := t.signalLocked(wallet, tx); ok {
+			signals = append(signals, signal)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, signal := range signals {
+		go t.publish(signal)
+	}
+}
+
+// Poll 获取聪明钱钱包上次轮询之后的兑换交易并按时间顺序发送信号，walletAddrs 中不是聪明钱的钱包被忽略；
+// 第一次轮询某个钱包时只记录最新交易，不发送历史信号
+func (t *CopyTrader) Poll(ctx context.Context, walletAddrs []string) {
+	if t.helius == nil {
+		return
+	}
+	for _, wallet := range walletAddrs {
+		t.mu.Lock()
+		tracked := t.wallets[wallet]
+		lastSeen, polled := t.lastSeen[wallet]
+		t.mu.Unlock()
+		if !tracked {
+			continue
+		}
+
+		txs, err := t.helius.FetchWalletTransactions(ctx, wallet, TransactionQuery{Limit: copyTradePollLimit, Type: "SWAP"})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			notifyLog.Warn("获取聪明钱交易失败", "wallet", wallet, "error", err)
+			continue
+		}
+		if len(txs) == 0 {
+			continue
+		}
+
+		// 交易按时间倒序返回，只处理上次轮询之后的部分
+		var fresh []*WalletTransaction
+		for _, tx := range txs {
+			if tx.Signature == lastSeen {
+				break
+			}
+			fresh = append(fresh, tx)
+		}
+
+		t.mu.Lock()
+		t.lastSeen[wallet] = txs[0].Signature
+		var signals []TradeSignal
+		if polled {
+			for i := len(fresh) - 1; i >= 0; i-- {
+				if signal, ok := t.signalLocked(wallet, fresh[i]); ok {
+					signals = append(signals, signal)
+				}
+			}
+		}
+		t.mu.Unlock()
+
+		for _, signal := range signals {
+			t.publish(signal)
+		}
+	}
+}
+
+// signalLocked 从交易中提取钱包的跟单信号，已处理过、不涉及该钱包或价值过低时返回 false；调用方需持有 t.mu
+func (t *CopyTrader) signalLocked(wallet string, tx *WalletTransaction) (TradeSignal, bool) {
+	key := wallet + "|" + tx.Signature
+	if t.seen[key] {
+		return TradeSignal{}, false
+	}
+	signal, ok := tradeSignal(wallet, tx)
+	if !ok {
+		return TradeSignal{}, false
+	}
+	t.seen[key] = true
+	t.order = append(t.order, key)
+	if len(t.order) > copyTradeSeenSize {
+		delete(t.seen, t.order[0])
+		t.order = t.order[1:]
+	}
+	if t.minValue > 0 && signal.ValueUSD > 0 && signal.ValueUSD < t.minValue {
+		return TradeSignal{}, false
+	}
+	return signal, true
+}
+
+// publish 将信号发送到所有输出渠道
+func (t *CopyTrader) publish(signal TradeSignal) {
+	for _, sink := range t.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := sink.PublishSignal(ctx, signal); err != nil {
+			notifyLog.Error("发送跟单信号失败", "wallet", signal.Wallet, "signature", signal.Signature, "error", err)
+		}
+		cancel()
+	}
+	notifyLog.Info("发送跟单信号", "wallet", signal.Wallet, "side", signal.Side, "mint", signal.Mint, "amount", signal.Amount, "signature", signal.Signature)
+}
+
+// tradeSignal 将钱包发起的兑换转换为信号：付出 SOL/稳定币换成其他代币为买入，反之为卖出，
+// 两种报价代币之间的兑换不生成信号；其他代币之间的兑换视为买入得到的代币
+func tradeSignal(wallet string, tx *WalletTransaction) (TradeSignal, bool) {
+	swap := tx.Swap
+	if swap == nil || !involvesWallet(wallet, tx) {
+		return TradeSignal{}, false
+	}
+	inQuote, outQuote := isQuoteMint(swap.InputMint), isQuoteMint(swap.OutputMint)
+	if inQuote && outQuote {
+		return TradeSignal{}, false
+	}
+
+	signal := TradeSignal{
+		Wallet:      wallet,
+		WalletLabel: WalletLabel(wallet),
+		Signature:   tx.Signature,
+		Source:      tx.Source,
+		Timestamp:   tx.Timestamp,
+	}
+	if outQuote {
+		signal.Side = TradeSideSell
+		signal.Mint, signal.Amount = swap.InputMint, swap.InputAmount
+		signal.QuoteMint, signal.QuoteAmount = swap.OutputMint, swap.OutputAmount
+	} else {
+		signal.Side = TradeSideBuy
+		signal.Mint, signal.Amount = swap.OutputMint, swap.OutputAmount
+		signal.QuoteMint, signal.QuoteAmount = swap.InputMint, swap.InputAmount
+	}
+	signal.ValueUSD = signalValue(signal)
+	return signal, true
+}
+
+// involvesWallet 返回交易中是否有钱包转出或收到的转账
+func involvesWallet(wallet string, tx *WalletTransaction) bool {
+	for _, t := range tx.Transfers {
+		if t.From == wallet || t.To == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuoteMint 返回代币是否为报价代币（SOL、wSOL 或配置的稳定币）
+func isQuoteMint(mintAddr string) bool {
+	return mintAddr == nativeSOLMint || mintAddr == wrappedSOLMint || configuredStablecoin(mintAddr)
+}
+
+// signalValue 按报价代币（稳定币按 $1）或交易代币的最近价格估算成交价值
+func signalValue(signal TradeSignal) float64 {
+	if configuredStablecoin(signal.QuoteMint) {
+		return signal.QuoteAmount
+	}
+	now := time.Now()
+	if price, _, ok := stalePrice(defiMint(signal.QuoteMint), now); ok && price.Price > 0 {
+		return signal.QuoteAmount * price.Price
+	}
+	if price, _, ok := stalePrice(signal.Mint, now); ok && price.Price > 0 {
+		return signal.Amount * price.Price
+	}
+	return 0
+}
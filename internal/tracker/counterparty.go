@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// Counterparty 与跟踪钱包有直接转账往来的地址
+type Counterparty struct {
+	Address      string    `json:"address"`
+	Tracked      bool      `json:"tracked"`      // 是否已在跟踪列表中
+	Wallets      []string  `json:"wallets"`      // 与其有往来的跟踪钱包
+	Transactions int       `json:"transactions"` // 有往来的交易数量
+	SentSOL      float64   `json:"sent_sol"`     // 转给跟踪钱包的 SOL
+	ReceivedSOL  float64   `json:"received_sol"` // 从跟踪钱包收到的 SOL
+	Funded       []string  `json:"funded"`       // 以其为最早资金来源的跟踪钱包
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// CounterpartyReport 跟踪钱包的交易对手分析结果
+type CounterpartyReport struct {
+	Wallets        []string          `json:"wallets"`
+	Transactions   int               `json:"transactions"`   // 扫描的交易数量
+	Funders        map[string]string `json:"funders"`        // 钱包 -> 扫描范围内最早转入 SOL 的地址
+	Counterparties []*Counterparty   `json:"counterparties"` // 按往来的跟踪钱包数、交易数降序
+}
+
+// AnalyzeCounterparties 扫描各钱包最近 limit 笔交易，统计直接转账往来的地址和资金来源；
+// 兑换交易的对手是流动性池，不计入统计。tracked 为已跟踪的所有钱包，用于标记已在列表中的地址
+func AnalyzeCounterparties(ctx context.Context, helius *HeliusService, walletAddrs []string, limit int, tracked map[string]bool) (*CounterpartyReport, error) {
+	report := &CounterpartyReport{
+		Wallets: walletAddrs,
+		Funders: make(map[string]string),
+	}
+	byAddr := make(map[string]*Counterparty)
+	var fetched int
+
+	for _, wallet := range walletAddrs {
+		txs, err := helius.FetchWalletTransactions(ctx, wallet, TransactionQuery{Limit: limit})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			walletLog.Warn("获取钱包交易失败", "wallet", wallet, "error", err)
+			if len(txs) == 0 {
+				continue
+			}
+		}
+		fetched++
+		report.Transactions += len(txs)
+
+		// 交易按时间倒序返回，最后一笔转入 SOL 的地址是扫描范围内最早的资金来源
+		var funder string
+		for _, tx := range txs {
+			if tx.Swap != nil {
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, t := range tx.Transfers {
+				var other string
+				switch {
+				case t.From == wallet && t.To != wallet:
+					other = t.To
+				case t.To == wallet && t.From != wallet:
+					other = t.From
+				default:
+					continue
+				}
+				if other == "" {
+					continue
+				}
+
+				c, ok := byAddr[other]
+				if !ok {
+					c = &Counterparty{Address: other, Tracked: tracked[other]}
+					byAddr[other] = c
+				}
+				if !seen[other] {
+					seen[other] = true
+					c.Transactions++
+					if !containsString(c.Wallets, wallet) {
+						c.Wallets = append(c.Wallets, wallet)
+					}
+					if tx.Timestamp.After(c.LastSeen) {
+						c.LastSeen = tx.Timestamp
+					}
+				}
+				if t.MintAddr != nativeSOLMint && t.MintAddr != wrappedSOLMint {
+					continue
+				}
+				if t.To == wallet {
+					c.SentSOL += t.Amount
+					funder = other
+				} else {
+					c.ReceivedSOL += t.Amount
+				}
+			}
+		}
+		if funder != "" {
+			report.Funders[wallet] = funder
+			byAddr[funder].Funded = append(byAddr[funder].Funded, wallet)
+		}
+	}
+	if fetched == 0 && len(walletAddrs) > 0 {
+		return nil, fmt.Errorf("所有钱包的交易都获取失败")
+	}
+
+	for _, c := range byAddr {
+		report.Counterparties = append(report.Counterparties, c)
+	}
+	sort.Slice(report.Counterparties, func(i, j int) bool {
+		a, b := report.Counterparties[i], report.Counterparties[j]
+		if len(a.Wallets) != len(b.Wallets) {
+			return len(a.Wallets) > len(b.Wallets)
+		}
+		if len(a.Funded) != len(b.Funded) {
+			return len(a.Funded) > len(b.Funded)
+		}
+		if a.Transactions != b.Transactions {
+			return a.Transactions > b.Transactions
+		}
+		return a.Address < b.Address
+	})
+	return report, nil
+}
+
+// containsString 返回切片中是否包含 s
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Top 返回只保留前 n 个交易对手的报告，n<=0 时返回全部
+func (r *CounterpartyReport) Top(n int) *CounterpartyReport {
+	if n <= 0 || len(r.Counterparties) <= n {
+		return r
+	}
+	top := *r
+	top.Counterparties = r.Counterparties[:n]
+	return &top
+}
+
+// String 输出交易对手表格和各钱包的资金来源，多个跟踪钱包共同的对手和资金来源排在前面
+func (r *CounterpartyReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf("交易对手分析: %d 个钱包，扫描 %d 笔交易", len(r.Wallets), r.Transactions) + "\n\n")
+
+	if len(r.Counterparties) == 0 {
+		sb.WriteString(i18n.T("没有发现直接转账往来的地址") + "\n")
+	} else {
+		table := &reportTable{columns: []tableColumn{
+			{header: i18n.T("地址"), width: 44, left: true},
+			{header: i18n.T("钱包数"), width: 6},
+			{header: i18n.T("交易数"), width: 6},
+			{header: i18n.T("转入SOL"), width: 10},
+			{header: i18n.T("转出SOL"), width: 10},
+			{header: i18n.T("资助钱包"), width: 8},
+			{header: i18n.T("最近往来"), width: 16, left: true},
+			{header: i18n.T("备注"), width: 8, left: true},
+		}}
+		for _, c := range r.Counterparties {
+			note := ""
+			if c.Tracked {
+				note = i18n.T("已跟踪") + " " + WalletLabel(c.Address)
+			}
+			table.addRow(c.Address, fmt.Sprintf("%d", len(c.Wallets)), fmt.Sprintf("%d", c.Transactions),
+				fmt.Sprintf("%.4f", c.SentSOL), fmt.Sprintf("%.4f", c.ReceivedSOL), fmt.Sprintf("%d", len(c.Funded)),
+				c.LastSeen.Local().Format("2006-01-02 15:04"), note)
+		}
+		sb.WriteString(table.render())
+	}
+
+	if len(r.Funders) > 0 {
+		sb.WriteString("\n" + i18n.T("资金来源（扫描范围内最早转入 SOL 的地址）") + "\n")
+		wallets := make([]string, 0, len(r.Funders))
+		for wallet := range r.Funders {
+			wallets = append(wallets, wallet)
+		}
+		sort.Strings(wallets)
+		for _, wallet := range wallets {
+			sb.WriteString(fmt.Sprintf("  %s <- %s\n", WalletLabel(wallet), r.Funders[wallet]))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,225 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	sparklineLength = 30 // 迷你走势图显示的最近快照数
+	dashboardRows   = 30 // 仪表盘最多显示的代币行数
+)
+
+// sparkTicks 迷你走势图使用的字符
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboardSortKeys 仪表盘支持的排序方式（按键 -> 名称），名称显示时经过翻译
+var dashboardSortKeys = map[byte]string{
+	'v': "价值",
+	'p': "价格",
+	'c': "变化率",
+	's': "代币",
+}
+
+// Dashboard 终端实时仪表盘
+type Dashboard struct {
+	monitor *TokenMonitor
+	out     io.Writer
+	sortKey byte
+	desc    bool
+}
+
+// NewDashboard 创建终端仪表盘
+func NewDashboard(monitor *TokenMonitor) *Dashboard {
+	return &Dashboard{
+		monitor: monitor,
+		out:     os.Stdout,
+		sortKey: 'v',
+		desc:    true,
+	}
+}
+
+// Run 运行仪表盘直到按下 q 或 ctx 被取消；按 v/p/c/s 切换排序，再次按下同一键反转顺序
+func (d *Dashboard) Run(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("标准输入不是终端，无法启动仪表盘")
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("切换终端模式失败: %v", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// 隐藏光标，退出时恢复
+	fmt.Fprint(d.out, "\x1b[?25l")
+	defer fmt.Fprint(d.out, "\x1b[?25h\x1b[2J\x1b[H")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := os.Stdin.Read(buf); err != nil {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+
+	events, unsubscribe := d.monitor.SubscribeSnapshots()
+	defer unsubscribe()
+
+	d.render()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-events:
+			d.render()
+		case key, ok := <-keys:
+			if !ok || key == 'q' || key == 3 { // q 或 Ctrl-C
+				return nil
+			}
+			if _, valid := dashboardSortKeys[key]; valid {
+				if key == d.sortKey {
+					d.desc = !d.desc
+				} else {
+					d.sortKey = key
+					d.desc = key != 's'
+				}
+				d.render()
+			}
+		}
+	}
+}
+
+// sortTokens 按当前排序方式排序代币
+func (d *Dashboard) sortTokens(tokens []*TokenData) {
+	less := func(a, b *TokenData) bool {
+		switch d.sortKey {
+		case 'p':
+			return a.Price < b.Price
+		case 'c':
+			return a.Change < b.Change
+		case 's':
+			return a.Symbol < b.Symbol
+		default:
+			return a.Value < b.Value
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if d.desc {
+			return less(tokens[j], tokens[i])
+		}
+		return less(tokens[i], tokens[j])
+	})
+}
+
+// render 重绘整个仪表盘
+func (d *Dashboard) render() {
+	tokens := d.monitor.Tokens()
+	d.sortTokens(tokens)
+	total, updatedAt := d.monitor.TotalValue()
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	sb.WriteString(fmt.Sprintf("%-4s %-12s %16s %16s %12s  %s\r\n",
+		"#", i18n.T("代币"), i18n.T("价格"), i18n.T("价值"), i18n.T("变化率%/s"), i18n.T("走势")))
+	sb.WriteString(strings.Repeat("─", 96) + "\r\n")
+
+	rows := len(tokens)
+	if rows > dashboardRows {
+		rows = dashboardRows
+	}
+	for i, token := range tokens[:rows] {
+		symbol := token.Symbol
+		if len(symbol) > 12 {
+			symbol = symbol[:12]
+		}
+		sb.WriteString(fmt.Sprintf("%-4d %-12s %16.6f %16.2f %12.4f  %s\r\n",
+			i+1,
+			symbol,
+			token.Price,
+			token.Value,
+			token.Change,
+			sparkline(d.monitor.recentPrices(token.MintAddr, sparklineLength))))
+	}
+
+	order := i18n.T("降序")
+	if !d.desc {
+		order = i18n.T("升序")
+	}
+	lastUpdate := "-"
+	if !updatedAt.IsZero() {
+		lastUpdate = updatedAt.Format("15:04:05")
+	}
+	sb.WriteString(strings.Repeat("─", 96) + "\r\n")
+	sb.WriteString("\x1b[7m" + i18n.Sprintf(" 总值: $%.2f | 代币数: %d | 更新: %s | 排序: %s(%s) | v/p/c/s 排序  q 退出 ",
+		total, len(tokens), lastUpdate, i18n.T(dashboardSortKeys[d.sortKey]), order) + "\x1b[0m\r\n")
+
+	fmt.Fprint(d.out, sb.String())
+}
+
+// sparkline 将价格序列渲染为迷你走势图
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		sb.WriteRune(sparkTicks[idx])
+	}
+	return sb.String()
+}
+
+// recentPrices 返回代币在最近 n 个快照中的价格，按时间升序
+func (m *TokenMonitor) recentPrices(mintAddr string, n int) []float64 {
+	m.historyMu.RLock()
+	defer m.historyMu.RUnlock()
+
+	prices := make([]float64, 0, n)
+	r := m.priceHistory
+	for i := 0; i < m.priceHistory.Len() && len(prices) < n; i++ {
+		if r.Value == nil {
+			break
+		}
+		if token, ok := r.Value.(*PriceSnapshot).TokenData[mintAddr]; ok && token.Price > 0 {
+			prices = append(prices, token.Price)
+		}
+		r = r.Prev()
+	}
+
+	// 反转为时间升序
+	for i, j := 0, len(prices)-1; i < j; i, j = i+1, j-1 {
+		prices[i], prices[j] = prices[j], prices[i]
+	}
+	return prices
+}
+
+// SetQuiet 设置是否在每次快照时输出状态行（仪表盘模式下需要关闭）
+func (m *TokenMonitor) SetQuiet(quiet bool) {
+	m.quiet = quiet
+}
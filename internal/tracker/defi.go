@@ -0,0 +1,871 @@
+package tracker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"wallet-tracker/internal/i18n"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+const (
+	kaminoLendProgramID = "KLend2g3cP87fffoy8q1mQqGKjrxjC8boSyAYavgmjD"
+	marginfiProgramID   = "MFv2hWf31Z9kbCa1snEPYctwafyhdvnV7FZnsebVacA"
+	whirlpoolProgramID  = "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc"
+	wrappedSOLMint      = "So11111111111111111111111111111111111111112"
+	raydiumAPIEndpoint  = "https://api-v3.raydium.io"
+
+	// Kamino Obligation 账户：owner 位于 discriminator(8) + tag(8) + last_update(16) + lending_market(32) 之后，
+	// deposits 为8个 ObligationCollateral（每个136字节，market_value_sf 偏移40），
+	// borrows 为5个 ObligationLiquidity（每个200字节，market_value_sf 偏移104）
+	kaminoObligationSize      = 3344
+	kaminoObligationOwner     = 64
+	kaminoDepositsOffset      = 96
+	kaminoDepositSize         = 136
+	kaminoDepositCount        = 8
+	kaminoDepositValueOffset  = 40
+	kaminoBorrowsOffset       = 1208
+	kaminoBorrowSize          = 200
+	kaminoBorrowCount         = 5
+	kaminoBorrowValueOffset   = 104
+	kaminoReserveMintOffset   = 128 // Reserve.liquidity.mint_pubkey
+	kaminoReservePriceOffset  = 248 // Reserve.liquidity.market_price_sf
+	kaminoFractionScale       = 1 << 60
+	marginfiAccountSize       = 2312
+	marginfiAccountOwner      = 40 // discriminator(8) + group(32)
+	marginfiBalancesOffset    = 72
+	marginfiBalanceSize       = 104
+	marginfiBalanceCount      = 16
+	marginfiBankMintOffset    = 8
+	marginfiBankDecimals      = 40
+	marginfiAssetShareValue   = 80
+	marginfiLiabilityShareVal = 96
+	whirlpoolPositionSize     = 216
+	whirlpoolMintAOffset      = 101
+	whirlpoolMintBOffset      = 181
+	splMintDecimalsOffset     = 44
+)
+
+// DeFi 仓位类型
+const (
+	DeFiKindLending = "lending"
+	DeFiKindLP      = "lp"
+)
+
+// DeFiAsset DeFi仓位中的一种底层资产
+type DeFiAsset struct {
+	MintAddr string
+	Symbol   string
+	Decimals uint8
+	Amount   float64
+	ValueUSD float64 // 协议记录的美元价值（Kamino），未知时为0，报告中优先按持仓价格估值
+}
+
+// DeFiPosition 钱包在DeFi协议中的一个仓位
+type DeFiPosition struct {
+	Wallet   string
+	Protocol string
+	Kind     string       // lending / lp
+	Account  string       // 借贷账户地址，LP仓位为钱包中的LP代币或仓位NFT的mint
+	Deposits []*DeFiAsset // 存款或LP的底层资产（LP仓位价格超出区间时其中一种为0）
+	Borrows  []*DeFiAsset
+
+	Shares float64      // 持有的LP代币数量，Orca 仓位NFT为1
+	Entry  []*DeFiAsset // LP建仓时存入的底层资产（按当前持有份额折算），没有找到存入交易时为空
+}
+
+// chainAccount getMultipleAccounts/getProgramAccounts 返回的账户
+type chainAccount struct {
+	Address string
+	Owner   string
+	Data    []byte
+}
+
+// fetchAccounts 使用 getMultipleAccounts 批量读取账户数据，不存在的账户不在结果中
+func fetchAccounts(ctx context.Context, helius *HeliusService, addrs []string) (map[string]*chainAccount, error) {
+	accounts := make(map[string]*chainAccount)
+	for i := 0; i < len(addrs); i += multipleAccountsLimit {
+		end := i + multipleAccountsLimit
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+		batch := addrs[i:end]
+
+		var result struct {
+			Value []*struct {
+				Owner string   `json:"owner"`
+				Data  []string `json:"data"`
+			} `json:"value"`
+		}
+		err := withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+			params := []interface{}{batch, map[string]interface{}{"encoding": "base64"}}
+			return endpoint.call(ctx, helius.client, "getMultipleAccounts", params, &result)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for j, account := range result.Value {
+			if account == nil || j >= len(batch) || len(account.Data) == 0 {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(account.Data[0])
+			if err != nil {
+				continue
+			}
+			accounts[batch[j]] = &chainAccount{Address: batch[j], Owner: account.Owner, Data: data}
+		}
+	}
+	return accounts, nil
+}
+
+// fetchOwnedProgramAccounts 使用 getProgramAccounts 查询程序下数据长度为 size、ownerOffset 处为钱包地址的账户
+func fetchOwnedProgramAccounts(ctx context.Context, helius *HeliusService, programID string, size, ownerOffset int, walletAddr string) ([]*chainAccount, error) {
+	var result []struct {
+		Pubkey  string `json:"pubkey"`
+		Account struct {
+			Data []string `json:"data"`
+		} `json:"account"`
+	}
+	err := withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+		params := []interface{}{
+			programID,
+			map[string]interface{}{
+				"encoding": "base64",
+				"filters": []interface{}{
+					map[string]interface{}{"dataSize": size},
+					map[string]interface{}{
+						"memcmp": map[string]interface{}{
+							"offset": ownerOffset,
+							"bytes":  walletAddr,
+						},
+					},
+				},
+			},
+		}
+		return endpoint.call(ctx, helius.client, "getProgramAccounts", params, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*chainAccount, 0, len(result))
+	for _, item := range result {
+		if len(item.Account.Data) == 0 {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(item.Account.Data[0])
+		if err != nil || len(data) < size {
+			continue
+		}
+		accounts = append(accounts, &chainAccount{Address: item.Pubkey, Owner: programID, Data: data})
+	}
+	return accounts, nil
+}
+
+// readPubkey 读取账户数据中的公钥，全零（未使用的槽位）时返回空字符串
+func readPubkey(data []byte, offset int) string {
+	key := data[offset : offset+32]
+	for _, b := range key {
+		if b != 0 {
+			return common.PublicKeyFromBytes(key).ToBase58()
+		}
+	}
+	return ""
+}
+
+// readU128 读取小端序的 u128
+func readU128(data []byte, offset int) float64 {
+	lo := binary.LittleEndian.Uint64(data[offset:])
+	hi := binary.LittleEndian.Uint64(data[offset+8:])
+	return float64(hi)*math.Pow(2, 64) + float64(lo)
+}
+
+// readI80F48 读取 MarginFi 使用的 I80F48 定点数（小端序 i128，低48位为小数）
+func readI80F48(data []byte, offset int) float64 {
+	raw := make([]byte, 16)
+	for i := 0; i < 16; i++ {
+		raw[15-i] = data[offset+i]
+	}
+	value := new(big.Int).SetBytes(raw)
+	if raw[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f / math.Pow(2, 48)
+}
+
+// defiMint 借贷协议中的 wSOL 与原生SOL持仓合并
+func defiMint(mint string) string {
+	if mint == wrappedSOLMint {
+		return nativeSOLMint
+	}
+	return mint
+}
+
+// fetchKaminoPositions 读取钱包在 Kamino Lend 的 obligation 账户，
+// 存款和借款的数量由 obligation 中上次刷新时记录的美元价值和 reserve 的市场价格折算
+func fetchKaminoPositions(ctx context.Context, helius *HeliusService, walletAddr string) ([]*DeFiPosition, error) {
+	obligations, err := fetchOwnedProgramAccounts(ctx, helius, kaminoLendProgramID, kaminoObligationSize, kaminoObligationOwner, walletAddr)
+	if err != nil || len(obligations) == 0 {
+		return nil, err
+	}
+
+	type slot struct {
+		reserve string
+		value   float64
+	}
+	readSlots := func(data []byte, start, size, count, valueOffset int) []slot {
+		var slots []slot
+		for i := 0; i < count; i++ {
+			offset := start + i*size
+			reserve := readPubkey(data, offset)
+			value := readU128(data, offset+valueOffset) / kaminoFractionScale
+			if reserve == "" || value <= 0 {
+				continue
+			}
+			slots = append(slots, slot{reserve: reserve, value: value})
+		}
+		return slots
+	}
+
+	reserveSet := make(map[string]bool)
+	deposits := make([][]slot, len(obligations))
+	borrows := make([][]slot, len(obligations))
+	for i, obligation := range obligations {
+		deposits[i] = readSlots(obligation.Data, kaminoDepositsOffset, kaminoDepositSize, kaminoDepositCount, kaminoDepositValueOffset)
+		borrows[i] = readSlots(obligation.Data, kaminoBorrowsOffset, kaminoBorrowSize, kaminoBorrowCount, kaminoBorrowValueOffset)
+		for _, s := range deposits[i] {
+			reserveSet[s.reserve] = true
+		}
+		for _, s := range borrows[i] {
+			reserveSet[s.reserve] = true
+		}
+	}
+	reserves, err := fetchAccounts(ctx, helius, sortedKeys(reserveSet))
+	if err != nil {
+		return nil, fmt.Errorf("读取 Kamino reserve 失败: %v", err)
+	}
+
+	toAssets := func(slots []slot) []*DeFiAsset {
+		var assets []*DeFiAsset
+		for _, s := range slots {
+			reserve, ok := reserves[s.reserve]
+			if !ok || len(reserve.Data) < kaminoReservePriceOffset+16 {
+				continue
+			}
+			price := readU128(reserve.Data, kaminoReservePriceOffset) / kaminoFractionScale
+			if price <= 0 {
+				continue
+			}
+			assets = append(assets, &DeFiAsset{
+				MintAddr: defiMint(readPubkey(reserve.Data, kaminoReserveMintOffset)),
+				Amount:   s.value / price,
+				ValueUSD: s.value,
+			})
+		}
+		return assets
+	}
+
+	var positions []*DeFiPosition
+	for i, obligation := range obligations {
+		position := &DeFiPosition{
+			Wallet:   walletAddr,
+			Protocol: "Kamino",
+			Kind:     DeFiKindLending,
+			Account:  obligation.Address,
+			Deposits: toAssets(deposits[i]),
+			Borrows:  toAssets(borrows[i]),
+		}
+		if len(position.Deposits) > 0 || len(position.Borrows) > 0 {
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+// fetchMarginfiPositions 读取钱包在 MarginFi 的账户，存款和借款按 bank 的份额价值折算为代币数量
+func fetchMarginfiPositions(ctx context.Context, helius *HeliusService, walletAddr string) ([]*DeFiPosition, error) {
+	accounts, err := fetchOwnedProgramAccounts(ctx, helius, marginfiProgramID, marginfiAccountSize, marginfiAccountOwner, walletAddr)
+	if err != nil || len(accounts) == 0 {
+		return nil, err
+	}
+
+	bankSet := make(map[string]bool)
+	for _, account := range accounts {
+		for i := 0; i < marginfiBalanceCount; i++ {
+			offset := marginfiBalancesOffset + i*marginfiBalanceSize
+			if account.Data[offset] == 0 {
+				continue
+			}
+			if bank := readPubkey(account.Data, offset+1); bank != "" {
+				bankSet[bank] = true
+			}
+		}
+	}
+	banks, err := fetchAccounts(ctx, helius, sortedKeys(bankSet))
+	if err != nil {
+		return nil, fmt.Errorf("读取 MarginFi bank 失败: %v", err)
+	}
+
+	var positions []*DeFiPosition
+	for _, account := range accounts {
+		position := &DeFiPosition{Wallet: walletAddr, Protocol: "MarginFi", Kind: DeFiKindLending, Account: account.Address}
+		for i := 0; i < marginfiBalanceCount; i++ {
+			offset := marginfiBalancesOffset + i*marginfiBalanceSize
+			if account.Data[offset] == 0 {
+				continue
+			}
+			bank, ok := banks[readPubkey(account.Data, offset+1)]
+			if !ok || len(bank.Data) < marginfiLiabilityShareVal+16 {
+				continue
+			}
+			mint := defiMint(readPubkey(bank.Data, marginfiBankMintOffset))
+			decimals := bank.Data[marginfiBankDecimals]
+			scale := math.Pow10(int(decimals))
+
+			assetShares := readI80F48(account.Data, offset+40)
+			liabilityShares := readI80F48(account.Data, offset+56)
+			if amount := assetShares * readI80F48(bank.Data, marginfiAssetShareValue) / scale; amount > 0 {
+				position.Deposits = append(position.Deposits, &DeFiAsset{MintAddr: mint, Decimals: decimals, Amount: amount})
+			}
+			if amount := liabilityShares * readI80F48(bank.Data, marginfiLiabilityShareVal) / scale; amount > 0 {
+				position.Borrows = append(position.Borrows, &DeFiAsset{MintAddr: mint, Decimals: decimals, Amount: amount})
+			}
+		}
+		if len(position.Deposits) > 0 || len(position.Borrows) > 0 {
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+// fetchWhirlpoolPositions 识别钱包中的 Orca Whirlpool 仓位NFT，按集中流动性公式拆分为两种底层代币（不含未领取的手续费）
+func fetchWhirlpoolPositions(ctx context.Context, helius *HeliusService, walletAddr string, tokens []*TokenData) ([]*DeFiPosition, error) {
+	var nftMints, positionAddrs []string
+	for _, token := range tokens {
+		if token.Decimals != 0 || token.Amount != 1 {
+			continue
+		}
+		pda, _, err := common.FindProgramAddress([][]byte{
+			[]byte("position"),
+			common.PublicKeyFromString(token.MintAddr).Bytes(),
+		}, common.PublicKeyFromString(whirlpoolProgramID))
+		if err != nil {
+			continue
+		}
+		nftMints = append(nftMints, token.MintAddr)
+		positionAddrs = append(positionAddrs, pda.ToBase58())
+	}
+	if len(positionAddrs) == 0 {
+		return nil, nil
+	}
+
+	positionAccounts, err := fetchAccounts(ctx, helius, positionAddrs)
+	if err != nil {
+		return nil, err
+	}
+	type whirlpoolPosition struct {
+		nftMint, pool      string
+		liquidity          float64
+		tickLower, tickUpr int32
+	}
+	var found []whirlpoolPosition
+	poolSet := make(map[string]bool)
+	for i, addr := range positionAddrs {
+		account, ok := positionAccounts[addr]
+		if !ok || account.Owner != whirlpoolProgramID || len(account.Data) < whirlpoolPositionSize {
+			continue
+		}
+		p := whirlpoolPosition{
+			nftMint:   nftMints[i],
+			pool:      readPubkey(account.Data, 8),
+			liquidity: readU128(account.Data, 72),
+			tickLower: int32(binary.LittleEndian.Uint32(account.Data[88:])),
+			tickUpr:   int32(binary.LittleEndian.Uint32(account.Data[92:])),
+		}
+		found = append(found, p)
+		poolSet[p.pool] = true
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	pools, err := fetchAccounts(ctx, helius, sortedKeys(poolSet))
+	if err != nil {
+		return nil, fmt.Errorf("读取 Whirlpool 失败: %v", err)
+	}
+	mintSet := make(map[string]bool)
+	for _, pool := range pools {
+		if len(pool.Data) >= whirlpoolMintBOffset+32 {
+			mintSet[readPubkey(pool.Data, whirlpoolMintAOffset)] = true
+			mintSet[readPubkey(pool.Data, whirlpoolMintBOffset)] = true
+		}
+	}
+	mints, err := fetchAccounts(ctx, helius, sortedKeys(mintSet))
+	if err != nil {
+		return nil, fmt.Errorf("读取代币精度失败: %v", err)
+	}
+
+	var positions []*DeFiPosition
+	for _, p := range found {
+		pool, ok := pools[p.pool]
+		if !ok || len(pool.Data) < whirlpoolMintBOffset+32 {
+			continue
+		}
+		mintA, mintB := readPubkey(pool.Data, whirlpoolMintAOffset), readPubkey(pool.Data, whirlpoolMintBOffset)
+		decimalsA, okA := mintDecimals(mints[mintA])
+		decimalsB, okB := mintDecimals(mints[mintB])
+		if !okA || !okB {
+			continue
+		}
+		sqrtPrice := readU128(pool.Data, 65) / math.Pow(2, 64)
+		rawA, rawB := concentratedAmounts(p.liquidity, sqrtPrice, p.tickLower, p.tickUpr)
+		positions = append(positions, &DeFiPosition{
+			Wallet:   walletAddr,
+			Protocol: "Orca",
+			Kind:     DeFiKindLP,
+			Account:  p.nftMint,
+			Shares:   1,
+			Deposits: []*DeFiAsset{
+				{MintAddr: defiMint(mintA), Decimals: decimalsA, Amount: rawA / math.Pow10(int(decimalsA))},
+				{MintAddr: defiMint(mintB), Decimals: decimalsB, Amount: rawB / math.Pow10(int(decimalsB))},
+			},
+		})
+	}
+	return positions, nil
+}
+
+// mintDecimals 读取 SPL mint 账户中的精度
+func mintDecimals(account *chainAccount) (uint8, bool) {
+	if account == nil || len(account.Data) <= splMintDecimalsOffset {
+		return 0, false
+	}
+	return account.Data[splMintDecimalsOffset], true
+}
+
+// concentratedAmounts 计算集中流动性仓位在当前价格下的两种代币数量（原始单位）
+func concentratedAmounts(liquidity, sqrtPrice float64, tickLower, tickUpper int32) (float64, float64) {
+	sqrtLower := math.Pow(1.0001, float64(tickLower)/2)
+	sqrtUpper := math.Pow(1.0001, float64(tickUpper)/2)
+	switch {
+	case sqrtPrice <= sqrtLower:
+		return liquidity * (sqrtUpper - sqrtLower) / (sqrtLower * sqrtUpper), 0
+	case sqrtPrice < sqrtUpper:
+		return liquidity * (sqrtUpper - sqrtPrice) / (sqrtPrice * sqrtUpper), liquidity * (sqrtPrice - sqrtLower)
+	default:
+		return 0, liquidity * (sqrtUpper - sqrtLower)
+	}
+}
+
+// RaydiumLPPool Raydium 池子的储备和LP供应量
+type RaydiumLPPool struct {
+	LPMint   string
+	MintA    DeFiAsset // Amount 为池子中的储备量
+	MintB    DeFiAsset
+	LPSupply float64
+}
+
+// RaydiumService 通过 Raydium API 查询LP代币对应的池子
+type RaydiumService struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewRaydiumService 创建 Raydium 服务
+func NewRaydiumService() *RaydiumService {
+	return NewRaydiumServiceWithConfig(raydiumAPIEndpoint, nil)
+}
+
+// NewRaydiumServiceWithConfig 使用指定的端点和HTTP客户端创建 Raydium 服务，client 为nil时使用共享客户端
+func NewRaydiumServiceWithConfig(baseURL string, client *http.Client) *RaydiumService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &RaydiumService{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// raydiumLPBatch 单次查询的LP mint数量
+const raydiumLPBatch = 50
+
+var (
+	raydiumNonLPMu sync.Mutex
+	raydiumNonLP   = make(map[string]bool) // 已确认不是 Raydium LP 的mint，不再重复查询
+)
+
+// LPPools 查询mint对应的 Raydium 池子，不是LP代币的mint不在结果中
+func (s *RaydiumService) LPPools(ctx context.Context, mints []string) (map[string]*RaydiumLPPool, error) {
+	raydiumNonLPMu.Lock()
+	var pending []string
+	for _, mint := range mints {
+		if !raydiumNonLP[mint] {
+			pending = append(pending, mint)
+		}
+	}
+	raydiumNonLPMu.Unlock()
+
+	pools := make(map[string]*RaydiumLPPool)
+	for i := 0; i < len(pending); i += raydiumLPBatch {
+		end := i + raydiumLPBatch
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[i:end]
+		if err := s.lpPoolsBatch(ctx, batch, pools); err != nil {
+			return nil, err
+		}
+
+		raydiumNonLPMu.Lock()
+		for _, mint := range batch {
+			if pools[mint] == nil {
+				raydiumNonLP[mint] = true
+			}
+		}
+		raydiumNonLPMu.Unlock()
+	}
+	return pools, nil
+}
+
+func (s *RaydiumService) lpPoolsBatch(ctx context.Context, mints []string, pools map[string]*RaydiumLPPool) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/pools/info/lps?lps="+url.QueryEscape(strings.Join(mints, ",")), nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	observeSourceResponse("raydium", resp, err)
+	if err != nil {
+		return fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Raydium 返回状态码 %d", resp.StatusCode)
+	}
+
+	type poolMint struct {
+		Address  string `json:"address"`
+		Symbol   string `json:"symbol"`
+		Decimals uint8  `json:"decimals"`
+	}
+	var result struct {
+		Success bool `json:"success"`
+		Data    []*struct {
+			MintA       poolMint `json:"mintA"`
+			MintB       poolMint `json:"mintB"`
+			MintAmountA float64  `json:"mintAmountA"`
+			MintAmountB float64  `json:"mintAmountB"`
+			LPMint      poolMint `json:"lpMint"`
+			LPAmount    float64  `json:"lpAmount"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("Raydium 查询失败")
+	}
+
+	for _, item := range result.Data {
+		if item == nil || item.LPMint.Address == "" || item.LPAmount <= 0 {
+			continue
+		}
+		pools[item.LPMint.Address] = &RaydiumLPPool{
+			LPMint:   item.LPMint.Address,
+			MintA:    DeFiAsset{MintAddr: defiMint(item.MintA.Address), Symbol: item.MintA.Symbol, Decimals: item.MintA.Decimals, Amount: item.MintAmountA},
+			MintB:    DeFiAsset{MintAddr: defiMint(item.MintB.Address), Symbol: item.MintB.Symbol, Decimals: item.MintB.Decimals, Amount: item.MintAmountB},
+			LPSupply: item.LPAmount,
+		}
+	}
+	return nil
+}
+
+// fetchRaydiumPositions 识别钱包中的 Raydium LP 代币，按持有份额拆分为池子中的两种底层代币
+func fetchRaydiumPositions(ctx context.Context, walletAddr string, tokens []*TokenData) ([]*DeFiPosition, error) {
+	var mints []string
+	for _, token := range tokens {
+		if token.Decimals > 0 && token.Amount > 0 && token.MintAddr != nativeSOLMint {
+			mints = append(mints, token.MintAddr)
+		}
+	}
+	if len(mints) == 0 {
+		return nil, nil
+	}
+
+	pools, err := NewRaydiumService().LPPools(ctx, mints)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []*DeFiPosition
+	for _, token := range tokens {
+		pool, ok := pools[token.MintAddr]
+		if !ok {
+			continue
+		}
+		share := token.Amount / pool.LPSupply
+		assetA, assetB := pool.MintA, pool.MintB
+		assetA.Amount *= share
+		assetB.Amount *= share
+		positions = append(positions, &DeFiPosition{
+			Wallet:   walletAddr,
+			Protocol: "Raydium",
+			Kind:     DeFiKindLP,
+			Account:  token.MintAddr,
+			Shares:   token.Amount,
+			Deposits: []*DeFiAsset{&assetA, &assetB},
+		})
+	}
+	return positions, nil
+}
+
+// FetchDeFiPositions 获取钱包在各DeFi协议中的仓位，单个协议失败时只记录日志
+func (s *HeliusService) FetchDeFiPositions(ctx context.Context, walletAddr string, tokens []*TokenData) []*DeFiPosition {
+	fetchers := []struct {
+		protocol string
+		fetch    func() ([]*DeFiPosition, error)
+	}{
+		{"Kamino", func() ([]*DeFiPosition, error) { return fetchKaminoPositions(ctx, s, walletAddr) }},
+		{"MarginFi", func() ([]*DeFiPosition, error) { return fetchMarginfiPositions(ctx, s, walletAddr) }},
+		{"Orca", func() ([]*DeFiPosition, error) { return fetchWhirlpoolPositions(ctx, s, walletAddr, tokens) }},
+		{"Raydium", func() ([]*DeFiPosition, error) { return fetchRaydiumPositions(ctx, walletAddr, tokens) }},
+	}
+
+	var positions []*DeFiPosition
+	for _, f := range fetchers {
+		result, err := f.fetch()
+		if err != nil {
+			walletLog.Warn("获取DeFi仓位失败", "wallet", walletAddr, "protocol", f.protocol, "error", err)
+			continue
+		}
+		positions = append(positions, result...)
+	}
+	s.attachLPEntries(ctx, walletAddr, positions)
+	return positions
+}
+
+// applyDeFiPositions 将DeFi仓位计入钱包持仓：LP代币和仓位NFT替换为底层资产，借贷存款加到对应代币上；
+// 返回合并后的持仓和新增的代币mint（需要查询元数据）
+func applyDeFiPositions(tokens []*TokenData, positions []*DeFiPosition) ([]*TokenData, []string) {
+	lpHoldings := make(map[string]bool)
+	for _, position := range positions {
+		if position.Kind == DeFiKindLP {
+			lpHoldings[position.Account] = true
+		}
+	}
+	result := make([]*TokenData, 0, len(tokens))
+	byMint := make(map[string]*TokenData)
+	for _, token := range tokens {
+		if lpHoldings[token.MintAddr] {
+			continue
+		}
+		result = append(result, token)
+		byMint[token.MintAddr] = token
+	}
+
+	var added []string
+	for _, position := range positions {
+		for _, asset := range position.Deposits {
+			if asset.MintAddr == "" || asset.Amount <= 0 {
+				continue
+			}
+			if token, ok := byMint[asset.MintAddr]; ok {
+				token.Amount += asset.Amount
+				token.DeFi += asset.Amount
+				continue
+			}
+			token := &TokenData{
+				MintAddr: asset.MintAddr,
+				Amount:   asset.Amount,
+				DeFi:     asset.Amount,
+				Decimals: asset.Decimals,
+				Symbol:   asset.Symbol,
+				Name:     asset.Symbol,
+			}
+			if asset.Symbol == "" {
+				token.Symbol = "UNKNOWN"
+				token.Name = "Unknown Token"
+				added = append(added, asset.MintAddr)
+			}
+			result = append(result, token)
+			byMint[asset.MintAddr] = token
+		}
+	}
+	return result, added
+}
+
+var (
+	defiPositionsMu sync.RWMutex
+	defiPositions   = make(map[string][]*DeFiPosition) // 钱包地址 -> DeFi仓位
+)
+
+// storeDeFiPositions 保存钱包最近一次获取的DeFi仓位，用于报告中的DeFi部分
+func storeDeFiPositions(walletAddr string, positions []*DeFiPosition) {
+	defiPositionsMu.Lock()
+	defer defiPositionsMu.Unlock()
+	if len(positions) == 0 {
+		delete(defiPositions, walletAddr)
+		return
+	}
+	defiPositions[walletAddr] = positions
+}
+
+// currentDeFiPositions 返回所有钱包最近一次获取的DeFi仓位
+func currentDeFiPositions() []*DeFiPosition {
+	defiPositionsMu.RLock()
+	defer defiPositionsMu.RUnlock()
+	var positions []*DeFiPosition
+	for _, walletPositions := range defiPositions {
+		positions = append(positions, walletPositions...)
+	}
+	return positions
+}
+
+// defiValue 返回代币中存放在DeFi协议中的部分的价值
+func defiValue(token *TokenData) float64 {
+	if token.DeFi <= 0 || token.Amount <= 0 {
+		return 0
+	}
+	return token.Value * token.DeFi / token.Amount
+}
+
+// proportionalDeFi 按钱包持有数量的比例分摊聚合代币的DeFi数量
+func proportionalDeFi(token *TokenData, amount float64) float64 {
+	if token.DeFi <= 0 || token.Amount <= 0 {
+		return 0
+	}
+	return token.DeFi * amount / token.Amount
+}
+
+// generateDeFiSection 生成DeFi仓位报告段落，按持仓中的价格估值并计算LP仓位的无常损失，没有仓位时为空
+func generateDeFiSection(tokens []*TokenData) string {
+	byMint := make(map[string]*TokenData)
+	wallets := make(map[string]bool)
+	for _, token := range tokens {
+		byMint[token.MintAddr] = token
+		for wallet := range token.WalletAmounts {
+			wallets[wallet] = true
+		}
+	}
+
+	defiPositionsMu.RLock()
+	var positions []*DeFiPosition
+	for wallet, walletPositions := range defiPositions {
+		// 只显示当前报告中的钱包
+		if len(wallets) == 0 || wallets[wallet] {
+			positions = append(positions, walletPositions...)
+		}
+	}
+	defiPositionsMu.RUnlock()
+	if len(positions) == 0 {
+		return ""
+	}
+
+	// describe 返回资产列表的文字描述和估值，无法估值的资产计入 unvalued
+	var unvalued int
+	describe := func(assets []*DeFiAsset) (string, float64) {
+		if len(assets) == 0 {
+			return "-", 0
+		}
+		var parts []string
+		var value float64
+		for _, asset := range assets {
+			if asset.Amount <= 0 {
+				continue
+			}
+			symbol := asset.Symbol
+			token, ok := byMint[asset.MintAddr]
+			if ok && !isUnknownSymbol(token.Symbol) {
+				symbol = token.Symbol
+			}
+			if symbol == "" {
+				symbol = truncateSymbol(asset.MintAddr)
+			}
+			parts = append(parts, fmt.Sprintf("%.4f %s", asset.Amount, symbol))
+			switch {
+			case ok && token.Price > 0:
+				value += asset.Amount * token.Price
+			case asset.ValueUSD > 0:
+				value += asset.ValueUSD
+			default:
+				unvalued++
+			}
+		}
+		return strings.Join(parts, " + "), value
+	}
+
+	type row struct {
+		position          *DeFiPosition
+		assets, debts     string
+		deposits, borrows float64
+	}
+	rows := make([]row, 0, len(positions))
+	var totalDeposits, totalBorrows float64
+	for _, position := range positions {
+		r := row{position: position}
+		r.assets, r.deposits = describe(position.Deposits)
+		r.debts, r.borrows = describe(position.Borrows)
+		totalDeposits += r.deposits
+		totalBorrows += r.borrows
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].deposits-rows[i].borrows > rows[j].deposits-rows[j].borrows
+	})
+
+	kinds := map[string]string{DeFiKindLending: i18n.T("借贷"), DeFiKindLP: "LP"}
+	table := &reportTable{columns: []tableColumn{
+		{header: i18n.T("钱包"), width: 12, left: true},
+		{header: i18n.T("协议"), width: 8, left: true},
+		{header: i18n.T("类型"), width: 6, left: true},
+		{header: i18n.T("存款/底层资产"), width: 36, left: true},
+		{header: i18n.T("借款"), width: 24, left: true},
+		{header: i18n.T("净值"), width: 12},
+		{header: i18n.T("无常损失"), width: 10},
+	}}
+	prices := make(map[string]float64, len(byMint))
+	for mint, token := range byMint {
+		prices[mint] = token.Price
+	}
+	for _, r := range rows {
+		loss := "-"
+		if il, ok := r.position.ImpermanentLoss(prices); ok {
+			loss = colorChange(fmt.Sprintf("%+.2f%%", il), il)
+		}
+		table.addRow(WalletLabel(r.position.Wallet), r.position.Protocol, kinds[r.position.Kind],
+			r.assets, r.debts, fmt.Sprintf("%.2f", r.deposits-r.borrows), loss)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n" + i18n.T("DeFi仓位") + "\n")
+	sb.WriteString(table.render())
+	sb.WriteString(i18n.Sprintf("DeFi存款: $%.2f，借款: $%.2f，净值: $%.2f", totalDeposits, totalBorrows, totalDeposits-totalBorrows))
+	if unvalued > 0 {
+		sb.WriteString(i18n.Sprintf(" (%d 项资产无价格)", unvalued))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// sortedKeys 返回集合中排序后的键，保证批量请求的顺序稳定
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
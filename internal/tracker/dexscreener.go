@@ -0,0 +1,132 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dexScreenerAPIEndpoint = "https://api.dexscreener.com/latest/dex/tokens"
+	dexScreenerBatchSize   = 30 // DexScreener 单次最多查询30个地址
+)
+
+// DexScreenerPriceService DexScreener价格服务
+type DexScreenerPriceService struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewDexScreenerPriceService 使用默认端点创建 DexScreener 价格服务
+func NewDexScreenerPriceService() *DexScreenerPriceService {
+	return NewDexScreenerPriceServiceWithConfig(dexScreenerAPIEndpoint, nil)
+}
+
+// NewDexScreenerPriceServiceWithConfig 使用指定的端点和HTTP客户端创建 DexScreener 价格服务
+func NewDexScreenerPriceServiceWithConfig(baseURL string, client *http.Client) *DexScreenerPriceService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &DexScreenerPriceService{
+		client:  client,
+		baseURL: baseURL,
+	}
+}
+
+// dexScreenerConfidence 根据交易对流动性估算价格可信度
+func dexScreenerConfidence(liquidityUSD float64) string {
+	switch {
+	case liquidityUSD >= 100_000:
+		return "high"
+	case liquidityUSD >= 10_000:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// GetTokenPrices 批量获取代币价格，每个代币取流动性最高的交易对
+func (s *DexScreenerPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	prices := make(map[string]*TokenPrice)
+	liquidity := make(map[string]float64)
+
+	for i := 0; i < len(mintAddrs); i += dexScreenerBatchSize {
+		if err := ctx.Err(); err != nil {
+			return prices, err
+		}
+
+		end := i + dexScreenerBatchSize
+		if end > len(mintAddrs) {
+			end = len(mintAddrs)
+		}
+		batch := mintAddrs[i:end]
+
+		// EVM 地址大小写不敏感，按请求时的写法返回价格
+		requested := make(map[string]string, len(batch))
+		for _, mintAddr := range batch {
+			requested[strings.ToLower(mintAddr)] = mintAddr
+		}
+
+		url := fmt.Sprintf("%s/%s", s.baseURL, strings.Join(batch, ","))
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return prices, fmt.Errorf("创建请求失败: %v", err)
+		}
+
+		resp, err := s.client.Do(req)
+		observeSourceResponse("dexscreener", resp, err)
+		if err != nil {
+			return prices, fmt.Errorf("请求失败: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return prices, fmt.Errorf("DexScreener 返回状态码 %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Pairs []struct {
+				BaseToken struct {
+					Address string `json:"address"`
+				} `json:"baseToken"`
+				PriceUSD  string `json:"priceUsd"`
+				Liquidity struct {
+					USD float64 `json:"usd"`
+				} `json:"liquidity"`
+			} `json:"pairs"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return prices, fmt.Errorf("解析响应失败: %v", err)
+		}
+
+		for _, pair := range result.Pairs {
+			mintAddr, ok := requested[strings.ToLower(pair.BaseToken.Address)]
+			if !ok {
+				continue
+			}
+			price, err := strconv.ParseFloat(pair.PriceUSD, 64)
+			if err != nil || price < minPriceUSD || price > maxPriceUSD {
+				continue
+			}
+			if existing, ok := liquidity[mintAddr]; ok && existing >= pair.Liquidity.USD {
+				continue
+			}
+			liquidity[mintAddr] = pair.Liquidity.USD
+			prices[mintAddr] = &TokenPrice{
+				Price:           price,
+				Source:          PriceSourceDexScreener,
+				Timestamp:       time.Now(),
+				ConfidenceLevel: dexScreenerConfidence(pair.Liquidity.USD),
+				Liquidity:       pair.Liquidity.USD,
+			}
+		}
+	}
+
+	priceLog.Info("从DexScreener获取价格完成", "priced", len(prices), "requested", len(mintAddrs))
+	return prices, nil
+}
@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// processStart 进程启动时间，用于计算运行时长
+var processStart = time.Now()
+
+// PhaseTiming 最近一次执行某个阶段的耗时
+type PhaseTiming struct {
+	Phase      string    `json:"phase"`
+	DurationMs int64     `json:"duration_ms"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+var (
+	timingMu sync.Mutex
+	timings  = make(map[string]PhaseTiming) // 阶段名 -> 最近一次耗时
+)
+
+// recordTiming 记录阶段从 start 到现在的耗时，用法: defer recordTiming("update_prices", time.Now())
+func recordTiming(phase string, start time.Time) {
+	now := time.Now()
+	timingMu.Lock()
+	defer timingMu.Unlock()
+	timings[phase] = PhaseTiming{Phase: phase, DurationMs: now.Sub(start).Milliseconds(), FinishedAt: now}
+}
+
+// lastTimings 返回各阶段最近一次的耗时，按阶段名排序
+func lastTimings() []PhaseTiming {
+	timingMu.Lock()
+	defer timingMu.Unlock()
+	result := make([]PhaseTiming, 0, len(timings))
+	for _, timing := range timings {
+		result = append(result, timing)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Phase < result[j].Phase
+	})
+	return result
+}
+
+// BreakerStatus 熔断器当前状态
+type BreakerStatus struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// breakerStatuses 返回 Helius、Jupiter 和各备用RPC端点的熔断器状态
+func breakerStatuses() []BreakerStatus {
+	breakers := []*CircuitBreaker{heliusBreaker, jupiterBreaker}
+	rpcMu.RLock()
+	for _, endpoint := range rpcFallbacks {
+		breakers = append(breakers, endpoint.breaker)
+	}
+	rpcMu.RUnlock()
+
+	statuses := make([]BreakerStatus, len(breakers))
+	for i, breaker := range breakers {
+		statuses[i] = BreakerStatus{Name: breaker.name, State: breaker.State()}
+	}
+	return statuses
+}
+
+// DebugStatus 运行状态诊断信息
+type DebugStatus struct {
+	StartedAt       time.Time       `json:"started_at"`
+	Uptime          string          `json:"uptime"`
+	Goroutines      int             `json:"goroutines"`
+	HeapAllocMB     float64         `json:"heap_alloc_mb"`
+	HeapObjects     uint64          `json:"heap_objects"`
+	NumGC           uint32          `json:"num_gc"`
+	HistoryUsed     int             `json:"history_used"`     // 环形缓冲区中已有的快照数
+	HistoryCapacity int             `json:"history_capacity"` // 环形缓冲区容量
+	Tokens          int             `json:"tokens"`
+	LastUpdate      time.Time       `json:"last_update"`
+	Timings         []PhaseTiming   `json:"timings"` // 最近一次获取钱包、更新价格和快照的耗时
+	Sources         []SourceStats   `json:"sources"` // 各API的请求数和错误率
+	Breakers        []BreakerStatus `json:"breakers"`
+	DegradedWallets int             `json:"degraded_wallets"`
+}
+
+// historyOccupancy 返回环形缓冲区中已有的快照数和容量
+func (m *TokenMonitor) historyOccupancy() (used, capacity int) {
+	m.historyMu.RLock()
+	defer m.historyMu.RUnlock()
+	m.priceHistory.Do(func(v interface{}) {
+		if v != nil {
+			used++
+		}
+	})
+	return used, m.priceHistory.Len()
+}
+
+// DebugStatus 收集监控器和进程的运行状态
+func (m *TokenMonitor) DebugStatus() DebugStatus {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	used, capacity := m.historyOccupancy()
+
+	return DebugStatus{
+		StartedAt:       processStart,
+		Uptime:          time.Since(processStart).Round(time.Second).String(),
+		Goroutines:      runtime.NumGoroutine(),
+		HeapAllocMB:     float64(mem.HeapAlloc) / 1024 / 1024,
+		HeapObjects:     mem.HeapObjects,
+		NumGC:           mem.NumGC,
+		HistoryUsed:     used,
+		HistoryCapacity: capacity,
+		Tokens:          len(m.Tokens()),
+		LastUpdate:      m.lastUpdate(),
+		Timings:         lastTimings(),
+		Sources:         AllSourceStats(),
+		Breakers:        breakerStatuses(),
+		DegradedWallets: len(degradedWallets()),
+	}
+}
+
+// registerDebugHandlers 注册 /debug/status
+func (s *Server) registerDebugHandlers() {
+	s.mux.HandleFunc("/debug/status", s.handleDebugStatus)
+}
+
+// EnablePprof 注册 net/http/pprof 的分析端点，请求需在 Authorization 头中附带 "Bearer <token>"；
+// cmdline 会暴露进程参数，profile 和 trace 会占用CPU，因此令牌不能为空
+func (s *Server) EnablePprof(token string) error {
+	if token == "" {
+		return fmt.Errorf("pprof 端点需要设置令牌")
+	}
+	handlers := map[string]http.HandlerFunc{
+		"/debug/pprof/":        pprof.Index,
+		"/debug/pprof/cmdline": pprof.Cmdline,
+		"/debug/pprof/profile": pprof.Profile,
+		"/debug/pprof/symbol":  pprof.Symbol,
+		"/debug/pprof/trace":   pprof.Trace,
+	}
+	for pattern, handler := range handlers {
+		s.mux.Handle(pattern, requireBearer(token, handler))
+	}
+	return nil
+}
+
+// requireBearer 只放行 Authorization 头为 "Bearer <token>" 的请求
+func requireBearer(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validBearer(r, token) {
+			serverLog.Warn("调试接口认证失败", "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validBearer 以固定时间比较请求的 Authorization 头与 "Bearer <token>"
+func validBearer(r *http.Request, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
+
+// handleDebugStatus 返回运行状态诊断信息
+func (s *Server) handleDebugStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, s.monitor.DebugStatus())
+}
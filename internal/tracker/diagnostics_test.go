@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPprofRequiresToken(t *testing.T) {
+	const token = "secret"
+
+	tests := []struct {
+		name   string
+		enable bool
+		auth   string
+		want   int
+	}{
+		{name: "未启用时不提供", want: http.StatusNotFound},
+		{name: "未启用时带令牌也不提供", auth: "Bearer " + token, want: http.StatusNotFound},
+		{name: "没有令牌", enable: true, want: http.StatusUnauthorized},
+		{name: "令牌错误", enable: true, auth: "Bearer wrong", want: http.StatusUnauthorized},
+		{name: "缺少 Bearer 前缀", enable: true, auth: token, want: http.StatusUnauthorized},
+		{name: "令牌正确", enable: true, auth: "Bearer " + token, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("", newTestMonitor(t, time.Minute))
+			if tt.enable {
+				if err := s.EnablePprof(token); err != nil {
+					t.Fatalf("EnablePprof 失败: %v", err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnablePprofWithoutToken(t *testing.T) {
+	s := NewServer("", newTestMonitor(t, time.Minute))
+	if err := s.EnablePprof(""); err == nil {
+		t.Fatal("没有令牌时应拒绝启用 pprof")
+	}
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("状态码 = %d, 期望 %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,127 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// Discord 嵌入消息颜色
+const (
+	discordColorUp      = 0x2ecc71
+	discordColorDown    = 0xe74c3c
+	discordColorNeutral = 0xf1c40f
+)
+
+// DiscordNotifier 通过 Discord webhook 发送报警
+type DiscordNotifier struct {
+	client     *http.Client
+	webhookURL string
+}
+
+// NewDiscordNotifier 创建 Discord webhook 通知渠道
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	return NewDiscordNotifierWithConfig(webhookURL, nil)
+}
+
+// NewDiscordNotifierWithConfig 使用指定的HTTP客户端创建 Discord 通知渠道，client 为nil时使用共享客户端
+func NewDiscordNotifierWithConfig(webhookURL string, client *http.Client) *DiscordNotifier {
+	if client == nil {
+		client = notifyHTTPClient()
+	}
+	return &DiscordNotifier{
+		client:     client,
+		webhookURL: webhookURL,
+	}
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Timestamp   string              `json:"timestamp"`
+}
+
+// Notify 将报警作为嵌入消息发送到 Discord
+func (d *DiscordNotifier) Notify(ctx context.Context, alert Alert) error {
+	embed := discordEmbed{
+		Title:       alertTitle(alert),
+		Description: alert.Message,
+		URL:         tokenURL(alert.Chain, alert.MintAddr),
+		Color:       discordColorNeutral,
+		Timestamp:   alert.Timestamp.Format(time.RFC3339),
+	}
+	if alert.ChangePct > 0 {
+		embed.Color = discordColorUp
+	} else if alert.ChangePct < 0 {
+		embed.Color = discordColorDown
+	}
+
+	if alert.Severity != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: i18n.T("级别"), Value: string(alert.Severity), Inline: true})
+	}
+	if alert.Symbol != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: i18n.T("代币"), Value: alert.Symbol, Inline: true})
+	}
+	if url := tokenURL(alert.Chain, alert.MintAddr); url != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{
+			Name:  "Mint",
+			Value: fmt.Sprintf("[%s](%s)", alert.MintAddr, url),
+		})
+	}
+	if alert.ChangePct != 0 {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: i18n.T("变化"), Value: fmt.Sprintf("%+.2f%%", alert.ChangePct), Inline: true})
+	}
+	if alert.Window > 0 {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: i18n.T("窗口"), Value: alert.Window.String(), Inline: true})
+	}
+	if alert.Wallet != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: i18n.T("钱包"), Value: WalletLabel(alert.Wallet), Inline: true})
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"embeds": []discordEmbed{embed},
+	})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %v", err)
+	}
+	return postJSON(ctx, d.client, d.webhookURL, payload, nil)
+}
+
+// postJSON 发送JSON请求，非2xx状态码视为失败
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("返回状态码 %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// minDivergenceSamples 判断偏离趋势所需的最少快照数
+const minDivergenceSamples = 3
+
+// SetSecondaryPriceService 设置用于交叉验证的价格数据源
+func (m *TokenMonitor) SetSecondaryPriceService(service PriceService) {
+	m.secondaryPriceService = service
+}
+
+// SetDivergenceAlert 设置数据源偏离报警：在 window 内偏离持续超过 threshold(%) 且呈上升趋势时报警
+func (m *TokenMonitor) SetDivergenceAlert(threshold float64, window time.Duration) {
+	m.divergenceThreshold = threshold
+	m.divergenceWindow = window
+
+	// 保证缓冲区能覆盖偏离观察窗口
+	if size := historySizeFor([]time.Duration{window}, m.shortestInterval()); size > m.priceHistory.Len() {
+		m.resizeHistory(size)
+	}
+}
+
+// crossCheckThreshold 返回降低可信度的偏离阈值（%），未设置偏离报警时使用聚合器的分歧阈值
+func (m *TokenMonitor) crossCheckThreshold() float64 {
+	if m.divergenceThreshold > 0 {
+		return m.divergenceThreshold
+	}
+	return priceDisagreementPct
+}
+
+// sourceDivergence 计算主数据源与交叉验证数据源的价格偏离（百分比）
+func sourceDivergence(token *TokenData) (float64, bool) {
+	if token == nil || token.Price <= 0 || token.SecondaryPrice <= 0 {
+		return 0, false
+	}
+	return abs(token.SecondaryPrice-token.Price) / token.Price * 100, true
+}
+
+// snapshotsSince 返回环形缓冲区中不早于 since 的快照，按时间升序排列
+func (m *TokenMonitor) snapshotsSince(since time.Time) []*PriceSnapshot {
+	var snapshots []*PriceSnapshot
+	m.historyMu.RLock()
+	m.priceHistory.Do(func(v interface{}) {
+		if v == nil {
+			return
+		}
+		snapshot := v.(*PriceSnapshot)
+		if !snapshot.Timestamp.Before(since) {
+			snapshots = append(snapshots, snapshot)
+		}
+	})
+	m.historyMu.RUnlock()
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+	return snapshots
+}
+
+// checkSourceDivergence 检查各代币的数据源偏离是否在窗口内持续且不断扩大
+func (m *TokenMonitor) checkSourceDivergence(currentSnapshot *PriceSnapshot) {
+	if m.divergenceThreshold <= 0 || m.divergenceWindow <= 0 {
+		return
+	}
+
+	windowStart := currentSnapshot.Timestamp.Add(-m.divergenceWindow)
+	history := m.snapshotsSince(windowStart)
+	if len(history) == 0 || history[len(history)-1] != currentSnapshot {
+		history = append(history, currentSnapshot)
+	}
+
+	// 窗口内的快照需覆盖足够长的时间，避免一次性偏离触发报警
+	if currentSnapshot.Timestamp.Sub(history[0].Timestamp) < m.divergenceWindow-m.longestInterval() {
+		return
+	}
+
+	for mintAddr, currentToken := range currentSnapshot.TokenData {
+		var divergences []float64
+		sustained := true
+		for _, snapshot := range history {
+			divergence, ok := sourceDivergence(snapshot.TokenData[mintAddr])
+			if !ok {
+				continue
+			}
+			if divergence < m.divergenceThreshold {
+				sustained = false
+				break
+			}
+			divergences = append(divergences, divergence)
+		}
+
+		if !sustained || len(divergences) < minDivergenceSamples {
+			continue
+		}
+
+		first, last := divergences[0], divergences[len(divergences)-1]
+		if last <= first {
+			continue
+		}
+
+		alertMsg := i18n.Sprintf("数据源偏离报警 - %s (%s) %s内价格偏离持续超过 %.2f%% 且不断扩大 (从 %.2f%% 到 %.2f%%, 主价格: $%.8f, 交叉验证价格: $%.8f)",
+			currentToken.Symbol,
+			mintAddr,
+			m.divergenceWindow.String(),
+			m.divergenceThreshold,
+			first,
+			last,
+			currentToken.Price,
+			currentToken.SecondaryPrice)
+
+		m.emitAlert(Alert{
+			Type:      AlertTypeDivergence,
+			MintAddr:  mintAddr,
+			Symbol:    currentToken.Symbol,
+			Window:    m.divergenceWindow,
+			ChangePct: last,
+			OldValue:  currentToken.Price,
+			NewValue:  currentToken.SecondaryPrice,
+			Message:   alertMsg,
+			Timestamp: currentSnapshot.Timestamp,
+		})
+	}
+}
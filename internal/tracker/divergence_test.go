@@ -0,0 +1,95 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alertLogLines 返回监控器报警日志中的报警行
+func alertLogLines(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dataDir, "alert.log"))
+	if err != nil {
+		t.Fatalf("读取报警日志失败: %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestCheckSourceDivergence(t *testing.T) {
+	const mint = "MintDiv"
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		divergences []float64 // 每分钟一个快照的偏离（%），0表示两个数据源一致
+		wantAlert   bool
+	}{
+		{name: "偏离持续超过阈值且不断扩大", divergences: []float64{3, 4, 5, 6, 7, 8}, wantAlert: true},
+		{name: "中间回落但整体扩大", divergences: []float64{3, 5, 4, 6, 5, 7}, wantAlert: true},
+		{name: "一次性的偏离", divergences: []float64{0, 0, 10, 0, 0, 0}},
+		{name: "最新快照的一次性偏离", divergences: []float64{0, 0, 0, 0, 0, 12}},
+		{name: "偏离持续但不变", divergences: []float64{5, 5, 5, 5, 5, 5}},
+		{name: "偏离持续但缩小", divergences: []float64{8, 7, 6, 5, 4, 3}},
+		{name: "窗口内有快照低于阈值", divergences: []float64{3, 4, 1, 6, 7, 8}},
+		{name: "快照没有覆盖整个窗口", divergences: []float64{3, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMonitor(t, time.Minute)
+			m.SetDivergenceAlert(2, 5*time.Minute)
+
+			// 与 takeSnapshot 相同：先写入缓冲区，再检查偏离
+			for i, divergence := range tt.divergences {
+				snapshot := &PriceSnapshot{
+					Timestamp: base.Add(time.Duration(i) * time.Minute),
+					TokenData: map[string]*TokenData{
+						mint: {MintAddr: mint, Symbol: "DIV", Price: 1, SecondaryPrice: 1 + divergence/100},
+					},
+				}
+				pushSnapshot(m, snapshot)
+				m.checkSourceDivergence(snapshot)
+			}
+
+			var alerts []string
+			for _, line := range alertLogLines(t) {
+				if strings.Contains(line, mint) {
+					alerts = append(alerts, line)
+				}
+			}
+			if tt.wantAlert && len(alerts) == 0 {
+				t.Errorf("偏离 %v 应触发报警", tt.divergences)
+			}
+			if !tt.wantAlert && len(alerts) > 0 {
+				t.Errorf("偏离 %v 不应触发报警, 得到 %q", tt.divergences, alerts)
+			}
+		})
+	}
+}
+
+func TestCheckSourceDivergenceDisabled(t *testing.T) {
+	m := newTestMonitor(t, time.Minute)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		snapshot := &PriceSnapshot{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			TokenData: map[string]*TokenData{
+				"MintDiv": {MintAddr: "MintDiv", Price: 1, SecondaryPrice: 1.1 + float64(i)/10},
+			},
+		}
+		pushSnapshot(m, snapshot)
+		m.checkSourceDivergence(snapshot)
+	}
+	if lines := alertLogLines(t); len(lines) > 0 {
+		t.Errorf("未设置偏离报警时不应报警, 得到 %q", lines)
+	}
+}
@@ -0,0 +1,198 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	// defaultEmailDigestWindow 收到第一条报警后等待合并的时长
+	defaultEmailDigestWindow = time.Minute
+
+	defaultEmailSubjectTemplate = `[wallet-tracker] {{len .Alerts}} 条报警{{if eq (len .Alerts) 1}}: {{(index .Alerts 0).Symbol}}{{end}}`
+	defaultEmailBodyTemplate    = `{{range .Alerts}}[{{.Timestamp.Format "2006-01-02 15:04:05"}}] {{.Message}}
+{{if .MintAddr}}{{tokenURL .MintAddr}}
+{{end}}
+{{end}}共 {{len .Alerts}} 条报警，汇总时间 {{.Start.Format "15:04:05"}} - {{.End.Format "15:04:05"}}
+`
+)
+
+// EmailConfig SMTP 通知配置
+type EmailConfig struct {
+	Host            string
+	Port            string
+	Username        string
+	Password        string
+	From            string
+	To              []string
+	DigestWindow    time.Duration // 报警合并窗口，<=0 时使用默认值
+	SubjectTemplate string        // 主题模板（text/template），为空时使用默认模板
+	BodyTemplate    string        // 正文模板（text/template），为空时使用默认模板
+}
+
+// EmailConfigFromEnv 从环境变量读取 SMTP 配置，未设置 SMTP_HOST 时返回 false
+func EmailConfigFromEnv() (EmailConfig, bool, error) {
+	cfg := EmailConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if cfg.Host == "" {
+		return cfg, false, nil
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	for _, to := range strings.Split(os.Getenv("SMTP_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			cfg.To = append(cfg.To, to)
+		}
+	}
+	if window := os.Getenv("SMTP_DIGEST_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return cfg, false, fmt.Errorf("SMTP_DIGEST_WINDOW 格式无效: %v", err)
+		}
+		cfg.DigestWindow = d
+	}
+	cfg.SubjectTemplate = os.Getenv("SMTP_SUBJECT_TEMPLATE")
+	if path := os.Getenv("SMTP_BODY_TEMPLATE_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, false, fmt.Errorf("读取邮件正文模板失败: %v", err)
+		}
+		cfg.BodyTemplate = string(data)
+	}
+	return cfg, true, nil
+}
+
+// emailDigest 模板数据
+type emailDigest struct {
+	Alerts []Alert
+	Start  time.Time
+	End    time.Time
+}
+
+// EmailNotifier 通过 SMTP 发送报警，窗口内的多条报警合并为一封摘要邮件
+type EmailNotifier struct {
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+	send    func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu      sync.Mutex
+	pending []Alert
+	timer   *time.Timer
+}
+
+// NewEmailNotifier 创建 SMTP 通知渠道
+func NewEmailNotifier(cfg EmailConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("SMTP 配置不完整: 需要 host、from 和 to")
+	}
+	if cfg.DigestWindow <= 0 {
+		cfg.DigestWindow = defaultEmailDigestWindow
+	}
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = i18n.T(defaultEmailSubjectTemplate)
+	}
+	if cfg.BodyTemplate == "" {
+		cfg.BodyTemplate = i18n.T(defaultEmailBodyTemplate)
+	}
+
+	funcs := template.FuncMap{"tokenURL": tokenURL, "walletLabel": WalletLabel, "t": i18n.T}
+	subject, err := template.New("subject").Funcs(funcs).Parse(cfg.SubjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("解析邮件主题模板失败: %v", err)
+	}
+	body, err := template.New("body").Funcs(funcs).Parse(cfg.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("解析邮件正文模板失败: %v", err)
+	}
+
+	return &EmailNotifier{
+		cfg:     cfg,
+		subject: subject,
+		body:    body,
+		send:    smtp.SendMail,
+	}, nil
+}
+
+// Notify 将报警加入待发送队列，合并窗口结束后统一发送
+func (e *EmailNotifier) Notify(_ context.Context, alert Alert) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pending = append(e.pending, alert)
+	if e.timer == nil {
+		e.timer = time.AfterFunc(e.cfg.DigestWindow, func() {
+			if err := e.Flush(); err != nil {
+				notifyLog.Error("发送报警邮件失败", "error", err)
+			}
+		})
+	}
+	return nil
+}
+
+// Flush 立即发送队列中的报警
+func (e *EmailNotifier) Flush() error {
+	e.mu.Lock()
+	alerts := e.pending
+	e.pending = nil
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.mu.Unlock()
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	digest := emailDigest{
+		Alerts: alerts,
+		Start:  alerts[0].Timestamp,
+		End:    alerts[len(alerts)-1].Timestamp,
+	}
+
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, digest); err != nil {
+		return fmt.Errorf("渲染邮件主题失败: %v", err)
+	}
+	if err := e.body.Execute(&body, digest); err != nil {
+		return fmt.Errorf("渲染邮件正文失败: %v", err)
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + e.cfg.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(e.cfg.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+	addr := net.JoinHostPort(e.cfg.Host, e.cfg.Port)
+	if err := e.send(addr, auth, e.cfg.From, e.cfg.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("SMTP发送失败: %v", err)
+	}
+	notifyLog.Info("已发送报警摘要邮件", "alerts", len(alerts))
+	return nil
+}
@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"wallet-tracker/config"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// EventPublisher 将消息发布到消息总线
+type EventPublisher interface {
+	// Publish 发布一条消息，key 用于 Kafka 分区（相同 key 的消息保持顺序），NATS 忽略
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+	// Close 发送缓冲中的消息并关闭连接
+	Close() error
+}
+
+// NewEventPublisher 根据配置连接消息总线，地址中的 ${ENV} 替换为环境变量
+func NewEventPublisher(cfg config.EventBus) (EventPublisher, error) {
+	brokers := make([]string, len(cfg.Brokers))
+	for i, broker := range cfg.Brokers {
+		brokers[i] = os.ExpandEnv(broker)
+	}
+	switch cfg.Driver {
+	case config.EventBusKafka:
+		return NewKafkaPublisher(brokers), nil
+	case config.EventBusNATS:
+		return NewNATSPublisher(brokers)
+	default:
+		return nil, fmt.Errorf("未知的消息总线: %s", cfg.Driver)
+	}
+}
+
+// EventBusNotifier 将报警和快照以JSON（与 webhook 相同的事件格式）发布到消息总线，供交易机器人和分析程序订阅
+type EventBusNotifier struct {
+	publisher     EventPublisher
+	snapshotTopic string
+	alertTopic    string
+}
+
+// NewEventBusNotifier 创建消息总线通知渠道
+func NewEventBusNotifier(publisher EventPublisher, snapshotTopic, alertTopic string) *EventBusNotifier {
+	return &EventBusNotifier{
+		publisher:     publisher,
+		snapshotTopic: snapshotTopic,
+		alertTopic:    alertTopic,
+	}
+}
+
+// Notify 发布报警事件，按代币（没有代币时按钱包）分区
+func (b *EventBusNotifier) Notify(ctx context.Context, alert Alert) error {
+	key := alert.MintAddr
+	if key == "" {
+		key = alert.Wallet
+	}
+	return b.publish(ctx, b.alertTopic, key, WebhookEvent{
+		Event:     "alert",
+		Timestamp: alert.Timestamp,
+		Data:      newAlertPayload(alert),
+	})
+}
+
+// ForwardSnapshots 订阅监控器的快照并逐个发布，直到 ctx 结束
+func (b *EventBusNotifier) ForwardSnapshots(ctx context.Context, monitor *TokenMonitor) {
+	events, unsubscribe := monitor.SubscribeSnapshots()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				publishCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+				if err := b.publish(publishCtx, b.snapshotTopic, "", WebhookEvent{
+					Event:     "snapshot",
+					Timestamp: event.Timestamp,
+					Data:      event,
+				}); err != nil {
+					notifyLog.Error("发布快照事件失败", "topic", b.snapshotTopic, "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// SignalSink 返回将跟单信号发布到 topic 的输出渠道，按钱包分区以保持同一钱包的信号顺序
+func (b *EventBusNotifier) SignalSink(topic string) SignalSink {
+	return &eventBusSignalSink{bus: b, topic: topic}
+}
+
+// eventBusSignalSink 将跟单信号发布到消息总线
+type eventBusSignalSink struct {
+	bus   *EventBusNotifier
+	topic string
+}
+
+// PublishSignal 发布跟单信号
+func (s *eventBusSignalSink) PublishSignal(ctx context.Context, signal TradeSignal) error {
+	return s.bus.publish(ctx, s.topic, signal.Wallet, WebhookEvent{
+		Event:     "trade_signal",
+		Timestamp: signal.Timestamp,
+		Data:      signal,
+	})
+}
+
+// Close 关闭消息总线连接
+func (b *EventBusNotifier) Close() error {
+	return b.publisher.Close()
+}
+
+// publish 序列化事件并发布
+func (b *EventBusNotifier) publish(ctx context.Context, topic, key string, event WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %v", err)
+	}
+	if err := b.publisher.Publish(ctx, topic, key, payload); err != nil {
+		return fmt.Errorf("发布到 %s 失败: %v", topic, err)
+	}
+	return nil
+}
+
+// KafkaPublisher 发布消息到 Kafka，topic 不存在时自动创建
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher 创建 Kafka 发布者，连接在第一次发布时建立
+func NewKafkaPublisher(brokers []string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireOne,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish 同步发布一条消息，等待 broker 确认
+func (p *KafkaPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	msg := kafka.Message{Topic: topic, Value: payload}
+	if key != "" {
+		msg.Key = []byte(key)
+	}
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+// Close 关闭 Kafka 连接
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// NATSPublisher 发布消息到 NATS，断线后自动重连，重连期间的消息缓存在客户端
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher 连接 NATS 服务器，servers 可包含用户名密码或令牌
+func NewNATSPublisher(servers []string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(strings.Join(servers, ","),
+		nats.Name("wallet-tracker"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				notifyLog.Warn("NATS连接断开", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			notifyLog.Info("NATS已重新连接", "server", conn.ConnectedUrlRedacted())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接NATS失败: %v", err)
+	}
+	return &NATSPublisher{conn: conn}, nil
+}
+
+// Publish 发布一条消息
+func (p *NATSPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.conn.Publish(topic, payload)
+}
+
+// Close 发送缓冲中的消息后关闭连接
+func (p *NATSPublisher) Close() error {
+	if err := p.conn.FlushTimeout(notifyTimeout); err != nil {
+		p.conn.Close()
+		return fmt.Errorf("发送NATS缓冲消息失败: %v", err)
+	}
+	p.conn.Close()
+	return nil
+}
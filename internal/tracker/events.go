@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+// snapshotSubscriberBuffer 每个订阅者的事件缓冲大小，消费过慢时丢弃新事件
+const snapshotSubscriberBuffer = 16
+
+// SnapshotEvent 每次快照推送给订阅者的数据
+type SnapshotEvent struct {
+	Timestamp  time.Time           `json:"timestamp"`
+	TotalValue float64             `json:"total_value"`
+	ChangePct  float64             `json:"change_pct"` // 相对上一个快照的总价值变化率
+	Tokens     []HoldingResponse   `json:"tokens"`
+	Allocation *Allocation         `json:"allocation"`         // 配置和集中度分析
+	Degraded   []WalletFetchStatus `json:"degraded,omitempty"` // 获取失败、总值不完整的钱包
+}
+
+// snapshotBroadcaster 将快照事件分发给多个订阅者
+type snapshotBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[chan SnapshotEvent]struct{}
+}
+
+// newSnapshotBroadcaster 创建快照事件分发器
+func newSnapshotBroadcaster() *snapshotBroadcaster {
+	return &snapshotBroadcaster{
+		subscribers: make(map[chan SnapshotEvent]struct{}),
+	}
+}
+
+// subscribe 注册订阅者，返回事件通道和取消订阅函数
+func (b *snapshotBroadcaster) subscribe() (<-chan SnapshotEvent, func()) {
+	ch := make(chan SnapshotEvent, snapshotSubscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish 向所有订阅者发送事件，不阻塞快照流程
+func (b *snapshotBroadcaster) publish(event SnapshotEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscribeSnapshots 订阅监控器的快照事件
+func (m *TokenMonitor) SubscribeSnapshots() (<-chan SnapshotEvent, func()) {
+	return m.snapshots.subscribe()
+}
+
+// newHoldingResponses 将代币数据转换为接口返回格式
+func newHoldingResponses(tokens []*TokenData) []HoldingResponse {
+	holdings := make([]HoldingResponse, 0, len(tokens))
+	for _, token := range tokens {
+		var riskScore *int
+		var riskFlags []string
+		if token.Safety != nil {
+			score := token.Safety.Score
+			riskScore = &score
+			riskFlags = token.Safety.Risks
+		}
+		holdings = append(holdings, HoldingResponse{
+			Symbol:          token.Symbol,
+			Mint:            token.MintAddr,
+			Amount:          token.Amount,
+			Price:           token.Price,
+			Value:           token.Value,
+			ConfidenceLevel: token.ConfidenceLevel,
+			StaleSeconds:    token.StaleFor.Seconds(),
+			Liquidity:       token.Liquidity,
+			BuyDepth:        token.BuyDepth,
+			SellDepth:       token.SellDepth,
+			RiskScore:       riskScore,
+			RiskFlags:       riskFlags,
+			Change:          token.Change,
+			Holders:         Holders(token),
+		})
+	}
+	return holdings
+}
+
+// NewSnapshotEvent 根据代币列表生成快照数据，用于一次性输出当前持仓
+func NewSnapshotEvent(tokens []*TokenData, at time.Time) SnapshotEvent {
+	var total float64
+	for _, token := range tokens {
+		total += token.Value
+	}
+	return SnapshotEvent{
+		Timestamp:  at,
+		TotalValue: total,
+		Tokens:     newHoldingResponses(tokens),
+		Allocation: AnalyzeAllocation(tokens),
+		Degraded:   degradedWallets(),
+	}
+}
@@ -0,0 +1,190 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"wallet-tracker/config"
+)
+
+// erc20BalanceOfSelector balanceOf(address) 的函数选择器
+const erc20BalanceOfSelector = "0x70a08231"
+
+// evmChainInfo EVM 链的默认参数
+type evmChainInfo struct {
+	endpointEnv   string // RPC 端点环境变量
+	nativeSymbol  string
+	wrappedNative string // 原生币对应的包装代币合约，用于查询价格
+}
+
+var evmChains = map[string]evmChainInfo{
+	config.ChainEthereum: {
+		endpointEnv:   "ETHEREUM_RPC_ENDPOINT",
+		nativeSymbol:  "ETH",
+		wrappedNative: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+	},
+	config.ChainBase: {
+		endpointEnv:   "BASE_RPC_ENDPOINT",
+		nativeSymbol:  "ETH",
+		wrappedNative: "0x4200000000000000000000000000000000000006",
+	},
+}
+
+// EVMChainClient 通过 JSON-RPC 查询 EVM 链上的原生币和 ERC-20 余额
+type EVMChainClient struct {
+	client   *http.Client
+	chain    string
+	endpoint string
+	info     evmChainInfo
+	tokens   []config.TokenConfig // 需要查询余额的 ERC-20 代币
+}
+
+// NewEVMChainClient 使用环境变量中的 RPC 端点创建 EVM 链客户端
+func NewEVMChainClient(chain string, tokens []config.TokenConfig) (*EVMChainClient, error) {
+	info, ok := evmChains[chain]
+	if !ok {
+		return nil, fmt.Errorf("不支持的 EVM 链: %s", chain)
+	}
+	endpoint := os.Getenv(info.endpointEnv)
+	if endpoint == "" {
+		return nil, fmt.Errorf("缺少 %s 配置", info.endpointEnv)
+	}
+	return NewEVMChainClientWithConfig(chain, endpoint, tokens, nil)
+}
+
+// NewEVMChainClientWithConfig 使用指定的端点和HTTP客户端创建 EVM 链客户端，client 为nil时使用共享客户端
+func NewEVMChainClientWithConfig(chain, endpoint string, tokens []config.TokenConfig, client *http.Client) (*EVMChainClient, error) {
+	info, ok := evmChains[chain]
+	if !ok {
+		return nil, fmt.Errorf("不支持的 EVM 链: %s", chain)
+	}
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &EVMChainClient{
+		client:   client,
+		chain:    chain,
+		endpoint: endpoint,
+		info:     info,
+		tokens:   tokens,
+	}, nil
+}
+
+// Chain 返回链名称
+func (e *EVMChainClient) Chain() string {
+	return e.chain
+}
+
+// FetchTokens 获取钱包的原生币余额和配置中各 ERC-20 代币的余额
+func (e *EVMChainClient) FetchTokens(ctx context.Context, walletAddr string) ([]*TokenData, error) {
+	walletLog.Info("开始获取钱包代币列表", "chain", e.chain, "wallet", walletAddr)
+
+	var tokens []*TokenData
+
+	nativeBalance, err := e.call(ctx, "eth_getBalance", []interface{}{walletAddr, "latest"})
+	if err != nil {
+		return nil, fmt.Errorf("获取原生币余额失败: %v", err)
+	}
+	if amount := scaleAmount(nativeBalance, 18); amount > 0 {
+		// 原生币使用包装代币合约查询价格
+		tokens = append(tokens, &TokenData{
+			MintAddr: e.info.wrappedNative,
+			Chain:    e.chain,
+			Amount:   amount,
+			Decimals: 18,
+			Symbol:   e.info.nativeSymbol,
+			Name:     e.info.nativeSymbol + " (" + e.chain + ")",
+		})
+	}
+
+	for _, token := range e.tokens {
+		data := erc20BalanceOfSelector + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(walletAddr, "0x"))
+		balance, err := e.call(ctx, "eth_call", []interface{}{
+			map[string]string{"to": token.Address, "data": data},
+			"latest",
+		})
+		if err != nil {
+			walletLog.Warn("获取代币余额失败", "chain", e.chain, "symbol", token.Symbol, "error", err)
+			continue
+		}
+		amount := scaleAmount(balance, token.Decimal)
+		if amount <= 0 {
+			continue
+		}
+		tokens = append(tokens, &TokenData{
+			MintAddr: token.Address,
+			Chain:    e.chain,
+			Amount:   amount,
+			Decimals: uint8(token.Decimal),
+			Symbol:   token.Symbol,
+			Name:     token.Name,
+		})
+	}
+
+	walletLog.Info("获取钱包代币完成", "chain", e.chain, "wallet", walletAddr, "tokens", len(tokens))
+	return tokens, nil
+}
+
+// call 发送 JSON-RPC 请求并返回十六进制结果
+func (e *EVMChainClient) call(ctx context.Context, method string, params []interface{}) (string, error) {
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析响应失败: %v", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("RPC错误: %s", result.Error.Message)
+	}
+	return result.Result, nil
+}
+
+// scaleAmount 将十六进制的原始数量按精度转换为实际数量
+func scaleAmount(hexAmount string, decimals int) float64 {
+	raw, ok := new(big.Int).SetString(strings.TrimPrefix(hexAmount, "0x"), 16)
+	if !ok || raw.Sign() <= 0 {
+		return 0
+	}
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	amount, _ := new(big.Float).Quo(new(big.Float).SetInt(raw), divisor).Float64()
+	return amount
+}
+
+// solanaMints 过滤掉 EVM 合约地址，只保留 Solana mint 地址
+func solanaMints(mintAddrs []string) []string {
+	filtered := make([]string, 0, len(mintAddrs))
+	for _, mintAddr := range mintAddrs {
+		if config.DetectChain(mintAddr) == config.ChainSolana {
+			filtered = append(filtered, mintAddr)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,447 @@
+package tracker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/config"
+)
+
+const (
+	binanceAPIEndpoint  = "https://api.binance.com"
+	okxAPIEndpoint      = "https://www.okx.com"
+	coinbaseAPIEndpoint = "https://api.coinbase.com"
+	coinbaseAPIVersion  = "2024-01-01"
+	exchangeMaxBody     = 1 << 20 // 错误响应最多读取的字节数
+)
+
+// exchangeAssetMints 交易所资产到链上代币 mint 地址的映射，映射后与链上持仓合并并使用链上价格
+var exchangeAssetMints = map[string]string{
+	"SOL":  nativeSOLMint,
+	"USDC": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+	"USDT": "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB",
+	"JUP":  "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN",
+	"JTO":  "jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL",
+	"PYTH": "HZ1JovNiVvGrGNiiYvEozEVgZ58xaU3RKwX8eACQBCt3",
+	"BONK": "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263",
+	"WIF":  "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm",
+}
+
+// exchangeAssetMint 返回交易所资产在组合中的标识：有对应链上代币时为其 mint 地址，否则为 cex:资产代码
+func exchangeAssetMint(asset string) string {
+	if mintAddr, ok := exchangeAssetMints[asset]; ok {
+		return mintAddr
+	}
+	return config.ExchangeAssetPrefix + asset
+}
+
+// isExchangeAsset 判断是否为没有对应链上代币的交易所资产，这类资产只使用交易所的报价
+func isExchangeAsset(mintAddr string) bool {
+	return strings.HasPrefix(mintAddr, config.ExchangeAssetPrefix)
+}
+
+var (
+	exchangePriceMu sync.RWMutex
+	exchangePrices  = make(map[string]*TokenPrice) // cex:资产代码 -> 最近一次获取余额时交易所的报价
+)
+
+// rememberExchangePrice 记录交易所资产的美元报价
+func rememberExchangePrice(mintAddr string, price float64, at time.Time) {
+	exchangePriceMu.Lock()
+	defer exchangePriceMu.Unlock()
+	exchangePrices[mintAddr] = &TokenPrice{
+		Price:           price,
+		Source:          PriceSourceExchange,
+		Timestamp:       at,
+		ConfidenceLevel: "high",
+	}
+}
+
+// exchangeQuotes 返回交易所资产最近一次的报价
+func exchangeQuotes(mintAddrs []string) map[string]*TokenPrice {
+	exchangePriceMu.RLock()
+	defer exchangePriceMu.RUnlock()
+	prices := make(map[string]*TokenPrice, len(mintAddrs))
+	for _, mintAddr := range mintAddrs {
+		if price, ok := exchangePrices[mintAddr]; ok {
+			copied := *price
+			prices[mintAddr] = &copied
+		}
+	}
+	return prices
+}
+
+// splitExchangeAssets 将交易所资产从需要查询链上价格的 mint 地址中分离出来
+func splitExchangeAssets(mintAddrs []string) (onChain, exchange []string) {
+	onChain = make([]string, 0, len(mintAddrs))
+	for _, mintAddr := range mintAddrs {
+		if isExchangeAsset(mintAddr) {
+			exchange = append(exchange, mintAddr)
+		} else {
+			onChain = append(onChain, mintAddr)
+		}
+	}
+	return onChain, exchange
+}
+
+// exchangeBalance 交易所账户中一种资产的余额
+type exchangeBalance struct {
+	asset  string
+	amount float64
+	price  float64 // 美元价格，0表示交易所没有报价
+}
+
+// exchangeConnector 交易所现货余额的只读接口
+type exchangeConnector interface {
+	balances(ctx context.Context) ([]exchangeBalance, error)
+}
+
+// ExchangeClient 通过交易所只读 API 获取现货账户余额，钱包地址为交易所名称
+type ExchangeClient struct {
+	client *http.Client
+}
+
+// NewExchangeClient 创建交易所客户端，API 凭证在获取余额时从环境变量读取
+func NewExchangeClient() *ExchangeClient {
+	return &ExchangeClient{client: apiHTTPClient()}
+}
+
+// Chain 返回链名称
+func (e *ExchangeClient) Chain() string {
+	return config.ChainExchange
+}
+
+// FetchTokens 获取交易所账户中余额不为0的资产，并记录交易所对这些资产的报价
+func (e *ExchangeClient) FetchTokens(ctx context.Context, account string) ([]*TokenData, error) {
+	exchange := strings.ToLower(account)
+	walletLog.Info("开始获取交易所余额", "exchange", exchange)
+
+	credentials := make([]string, 0, len(config.ExchangeCredentialEnvs[exchange]))
+	for _, env := range config.ExchangeCredentialEnvs[exchange] {
+		value := os.Getenv(env)
+		if value == "" {
+			return nil, fmt.Errorf("缺少 %s 配置", env)
+		}
+		credentials = append(credentials, value)
+	}
+
+	var connector exchangeConnector
+	switch exchange {
+	case config.ExchangeBinance:
+		connector = &binanceConnector{client: e.client, baseURL: binanceAPIEndpoint, apiKey: credentials[0], secret: credentials[1]}
+	case config.ExchangeOKX:
+		connector = &okxConnector{client: e.client, baseURL: okxAPIEndpoint, apiKey: credentials[0], secret: credentials[1], passphrase: credentials[2]}
+	case config.ExchangeCoinbase:
+		connector = &coinbaseConnector{client: e.client, baseURL: coinbaseAPIEndpoint, apiKey: credentials[0], secret: credentials[1]}
+	default:
+		return nil, fmt.Errorf("不支持的交易所: %s", account)
+	}
+
+	balances, err := connector.balances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 余额失败: %v", exchange, err)
+	}
+	tokens := exchangeTokens(balances, time.Now())
+	walletLog.Info("获取交易所余额完成", "exchange", exchange, "tokens", len(tokens))
+	return tokens, nil
+}
+
+// exchangeTokens 将交易所余额转换为代币数据，同一资产的多条余额合并
+func exchangeTokens(balances []exchangeBalance, now time.Time) []*TokenData {
+	byMint := make(map[string]*TokenData)
+	var tokens []*TokenData
+	for _, balance := range balances {
+		if balance.amount <= 0 {
+			continue
+		}
+		asset := strings.ToUpper(balance.asset)
+		mintAddr := exchangeAssetMint(asset)
+		if isExchangeAsset(mintAddr) && balance.price >= minPriceUSD && balance.price <= maxPriceUSD {
+			rememberExchangePrice(mintAddr, balance.price, now)
+		}
+		if token, ok := byMint[mintAddr]; ok {
+			token.Amount += balance.amount
+			continue
+		}
+		token := &TokenData{
+			MintAddr: mintAddr,
+			Amount:   balance.amount,
+			Symbol:   asset,
+			Name:     asset,
+		}
+		byMint[mintAddr] = token
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// hmacSHA256 使用密钥计算消息的 HMAC-SHA256
+func hmacSHA256(secret, message string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// doExchangeRequest 发送请求并将JSON响应解析到 out，非200状态码时返回响应内容
+func doExchangeRequest(client *http.Client, req *http.Request, source string, out interface{}) error {
+	resp, err := client.Do(req)
+	observeSourceResponse(source, resp, err)
+	if err != nil {
+		return fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, exchangeMaxBody))
+		return fmt.Errorf("返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// parseNumber 解析字符串形式的数值，无效时返回0
+func parseNumber(s string) float64 {
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// binanceConnector 币安现货账户，使用 HMAC-SHA256 签名的 API 密钥
+type binanceConnector struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	secret  string
+}
+
+// binanceQuotes 计价时依次尝试的报价币种
+var binanceQuotes = []string{"USDT", "USDC", "FDUSD"}
+
+// balances 获取现货账户的可用和冻结余额，价格取该资产对 USDT/USDC/FDUSD 交易对的最新价
+func (b *binanceConnector) balances(ctx context.Context) ([]exchangeBalance, error) {
+	query := url.Values{}
+	query.Set("omitZeroBalances", "true")
+	query.Set("recvWindow", "10000")
+	query.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	signed := query.Encode() + "&signature=" + hex.EncodeToString(hmacSHA256(b.secret, query.Encode()))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+"/api/v3/account?"+signed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("X-MBX-APIKEY", b.apiKey)
+
+	var account struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := doExchangeRequest(b.client, req, "binance", &account); err != nil {
+		return nil, err
+	}
+
+	prices, err := b.tickerPrices(ctx)
+	if err != nil {
+		walletLog.Warn("获取币安报价失败", "error", err)
+	}
+
+	var balances []exchangeBalance
+	for _, balance := range account.Balances {
+		amount := parseNumber(balance.Free) + parseNumber(balance.Locked)
+		if amount <= 0 {
+			continue
+		}
+		var price float64
+		for _, quote := range binanceQuotes {
+			if balance.Asset == quote {
+				price = 1
+				break
+			}
+			if p, ok := prices[balance.Asset+quote]; ok {
+				price = p
+				break
+			}
+		}
+		balances = append(balances, exchangeBalance{asset: balance.Asset, amount: amount, price: price})
+	}
+	return balances, nil
+}
+
+// tickerPrices 获取所有交易对的最新价（交易对 -> 价格）
+func (b *binanceConnector) tickerPrices(ctx context.Context) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+"/api/v3/ticker/price", nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	var tickers []struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	if err := doExchangeRequest(b.client, req, "binance", &tickers); err != nil {
+		return nil, err
+	}
+	prices := make(map[string]float64, len(tickers))
+	for _, ticker := range tickers {
+		if price := parseNumber(ticker.Price); price > 0 {
+			prices[ticker.Symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+// okxConnector OKX 交易账户，使用 API 密钥、密钥口令和 HMAC-SHA256 签名
+type okxConnector struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	secret     string
+	passphrase string
+}
+
+// balances 获取交易账户各币种的权益，价格由美元权益折算
+func (o *okxConnector) balances(ctx context.Context) ([]exchangeBalance, error) {
+	const path = "/api/v5/account/balance"
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	req, err := http.NewRequestWithContext(ctx, "GET", o.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("OK-ACCESS-KEY", o.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", base64.StdEncoding.EncodeToString(hmacSHA256(o.secret, timestamp+"GET"+path)))
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", o.passphrase)
+
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Details []struct {
+				Currency  string `json:"ccy"`
+				Equity    string `json:"eq"`
+				EquityUSD string `json:"eqUsd"`
+			} `json:"details"`
+		} `json:"data"`
+	}
+	if err := doExchangeRequest(o.client, req, "okx", &result); err != nil {
+		return nil, err
+	}
+	if result.Code != "0" {
+		return nil, fmt.Errorf("OKX错误 %s: %s", result.Code, result.Msg)
+	}
+
+	var balances []exchangeBalance
+	for _, data := range result.Data {
+		for _, detail := range data.Details {
+			amount := parseNumber(detail.Equity)
+			if amount <= 0 {
+				continue
+			}
+			balances = append(balances, exchangeBalance{
+				asset:  detail.Currency,
+				amount: amount,
+				price:  parseNumber(detail.EquityUSD) / amount,
+			})
+		}
+	}
+	return balances, nil
+}
+
+// coinbaseConnector Coinbase 账户，使用 HMAC-SHA256 签名的 API 密钥
+type coinbaseConnector struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	secret  string
+}
+
+// balances 获取所有账户的余额（自动翻页），价格取各资产对美元的现货价
+func (c *coinbaseConnector) balances(ctx context.Context) ([]exchangeBalance, error) {
+	var balances []exchangeBalance
+	prices := make(map[string]float64)
+
+	path := "/v2/accounts?limit=100"
+	for path != "" {
+		var page struct {
+			Pagination struct {
+				NextURI string `json:"next_uri"`
+			} `json:"pagination"`
+			Data []struct {
+				Balance struct {
+					Amount   string `json:"amount"`
+					Currency string `json:"currency"`
+				} `json:"balance"`
+			} `json:"data"`
+		}
+		if err := c.get(ctx, path, true, &page); err != nil {
+			return nil, err
+		}
+
+		for _, account := range page.Data {
+			amount := parseNumber(account.Balance.Amount)
+			asset := account.Balance.Currency
+			if amount <= 0 {
+				continue
+			}
+			price, ok := prices[asset]
+			if !ok {
+				price = c.spotPrice(ctx, asset)
+				prices[asset] = price
+			}
+			balances = append(balances, exchangeBalance{asset: asset, amount: amount, price: price})
+		}
+		path = page.Pagination.NextURI
+	}
+	return balances, nil
+}
+
+// spotPrice 获取资产对美元的现货价，失败时返回0
+func (c *coinbaseConnector) spotPrice(ctx context.Context, asset string) float64 {
+	if asset == "USD" {
+		return 1
+	}
+	var result struct {
+		Data struct {
+			Amount string `json:"amount"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/v2/prices/"+url.PathEscape(asset)+"-USD/spot", false, &result); err != nil {
+		walletLog.Warn("获取Coinbase报价失败", "asset", asset, "error", err)
+		return 0
+	}
+	return parseNumber(result.Data.Amount)
+}
+
+// get 发送GET请求，signed 为 true 时附带签名头
+func (c *coinbaseConnector) get(ctx context.Context, path string, signed bool, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("CB-VERSION", coinbaseAPIVersion)
+	if signed {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("CB-ACCESS-KEY", c.apiKey)
+		req.Header.Set("CB-ACCESS-SIGN", hex.EncodeToString(hmacSHA256(c.secret, timestamp+"GET"+path)))
+		req.Header.Set("CB-ACCESS-TIMESTAMP", timestamp)
+	}
+	return doExchangeRequest(c.client, req, "coinbase", out)
+}
@@ -0,0 +1,449 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// errExprMissing 表达式引用的变量当前没有数据（如历史快照不足），此时规则视为不满足
+var errExprMissing = errors.New("变量没有数据")
+
+// exprEnv 表达式求值时的变量来源
+type exprEnv interface {
+	// lookup 返回变量的值（float64/string/bool），没有数据时返回 errExprMissing
+	lookup(name string) (interface{}, error)
+}
+
+// exprNode 表达式语法树节点
+type exprNode interface {
+	eval(env exprEnv) (interface{}, error)
+}
+
+// Expr 编译后的规则表达式，支持数字、字符串、布尔字面量，变量，
+// 算术运算 + - * /，比较运算 == != < <= > >=，逻辑运算 && || ! 和括号
+type Expr struct {
+	source string
+	root   exprNode
+	vars   []string // 表达式引用的变量名
+}
+
+// CompileExpr 解析表达式，validVar 非nil时校验变量名
+func CompileExpr(source string, validVar func(name string) bool) (*Expr, error) {
+	tokens, err := lexExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("表达式第 %d 个字符附近有多余内容: %s", p.peek().pos+1, p.peek().text)
+	}
+	for _, name := range p.vars {
+		if validVar != nil && !validVar(name) {
+			return nil, fmt.Errorf("未知变量: %s", name)
+		}
+	}
+	return &Expr{source: source, root: root, vars: p.vars}, nil
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Vars 返回表达式引用的变量名
+func (e *Expr) Vars() []string {
+	return e.vars
+}
+
+// evalBool 求值并要求结果为布尔值
+func (e *Expr) evalBool(env exprEnv) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("表达式结果不是布尔值: %v", v)
+	}
+	return b, nil
+}
+
+// exprToken 词法单元
+type exprToken struct {
+	kind string // num/str/ident/op
+	text string
+	pos  int
+}
+
+// lexExpr 将表达式拆分为词法单元
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "num", text: src[start:i], pos: start})
+		case c == '\'' || c == '"':
+			start := i
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end < 0 {
+				return nil, fmt.Errorf("表达式第 %d 个字符处的字符串没有结束引号", start+1)
+			}
+			tokens = append(tokens, exprToken{kind: "str", text: src[i+1 : i+1+end], pos: start})
+			i += end + 2
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: src[start:i], pos: start})
+		default:
+			op := ""
+			if i+1 < len(src) {
+				switch two := src[i : i+2]; two {
+				case "&&", "||", "==", "!=", "<=", ">=":
+					op = two
+				}
+			}
+			if op == "" && strings.ContainsRune("+-*/<>!()", c) {
+				op = string(c)
+			}
+			if op == "" {
+				return nil, fmt.Errorf("表达式第 %d 个字符无效: %q", i+1, c)
+			}
+			tokens = append(tokens, exprToken{kind: "op", text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser 递归下降解析器，优先级从低到高: || && 比较 +- */ 一元运算
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	vars   []string
+}
+
+func (p *exprParser) peek() exprToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return exprToken{}
+}
+
+// accept 当前词法单元是指定运算符时前进并返回true
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseAdd() (exprNode, error) {
+	left, err := p.parseMul()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMul()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseMul() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("表达式意外结束")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "num":
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("表达式第 %d 个字符处的数字无效: %s", t.pos+1, t.text)
+		}
+		return &literalNode{value: v}, nil
+	case "str":
+		return &literalNode{value: t.text}, nil
+	case "ident":
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		p.vars = append(p.vars, t.text)
+		return &varNode{name: t.text}, nil
+	case "op":
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("表达式第 %d 个字符处的括号没有闭合", t.pos+1)
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("表达式第 %d 个字符附近语法错误: %s", t.pos+1, t.text)
+}
+
+// literalNode 字面量
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(exprEnv) (interface{}, error) {
+	return n.value, nil
+}
+
+// varNode 变量引用
+type varNode struct {
+	name string
+}
+
+func (n *varNode) eval(env exprEnv) (interface{}, error) {
+	return env.lookup(n.name)
+}
+
+// unaryNode 一元运算 ! 和 -
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+func (n *unaryNode) eval(env exprEnv) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! 只能用于布尔值: %v", v)
+		}
+		return !b, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("- 只能用于数字: %v", v)
+	}
+	return -f, nil
+}
+
+// logicNode 短路求值的 && 和 ||；缺少数据的一侧视为 false
+type logicNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *logicNode) eval(env exprEnv) (interface{}, error) {
+	left, err := evalOperandBool(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" && !left {
+		return false, nil
+	}
+	if n.op == "||" && left {
+		return true, nil
+	}
+	return evalOperandBool(n.right, env)
+}
+
+// evalOperandBool 求值逻辑运算的一侧，缺少数据时视为 false，使 || 的另一侧仍可生效
+func evalOperandBool(node exprNode, env exprEnv) (bool, error) {
+	v, err := node.eval(env)
+	if errors.Is(err, errExprMissing) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("逻辑运算只能用于布尔值: %v", v)
+	}
+	return b, nil
+}
+
+// binaryNode 算术和比较运算
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *binaryNode) eval(env exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "==" || n.op == "!=" {
+		equal, err := exprEqual(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return equal == (n.op == "=="), nil
+	}
+
+	// 字符串只支持相等比较
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s 只能用于数字: %v %s %v", n.op, left, n.op, right)
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, errExprMissing
+		}
+		return l / r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	}
+	return nil, fmt.Errorf("未知运算符: %s", n.op)
+}
+
+// exprEqual 比较两个同类型的值，字符串比较不区分大小写
+func exprEqual(left, right interface{}) (bool, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return l == r, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.EqualFold(l, r), nil
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			return l == r, nil
+		}
+	}
+	return false, fmt.Errorf("无法比较不同类型的值: %v 和 %v", left, right)
+}
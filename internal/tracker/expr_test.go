@@ -0,0 +1,158 @@
+package tracker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapEnv 以 map 提供变量，map 中没有的变量视为没有数据
+type mapEnv map[string]interface{}
+
+func (e mapEnv) lookup(name string) (interface{}, error) {
+	if v, ok := e[name]; ok {
+		return v, nil
+	}
+	return nil, errExprMissing
+}
+
+func TestExprEvalBool(t *testing.T) {
+	env := mapEnv{
+		"t":      true,
+		"f":      false,
+		"x":      3.0,
+		"y":      2.0,
+		"symbol": "USDC",
+	}
+
+	tests := []struct {
+		name        string
+		expr        string
+		want        bool
+		wantMissing bool // 结果为 errExprMissing
+		wantErr     bool // 结果为其他错误
+	}{
+		// 优先级
+		{name: "&& 优先于 ||", expr: "t || f && f", want: true},
+		{name: "&& 优先于 || 左侧", expr: "f && f || t", want: true},
+		{name: "括号改变优先级", expr: "(t || f) && f", want: false},
+		{name: "乘法优先于加法", expr: "2 + 3 * 4 == 14", want: true},
+		{name: "除法左结合", expr: "12 / 3 / 2 == 2", want: true},
+		{name: "减法左结合", expr: "10 - 3 - 2 == 5", want: true},
+		{name: "算术优先于比较", expr: "x + 1 > y * 2 - 1", want: true},
+		{name: "一元负号优先于乘法", expr: "-x * 2 == -6", want: true},
+		{name: "双重负号", expr: "- -x == 3", want: true},
+		{name: "负号用于括号", expr: "-(x - y) < 0", want: true},
+
+		// 一元 !
+		{name: "取反", expr: "!f", want: true},
+		{name: "双重取反", expr: "!!t", want: true},
+		{name: "取反比较结果", expr: "!(x > y)", want: false},
+		{name: "! 优先于 &&", expr: "!f && t", want: true},
+		{name: "! 用于数字", expr: "!x", wantErr: true},
+		{name: "- 用于布尔值", expr: "-t == 1", wantErr: true},
+
+		// 字符串
+		{name: "字符串相等不区分大小写", expr: "symbol == 'usdc'", want: true},
+		{name: "双引号字符串", expr: `symbol == "Usdc"`, want: true},
+		{name: "字符串不等", expr: "symbol != 'USDT'", want: true},
+		{name: "字符串不支持大小比较", expr: "symbol < 'z'", wantErr: true},
+		{name: "不同类型不能比较", expr: "symbol == 1", wantErr: true},
+
+		// 缺少数据
+		{name: "除以零视为没有数据", expr: "x / 0 > 1", wantMissing: true},
+		{name: "|| 中除以零的一侧视为 false", expr: "x / 0 > 1 || y > 1", want: true},
+		{name: "|| 两侧都没有数据", expr: "x / 0 > 1 || z > 1", want: false},
+		{name: "&& 中除以零的一侧视为 false", expr: "x / 0 > 1 && y > 1", want: false},
+		{name: "缺少变量", expr: "z > 1", wantMissing: true},
+		{name: "|| 中缺少变量的一侧视为 false", expr: "z > 1 || t", want: true},
+		{name: "! 不会将缺少数据变为 true", expr: "!(z > 1)", wantMissing: true},
+
+		// 结果类型
+		{name: "结果不是布尔值", expr: "x + 1", wantErr: true},
+		{name: "逻辑运算用于数字", expr: "x || t", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileExpr(tt.expr, nil)
+			if err != nil {
+				t.Fatalf("CompileExpr(%q) 失败: %v", tt.expr, err)
+			}
+			got, err := expr.evalBool(env)
+			switch {
+			case tt.wantMissing:
+				if !errors.Is(err, errExprMissing) {
+					t.Errorf("%q: 期望 errExprMissing, 得到 %v, %v", tt.expr, got, err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.Is(err, errExprMissing) {
+					t.Errorf("%q: 期望求值错误, 得到 %v, %v", tt.expr, got, err)
+				}
+			case err != nil:
+				t.Errorf("%q: 求值失败: %v", tt.expr, err)
+			case got != tt.want:
+				t.Errorf("%q = %v, 期望 %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileExprErrors(t *testing.T) {
+	validVar := func(name string) bool {
+		_, _, ok := parseRuleVar(name)
+		return ok
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string // 错误信息应包含的内容
+	}{
+		{name: "字符串没有结束引号", expr: "symbol == 'USDC", wantErr: "没有结束引号"},
+		{name: "引号不匹配", expr: `symbol == "USDC'`, wantErr: "没有结束引号"},
+		{name: "括号没有闭合", expr: "(price > 1 && value > 2", wantErr: "括号没有闭合"},
+		{name: "嵌套括号没有闭合", expr: "((price > 1)", wantErr: "括号没有闭合"},
+		{name: "多余的右括号", expr: "price > 1)", wantErr: "多余内容"},
+		{name: "多余的操作数", expr: "price > 1 value", wantErr: "多余内容"},
+		{name: "比较不能连用", expr: "1 < price < 2", wantErr: "多余内容"},
+		{name: "表达式意外结束", expr: "price >", wantErr: "意外结束"},
+		{name: "空表达式", expr: "", wantErr: "意外结束"},
+		{name: "无效字符", expr: "price # 1", wantErr: "字符无效"},
+		{name: "无效数字", expr: "price > 1.2.3", wantErr: "数字无效"},
+		{name: "缺少操作数", expr: "price > * 2", wantErr: "语法错误"},
+		{name: "未知变量", expr: "prise > 1", wantErr: "未知变量: prise"},
+		{name: "未知的时间窗口", expr: "change_5x > 1", wantErr: "未知变量: change_5x"},
+		{name: "|| 右侧的未知变量", expr: "price > 1 || volume > 2", wantErr: "未知变量: volume"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileExpr(tt.expr, validVar)
+			if err == nil {
+				t.Fatalf("CompileExpr(%q) 应返回错误, 得到 %v", tt.expr, expr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CompileExpr(%q) 错误 = %q, 期望包含 %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileRuleVars(t *testing.T) {
+	rule, err := CompileRule("test", "change_5m > 10 && portfolio.change_1h < -5 || token.symbol == 'sol'", "", nil, "")
+	if err != nil {
+		t.Fatalf("CompileRule 失败: %v", err)
+	}
+	if want := []string{"change_5m", "portfolio.change_1h", "token.symbol"}; !reflect.DeepEqual(rule.When.Vars(), want) {
+		t.Errorf("Vars = %v, 期望 %v", rule.When.Vars(), want)
+	}
+	if !rule.perToken {
+		t.Error("引用代币变量的规则应按代币检查")
+	}
+	if want := []time.Duration{5 * time.Minute, time.Hour}; !reflect.DeepEqual(rule.windows, want) {
+		t.Errorf("windows = %v, 期望 %v", rule.windows, want)
+	}
+}
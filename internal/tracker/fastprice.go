@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"context"
+	"time"
+)
+
+// FastPriceWatch 以比监控快照更短的间隔轮询价值最高的若干代币的价格，
+// 价格变化超过报警阈值时立即发送价格报警，不必等待下一次快照；
+// 与快照使用相同的报警去重键，同一变化不会重复报警
+type FastPriceWatch struct {
+	service  QuotePriceService
+	top      int
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewFastPriceWatch 创建快速价格轮询，service 应直接请求数据源而不经过价格缓存
+func NewFastPriceWatch(service QuotePriceService, top int, interval time.Duration) *FastPriceWatch {
+	return &FastPriceWatch{
+		service:  service,
+		top:      top,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run 在后台轮询价格直到 ctx 取消
+func (w *FastPriceWatch) Run(ctx context.Context, monitor *TokenMonitor) {
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.poll(ctx, monitor)
+			}
+		}
+	}()
+}
+
+// Close 等待轮询退出，需在 Run 的 ctx 结束后调用
+func (w *FastPriceWatch) Close() {
+	<-w.done
+}
+
+// poll 获取价值最高的代币的最新价格并检查价格报警
+func (w *FastPriceWatch) poll(ctx context.Context, monitor *TokenMonitor) {
+	if !monitor.builtinAlertsEnabled() || monitor.Paused() {
+		return
+	}
+	var tokens []*TokenData
+	for _, token := range FilterTopTokensByValue(monitor.Tokens(), len(monitor.Tokens()), 0) {
+		// 稳定币按 $1 估值，价格报警没有意义
+		if !isStablecoin(token) {
+			tokens = append(tokens, token)
+		}
+		if len(tokens) == w.top {
+			break
+		}
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	mintAddrs := make([]string, len(tokens))
+	for i, token := range tokens {
+		mintAddrs[i] = token.MintAddr
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, w.interval)
+	defer cancel()
+	prices, err := w.service.GetTokenPrices(pollCtx, mintAddrs)
+	if err != nil {
+		if ctx.Err() == nil {
+			priceLog.Debug("快速价格轮询失败", "tokens", len(mintAddrs), "error", err)
+		}
+		return
+	}
+	monitor.checkFastPrices(tokens, prices, time.Now())
+}
+
+// checkFastPrices 用轮询到的最新价格与历史快照比较，变化超过阈值时发送价格报警
+func (m *TokenMonitor) checkFastPrices(tokens []*TokenData, prices map[string]*TokenPrice, now time.Time) {
+	windows := m.windows()
+	threshold, _ := m.alertThresholds()
+	oldSnapshots := m.windowSnapshots(now, windows)
+	for _, token := range tokens {
+		price, ok := prices[token.MintAddr]
+		if !ok || price.Price <= 0 {
+			continue
+		}
+		current := *token
+		current.Price = price.Price
+		current.Value = current.Amount * price.Price
+
+		for i, window := range windows {
+			oldSnapshot := oldSnapshots[i]
+			if oldSnapshot == nil {
+				continue
+			}
+			oldToken, exists := oldSnapshot.TokenData[token.MintAddr]
+			if !exists || oldToken.Price <= 0 {
+				continue
+			}
+			priceChange := (current.Price - oldToken.Price) / oldToken.Price * 100
+			if abs(priceChange) >= threshold {
+				monitorLog.Debug("快速价格轮询触发报警", "symbol", token.Symbol, "window", window, "change_pct", priceChange)
+				m.emitPriceAlert(&current, oldToken, window, priceChange, now)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package tracker
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// WalletFetchStatus 单个钱包最近一次获取代币的结果
+type WalletFetchStatus struct {
+	Wallet      string    `json:"wallet"`
+	Label       string    `json:"label,omitempty"`
+	OK          bool      `json:"ok"`
+	Cached      bool      `json:"cached,omitempty"` // 获取失败，沿用上一次成功获取的代币列表
+	Tokens      int       `json:"tokens"`
+	Error       string    `json:"error,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	LastSuccess time.Time `json:"last_success,omitempty"` // 最近一次成功获取的时间
+}
+
+// Degraded 判断该钱包的数据是否不完整（获取失败或沿用旧数据）
+func (s *WalletFetchStatus) Degraded() bool {
+	return !s.OK || s.Cached
+}
+
+// WalletFetchResult 一次获取多个钱包代币的结果
+type WalletFetchResult struct {
+	Tokens     map[string][]*TokenData       // 成功获取（或沿用旧数据）的钱包的代币列表
+	Errors     map[string]error              // 获取失败的钱包的错误
+	Statuses   map[string]*WalletFetchStatus // 每个钱包的获取结果
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Degraded 返回数据不完整的钱包地址（按地址排序）
+func (r *WalletFetchResult) Degraded() []string {
+	var wallets []string
+	for wallet, status := range r.Statuses {
+		if status.Degraded() {
+			wallets = append(wallets, wallet)
+		}
+	}
+	sort.Strings(wallets)
+	return wallets
+}
+
+var (
+	walletStatusMu sync.RWMutex
+	walletStatuses = make(map[string]*WalletFetchStatus) // 钱包地址 -> 最近一次获取的结果
+)
+
+// recordWalletStatuses 记录本次获取的结果，失败的钱包保留上一次成功获取的时间
+func recordWalletStatuses(statuses map[string]*WalletFetchStatus) {
+	walletStatusMu.Lock()
+	defer walletStatusMu.Unlock()
+	for wallet, status := range statuses {
+		if status.LastSuccess.IsZero() {
+			if old, ok := walletStatuses[wallet]; ok {
+				status.LastSuccess = old.LastSuccess
+			}
+		}
+		walletStatuses[wallet] = status
+	}
+}
+
+// PruneWalletStatuses 删除不再跟踪的钱包的获取结果，钱包列表变化时调用
+func PruneWalletStatuses(walletAddrs []string) {
+	keep := make(map[string]bool, len(walletAddrs))
+	for _, addr := range walletAddrs {
+		keep[addr] = true
+	}
+	walletStatusMu.Lock()
+	defer walletStatusMu.Unlock()
+	for wallet := range walletStatuses {
+		if !keep[wallet] {
+			delete(walletStatuses, wallet)
+		}
+	}
+}
+
+// WalletStatuses 返回各钱包最近一次获取的结果，按地址排序
+func WalletStatuses() []WalletFetchStatus {
+	walletStatusMu.RLock()
+	defer walletStatusMu.RUnlock()
+	statuses := make([]WalletFetchStatus, 0, len(walletStatuses))
+	for _, status := range walletStatuses {
+		s := *status
+		s.Label = WalletLabel(s.Wallet)
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Wallet < statuses[j].Wallet
+	})
+	return statuses
+}
+
+// degradedWallets 返回数据不完整的钱包
+func degradedWallets() []WalletFetchStatus {
+	var degraded []WalletFetchStatus
+	for _, status := range WalletStatuses() {
+		if status.Degraded() {
+			degraded = append(degraded, status)
+		}
+	}
+	return degraded
+}
+
+// describeWalletStatus 单个数据不完整钱包的说明，如 "主钱包: 请求超时（上次成功 14:05）"
+func describeWalletStatus(status WalletFetchStatus) string {
+	desc := status.Label + ": " + status.Error
+	if status.Cached {
+		desc += i18n.T("，沿用旧数据")
+	}
+	if !status.LastSuccess.IsZero() {
+		desc += i18n.Sprintf("（上次成功 %s）", status.LastSuccess.Format("01-02 15:04"))
+	}
+	return desc
+}
+
+// degradedNotice 报告开头的数据不完整提示，所有钱包正常时返回空字符串
+func degradedNotice() string {
+	degraded := degradedWallets()
+	if len(degraded) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf("\n⚠ 数据不完整: %d 个钱包获取失败，总值可能偏低\n", len(degraded)))
+	for _, status := range degraded {
+		sb.WriteString("  " + describeWalletStatus(status) + "\n")
+	}
+	return sb.String()
+}
+
+// degradedWatch 已发出数据不完整报警的钱包
+type degradedWatch struct {
+	mu      sync.Mutex
+	alerted map[string]bool
+}
+
+// checkDegraded 钱包开始获取失败时发出数据不完整报警，恢复后重新生效
+func (m *TokenMonitor) checkDegraded(snapshot *PriceSnapshot) {
+	degraded := degradedWallets()
+
+	m.degraded.mu.Lock()
+	defer m.degraded.mu.Unlock()
+	if m.degraded.alerted == nil {
+		m.degraded.alerted = make(map[string]bool)
+	}
+
+	current := make(map[string]bool, len(degraded))
+	for _, status := range degraded {
+		current[status.Wallet] = true
+		if m.degraded.alerted[status.Wallet] {
+			continue
+		}
+		m.degraded.alerted[status.Wallet] = true
+		m.emitAlert(Alert{
+			Type:     AlertTypeDegraded,
+			Wallet:   status.Wallet,
+			NewValue: snapshot.Value,
+			Message: i18n.Sprintf("数据不完整: 钱包 %s 获取失败，组合总值 $%.2f 不包含该钱包的最新持仓\n%s\n当前共 %d 个钱包数据不完整",
+				status.Label, snapshot.Value, describeWalletStatus(status), len(degraded)),
+			Timestamp: snapshot.Timestamp,
+		})
+	}
+	for wallet := range m.degraded.alerted {
+		if !current[wallet] {
+			delete(m.degraded.alerted, wallet)
+			monitorLog.Info("钱包数据已恢复", "wallet", WalletLabel(wallet))
+		}
+	}
+}
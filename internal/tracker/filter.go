@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"sync"
+)
+
+// defaultMaxTokens 默认保留的代币数量
+const defaultMaxTokens = 50
+
+// TokenFilter 价格更新后应用的代币过滤规则
+type TokenFilter struct {
+	MinValue          float64         // 最小价值（美元），0表示不过滤
+	MinLiquidity      float64         // 最小流动性（美元），流动性未知的代币不受限制
+	Blacklist         map[string]bool // 始终隐藏的 mint 地址
+	Whitelist         map[string]bool // 非空时只保留其中的 mint 地址
+	HideLowConfidence bool            // 隐藏低可信度价格的代币
+	MaxTokens         int             // 按价值保留的最多代币数量，<=0 时使用默认值
+	HideSpam          bool            // 隐藏名称带有诈骗特征或未经买入转入的粉尘垃圾代币
+}
+
+// DefaultTokenFilter 返回默认的过滤规则：隐藏低可信度价格和垃圾代币，保留价值最高的50个代币
+func DefaultTokenFilter() TokenFilter {
+	return TokenFilter{
+		HideLowConfidence: true,
+		HideSpam:          true,
+		MaxTokens:         defaultMaxTokens,
+	}
+}
+
+var (
+	tokenFilterMu sync.RWMutex
+	tokenFilter   = DefaultTokenFilter()
+)
+
+// SetTokenFilter 设置代币过滤规则，运行中可重复调用
+func SetTokenFilter(filter TokenFilter) {
+	if filter.MinValue < 0 {
+		filter.MinValue = 0
+	}
+	if filter.MaxTokens <= 0 {
+		filter.MaxTokens = defaultMaxTokens
+	}
+	tokenFilterMu.Lock()
+	tokenFilter = filter
+	tokenFilterMu.Unlock()
+}
+
+// currentTokenFilter 返回当前的过滤规则
+func currentTokenFilter() TokenFilter {
+	tokenFilterMu.RLock()
+	defer tokenFilterMu.RUnlock()
+	return tokenFilter
+}
+
+// SetMinTokenValue 设置报告中显示代币的最小价值（美元），运行中可重复调用
+func SetMinTokenValue(value float64) {
+	if value < 0 {
+		value = 0
+	}
+	tokenFilterMu.Lock()
+	tokenFilter.MinValue = value
+	tokenFilterMu.Unlock()
+}
+
+// currentMinTokenValue 返回当前的最小显示价值
+func currentMinTokenValue() float64 {
+	tokenFilterMu.RLock()
+	defer tokenFilterMu.RUnlock()
+	return tokenFilter.MinValue
+}
+
+// Apply 按规则过滤代币，返回按价值降序排列的结果，并记录各规则隐藏的数量
+func (f TokenFilter) Apply(tokens []*TokenData) []*TokenData {
+	var kept []*TokenData
+	hidden := make(map[string]int)
+	var hiddenValue float64
+
+	for _, token := range tokens {
+		reason := f.hideReason(token)
+		if reason != "" {
+			hidden[reason]++
+			hiddenValue += token.Value
+			continue
+		}
+		kept = append(kept, token)
+	}
+
+	for reason, count := range hidden {
+		priceLog.Info("过滤规则隐藏代币", "rule", reason, "count", count)
+	}
+	if len(hidden) > 0 {
+		priceLog.Info("被过滤代币合计价值", "value", hiddenValue)
+	}
+
+	return FilterTopTokensByValue(kept, f.MaxTokens, f.MinValue)
+}
+
+// hideReason 返回代币被隐藏的原因，不隐藏时返回空字符串
+func (f TokenFilter) hideReason(token *TokenData) string {
+	switch {
+	case f.Blacklist[token.MintAddr]:
+		return "黑名单"
+	case len(f.Whitelist) > 0 && !f.Whitelist[token.MintAddr]:
+		return "不在白名单"
+	case f.HideLowConfidence && token.ConfidenceLevel == "low":
+		return "低可信度"
+	case f.HideSpam && !f.Whitelist[token.MintAddr] && isSpamToken(token):
+		return "垃圾代币"
+	case f.MinLiquidity > 0 && token.Liquidity > 0 && token.Liquidity < f.MinLiquidity:
+		return "低流动性"
+	case token.Value < f.MinValue:
+		return "小额"
+	}
+	return ""
+}
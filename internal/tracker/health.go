@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"net/http"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// healthLimits 健康检查的判定阈值
+type healthLimits struct {
+	maxSnapshotAge time.Duration // 快照循环的最长间隔，<=0 时为5个监控间隔
+	maxFetchAge    time.Duration // 成功获取钱包持仓的最长间隔，<=0 表示不检查
+}
+
+// ProviderStatus 数据源的可达性，熔断器打开时视为不可达
+type ProviderStatus struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Reachable bool   `json:"reachable"`
+}
+
+// HealthStatus /healthz 和 /readyz 的返回数据
+type HealthStatus struct {
+	OK           bool             `json:"ok"`
+	Reason       string           `json:"reason,omitempty"` // 检查失败的原因
+	Paused       bool             `json:"paused,omitempty"` // 监控已通过控制接口暂停，暂停期间不检查快照和获取时长
+	Uptime       string           `json:"uptime"`
+	LastFetch    *time.Time       `json:"last_fetch,omitempty"`    // 最近一次成功获取钱包持仓的时间
+	LastSnapshot *time.Time       `json:"last_snapshot,omitempty"` // 最近一次成功快照的时间
+	LastCycle    *time.Time       `json:"last_cycle,omitempty"`    // 快照循环最近一次结束的时间（无论成功与否）
+	Providers    []ProviderStatus `json:"providers"`
+}
+
+// SetHealthLimits 设置 /healthz 和 /readyz 的判定阈值：maxSnapshotAge 为快照循环的最长间隔（<=0 时为5个监控间隔），
+// maxFetchAge 为成功获取钱包持仓的最长间隔（<=0 表示不检查）
+func (m *TokenMonitor) SetHealthLimits(maxSnapshotAge, maxFetchAge time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health = healthLimits{maxSnapshotAge: maxSnapshotAge, maxFetchAge: maxFetchAge}
+}
+
+// healthLimits 返回当前的判定阈值
+func (m *TokenMonitor) healthLimits() healthLimits {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	limits := m.health
+	if limits.maxSnapshotAge <= 0 {
+		limits.maxSnapshotAge = 5 * m.longestInterval()
+	}
+	return limits
+}
+
+// lastSnapshot 返回最近一次成功快照的时间
+func (m *TokenMonitor) lastSnapshot() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastSnapshotTime
+}
+
+// lastFetchSuccess 返回所有钱包中最近一次成功获取的时间
+func lastFetchSuccess() time.Time {
+	var last time.Time
+	for _, status := range WalletStatuses() {
+		if status.LastSuccess.After(last) {
+			last = status.LastSuccess
+		}
+	}
+	return last
+}
+
+// lastPhaseFinished 返回阶段最近一次结束的时间，没有记录时返回零值
+func lastPhaseFinished(phase string) time.Time {
+	timingMu.Lock()
+	defer timingMu.Unlock()
+	return timings[phase].FinishedAt
+}
+
+// providerStatuses 返回 Helius、Jupiter 和各备用RPC端点的可达性
+func providerStatuses() []ProviderStatus {
+	breakers := breakerStatuses()
+	providers := make([]ProviderStatus, len(breakers))
+	for i, breaker := range breakers {
+		providers[i] = ProviderStatus{
+			Name:      breaker.Name,
+			State:     breaker.State,
+			Reachable: breaker.State != breakerOpen.String(),
+		}
+	}
+	return providers
+}
+
+// healthStatus 收集健康检查的公共信息
+func (m *TokenMonitor) healthStatus() HealthStatus {
+	return HealthStatus{
+		OK:           true,
+		Paused:       m.Paused(),
+		Uptime:       time.Since(processStart).Round(time.Second).String(),
+		LastFetch:    optionalTime(lastFetchSuccess()),
+		LastSnapshot: optionalTime(m.lastSnapshot()),
+		LastCycle:    optionalTime(lastPhaseFinished("snapshot")),
+		Providers:    providerStatuses(),
+	}
+}
+
+// Liveness 存活检查：快照循环超过阈值没有完成一轮时视为卡住；数据源故障不影响存活，重启也无法恢复
+func (m *TokenMonitor) Liveness() HealthStatus {
+	status := m.healthStatus()
+	limits := m.healthLimits()
+
+	// 还没有完成过快照时，从进程启动开始计算
+	since := processStart
+	if status.LastCycle != nil {
+		since = *status.LastCycle
+	}
+	if age := time.Since(since); !status.Paused && age > limits.maxSnapshotAge {
+		status.OK = false
+		status.Reason = i18n.Sprintf("快照循环已 %s 没有完成", age.Round(time.Second))
+	}
+	return status
+}
+
+// Readiness 就绪检查：需要已成功获取钱包持仓并生成快照，且都没有超过阈值
+func (m *TokenMonitor) Readiness() HealthStatus {
+	status := m.healthStatus()
+	limits := m.healthLimits()
+
+	switch {
+	case status.Paused:
+		// 暂停是有意为之，沿用暂停前的数据
+	case status.LastFetch == nil:
+		status.Reason = i18n.T("尚未成功获取钱包持仓")
+	case limits.maxFetchAge > 0 && time.Since(*status.LastFetch) > limits.maxFetchAge:
+		status.Reason = i18n.Sprintf("钱包持仓已 %s 没有成功获取", time.Since(*status.LastFetch).Round(time.Second))
+	case status.LastSnapshot == nil:
+		status.Reason = i18n.T("尚未生成快照")
+	case time.Since(*status.LastSnapshot) > limits.maxSnapshotAge:
+		status.Reason = i18n.Sprintf("快照已 %s 没有更新", time.Since(*status.LastSnapshot).Round(time.Second))
+	}
+	status.OK = status.Reason == ""
+	return status
+}
+
+// optionalTime 零值时间返回nil，使JSON中省略该字段
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// handleHealthz 存活检查，失败时返回503
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, s.monitor.Liveness())
+}
+
+// handleReadyz 就绪检查，失败时返回503
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, s.monitor.Readiness())
+}
+
+// writeHealth 返回检查结果，失败时状态码为503
+func writeHealth(w http.ResponseWriter, r *http.Request, status HealthStatus) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !status.OK {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, status)
+}
@@ -0,0 +1,166 @@
+package tracker
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	heliusWebhookMaxBody  = 5 << 20 // webhook 请求体上限
+	heliusWebhookSeenSize = 1000    // 记录最近处理过的交易签名数量，用于忽略 Helius 的重试
+)
+
+// HeliusWebhookHandler 接收 Helius 增强交易 webhook：为涉及跟踪钱包的交易发出活动报警，
+// 并通过 onActivity 通知调用方刷新这些钱包的余额
+type HeliusWebhookHandler struct {
+	monitor    *TokenMonitor
+	authHeader string // Helius 在 Authorization 头中附带的密钥，为空表示不校验
+	onActivity func(walletAddrs []string)
+	copyTrader *CopyTrader // 为聪明钱钱包的交易生成跟单信号，为nil时不生成
+
+	mu      sync.Mutex
+	wallets map[string]bool
+	seen    map[string]bool
+	order   []string // seen 中签名的插入顺序
+}
+
+// NewHeliusWebhookHandler 创建 Helius webhook 处理器
+func NewHeliusWebhookHandler(monitor *TokenMonitor, authHeader string, onActivity func(walletAddrs []string)) *HeliusWebhookHandler {
+	return &HeliusWebhookHandler{
+		monitor:    monitor,
+		authHeader: authHeader,
+		onActivity: onActivity,
+		wallets:    make(map[string]bool),
+		seen:       make(map[string]bool),
+	}
+}
+
+// SetWallets 设置跟踪的钱包，只处理涉及这些钱包的交易
+func (h *HeliusWebhookHandler) SetWallets(walletAddrs []string) {
+	wallets := make(map[string]bool, len(walletAddrs))
+	for _, addr := range walletAddrs {
+		wallets[addr] = true
+	}
+	h.mu.Lock()
+	h.wallets = wallets
+	h.mu.Unlock()
+}
+
+// SetCopyTrader 设置跟单信号输出，推送的交易同时用于生成信号
+func (h *HeliusWebhookHandler) SetCopyTrader(copyTrader *CopyTrader) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.copyTrader = copyTrader
+}
+
+// ServeHTTP 处理 webhook 请求，请求体为增强交易数组
+func (h *HeliusWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.authHeader != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(h.authHeader)) != 1 {
+		serverLog.Warn("Helius webhook 认证失败", "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, heliusWebhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	var transactions []heliusTransaction
+	if err := json.Unmarshal(body, &transactions); err != nil {
+		serverLog.Warn("解析 Helius webhook 失败", "error", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	active := make(map[string]bool)
+	for _, tx := range transactions {
+		parsed := parseHeliusTransaction(tx)
+		wallets := h.trackedWallets(parsed)
+		for _, wallet := range wallets {
+			active[wallet] = true
+			if h.monitor != nil {
+				h.monitor.emitAlert(activityAlert(wallet, parsed))
+			}
+		}
+		if copyTrader := h.currentCopyTrader(); copyTrader != nil && len(wallets) > 0 {
+			copyTrader.Observe(parsed)
+		}
+	}
+	serverLog.Debug("收到 Helius webhook", "transactions", len(transactions), "wallets", len(active))
+
+	if len(active) > 0 && h.onActivity != nil {
+		wallets := make([]string, 0, len(active))
+		for wallet := range active {
+			wallets = append(wallets, wallet)
+		}
+		sort.Strings(wallets)
+		h.onActivity(wallets)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// currentCopyTrader 返回跟单信号输出
+func (h *HeliusWebhookHandler) currentCopyTrader() *CopyTrader {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.copyTrader
+}
+
+// trackedWallets 返回交易涉及的跟踪钱包；已处理过的签名返回nil
+func (h *HeliusWebhookHandler) trackedWallets(tx *WalletTransaction) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if tx.Signature != "" {
+		if h.seen[tx.Signature] {
+			return nil
+		}
+		h.seen[tx.Signature] = true
+		h.order = append(h.order, tx.Signature)
+		if len(h.order) > heliusWebhookSeenSize {
+			delete(h.seen, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+
+	found := make(map[string]bool)
+	var wallets []string
+	for _, t := range tx.Transfers {
+		for _, addr := range []string{t.From, t.To} {
+			if h.wallets[addr] && !found[addr] {
+				found[addr] = true
+				wallets = append(wallets, addr)
+			}
+		}
+	}
+	return wallets
+}
+
+// activityAlert 生成钱包交易活动报警
+func activityAlert(wallet string, tx *WalletTransaction) Alert {
+	description := tx.Description
+	if description == "" {
+		description = tx.Type
+		if tx.Source != "" {
+			description += " (" + tx.Source + ")"
+		}
+	}
+	return Alert{
+		Type:      AlertTypeActivity,
+		Wallet:    wallet,
+		Signature: tx.Signature,
+		Message:   i18n.Sprintf("钱包活动 - 钱包 %s: %s, 交易 %s", WalletLabel(wallet), description, tx.Signature),
+		Timestamp: tx.Timestamp,
+	}
+}
@@ -0,0 +1,164 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// TokenChange 代币在一段时间内的价格和价值变化
+type TokenChange struct {
+	MintAddr       string
+	Symbol         string
+	StartPrice     float64
+	EndPrice       float64
+	StartValue     float64
+	EndValue       float64
+	PriceChangePct float64
+	Added          bool // 区间开始时未持有
+	Removed        bool // 区间结束时已不再持有
+}
+
+// HistoryReport 根据存储的快照生成的区间汇总
+type HistoryReport struct {
+	Since      time.Time
+	Until      time.Time
+	Snapshots  int
+	StartValue float64
+	EndValue   float64
+	MinValue   float64
+	MaxValue   float64
+	Tokens     []TokenChange
+}
+
+// ChangePct 区间内组合总价值的变化率（%）
+func (r *HistoryReport) ChangePct() float64 {
+	if r.StartValue <= 0 {
+		return 0
+	}
+	return (r.EndValue - r.StartValue) / r.StartValue * 100
+}
+
+// BuildHistoryReport 汇总 [since, until] 内存储的快照，区间内没有快照时返回错误
+func BuildHistoryReport(ctx context.Context, store SnapshotStore, since, until time.Time) (*HistoryReport, error) {
+	records, err := store.PortfolioHistory(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s 至 %s 之间没有快照",
+			since.Format("2006-01-02 15:04:05"), until.Format("2006-01-02 15:04:05"))
+	}
+
+	first, last := records[0], records[len(records)-1]
+	report := &HistoryReport{
+		Since:      first.Timestamp,
+		Until:      last.Timestamp,
+		Snapshots:  len(records),
+		StartValue: first.TotalValue,
+		EndValue:   last.TotalValue,
+		MinValue:   first.TotalValue,
+		MaxValue:   first.TotalValue,
+	}
+	for _, r := range records {
+		if r.TotalValue < report.MinValue {
+			report.MinValue = r.TotalValue
+		}
+		if r.TotalValue > report.MaxValue {
+			report.MaxValue = r.TotalValue
+		}
+	}
+
+	start, err := store.SnapshotNear(ctx, first.Timestamp, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := store.SnapshotNear(ctx, last.Timestamp, 0)
+	if err != nil {
+		return nil, err
+	}
+	if start != nil && end != nil {
+		report.Tokens = tokenChanges(start, end)
+	}
+	return report, nil
+}
+
+// tokenChanges 比较首尾两个快照中各代币的变化，按结束时的价值排序
+func tokenChanges(start, end *PriceSnapshot) []TokenChange {
+	changes := make(map[string]*TokenChange)
+	for mint, token := range start.TokenData {
+		changes[mint] = &TokenChange{
+			MintAddr:   mint,
+			Symbol:     token.Symbol,
+			StartPrice: token.Price,
+			StartValue: token.Value,
+			Removed:    true,
+		}
+	}
+	for mint, token := range end.TokenData {
+		change, ok := changes[mint]
+		if !ok {
+			change = &TokenChange{MintAddr: mint, Added: true}
+			changes[mint] = change
+		}
+		change.Symbol = token.Symbol
+		change.EndPrice = token.Price
+		change.EndValue = token.Value
+		change.Removed = false
+	}
+
+	result := make([]TokenChange, 0, len(changes))
+	for _, change := range changes {
+		if change.StartPrice > 0 && change.EndPrice > 0 {
+			change.PriceChangePct = (change.EndPrice - change.StartPrice) / change.StartPrice * 100
+		}
+		result = append(result, *change)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EndValue != result[j].EndValue {
+			return result[i].EndValue > result[j].EndValue
+		}
+		return result[i].StartValue > result[j].StartValue
+	})
+	return result
+}
+
+// String 生成区间汇总的文本报告
+func (r *HistoryReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf("\n区间报告: %s 至 %s (%d 个快照)\n",
+		r.Since.Format("2006-01-02 15:04:05"), r.Until.Format("2006-01-02 15:04:05"), r.Snapshots))
+	sb.WriteString(i18n.Sprintf("总价值: $%.2f -> $%.2f (%+.2f%%)\n", r.StartValue, r.EndValue, r.ChangePct()))
+	sb.WriteString(i18n.Sprintf("区间最低: $%.2f  区间最高: $%.2f\n", r.MinValue, r.MaxValue))
+
+	if len(r.Tokens) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%-4s %-16s %16s %16s %12s %16s\n",
+		"#", i18n.T("代币"), i18n.T("起始价格"), i18n.T("结束价格"), i18n.T("价格变化"), i18n.T("结束价值")))
+	sb.WriteString(strings.Repeat("-", 86) + "\n")
+	for i, change := range r.Tokens {
+		var pct string
+		switch {
+		case change.Added:
+			pct = i18n.T("新增")
+		case change.Removed:
+			pct = i18n.T("已清仓")
+		default:
+			pct = fmt.Sprintf("%+.2f%%", change.PriceChangePct)
+		}
+		sb.WriteString(fmt.Sprintf("%-4d %-16s %16.4f %16.4f %12s %16.2f\n",
+			i+1,
+			truncateSymbol(change.Symbol),
+			change.StartPrice,
+			change.EndPrice,
+			pct,
+			change.EndValue))
+	}
+	return sb.String()
+}
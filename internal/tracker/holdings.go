@@ -0,0 +1,199 @@
+package tracker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// holdingTracker 记录每个钱包上一次刷新时的持仓数量
+type holdingTracker struct {
+	mu              sync.Mutex
+	holdings        map[string]map[string]*TokenData // 钱包地址 -> mint地址 -> 持仓
+	lastPrices      map[string]float64               // mint地址 -> 最近一次的有效价格
+	reduceThreshold float64                          // 减仓报警阈值（百分比），0表示只报清仓
+	airdropValue    float64                          // 空投报警阈值（美元），负数表示不报警
+}
+
+// walletHoldings 汇总单个钱包内各mint的数量
+func walletHoldings(tokens []*TokenData) map[string]*TokenData {
+	holdings := make(map[string]*TokenData, len(tokens))
+	for _, token := range tokens {
+		if token.Amount <= 0 {
+			continue
+		}
+		if existing, ok := holdings[token.MintAddr]; ok {
+			existing.Amount += token.Amount
+			continue
+		}
+		holdings[token.MintAddr] = &TokenData{
+			MintAddr: token.MintAddr,
+			Amount:   token.Amount,
+			Symbol:   token.Symbol,
+			Name:     token.Name,
+		}
+	}
+	return holdings
+}
+
+// SetPositionReduceThreshold 设置减仓报警阈值：持仓数量在两次刷新间减少超过 threshold(%) 时报警，0表示只在清仓时报警
+func (m *TokenMonitor) SetPositionReduceThreshold(threshold float64) {
+	m.holdings.mu.Lock()
+	defer m.holdings.mu.Unlock()
+	m.holdings.reduceThreshold = threshold
+}
+
+// checkHoldingChanges 比较各钱包与上次刷新的持仓，对新买入、空投、减仓和清仓发出报警
+func (m *TokenMonitor) checkHoldingChanges(tokens map[string][]*TokenData, prices map[string]*TokenPrice) {
+	m.holdings.mu.Lock()
+	defer m.holdings.mu.Unlock()
+
+	if m.holdings.holdings == nil {
+		m.holdings.holdings = make(map[string]map[string]*TokenData)
+		m.holdings.lastPrices = make(map[string]float64)
+	}
+	for mintAddr, p := range prices {
+		if p.Price > 0 {
+			m.holdings.lastPrices[mintAddr] = p.Price
+		}
+	}
+
+	now := time.Now()
+	for wallet, walletTokens := range tokens {
+		current := walletHoldings(walletTokens)
+		previous, seen := m.holdings.holdings[wallet]
+		m.holdings.holdings[wallet] = current
+
+		// 首次看到该钱包时只记录持仓，不报警
+		if !seen {
+			continue
+		}
+
+		// 钱包没有任何花费时新出现的代币是别人转入的，可能是空投或垃圾代币
+		unsolicited := !walletSpent(previous, current)
+		for mintAddr, token := range current {
+			if _, held := previous[mintAddr]; held {
+				continue
+			}
+			switch {
+			case hasSpamName(token):
+				markUnsolicited(mintAddr)
+			case unsolicited:
+				markUnsolicited(mintAddr)
+				if m.holdings.airdropValue >= 0 {
+					m.alertAirdrop(wallet, token, now)
+				} else {
+					m.alertNewToken(wallet, token, now)
+				}
+			default:
+				m.alertNewToken(wallet, token, now)
+			}
+		}
+		for mintAddr, before := range previous {
+			after := 0.0
+			if token, ok := current[mintAddr]; ok {
+				after = token.Amount
+			}
+			m.alertPositionReduced(wallet, before, after, now)
+		}
+	}
+}
+
+// alertNewToken 发出新代币买入报警
+func (m *TokenMonitor) alertNewToken(wallet string, token *TokenData, now time.Time) {
+	price := m.holdings.lastPrices[token.MintAddr]
+	value := token.Amount * price
+
+	// 忽略低于最小显示价值的小额转入
+	if price > 0 && value < currentMinTokenValue() {
+		return
+	}
+
+	valueText := i18n.T("未知")
+	if price > 0 {
+		valueText = fmt.Sprintf("$%.2f", value)
+	}
+	alertMsg := i18n.Sprintf("新代币买入 - 钱包 %s 买入 %s (%s), 数量 %.4f, 价值 %s",
+		WalletLabel(wallet),
+		displaySymbol(token),
+		token.MintAddr,
+		token.Amount,
+		valueText)
+
+	m.emitAlert(Alert{
+		Type:      AlertTypeNewToken,
+		Wallet:    wallet,
+		MintAddr:  token.MintAddr,
+		Chain:     token.Chain,
+		Symbol:    token.Symbol,
+		NewValue:  value,
+		Message:   alertMsg,
+		Timestamp: now,
+	})
+}
+
+// alertPositionReduced 持仓清空或减少超过阈值时发出报警
+func (m *TokenMonitor) alertPositionReduced(wallet string, before *TokenData, after float64, now time.Time) {
+	if after >= before.Amount {
+		return
+	}
+
+	closed := after <= 0
+	reducedPct := (before.Amount - after) / before.Amount * 100
+	if !closed && (m.holdings.reduceThreshold <= 0 || reducedPct < m.holdings.reduceThreshold) {
+		return
+	}
+
+	price := m.holdings.lastPrices[before.MintAddr]
+	oldValue := before.Amount * price
+	newValue := after * price
+
+	// 忽略低于最小显示价值的小额持仓
+	if price > 0 && oldValue < currentMinTokenValue() {
+		return
+	}
+
+	action := i18n.T("减仓")
+	if closed {
+		action = i18n.T("清仓")
+	}
+	deltaText := i18n.T("未知")
+	if price > 0 {
+		deltaText = fmt.Sprintf("$%.2f", newValue-oldValue)
+	}
+	alertMsg := i18n.Sprintf("持仓%s - 钱包 %s 的 %s (%s) 数量从 %.4f 减少到 %.4f (-%.2f%%), 估计价值变化 %s",
+		action,
+		WalletLabel(wallet),
+		displaySymbol(before),
+		before.MintAddr,
+		before.Amount,
+		after,
+		reducedPct,
+		deltaText)
+
+	m.emitAlert(Alert{
+		Type:      AlertTypePositionReduced,
+		Wallet:    wallet,
+		MintAddr:  before.MintAddr,
+		Chain:     before.Chain,
+		Symbol:    before.Symbol,
+		ChangePct: -reducedPct,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Message:   alertMsg,
+		Timestamp: now,
+	})
+}
+
+// displaySymbol 返回用于报警文本的代币名称
+func displaySymbol(token *TokenData) string {
+	if !isUnknownSymbol(token.Symbol) {
+		return token.Symbol
+	}
+	if token.Name != "" {
+		return token.Name
+	}
+	return "Unknown"
+}
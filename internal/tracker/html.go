@@ -0,0 +1,358 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// htmlReportHistory HTML报告中价值走势图覆盖的时间范围
+const htmlReportHistory = 24 * time.Hour
+
+// htmlPieSlices 饼图中单独显示的代币数量，其余合并为"其他"
+const htmlPieSlices = 8
+
+// 图表尺寸
+const (
+	pieRadius   = 100.0
+	chartWidth  = 640.0
+	chartHeight = 200.0
+)
+
+// pieColors 饼图配色
+var pieColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f",
+}
+
+// ValuePoint 组合价值时间序列中的一个点
+type ValuePoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// htmlRow HTML报告表格中的一行
+type htmlRow struct {
+	Index      int
+	Symbol     string
+	Mint       string
+	Risk       string // 高风险代币的风险项，为空表示未标记
+	Stale      string // 价格过期时长，价格为最新时为空
+	Amount     string
+	Price      string
+	Value      string
+	Liquidity  string
+	Percentage string
+	PnL        string
+}
+
+// htmlSlice 饼图的一个扇区
+type htmlSlice struct {
+	Label      string
+	Path       string
+	Color      string
+	Percentage string
+}
+
+// htmlReportData HTML模板数据
+type htmlReportData struct {
+	GeneratedAt string
+	TotalValue  string
+	Degraded    []string // 数据不完整的钱包说明
+	Rows        []htmlRow
+	Slices      []htmlSlice
+	FullCircle  bool // 只有一个扇区时直接画圆
+	ChartPoints string
+	ChartMin    string
+	ChartMax    string
+	ChartStart  string
+	ChartEnd    string
+	PieRadius   float64
+	ChartWidth  float64
+	ChartHeight float64
+}
+
+// GenerateHTMLReport 生成独立的HTML报告页面：持仓表格、占比饼图和价值走势图，不依赖外部资源
+func GenerateHTMLReport(tokens []*TokenData, history []ValuePoint) (string, error) {
+	sorted := make([]*TokenData, len(tokens))
+	copy(sorted, tokens)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Value > sorted[j].Value
+	})
+
+	var total float64
+	for _, token := range sorted {
+		total += token.Value
+	}
+
+	data := htmlReportData{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		TotalValue:  fmt.Sprintf("$%.2f", total),
+		PieRadius:   pieRadius,
+		ChartWidth:  chartWidth,
+		ChartHeight: chartHeight,
+	}
+	for _, status := range degradedWallets() {
+		data.Degraded = append(data.Degraded, describeWalletStatus(status))
+	}
+	for i, token := range sorted {
+		var pct float64
+		if total > 0 {
+			pct = token.Value / total * 100
+		}
+		data.Rows = append(data.Rows, htmlRow{
+			Index:      i + 1,
+			Symbol:     displaySymbol(token),
+			Mint:       token.MintAddr,
+			Risk:       riskLabel(token),
+			Stale:      formatStaleAge(token),
+			Amount:     fmt.Sprintf("%.4f", token.Amount),
+			Price:      fmt.Sprintf("$%.6f", token.Price),
+			Value:      fmt.Sprintf("$%.2f", token.Value),
+			Liquidity:  formatUSDCompact(token.Liquidity),
+			Percentage: fmt.Sprintf("%.2f%%", pct),
+			PnL:        formatPnL(token),
+		})
+	}
+
+	data.Slices, data.FullCircle = pieSlices(sorted, total)
+	data.ChartPoints, data.ChartMin, data.ChartMax = chartPoints(history)
+	if len(history) > 0 {
+		data.ChartStart = history[0].Timestamp.Format("01-02 15:04")
+		data.ChartEnd = history[len(history)-1].Timestamp.Format("01-02 15:04")
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染HTML报告失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// pieSlices 计算饼图扇区的SVG路径，超出数量的代币合并为"其他"
+func pieSlices(tokens []*TokenData, total float64) ([]htmlSlice, bool) {
+	if total <= 0 {
+		return nil, false
+	}
+
+	type part struct {
+		label string
+		value float64
+	}
+	var parts []part
+	var other float64
+	for i, token := range tokens {
+		if token.Value <= 0 {
+			continue
+		}
+		if i < htmlPieSlices {
+			parts = append(parts, part{displaySymbol(token), token.Value})
+		} else {
+			other += token.Value
+		}
+	}
+	if other > 0 {
+		parts = append(parts, part{i18n.T("其他"), other})
+	}
+
+	slices := make([]htmlSlice, 0, len(parts))
+	angle := -math.Pi / 2 // 从12点方向开始
+	for i, p := range parts {
+		sweep := p.value / total * 2 * math.Pi
+		x1, y1 := pieRadius*math.Cos(angle), pieRadius*math.Sin(angle)
+		x2, y2 := pieRadius*math.Cos(angle+sweep), pieRadius*math.Sin(angle+sweep)
+		largeArc := 0
+		if sweep > math.Pi {
+			largeArc = 1
+		}
+		slices = append(slices, htmlSlice{
+			Label:      p.label,
+			Path:       fmt.Sprintf("M 0 0 L %.2f %.2f A %.0f %.0f 0 %d 1 %.2f %.2f Z", x1, y1, pieRadius, pieRadius, largeArc, x2, y2),
+			Color:      pieColors[i%len(pieColors)],
+			Percentage: fmt.Sprintf("%.1f%%", p.value/total*100),
+		})
+		angle += sweep
+	}
+	return slices, len(slices) == 1
+}
+
+// chartPoints 将价值序列缩放为SVG折线坐标，返回坐标串和纵轴的最小/最大值标签
+func chartPoints(history []ValuePoint) (string, string, string) {
+	if len(history) < 2 {
+		return "", "", ""
+	}
+
+	minValue, maxValue := history[0].Value, history[0].Value
+	for _, p := range history {
+		minValue = math.Min(minValue, p.Value)
+		maxValue = math.Max(maxValue, p.Value)
+	}
+	valueRange := maxValue - minValue
+	if valueRange == 0 {
+		valueRange = 1
+	}
+	start := history[0].Timestamp
+	span := history[len(history)-1].Timestamp.Sub(start).Seconds()
+	if span <= 0 {
+		span = 1
+	}
+
+	points := make([]string, 0, len(history))
+	for _, p := range history {
+		x := p.Timestamp.Sub(start).Seconds() / span * chartWidth
+		y := chartHeight - (p.Value-minValue)/valueRange*chartHeight
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(points, " "), fmt.Sprintf("$%.2f", minValue), fmt.Sprintf("$%.2f", maxValue)
+}
+
+// valueHistory 返回 since 之后的组合价值序列：优先使用持久化存储，否则使用内存中的快照
+func (m *TokenMonitor) valueHistory(since time.Time) []ValuePoint {
+	var points []ValuePoint
+	if m.store != nil {
+		records, err := m.store.PortfolioHistory(m.ctx, since, time.Now())
+		if err == nil {
+			for _, r := range records {
+				points = append(points, ValuePoint{Timestamp: r.Timestamp, Value: r.TotalValue})
+			}
+			return points
+		}
+		monitorLog.Error("从存储查询组合历史失败", "error", err)
+	}
+
+	for _, snapshot := range m.snapshotsSince(since) {
+		points = append(points, ValuePoint{Timestamp: snapshot.Timestamp, Value: snapshot.Value})
+	}
+	return points
+}
+
+// HTMLReport 根据当前持仓和最近24小时的快照生成HTML报告
+func (m *TokenMonitor) HTMLReport(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return GenerateHTMLReport(m.Tokens(), m.valueHistory(time.Now().Add(-htmlReportHistory)))
+}
+
+// HTMLReportRange 生成HTML报告，价值走势图使用 [since, until] 内按 interval 聚合的收盘价
+func (m *TokenMonitor) HTMLReportRange(ctx context.Context, interval time.Duration, since, until time.Time) (string, error) {
+	candles, err := m.ValueCandles(ctx, interval, since, until)
+	if err != nil {
+		return "", err
+	}
+	history := make([]ValuePoint, 0, len(candles))
+	for _, c := range candles {
+		history = append(history, ValuePoint{Timestamp: c.Time, Value: c.Close})
+	}
+	return GenerateHTMLReport(m.Tokens(), history)
+}
+
+// WriteHTMLReport 生成HTML报告并写入 path，先写临时文件再替换，避免浏览器读到不完整的页面
+func (m *TokenMonitor) WriteHTMLReport(path string) error {
+	page, err := m.HTMLReport(m.ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(page), 0644); err != nil {
+		return fmt.Errorf("写入HTML报告失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入HTML报告失败: %v", err)
+	}
+	return nil
+}
+
+// htmlLang HTML报告的 lang 属性
+func htmlLang() string {
+	if i18n.Language() == i18n.English {
+		return "en"
+	}
+	return "zh-CN"
+}
+
+// htmlReportTemplate HTML报告模板，样式和图表均内联
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"t": i18n.T, "lang": htmlLang}).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{t "钱包资产报告"}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; background: #fafafa; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+.meta { color: #666; margin-bottom: 20px; }
+.charts { display: flex; flex-wrap: wrap; gap: 24px; margin-bottom: 24px; }
+.card { background: #fff; border: 1px solid #e3e3e3; border-radius: 6px; padding: 16px; }
+.legend { list-style: none; padding: 0; margin: 0 0 0 16px; font-size: 13px; }
+.legend li { margin: 4px 0; }
+.swatch { display: inline-block; width: 10px; height: 10px; margin-right: 6px; border-radius: 2px; }
+table { border-collapse: collapse; width: 100%; background: #fff; font-size: 14px; }
+th, td { padding: 6px 10px; border-bottom: 1px solid #eee; text-align: right; }
+th:nth-child(2), td:nth-child(2) { text-align: left; }
+.mint { font-family: monospace; font-size: 12px; color: #888; }
+.empty { color: #999; }
+.risk { font-size: 12px; color: #c0392b; }
+.stale { font-size: 12px; color: #b9770e; }
+.degraded { background: #fdf2e9; border: 1px solid #f0b27a; border-radius: 6px; padding: 10px 16px; margin-bottom: 20px; color: #a04000; font-size: 14px; }
+.degraded ul { margin: 6px 0 0; padding-left: 20px; }
+</style>
+</head>
+<body>
+<h1>{{t "钱包资产报告"}}</h1>
+<div class="meta">{{t "总值"}} <strong>{{.TotalValue}}</strong> · {{t "生成时间"}} {{.GeneratedAt}}</div>
+{{if .Degraded}}
+<div class="degraded">⚠ {{printf (t "数据不完整: %d 个钱包获取失败，总值可能偏低") (len .Degraded)}}
+  <ul>{{range .Degraded}}<li>{{.}}</li>{{end}}</ul>
+</div>
+{{end}}
+
+<div class="charts">
+  <div class="card" style="display:flex;align-items:center">
+    {{if .Slices}}
+    <svg width="220" height="220" viewBox="-105 -105 210 210">
+      {{if .FullCircle}}{{with index .Slices 0}}<circle cx="0" cy="0" r="{{$.PieRadius}}" fill="{{.Color}}"><title>{{.Label}} {{.Percentage}}</title></circle>{{end}}
+      {{else}}{{range .Slices}}<path d="{{.Path}}" fill="{{.Color}}" stroke="#fff" stroke-width="1"><title>{{.Label}} {{.Percentage}}</title></path>
+      {{end}}{{end}}
+    </svg>
+    <ul class="legend">
+      {{range .Slices}}<li><span class="swatch" style="background:{{.Color}}"></span>{{.Label}} {{.Percentage}}</li>
+      {{end}}
+    </ul>
+    {{else}}<span class="empty">{{t "暂无持仓"}}</span>{{end}}
+  </div>
+
+  <div class="card">
+    <div>{{t "最近24小时总价值"}}</div>
+    {{if .ChartPoints}}
+    <svg width="{{.ChartWidth}}" height="{{.ChartHeight}}" viewBox="0 -10 {{.ChartWidth}} {{.ChartHeight}}" style="overflow:visible">
+      <polyline points="{{.ChartPoints}}" fill="none" stroke="#4e79a7" stroke-width="2"/>
+      <text x="0" y="0" font-size="11" fill="#666">{{.ChartMax}}</text>
+      <text x="0" y="{{.ChartHeight}}" font-size="11" fill="#666">{{.ChartMin}}</text>
+    </svg>
+    <div class="meta">{{.ChartStart}} — {{.ChartEnd}}</div>
+    {{else}}<div class="empty">{{t "快照数据不足"}}</div>{{end}}
+  </div>
+</div>
+
+<table>
+  <tr><th>#</th><th>{{t "代币"}}</th><th>{{t "数量"}}</th><th>{{t "价格"}}</th><th>{{t "价值"}}</th><th>{{t "流动性"}}</th><th>{{t "占比"}}</th><th>{{t "盈亏"}}</th></tr>
+  {{range .Rows}}<tr><td>{{.Index}}</td><td>{{.Symbol}}<br><span class="mint">{{.Mint}}</span>{{if .Risk}}<br><span class="risk">⚠ {{.Risk}}</span>{{end}}{{if .Stale}}<br><span class="stale">{{.Stale}}</span>{{end}}</td><td>{{.Amount}}</td><td>{{.Price}}</td><td>{{.Value}}</td><td>{{.Liquidity}}</td><td>{{.Percentage}}</td><td>{{.PnL}}</td></tr>
+  {{end}}
+</table>
+</body>
+</html>
+`))
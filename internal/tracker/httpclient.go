@@ -0,0 +1,119 @@
+package tracker
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 共享HTTP客户端的默认设置
+const (
+	defaultAPITimeout          = 30 * time.Second
+	defaultNotifyTimeout       = 10 * time.Second
+	defaultMaxIdleConnsPerHost = 10
+)
+
+// HTTPConfig 共享HTTP客户端设置
+type HTTPConfig struct {
+	Timeout             time.Duration      // 数据API请求的超时时间
+	NotifyTimeout       time.Duration      // 通知渠道请求的超时时间
+	MaxIdleConnsPerHost int                // 每个主机保留的空闲连接数
+	RateLimits          map[string]float64 // 主机名到每秒最大请求数的映射
+	RateLimitBursts     map[string]int     // 主机名到允许突发的请求数的映射，未列出的主机为1
+}
+
+// limitedTransport 所有API和通知请求共用的传输层：复用连接并按主机限流
+type limitedTransport struct {
+	mu       sync.Mutex
+	cfg      HTTPConfig
+	base     *http.Transport
+	limiters map[string]*rateLimiter
+}
+
+var sharedTransport = newLimitedTransport()
+
+func newLimitedTransport() *limitedTransport {
+	t := &limitedTransport{limiters: make(map[string]*rateLimiter)}
+	t.configure(HTTPConfig{})
+	return t
+}
+
+// SetHTTPConfig 设置共享HTTP客户端；限流立即生效，超时时间对之后创建的服务生效
+func SetHTTPConfig(cfg HTTPConfig) {
+	sharedTransport.configure(cfg)
+}
+
+// configure 应用设置，保留限流频率和突发数未变的主机的限流状态
+func (t *limitedTransport) configure(cfg HTTPConfig) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultAPITimeout
+	}
+	if cfg.NotifyTimeout <= 0 {
+		cfg.NotifyTimeout = defaultNotifyTimeout
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.base == nil || t.cfg.MaxIdleConnsPerHost != cfg.MaxIdleConnsPerHost {
+		base := http.DefaultTransport.(*http.Transport).Clone()
+		base.MaxIdleConns = 0
+		base.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if t.base != nil {
+			t.base.CloseIdleConnections()
+		}
+		t.base = base
+	}
+
+	limiters := make(map[string]*rateLimiter, len(cfg.RateLimits))
+	for host, rps := range cfg.RateLimits {
+		if rps <= 0 {
+			continue
+		}
+		burst := cfg.RateLimitBursts[host]
+		if limiter, ok := t.limiters[host]; ok && t.cfg.RateLimits[host] == rps && t.cfg.RateLimitBursts[host] == burst {
+			limiters[host] = limiter
+			continue
+		}
+		limiters[host] = newRateLimiter(rps, burst)
+	}
+	t.limiters = limiters
+	t.cfg = cfg
+}
+
+// RoundTrip 等待目标主机的限流许可后发送请求
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	limiter := t.limiters[req.URL.Hostname()]
+	base := t.base
+	t.mu.Unlock()
+
+	if limiter != nil {
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return base.RoundTrip(req)
+}
+
+// timeouts 返回当前的API和通知超时时间
+func (t *limitedTransport) timeouts() (time.Duration, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg.Timeout, t.cfg.NotifyTimeout
+}
+
+// apiHTTPClient 返回使用共享传输层的数据API客户端
+func apiHTTPClient() *http.Client {
+	timeout, _ := sharedTransport.timeouts()
+	return &http.Client{Transport: sharedTransport, Timeout: timeout}
+}
+
+// notifyHTTPClient 返回使用共享传输层的通知渠道客户端
+func notifyHTTPClient() *http.Client {
+	_, timeout := sharedTransport.timeouts()
+	return &http.Client{Transport: sharedTransport, Timeout: timeout}
+}
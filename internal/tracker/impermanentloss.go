@@ -0,0 +1,259 @@
+package tracker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	lpEntryTxLimit   = 1000      // 查找LP存入交易时最多扫描的交易数量
+	lpEntryMissRetry = time.Hour // 没有找到存入交易的LP仓位在该时间之后才重新查询
+)
+
+// lpEntry LP仓位的存入记录
+type lpEntry struct {
+	received  float64            // 存入交易中收到的LP代币数量（Orca 仓位NFT为1）
+	deposited map[string]float64 // mint地址 -> 存入的数量
+}
+
+var (
+	lpEntriesMu   sync.Mutex
+	lpEntries     = make(map[string]*lpEntry)  // 钱包|LP mint -> 存入记录，存入交易不会变化，只查询一次
+	lpEntryMisses = make(map[string]time.Time) // 钱包|LP mint -> 没有找到存入交易的查询时间
+)
+
+// attachLPEntries 为LP仓位查找存入交易，按当前持有的份额折算建仓时存入的底层资产
+func (s *HeliusService) attachLPEntries(ctx context.Context, walletAddr string, positions []*DeFiPosition) {
+	now := time.Now()
+	var pending []*DeFiPosition
+	lpEntriesMu.Lock()
+	for _, p := range positions {
+		if p.Kind != DeFiKindLP {
+			continue
+		}
+		key := walletAddr + "|" + p.Account
+		if _, ok := lpEntries[key]; ok {
+			continue
+		}
+		if missedAt, ok := lpEntryMisses[key]; ok && now.Sub(missedAt) < lpEntryMissRetry {
+			continue
+		}
+		pending = append(pending, p)
+	}
+	lpEntriesMu.Unlock()
+
+	if len(pending) > 0 {
+		// 获取中断时使用已获取的交易，但没有找到的仓位不记为未找到
+		txs, err := s.FetchWalletTransactions(ctx, walletAddr, TransactionQuery{Limit: lpEntryTxLimit})
+		if err != nil {
+			walletLog.Warn("获取LP存入交易失败", "wallet", walletAddr, "error", err)
+		}
+		found := findLPEntries(walletAddr, txs, pending)
+
+		lpEntriesMu.Lock()
+		for _, p := range pending {
+			key := walletAddr + "|" + p.Account
+			if entry, ok := found[p.Account]; ok {
+				lpEntries[key] = entry
+			} else if err == nil {
+				lpEntryMisses[key] = now
+			}
+		}
+		lpEntriesMu.Unlock()
+	}
+
+	lpEntriesMu.Lock()
+	defer lpEntriesMu.Unlock()
+	for _, p := range positions {
+		entry := lpEntries[walletAddr+"|"+p.Account]
+		if p.Kind != DeFiKindLP || entry == nil || entry.received <= 0 {
+			continue
+		}
+		// 之后部分取出的仓位按剩余份额折算
+		ratio := p.Shares / entry.received
+		for _, asset := range p.Deposits {
+			p.Entry = append(p.Entry, &DeFiAsset{
+				MintAddr: asset.MintAddr,
+				Symbol:   asset.Symbol,
+				Decimals: asset.Decimals,
+				Amount:   entry.deposited[asset.MintAddr] * ratio,
+			})
+		}
+	}
+}
+
+// findLPEntries 在钱包交易中查找收到LP代币或仓位NFT的交易，累计其中转出的底层资产
+func findLPEntries(walletAddr string, txs []*WalletTransaction, positions []*DeFiPosition) map[string]*lpEntry {
+	entries := make(map[string]*lpEntry)
+	for _, tx := range txs {
+		for _, p := range positions {
+			var received float64
+			for _, t := range tx.Transfers {
+				if t.MintAddr == p.Account && t.To == walletAddr {
+					received += t.Amount
+				}
+			}
+			if received <= 0 {
+				continue
+			}
+
+			entry, ok := entries[p.Account]
+			if !ok {
+				entry = &lpEntry{deposited: make(map[string]float64)}
+				entries[p.Account] = entry
+			}
+			entry.received += received
+			for _, asset := range p.Deposits {
+				if amount := netDeposit(tx, walletAddr, asset.MintAddr); amount > 0 {
+					entry.deposited[asset.MintAddr] += amount
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// netDeposit 返回交易中钱包转出的某种代币的净数量；SOL 有 wSOL 转账时只按 wSOL 计算，
+// 避免与包装时的原生SOL转账重复计算
+func netDeposit(tx *WalletTransaction, walletAddr, mint string) float64 {
+	var direct, wrapped float64
+	for _, t := range tx.Transfers {
+		var sign float64
+		switch {
+		case t.From == walletAddr && t.To != walletAddr:
+			sign = 1
+		case t.To == walletAddr && t.From != walletAddr:
+			sign = -1
+		default:
+			continue
+		}
+		switch {
+		case mint == nativeSOLMint && t.MintAddr == wrappedSOLMint:
+			wrapped += sign * t.Amount
+		case t.MintAddr == mint:
+			direct += sign * t.Amount
+		}
+	}
+	if wrapped != 0 {
+		return wrapped
+	}
+	return direct
+}
+
+// lpValues 按当前价格返回LP仓位的价值和持有存入资产不动的价值，缺少存入记录或价格时返回 false
+func (p *DeFiPosition) lpValues(prices map[string]float64) (current, hold float64, ok bool) {
+	if p.Kind != DeFiKindLP || len(p.Entry) == 0 {
+		return 0, 0, false
+	}
+	for _, asset := range p.Deposits {
+		price := prices[asset.MintAddr]
+		if price <= 0 {
+			return 0, 0, false
+		}
+		current += asset.Amount * price
+	}
+	for _, asset := range p.Entry {
+		price := prices[asset.MintAddr]
+		if price <= 0 {
+			return 0, 0, false
+		}
+		hold += asset.Amount * price
+	}
+	return current, hold, hold > 0
+}
+
+// ImpermanentLoss 返回LP仓位相对持有存入资产不动的盈亏比例（%，负数为损失，已包含累积在池子中的手续费）
+func (p *DeFiPosition) ImpermanentLoss(prices map[string]float64) (float64, bool) {
+	current, hold, ok := p.lpValues(prices)
+	if !ok {
+		return 0, false
+	}
+	return (current/hold - 1) * 100, true
+}
+
+// lpPairName 返回LP仓位的交易对名称，如 SOL-USDC
+func lpPairName(p *DeFiPosition, symbols map[string]string) string {
+	names := make([]string, 0, len(p.Deposits))
+	for _, asset := range p.Deposits {
+		name := symbols[asset.MintAddr]
+		if isUnknownSymbol(name) {
+			name = asset.Symbol
+		}
+		if name == "" {
+			name = truncateSymbol(asset.MintAddr)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, "-")
+}
+
+// ilWatch 记录无常损失已超过阈值的LP仓位
+type ilWatch struct {
+	mu      sync.Mutex
+	pct     float64         // 相对持有不动的损失超过该比例（%）时报警，0表示关闭
+	alerted map[string]bool // 钱包|LP mint -> 已报警，损失回到阈值以内后重新生效
+}
+
+// SetImpermanentLossAlert 设置无常损失报警：LP仓位相对持有存入资产不动的损失超过 pct(%) 时报警，0表示关闭
+func (m *TokenMonitor) SetImpermanentLossAlert(pct float64) {
+	m.impermanentLoss.mu.Lock()
+	defer m.impermanentLoss.mu.Unlock()
+	m.impermanentLoss.pct = pct
+}
+
+// checkImpermanentLoss 按本次价格计算各LP仓位的无常损失，超过阈值时报警一次
+func (m *TokenMonitor) checkImpermanentLoss(tokens []*TokenData) {
+	m.impermanentLoss.mu.Lock()
+	defer m.impermanentLoss.mu.Unlock()
+
+	if m.impermanentLoss.pct <= 0 {
+		return
+	}
+	prices := make(map[string]float64, len(tokens))
+	symbols := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		prices[token.MintAddr] = token.Price
+		symbols[token.MintAddr] = token.Symbol
+	}
+	previous := m.impermanentLoss.alerted
+	m.impermanentLoss.alerted = make(map[string]bool)
+
+	now := time.Now()
+	for _, p := range currentDeFiPositions() {
+		key := p.Wallet + "|" + p.Account
+		current, hold, ok := p.lpValues(prices)
+		if !ok {
+			// 本次缺少价格时保持原来的报警状态
+			if previous[key] {
+				m.impermanentLoss.alerted[key] = true
+			}
+			continue
+		}
+		loss := (current/hold - 1) * 100
+		if -loss < m.impermanentLoss.pct {
+			continue
+		}
+		m.impermanentLoss.alerted[key] = true
+		if previous[key] {
+			continue
+		}
+
+		pair := lpPairName(p, symbols)
+		m.emitAlert(Alert{
+			Type:      AlertTypeImpermanentLoss,
+			Wallet:    p.Wallet,
+			MintAddr:  p.Account,
+			Symbol:    pair,
+			ChangePct: loss,
+			OldValue:  hold,
+			NewValue:  current,
+			Message: i18n.Sprintf("无常损失报警 - 钱包 %s 的 %s %s LP 相对持有不动 %.2f%%（阈值 %.2f%%），当前价值 $%.2f，持有不动价值 $%.2f",
+				WalletLabel(p.Wallet), p.Protocol, pair, loss, m.impermanentLoss.pct, current, hold),
+			Timestamp: now,
+		})
+	}
+}
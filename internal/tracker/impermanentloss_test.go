@@ -0,0 +1,161 @@
+package tracker
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	ilSOL  = "So11111111111111111111111111111111111111112"
+	ilUSDC = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	ilBONK = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+)
+
+// testLPPosition 返回建仓时存入 1 SOL 和 100 USDC、当前池中为 sol/usdc 的LP仓位
+func testLPPosition(sol, usdc float64) *DeFiPosition {
+	return &DeFiPosition{
+		Wallet:   "WalletIL",
+		Protocol: "Orca",
+		Kind:     DeFiKindLP,
+		Account:  "LPMintIL",
+		Deposits: []*DeFiAsset{{MintAddr: ilSOL, Symbol: "SOL", Amount: sol}, {MintAddr: ilUSDC, Symbol: "USDC", Amount: usdc}},
+		Shares:   1,
+		Entry:    []*DeFiAsset{{MintAddr: ilSOL, Amount: 1}, {MintAddr: ilUSDC, Amount: 100}},
+	}
+}
+
+func TestImpermanentLoss(t *testing.T) {
+	tests := []struct {
+		name     string
+		position *DeFiPosition
+		prices   map[string]float64
+		want     float64
+		wantOK   bool
+	}{
+		{
+			name:     "价格不变",
+			position: testLPPosition(1, 100),
+			prices:   map[string]float64{ilSOL: 100, ilUSDC: 1},
+			want:     0,
+			wantOK:   true,
+		},
+		{
+			// SOL 涨到 400：池中为 2 SOL + 200 USDC = $1000，持有不动为 $500，
+			// 恒定乘积池的理论无常损失为 2*sqrt(4)/(1+4)-1 = -20%
+			name:     "价格上涨四倍",
+			position: testLPPosition(0.5, 200),
+			prices:   map[string]float64{ilSOL: 400, ilUSDC: 1},
+			want:     -20,
+			wantOK:   true,
+		},
+		{
+			name:     "手续费使仓位超过持有不动",
+			position: testLPPosition(1.1, 110),
+			prices:   map[string]float64{ilSOL: 100, ilUSDC: 1},
+			want:     10,
+			wantOK:   true,
+		},
+		{
+			name:     "池中资产缺少价格",
+			position: testLPPosition(1, 100),
+			prices:   map[string]float64{ilSOL: 100},
+		},
+		{
+			name: "存入记录中的资产缺少价格",
+			position: func() *DeFiPosition {
+				p := testLPPosition(1, 100)
+				p.Entry = append(p.Entry, &DeFiAsset{MintAddr: ilBONK, Amount: 1000})
+				return p
+			}(),
+			prices: map[string]float64{ilSOL: 100, ilUSDC: 1},
+		},
+		{
+			name: "没有存入记录",
+			position: func() *DeFiPosition {
+				p := testLPPosition(1, 100)
+				p.Entry = nil
+				return p
+			}(),
+			prices: map[string]float64{ilSOL: 100, ilUSDC: 1},
+		},
+		{
+			name: "借贷仓位",
+			position: func() *DeFiPosition {
+				p := testLPPosition(1, 100)
+				p.Kind = DeFiKindLending
+				return p
+			}(),
+			prices: map[string]float64{ilSOL: 100, ilUSDC: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.position.ImpermanentLoss(tt.prices)
+			if ok != tt.wantOK {
+				t.Fatalf("ImpermanentLoss ok = %v, 期望 %v", ok, tt.wantOK)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ImpermanentLoss = %.4f%%, 期望 %.4f%%", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckImpermanentLoss(t *testing.T) {
+	m := newTestMonitor(t, time.Minute)
+	m.SetAlertCooldown(-1)
+	m.SetImpermanentLossAlert(10)
+
+	position := testLPPosition(0.5, 200)
+	storeDeFiPositions(position.Wallet, []*DeFiPosition{position})
+	t.Cleanup(func() { storeDeFiPositions(position.Wallet, nil) })
+
+	tokens := func(solPrice float64) []*TokenData {
+		return []*TokenData{
+			{MintAddr: ilSOL, Symbol: "SOL", Price: solPrice},
+			{MintAddr: ilUSDC, Symbol: "USDC", Price: 1},
+		}
+	}
+
+	// 池中为 0.5 SOL + 200 USDC，SOL 价格为 400 时损失 20%，为 250 时损失约 3.7%
+	steps := []struct {
+		name       string
+		tokens     []*TokenData
+		wantAlerts int // 到这一步为止的报警总数
+	}{
+		{name: "损失超过阈值时报警", tokens: tokens(400), wantAlerts: 1},
+		{name: "持续超过阈值不重复报警", tokens: tokens(400), wantAlerts: 1},
+		{name: "缺少价格时保持报警状态", tokens: tokens(400)[1:], wantAlerts: 1},
+		{name: "缺少价格后仍超过阈值不重复报警", tokens: tokens(400), wantAlerts: 1},
+		{name: "损失回到阈值以内", tokens: tokens(250), wantAlerts: 1},
+		{name: "重新超过阈值时再次报警", tokens: tokens(400), wantAlerts: 2},
+	}
+
+	for _, step := range steps {
+		m.checkImpermanentLoss(step.tokens)
+		var alerts []string
+		for _, line := range alertLogLines(t) {
+			if strings.Contains(line, "SOL-USDC") {
+				alerts = append(alerts, line)
+			}
+		}
+		if len(alerts) != step.wantAlerts {
+			t.Fatalf("%s: 报警数 = %d, 期望 %d: %q", step.name, len(alerts), step.wantAlerts, alerts)
+		}
+	}
+}
+
+func TestCheckImpermanentLossDisabled(t *testing.T) {
+	m := newTestMonitor(t, time.Minute)
+	position := testLPPosition(0.5, 200)
+	storeDeFiPositions(position.Wallet, []*DeFiPosition{position})
+	t.Cleanup(func() { storeDeFiPositions(position.Wallet, nil) })
+
+	m.checkImpermanentLoss([]*TokenData{{MintAddr: ilSOL, Price: 400}, {MintAddr: ilUSDC, Price: 1}})
+	if lines := alertLogLines(t); len(lines) > 0 {
+		t.Errorf("未设置无常损失报警时不应报警, 得到 %q", lines)
+	}
+}
@@ -0,0 +1,285 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	liquidityDepthPct   = 2.0         // 市场深度按价格变动2%估算
+	defaultLiquidityTTL = time.Minute // 流动性缓存的默认有效期
+)
+
+// LiquidityInfo 代币在各交易池中的流动性和市场深度
+type LiquidityInfo struct {
+	Liquidity float64    // 所有交易对的流动性合计（美元）
+	Pools     int        // 交易对数量
+	Depth     TokenDepth // 价格变动2%所需的买入/卖出金额（美元），按恒定乘积池估算
+	UpdatedAt time.Time
+}
+
+// LiquidityService 从 DexScreener 获取代币的池子流动性并估算市场深度，结果按TTL缓存
+type LiquidityService struct {
+	client  *http.Client
+	baseURL string
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*LiquidityInfo
+}
+
+// NewLiquidityService 使用默认端点创建流动性服务，ttl<=0 时使用默认有效期
+func NewLiquidityService(ttl time.Duration) *LiquidityService {
+	return NewLiquidityServiceWithConfig(dexScreenerAPIEndpoint, ttl, nil)
+}
+
+// NewLiquidityServiceWithConfig 使用指定的端点、缓存有效期和HTTP客户端创建流动性服务，client 为nil时使用共享客户端
+func NewLiquidityServiceWithConfig(baseURL string, ttl time.Duration, client *http.Client) *LiquidityService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	if ttl <= 0 {
+		ttl = defaultLiquidityTTL
+	}
+	return &LiquidityService{
+		client:  client,
+		baseURL: baseURL,
+		ttl:     ttl,
+		cache:   make(map[string]*LiquidityInfo),
+	}
+}
+
+var (
+	liquidityServiceMu sync.RWMutex
+	liquidityService   *LiquidityService
+)
+
+// SetLiquidityService 设置 UpdateTokenPrices 使用的流动性服务，nil 表示不获取流动性
+func SetLiquidityService(service *LiquidityService) {
+	liquidityServiceMu.Lock()
+	defer liquidityServiceMu.Unlock()
+	liquidityService = service
+}
+
+func currentLiquidityService() *LiquidityService {
+	liquidityServiceMu.RLock()
+	defer liquidityServiceMu.RUnlock()
+	return liquidityService
+}
+
+// GetLiquidity 返回代币的流动性信息，缓存未过期的代币不重复查询
+func (s *LiquidityService) GetLiquidity(ctx context.Context, mintAddrs []string) (map[string]*LiquidityInfo, error) {
+	result := make(map[string]*LiquidityInfo, len(mintAddrs))
+	var missing []string
+
+	now := time.Now()
+	s.mu.Lock()
+	for _, mintAddr := range mintAddrs {
+		if info, ok := s.cache[mintAddr]; ok && now.Sub(info.UpdatedAt) < s.ttl {
+			result[mintAddr] = info
+			continue
+		}
+		missing = append(missing, mintAddr)
+	}
+	s.mu.Unlock()
+
+	for i := 0; i < len(missing); i += dexScreenerBatchSize {
+		end := i + dexScreenerBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		fetched, err := s.fetchBatch(ctx, missing[i:end])
+		if err != nil {
+			return result, err
+		}
+
+		s.mu.Lock()
+		for mintAddr, info := range fetched {
+			s.cache[mintAddr] = info
+			result[mintAddr] = info
+		}
+		s.mu.Unlock()
+	}
+	return result, nil
+}
+
+// fetchBatch 查询一批代币的所有交易对并汇总流动性和深度
+func (s *LiquidityService) fetchBatch(ctx context.Context, batch []string) (map[string]*LiquidityInfo, error) {
+	// EVM 地址大小写不敏感，按请求时的写法返回
+	requested := make(map[string]string, len(batch))
+	for _, mintAddr := range batch {
+		requested[strings.ToLower(mintAddr)] = mintAddr
+	}
+
+	url := fmt.Sprintf("%s/%s", s.baseURL, strings.Join(batch, ","))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DexScreener 返回状态码 %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Pairs []struct {
+			BaseToken struct {
+				Address string `json:"address"`
+			} `json:"baseToken"`
+			QuoteToken struct {
+				Address string `json:"address"`
+			} `json:"quoteToken"`
+			Liquidity struct {
+				USD float64 `json:"usd"`
+			} `json:"liquidity"`
+		} `json:"pairs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	now := time.Now()
+	infos := make(map[string]*LiquidityInfo, len(batch))
+	for _, mintAddr := range batch {
+		// 没有交易对的代币记录为零流动性，同样缓存
+		infos[mintAddr] = &LiquidityInfo{UpdatedAt: now}
+	}
+	for _, pair := range body.Pairs {
+		if pair.Liquidity.USD <= 0 {
+			continue
+		}
+		buy, sell := poolDepth(pair.Liquidity.USD, liquidityDepthPct)
+		for _, addr := range []string{pair.BaseToken.Address, pair.QuoteToken.Address} {
+			mintAddr, ok := requested[strings.ToLower(addr)]
+			if !ok {
+				continue
+			}
+			info := infos[mintAddr]
+			info.Liquidity += pair.Liquidity.USD
+			info.Pools++
+			info.Depth.BuyDepth += buy
+			info.Depth.SellDepth += sell
+		}
+	}
+	return infos, nil
+}
+
+// poolDepth 估算恒定乘积池中使价格上涨/下跌 pct% 所需的买入/卖出金额（美元）；
+// 池子两侧各占一半流动性，集中流动性池的实际深度通常更高
+func poolDepth(liquidityUSD, pct float64) (float64, float64) {
+	quote := liquidityUSD / 2
+	buy := quote * (math.Sqrt(1+pct/100) - 1)
+	sell := quote * (1 - math.Sqrt(1-pct/100))
+	return buy, sell
+}
+
+// enrichLiquidity 为代币填充流动性和市场深度
+func enrichLiquidity(ctx context.Context, service *LiquidityService, tokens []*TokenData) {
+	mintAddrs := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		mintAddrs = append(mintAddrs, token.MintAddr)
+	}
+	infos, err := service.GetLiquidity(ctx, mintAddrs)
+	if err != nil && ctx.Err() == nil {
+		priceLog.Warn("获取流动性失败", "error", err)
+	}
+	for _, token := range tokens {
+		if info, ok := infos[token.MintAddr]; ok && info.Pools > 0 {
+			token.Liquidity = info.Liquidity
+			token.BuyDepth = info.Depth.BuyDepth
+			token.SellDepth = info.Depth.SellDepth
+		}
+	}
+}
+
+// liquidityWatch 记录持仓代币上一次的流动性，用于流动性报警
+type liquidityWatch struct {
+	mu      sync.Mutex
+	floor   float64            // 流动性下限（美元），0表示关闭
+	dropPct float64            // 两次检查间流动性下降超过该比例时报警，0表示关闭
+	last    map[string]float64 // mint地址 -> 上一次的流动性
+}
+
+// SetLiquidityAlerts 设置流动性报警：持仓代币的流动性跌破 floor（美元）或两次检查间下降超过 dropPct(%) 时报警，0表示关闭对应报警
+func (m *TokenMonitor) SetLiquidityAlerts(floor, dropPct float64) {
+	m.liquidity.mu.Lock()
+	defer m.liquidity.mu.Unlock()
+	m.liquidity.floor = floor
+	m.liquidity.dropPct = dropPct
+}
+
+// checkLiquidity 检查持仓代币的流动性变化，跌破下限或骤降时发出报警（可能是撤池）
+func (m *TokenMonitor) checkLiquidity(tokens []*TokenData) {
+	m.liquidity.mu.Lock()
+	defer m.liquidity.mu.Unlock()
+
+	if m.liquidity.last == nil {
+		m.liquidity.last = make(map[string]float64)
+	}
+	floor, dropPct := m.liquidity.floor, m.liquidity.dropPct
+
+	now := time.Now()
+	for _, token := range tokens {
+		current := token.Liquidity
+		if current <= 0 {
+			continue
+		}
+		last, seen := m.liquidity.last[token.MintAddr]
+		m.liquidity.last[token.MintAddr] = current
+
+		var changePct float64
+		if seen && last > 0 {
+			changePct = (current - last) / last * 100
+		}
+
+		var reason string
+		switch {
+		case floor > 0 && current < floor && (!seen || last >= floor):
+			reason = i18n.Sprintf("跌破下限 $%.0f", floor)
+		case dropPct > 0 && seen && -changePct >= dropPct:
+			reason = i18n.Sprintf("下降 %.2f%%", -changePct)
+		default:
+			continue
+		}
+
+		m.emitAlert(Alert{
+			Type:      AlertTypeLiquidity,
+			MintAddr:  token.MintAddr,
+			Symbol:    token.Symbol,
+			ChangePct: changePct,
+			OldValue:  last,
+			NewValue:  current,
+			Message: i18n.Sprintf("流动性报警 - %s (%s) 流动性%s: $%.0f -> $%.0f，持仓价值 $%.2f",
+				displaySymbol(token), token.MintAddr, reason, last, current, token.Value),
+			Timestamp: now,
+		})
+	}
+}
+
+// formatUSDCompact 以 K/M/B 缩写显示美元金额，0显示为"-"
+func formatUSDCompact(v float64) string {
+	switch {
+	case v <= 0:
+		return "-"
+	case v >= 1e9:
+		return fmt.Sprintf("$%.2fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("$%.2fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("$%.1fK", v/1e3)
+	default:
+		return fmt.Sprintf("$%.0f", v)
+	}
+}
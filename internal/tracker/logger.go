@@ -0,0 +1,16 @@
+package tracker
+
+import "wallet-tracker/internal/logging"
+
+// 各模块的日志记录器
+var (
+	walletLog  = logging.For("wallet")
+	priceLog   = logging.For("price")
+	monitorLog = logging.For("monitor")
+	notifyLog  = logging.For("notify")
+	serverLog  = logging.For("server")
+	httpLog    = logging.For("http")
+	seriesLog  = logging.For("timeseries")
+	exportLog  = logging.For("export")
+	sheetsLog  = logging.For("sheets")
+)
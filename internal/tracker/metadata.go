@@ -0,0 +1,216 @@
+package tracker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/config"
+
+	"github.com/portto/solana-go-sdk/common"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// metadataMissRetry 查不到元数据的代币多久后重新查询
+const metadataMissRetry = 6 * time.Hour
+
+var (
+	metadataMu     sync.Mutex
+	metadataMisses = make(map[string]time.Time) // 查不到元数据的 mint地址 -> 查询时间
+)
+
+// resolveUnknownMetadata 为 DAS 没有返回的代币查询元数据：先用 Helius getAssetBatch，查不到的再读取 Metaplex 元数据账户；
+// 查到的元数据写入配置的元数据缓存（由 fillTokenMetadata 回填），查不到的代币在 metadataMissRetry 之后才重新查询
+func resolveUnknownMetadata(ctx context.Context, helius *HeliusService, cfg *config.Config, mints []string) {
+	if cfg == nil {
+		return
+	}
+	now := time.Now()
+	var pending []string
+	metadataMu.Lock()
+	for _, mintAddr := range mints {
+		if metadata := cfg.GetTokenMetadata(mintAddr); metadata != nil && !isUnknownSymbol(metadata.Symbol) {
+			continue
+		}
+		if missedAt, ok := metadataMisses[mintAddr]; ok && now.Sub(missedAt) < metadataMissRetry {
+			continue
+		}
+		pending = append(pending, mintAddr)
+	}
+	metadataMu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, span := StartSpan(ctx, "resolveUnknownMetadata", attribute.Int("mint.count", len(pending)))
+	defer span.End()
+
+	found := make(map[string]*config.TokenMetadata)
+	if helius != nil {
+		if err := helius.fetchAssetMetadata(ctx, pending, found); err != nil {
+			walletLog.Warn("DAS获取代币元数据失败", "error", err)
+		}
+	}
+	var missing []string
+	for _, mintAddr := range pending {
+		if _, ok := found[mintAddr]; !ok {
+			missing = append(missing, mintAddr)
+		}
+	}
+	recordMisses := true
+	if len(missing) > 0 {
+		if err := fetchMetaplexMetadata(ctx, helius, missing, found); err != nil {
+			// 查询失败时不记录为查不到，下次刷新重试
+			walletLog.Warn("获取Metaplex元数据失败", "error", err)
+			recordMisses = false
+		}
+	}
+
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+	for _, mintAddr := range pending {
+		if metadata, ok := found[mintAddr]; ok {
+			cfg.SetTokenMetadata(mintAddr, metadata)
+			delete(metadataMisses, mintAddr)
+		} else if recordMisses {
+			metadataMisses[mintAddr] = now
+		}
+	}
+	walletLog.Info("查询未知代币元数据完成", "requested", len(pending), "resolved", len(found))
+}
+
+// fetchAssetMetadata 使用 Helius getAssetBatch 查询代币元数据
+func (s *HeliusService) fetchAssetMetadata(ctx context.Context, mints []string, found map[string]*config.TokenMetadata) error {
+	endpoint := s.rpcEndpoint()
+	for i := 0; i < len(mints); i += dasPageLimit {
+		end := i + dasPageLimit
+		if end > len(mints) {
+			end = len(mints)
+		}
+
+		var assets []*struct {
+			ID      string `json:"id"`
+			Content struct {
+				Metadata struct {
+					Symbol string `json:"symbol"`
+					Name   string `json:"name"`
+				} `json:"metadata"`
+			} `json:"content"`
+			TokenInfo struct {
+				Symbol   string `json:"symbol"`
+				Decimals int    `json:"decimals"`
+			} `json:"token_info"`
+		}
+		params := map[string]interface{}{"ids": mints[i:end]}
+		if err := endpoint.call(ctx, s.client, "getAssetBatch", params, &assets); err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			if asset == nil {
+				continue
+			}
+			symbol := asset.TokenInfo.Symbol
+			if symbol == "" {
+				symbol = asset.Content.Metadata.Symbol
+			}
+			if isUnknownSymbol(symbol) {
+				continue
+			}
+			found[asset.ID] = &config.TokenMetadata{
+				Symbol:   symbol,
+				Name:     asset.Content.Metadata.Name,
+				Decimals: asset.TokenInfo.Decimals,
+				Source:   "das",
+			}
+		}
+	}
+	return nil
+}
+
+// fetchMetaplexMetadata 通过 getMultipleAccounts 读取代币的 Metaplex 元数据账户，可使用备用RPC端点
+func fetchMetaplexMetadata(ctx context.Context, helius *HeliusService, mints []string, found map[string]*config.TokenMetadata) error {
+	client := apiHTTPClient()
+	if helius != nil {
+		client = helius.client
+	}
+
+	for i := 0; i < len(mints); i += multipleAccountsLimit {
+		end := i + multipleAccountsLimit
+		if end > len(mints) {
+			end = len(mints)
+		}
+		batch := mints[i:end]
+
+		accounts := make([]string, len(batch))
+		for j, mintAddr := range batch {
+			pda, _, err := common.FindProgramAddress([][]byte{
+				[]byte("metadata"),
+				common.MetaplexTokenMetaProgramID.Bytes(),
+				common.PublicKeyFromString(mintAddr).Bytes(),
+			}, common.MetaplexTokenMetaProgramID)
+			if err != nil {
+				return fmt.Errorf("计算元数据账户地址失败: %v", err)
+			}
+			accounts[j] = pda.ToBase58()
+		}
+
+		var result struct {
+			Value []*struct {
+				Data []string `json:"data"`
+			} `json:"value"`
+		}
+		err := withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+			params := []interface{}{accounts, map[string]interface{}{"encoding": "base64"}}
+			return endpoint.call(ctx, client, "getMultipleAccounts", params, &result)
+		})
+		if err != nil {
+			return err
+		}
+
+		for j, account := range result.Value {
+			if account == nil || j >= len(batch) || len(account.Data) == 0 {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(account.Data[0])
+			if err != nil {
+				continue
+			}
+			name, symbol, ok := parseMetaplexMetadata(data)
+			if !ok || symbol == "" {
+				continue
+			}
+			found[batch[j]] = &config.TokenMetadata{Symbol: symbol, Name: name, Source: "metaplex"}
+		}
+	}
+	return nil
+}
+
+// parseMetaplexMetadata 从 Metaplex 元数据账户中读取名称和符号：
+// key(1) + update_authority(32) + mint(32) 之后依次为 borsh 编码的 name、symbol（u32 长度 + 内容，以 \x00 填充）
+func parseMetaplexMetadata(data []byte) (name, symbol string, ok bool) {
+	offset := 1 + 32 + 32
+	readString := func() (string, bool) {
+		if len(data) < offset+4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if n < 0 || len(data) < offset+n {
+			return "", false
+		}
+		s := strings.TrimSpace(strings.TrimRight(string(data[offset:offset+n]), "\x00"))
+		offset += n
+		return s, true
+	}
+	if name, ok = readString(); !ok {
+		return "", "", false
+	}
+	if symbol, ok = readString(); !ok {
+		return "", "", false
+	}
+	return name, symbol, true
+}
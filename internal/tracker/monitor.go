@@ -4,9 +4,14 @@ import (
 	"container/ring"
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
 )
 
 // PriceSnapshot 价格快照
@@ -18,56 +23,104 @@ type PriceSnapshot struct {
 
 // TokenMonitor 代币监控器
 type TokenMonitor struct {
+	mu             sync.RWMutex  // 保护 tokens/lastTotalValue/lastUpdateTime 和报警阈值
 	tokens         []*TokenData  // 当前监控的代币列表
 	interval       time.Duration // 监控间隔
 	ctx            context.Context
 	cancel         context.CancelFunc
-	onUpdate       func([]*TokenData) // 更新回调函数
-	csvFile        *os.File           // CSV文件句柄
-	alertFile      *os.File           // 报警日志文件句柄
-	lastTotalValue float64            // 上次更新时的总价值
-	lastUpdateTime time.Time          // 上次更新时间
-	priceHistory   *ring.Ring         // 价格历史环形缓冲区
-	alertThreshold float64            // 报警阈值（百分比）
+	onUpdate       func([]*TokenData)    // 更新回调函数
+	csvFile        *logging.RotatingFile // CSV文件句柄
+	portfolioFile  *logging.RotatingFile // 组合价值序列CSV文件句柄
+	alertFile      *logging.RotatingFile // 报警日志文件句柄
+	lastTotalValue float64               // 上次更新时的总价值
+	lastUpdateTime time.Time             // 上次更新时间
+	priceHistory   *ring.Ring            // 价格历史环形缓冲区
+	historyMu      sync.RWMutex          // 保护 priceHistory 和 alertWindows
+	alertWindows   []time.Duration       // 报警检查的时间窗口
+	alertThreshold float64               // 报警阈值（百分比）
+	ruleWindows    []time.Duration       // 自定义规则引用的时间窗口
+	moverWindows   []time.Duration       // 报告涨跌榜的时间窗口
+	moverCount     int                   // 涨跌榜每个榜单显示的代币数
+	changeWindows  []time.Duration       // 报告中价格变化率列的时间窗口
+
+	lastSnapshotTime time.Time    // 上次成功快照的时间
+	health           healthLimits // 健康检查的判定阈值
+
+	portfolioThreshold float64 // 组合总价值报警阈值（百分比）
+
+	secondaryPriceService PriceService  // 交叉验证价格数据源（可选）
+	divergenceThreshold   float64       // 数据源持续偏离报警阈值（百分比，0表示关闭）
+	divergenceWindow      time.Duration // 数据源偏离的观察窗口
+
+	notifiers   *NotifierRegistry // 报警通知渠道
+	holdings    holdingTracker    // 各钱包上次刷新的持仓，用于买入/卖出报警
+	liquidity   liquidityWatch    // 持仓代币上次的流动性，用于流动性报警
+	safety      safetyWatch       // 已报警的高风险代币
+	peg         pegWatch          // 已脱锚的稳定币
+	targets     targetWatch       // 已触发的价格目标
+	trailing    trailingWatch     // 持仓代币的最高价，用于回撤报警
+	rules       ruleWatch         // 自定义报警规则
+	severity    severityState     // 报警级别、按级别路由和静默时段
+	degraded    degradedWatch     // 已报警的获取失败钱包
+	deduper     *alertDeduper     // 报警去重与冷却
+	coordinator AlertCoordinator  // 多实例报警去重（可选）
+	store       SnapshotStore     // 快照持久化存储（可选，设置后替代CSV）
+	snapshots   *snapshotBroadcaster
+	quiet       bool // 不输出每次快照的状态行
+
+	adaptive adaptiveInterval // 根据波动调整的快照间隔
+	paused   atomic.Bool      // 暂停期间跳过快照
+
+	liquidation     liquidationWatch // 已报警的接近强平的永续合约仓位
+	impermanentLoss ilWatch          // 无常损失超过阈值的LP仓位
+}
+
+// dataDir 监控CSV和报警日志所在目录
+var dataDir = "reports"
+
+// SetDataDir 设置监控CSV和报警日志所在目录，需在 NewTokenMonitor 之前调用
+func SetDataDir(dir string) {
+	dataDir = dir
 }
 
 // NewTokenMonitor 创建新的代币监控器
 func NewTokenMonitor(interval time.Duration, onUpdate func([]*TokenData)) *TokenMonitor {
-	// 创建reports目录
-	if err := os.MkdirAll("reports", 0755); err != nil {
-		log.Printf("创建reports目录失败: %v", err)
-	}
-
-	// 使用固定的CSV文件名
-	csvPath := "reports/monitor.csv"
-	csvFile, err := os.OpenFile(
-		csvPath,
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
-		0666,
-	)
+	// 创建数据目录
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		monitorLog.Error("创建数据目录失败", "path", dataDir, "error", err)
+	}
+
+	// 使用固定的CSV文件名，轮转后的新文件同样写入表头
+	csvPath := filepath.Join(dataDir, "monitor.csv")
+	csvFile, err := logging.OpenRotatingFile(csvPath, logging.RotateConfig{}, GenerateCSVHeader())
+	if err != nil {
+		monitorLog.Error("创建CSV文件失败", "error", err)
+	} else {
+		monitorLog.Info("CSV报告保存路径", "path", csvPath)
+	}
+
+	// 组合总价值时间序列CSV，每个快照一行
+	portfolioPath := filepath.Join(dataDir, "portfolio.csv")
+	portfolioFile, err := logging.OpenRotatingFile(portfolioPath, logging.RotateConfig{}, GeneratePortfolioCSVHeader())
 	if err != nil {
-		log.Printf("创建CSV文件失败: %v", err)
+		monitorLog.Error("创建组合CSV文件失败", "error", err)
 	} else {
-		log.Printf("CSV报告将保存到: %s", csvPath)
+		monitorLog.Info("组合价值序列保存路径", "path", portfolioPath)
 	}
 
 	// 创建报警日志文件
-	alertPath := "reports/alert.log"
-	alertFile, err := os.OpenFile(
-		alertPath,
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
-		0666,
-	)
+	alertPath := filepath.Join(dataDir, "alert.log")
+	alertFile, err := logging.OpenRotatingFile(alertPath, logging.RotateConfig{}, "")
 	if err != nil {
-		log.Printf("创建报警日志文件失败: %v", err)
+		monitorLog.Error("创建报警日志文件失败", "error", err)
 	} else {
-		log.Printf("报警日志将保存到: %s", alertPath)
+		monitorLog.Info("报警日志保存路径", "path", alertPath)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// 创建环形缓冲区，存储最近300个数据点（假设interval为1秒，则存储5分钟数据）
-	priceHistory := ring.New(300)
+	// 创建环形缓冲区，容量根据最长报警窗口和监控间隔推算
+	priceHistory := ring.New(historySizeFor(defaultAlertWindows, interval))
 
 	return &TokenMonitor{
 		tokens:         make([]*TokenData, 0),
@@ -76,30 +129,98 @@ func NewTokenMonitor(interval time.Duration, onUpdate func([]*TokenData)) *Token
 		cancel:         cancel,
 		onUpdate:       onUpdate,
 		csvFile:        csvFile,
+		portfolioFile:  portfolioFile,
 		alertFile:      alertFile,
 		lastTotalValue: 0,
 		lastUpdateTime: time.Time{},
 		priceHistory:   priceHistory,
+		alertWindows:   defaultAlertWindows,
 		alertThreshold: 5.0, // 5%的报警阈值
+
+		portfolioThreshold: 5.0, // 组合5%的报警阈值
+
+		notifiers: NewNotifierRegistry(),
+		deduper:   newAlertDeduper(defaultAlertCooldown),
+		rules:     ruleWatch{builtinEnabled: true},
+		snapshots: newSnapshotBroadcaster(),
 	}
 }
 
+// SetStore 设置快照持久化存储，设置后快照写入存储而不再追加到CSV
+func (m *TokenMonitor) SetStore(store SnapshotStore) {
+	m.store = store
+}
+
 // UpdateTokens 更新监控的代币列表
 func (m *TokenMonitor) UpdateTokens(tokens []*TokenData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.tokens = tokens
 }
 
-// Start 开始监控
-func (m *TokenMonitor) Start() {
-	ticker := time.NewTicker(m.interval)
+// Tokens 返回当前监控代币列表的副本
+func (m *TokenMonitor) Tokens() []*TokenData {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tokens := make([]*TokenData, len(m.tokens))
+	copy(tokens, m.tokens)
+	return tokens
+}
+
+// TotalValue 返回当前监控代币的总价值及最近一次更新时间
+func (m *TokenMonitor) TotalValue() (float64, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var total float64
+	for _, token := range m.tokens {
+		total += token.Value
+	}
+	return total, m.lastUpdateTime
+}
+
+// lastUpdate 返回上次更新时间
+func (m *TokenMonitor) lastUpdate() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastUpdateTime
+}
+
+// setLastUpdate 记录上次更新时间
+func (m *TokenMonitor) setLastUpdate(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastUpdateTime = t
+}
+
+// recordSnapshot 记录快照的时间和总价值
+func (m *TokenMonitor) recordSnapshot(t time.Time, totalValue float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastUpdateTime = t
+	m.lastSnapshotTime = t
+	m.lastTotalValue = totalValue
+}
+
+// Start 开始监控，ctx 取消时停止快照并中断进行中的价格请求；
+// 启用自适应间隔时每次快照后根据波动重新计算下一次快照的时间
+func (m *TokenMonitor) Start(ctx context.Context) {
+	timer := time.NewTimer(m.currentInterval())
 	go func() {
+		defer timer.Stop()
 		for {
 			select {
+			case <-ctx.Done():
+				m.cancel()
+				return
 			case <-m.ctx.Done():
-				ticker.Stop()
 				return
-			case <-ticker.C:
-				m.takeSnapshot()
+			case <-timer.C:
+				started := time.Now()
+				if !m.Paused() {
+					m.takeSnapshot(m.ctx)
+				}
+				// 间隔从快照开始时计算，与固定间隔的节奏一致
+				timer.Reset(max(m.currentInterval()-time.Since(started), 0))
 			}
 		}
 	}()
@@ -111,18 +232,207 @@ func (m *TokenMonitor) Stop() {
 	if m.csvFile != nil {
 		m.csvFile.Close()
 	}
+	if m.portfolioFile != nil {
+		m.portfolioFile.Close()
+	}
 	if m.alertFile != nil {
 		m.alertFile.Close()
 	}
 }
 
+// SetOutputRotation 设置CSV报告和报警日志的轮转规则
+func (m *TokenMonitor) SetOutputRotation(csv, alerts logging.RotateConfig) {
+	for _, file := range []*logging.RotatingFile{m.csvFile, m.portfolioFile} {
+		if file != nil {
+			file.SetConfig(csv)
+		}
+	}
+	if m.alertFile != nil {
+		m.alertFile.SetConfig(alerts)
+	}
+}
+
+// defaultAlertWindows 默认的报警检查时间窗口
+var defaultAlertWindows = []time.Duration{
+	30 * time.Second, // 短期
+	1 * time.Minute,  // 中期
+	5 * time.Minute,  // 长期
+}
+
+// minHistorySize 环形缓冲区的最小容量，保证仪表盘走势图等有足够数据
+const minHistorySize = 60
+
+// historySizeFor 根据最长窗口和监控间隔推算环形缓冲区容量（额外保留两个快照的余量）
+func historySizeFor(windows []time.Duration, interval time.Duration) int {
+	if interval <= 0 {
+		return minHistorySize
+	}
+	var longest time.Duration
+	for _, w := range windows {
+		if w > longest {
+			longest = w
+		}
+	}
+	size := int((longest+interval-1)/interval) + 2
+	if size < minHistorySize {
+		size = minHistorySize
+	}
+	return size
+}
+
+// SetAlertWindows 设置报警时间窗口；historySize<=0 时根据最长窗口自动推算缓冲区容量
+func (m *TokenMonitor) SetAlertWindows(windows []time.Duration, historySize int) {
+	m.historyMu.Lock()
+	if len(windows) > 0 {
+		m.alertWindows = windows
+	}
+	// 数据源偏离窗口、自定义规则、涨跌榜和变化率列的窗口同样依赖历史快照
+	if historySize <= 0 {
+		needed := append([]time.Duration{m.divergenceWindow}, m.alertWindows...)
+		needed = append(needed, m.moverWindows...)
+		needed = append(needed, m.changeWindows...)
+		historySize = historySizeFor(append(needed, m.ruleWindows...), m.shortestInterval())
+	}
+	m.historyMu.Unlock()
+
+	m.resizeHistory(historySize)
+}
+
+// windows 返回当前的报警时间窗口
+func (m *TokenMonitor) windows() []time.Duration {
+	m.historyMu.RLock()
+	defer m.historyMu.RUnlock()
+	return m.alertWindows
+}
+
+// resizeHistory 调整环形缓冲区容量，保留最近的快照
+func (m *TokenMonitor) resizeHistory(size int) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+
+	if size == m.priceHistory.Len() {
+		return
+	}
+
+	// 按时间从旧到新收集现有快照
+	var snapshots []*PriceSnapshot
+	r := m.priceHistory.Next()
+	for i := 0; i < m.priceHistory.Len(); i++ {
+		if r.Value != nil {
+			snapshots = append(snapshots, r.Value.(*PriceSnapshot))
+		}
+		r = r.Next()
+	}
+	if len(snapshots) > size {
+		snapshots = snapshots[len(snapshots)-size:]
+	}
+
+	history := ring.New(size)
+	for _, snapshot := range snapshots {
+		history = history.Next()
+		history.Value = snapshot
+	}
+	m.priceHistory = history
+	monitorLog.Info("历史快照缓冲区容量", "size", size, "interval", m.shortestInterval())
+}
+
+// defaultAlertCooldown 同一报警的默认抑制时长
+const defaultAlertCooldown = 5 * time.Minute
+
+// portfolioDominanceRatio 单个代币贡献的价值变化占比超过该比例时视为主导组合变化
+const portfolioDominanceRatio = 0.8
+
+// SetPortfolioAlertThreshold 设置组合总价值报警阈值（百分比，0表示关闭）
+func (m *TokenMonitor) SetPortfolioAlertThreshold(threshold float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.portfolioThreshold = threshold
+}
+
+// SetAlertThreshold 设置单币价格/价值报警阈值（百分比）
+func (m *TokenMonitor) SetAlertThreshold(threshold float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alertThreshold = threshold
+}
+
+// alertThresholds 返回单币和组合的报警阈值，运行中可能通过控制接口修改
+func (m *TokenMonitor) alertThresholds() (price, portfolio float64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.alertThreshold, m.portfolioThreshold
+}
+
+// checkPortfolioAlert 检查组合总价值在各时间窗口内的变化并生成报警
+func (m *TokenMonitor) checkPortfolioAlert(currentSnapshot *PriceSnapshot) {
+	alertThreshold, portfolioThreshold := m.alertThresholds()
+	if portfolioThreshold <= 0 || currentSnapshot.Value <= 0 {
+		return
+	}
+
+	for _, window := range m.windows() {
+		oldSnapshot := m.findSnapshotAt(currentSnapshot.Timestamp.Add(-window), m.longestInterval())
+		if oldSnapshot == nil || oldSnapshot == currentSnapshot || oldSnapshot.Value <= 0 {
+			continue
+		}
+
+		totalChange := currentSnapshot.Value - oldSnapshot.Value
+		changePct := totalChange / oldSnapshot.Value * 100
+		if abs(changePct) < portfolioThreshold {
+			continue
+		}
+
+		// 如果变化几乎全部来自某一个代币且该代币已触发单币报警，则不重复报警
+		if mintAddr, tokenChangePct, ok := dominantContributor(oldSnapshot, currentSnapshot, totalChange); ok &&
+			abs(tokenChangePct) >= alertThreshold {
+			monitorLog.Info("组合价值变化主要由单个代币引起，已由单币报警覆盖",
+				"window", window, "change_pct", changePct, "mint", mintAddr)
+			continue
+		}
+
+		alertMsg := i18n.Sprintf("组合价值报警 - %s内总价值变化率: %.2f%% (从 $%.2f 到 $%.2f)",
+			window.String(),
+			changePct,
+			oldSnapshot.Value,
+			currentSnapshot.Value)
+
+		m.emitAlert(Alert{
+			Type:      AlertTypePortfolio,
+			Window:    window,
+			ChangePct: changePct,
+			OldValue:  oldSnapshot.Value,
+			NewValue:  currentSnapshot.Value,
+			Message:   alertMsg,
+			Timestamp: currentSnapshot.Timestamp,
+		})
+	}
+}
+
+// dominantContributor 查找贡献了绝大部分组合价值变化的代币，返回其mint地址和自身价值变化率
+func dominantContributor(oldSnapshot, currentSnapshot *PriceSnapshot, totalChange float64) (string, float64, bool) {
+	if totalChange == 0 {
+		return "", 0, false
+	}
+
+	for mintAddr, currentToken := range currentSnapshot.TokenData {
+		oldToken, exists := oldSnapshot.TokenData[mintAddr]
+		if !exists || oldToken.Value <= 0 {
+			continue
+		}
+		delta := currentToken.Value - oldToken.Value
+		if delta/totalChange >= portfolioDominanceRatio {
+			return mintAddr, delta / oldToken.Value * 100, true
+		}
+	}
+	return "", 0, false
+}
+
 // checkPriceAlert 检查价格变化并生成报警
 func (m *TokenMonitor) checkPriceAlert(currentSnapshot *PriceSnapshot) {
-	timeWindows := []time.Duration{
-		30 * time.Second, // 短期
-		1 * time.Minute,  // 中期
-		5 * time.Minute,  // 长期
-	}
+	timeWindows := m.windows()
+	threshold, _ := m.alertThresholds()
+	// 各窗口的历史快照与代币无关，在遍历代币前查找一次
+	oldSnapshots := m.windowSnapshots(currentSnapshot.Timestamp, timeWindows)
 
 	// 遍历每个代币
 	for mintAddr, currentToken := range currentSnapshot.TokenData {
@@ -130,33 +440,13 @@ func (m *TokenMonitor) checkPriceAlert(currentSnapshot *PriceSnapshot) {
 			continue
 		}
 
-		log.Printf("检查代币 %s (%s) 的价格变化, 当前价值: $%.2f",
-			currentToken.Symbol, mintAddr, currentToken.Value)
+		monitorLog.Debug("检查代币价格变化", "symbol", currentToken.Symbol, "mint", mintAddr, "value", currentToken.Value)
 
 		// 对每个时间窗口检查价格变化
-		for _, window := range timeWindows {
-			// 从当前位置开始遍历整个环形缓冲区
-			r := m.priceHistory
-			var oldSnapshot *PriceSnapshot
-			found := false
-
-			// 遍历环形缓冲区查找合适的历史快照
-			for i := 0; i < m.priceHistory.Len(); i++ {
-				if r.Value != nil {
-					snapshot := r.Value.(*PriceSnapshot)
-					timeDiff := currentSnapshot.Timestamp.Sub(snapshot.Timestamp)
-
-					// 放宽时间窗口匹配条件
-					if timeDiff >= window && timeDiff <= window+(5*time.Second) {
-						oldSnapshot = snapshot
-						found = true
-						break
-					}
-				}
-				r = r.Next()
-			}
+		for i, window := range timeWindows {
+			oldSnapshot := oldSnapshots[i]
 
-			if found && oldSnapshot != nil {
+			if oldSnapshot != nil && oldSnapshot != currentSnapshot {
 				// 检查历史快照中是否存在该代币
 				if oldToken, exists := oldSnapshot.TokenData[mintAddr]; exists && oldToken.Value > 0 {
 					// 计算价格变化
@@ -166,52 +456,57 @@ func (m *TokenMonitor) checkPriceAlert(currentSnapshot *PriceSnapshot) {
 
 					// 记录显著的价格变化
 					if abs(priceChange) > 1.0 || abs(valueChange) > 1.0 {
-						log.Printf("代币 %s 在 %s 内的变化: 价格变化率: %.2f%%, 价值变化率: %.2f%%",
-							currentToken.Symbol, window, priceChange, valueChange)
+						monitorLog.Info("代币价格显著变化", "symbol", currentToken.Symbol, "window", window,
+							"price_change_pct", priceChange, "value_change_pct", valueChange)
 					}
 
-					// 在检查价格变化时添加详细日志
-					log.Printf("检查价格变化 - 代币: %s, 窗口: %s, 当前价格: $%.8f, 历史价格: $%.8f, 变化率: %.2f%%, 阈值: %.2f%%",
-						currentToken.Symbol,
-						window.String(),
-						currentToken.Price,
-						oldToken.Price,
-						priceChange,
-						m.alertThreshold)
+					monitorLog.Debug("检查价格变化",
+						"symbol", currentToken.Symbol,
+						"window", window,
+						"price", currentToken.Price,
+						"old_price", oldToken.Price,
+						"change_pct", priceChange,
+						"threshold", threshold)
 
 					// 如果价格变化超过阈值，生成报警
-					if abs(priceChange) >= m.alertThreshold {
-						alertMsg := fmt.Sprintf("⚠️ 代币价格报警 - %s (%s)\n"+
-							"时间窗口: %s\n"+
-							"价格变化: %.2f%%\n"+
-							"当前价格: $%.8f\n"+
-							"历史价格: $%.8f\n"+
-							"当前价值: $%.2f",
-							currentToken.Symbol,
-							mintAddr,
-							window.String(),
-							priceChange,
-							currentToken.Price,
-							oldToken.Price,
-							currentToken.Value)
-
-						// 立即写入报警日志并打印
-						m.writeAlertLog(alertMsg)
-						log.Print(alertMsg)
+					if abs(priceChange) >= threshold {
+						m.emitPriceAlert(currentToken, oldToken, window, priceChange, currentSnapshot.Timestamp)
+					}
+
+					// 持仓数量发生变化（转入、转出或兑换）时价值变化不能反映价格波动，改为报告数量变化
+					if amountChanged(oldToken.Amount, currentToken.Amount) {
+						if oldToken.Amount > 0 {
+							amountChange := (currentToken.Amount - oldToken.Amount) / oldToken.Amount * 100
+							if abs(amountChange) >= threshold {
+								m.emitAmountAlert(currentToken, oldToken, window, amountChange, currentSnapshot.Timestamp)
+							}
+						}
+						continue
 					}
 
 					// 如果价值变化超过阈值，生成报警
-					if abs(valueChange) >= m.alertThreshold {
-						alertMsg := fmt.Sprintf("代币价值报警 - %s (%s) %s内价值变化率: %.2f%% (从 $%.2f 到 $%.2f)",
+					if abs(valueChange) >= threshold {
+						alertMsg := i18n.Sprintf("代币价值报警 - %s (%s) %s内价值变化率: %.2f%% (从 $%.2f 到 $%.2f, 持有钱包: %s)",
 							currentToken.Symbol,
 							mintAddr,
 							window.String(),
 							valueChange,
 							oldToken.Value,
-							currentToken.Value)
-
-						m.writeAlertLog(alertMsg)
-						log.Print("⚠️ " + alertMsg)
+							currentToken.Value,
+							holderLabels(currentToken))
+
+						m.emitAlert(Alert{
+							Type:      AlertTypeValue,
+							Wallet:    soleHolder(currentToken),
+							MintAddr:  mintAddr,
+							Symbol:    currentToken.Symbol,
+							Window:    window,
+							ChangePct: valueChange,
+							OldValue:  oldToken.Value,
+							NewValue:  currentToken.Value,
+							Message:   alertMsg,
+							Timestamp: currentSnapshot.Timestamp,
+						})
 					}
 				}
 			}
@@ -219,6 +514,121 @@ func (m *TokenMonitor) checkPriceAlert(currentSnapshot *PriceSnapshot) {
 	}
 }
 
+// emitPriceAlert 发送代币价格报警，快照和快速价格轮询共用
+func (m *TokenMonitor) emitPriceAlert(currentToken, oldToken *TokenData, window time.Duration, priceChange float64, timestamp time.Time) {
+	alertMsg := i18n.Sprintf("代币价格报警 - %s (%s)\n"+
+		"时间窗口: %s\n"+
+		"价格变化: %.2f%%\n"+
+		"当前价格: $%.8f\n"+
+		"历史价格: $%.8f\n"+
+		"当前价值: $%.2f\n"+
+		"持有钱包: %s",
+		currentToken.Symbol,
+		currentToken.MintAddr,
+		window.String(),
+		priceChange,
+		currentToken.Price,
+		oldToken.Price,
+		currentToken.Value,
+		holderLabels(currentToken))
+
+	// 立即写入报警日志并通知
+	m.emitAlert(Alert{
+		Type:      AlertTypePrice,
+		Wallet:    soleHolder(currentToken),
+		MintAddr:  currentToken.MintAddr,
+		Symbol:    currentToken.Symbol,
+		Window:    window,
+		ChangePct: priceChange,
+		OldValue:  oldToken.Price,
+		NewValue:  currentToken.Price,
+		Message:   alertMsg,
+		Timestamp: timestamp,
+	})
+}
+
+// emitAmountAlert 发送持仓数量变化报警，并把价值变化拆分为价格和数量两部分
+func (m *TokenMonitor) emitAmountAlert(currentToken, oldToken *TokenData, window time.Duration, amountChange float64, timestamp time.Time) {
+	priceEffect := (currentToken.Price - oldToken.Price) * oldToken.Amount
+	amountEffect := (currentToken.Amount - oldToken.Amount) * currentToken.Price
+	alertMsg := i18n.Sprintf("持仓数量变化 - %s (%s) %s内数量从 %.4f 变为 %.4f (%+.2f%%)，价值从 $%.2f 到 $%.2f（价格影响 %+.2f，数量影响 %+.2f），持有钱包: %s",
+		currentToken.Symbol,
+		currentToken.MintAddr,
+		window.String(),
+		oldToken.Amount,
+		currentToken.Amount,
+		amountChange,
+		oldToken.Value,
+		currentToken.Value,
+		priceEffect,
+		amountEffect,
+		holderLabels(currentToken))
+
+	m.emitAlert(Alert{
+		Type:      AlertTypeAmount,
+		Wallet:    soleHolder(currentToken),
+		MintAddr:  currentToken.MintAddr,
+		Symbol:    currentToken.Symbol,
+		Window:    window,
+		ChangePct: amountChange,
+		OldValue:  oldToken.Amount,
+		NewValue:  currentToken.Amount,
+		Message:   alertMsg,
+		Timestamp: timestamp,
+	})
+}
+
+// windowSnapshots 返回 now 之前各时间窗口处的历史快照（与 windows 一一对应，找不到时为nil），
+// 查找最接近 (now - 窗口) 的快照，容差为一个监控间隔
+func (m *TokenMonitor) windowSnapshots(now time.Time, windows []time.Duration) []*PriceSnapshot {
+	tolerance := m.longestInterval()
+	snapshots := make([]*PriceSnapshot, len(windows))
+	for i, window := range windows {
+		snapshots[i] = m.findSnapshotAt(now.Add(-window), tolerance)
+	}
+	return snapshots
+}
+
+// findSnapshotAt 在环形缓冲区中查找时间上最接近 target 的快照，超出 tolerance 时返回nil
+func (m *TokenMonitor) findSnapshotAt(target time.Time, tolerance time.Duration) *PriceSnapshot {
+	var nearest *PriceSnapshot
+	var nearestDiff time.Duration
+
+	m.historyMu.RLock()
+	m.priceHistory.Do(func(v interface{}) {
+		if v == nil {
+			return
+		}
+		snapshot := v.(*PriceSnapshot)
+		diff := snapshot.Timestamp.Sub(target)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			return
+		}
+		// 距离相同时优先选择较早的快照，保证覆盖完整窗口
+		if nearest == nil || diff < nearestDiff ||
+			(diff == nearestDiff && snapshot.Timestamp.Before(nearest.Timestamp)) {
+			nearest = snapshot
+			nearestDiff = diff
+		}
+	})
+	m.historyMu.RUnlock()
+
+	// 内存中没有合适的快照时从持久化存储中查找（例如重启后）
+	if nearest == nil && m.store != nil {
+		snapshot, err := m.store.SnapshotNear(m.ctx, target, tolerance)
+		if err != nil {
+			monitorLog.Error("从存储查询历史快照失败", "error", err)
+			return nil
+		}
+		return snapshot
+	}
+
+	return nearest
+}
+
 // abs 返回浮点数的绝对值
 func abs(x float64) float64 {
 	if x < 0 {
@@ -227,16 +637,31 @@ func abs(x float64) float64 {
 	return x
 }
 
+// amountEpsilon 数量的相对变化小于该值视为未变化（浮点误差）
+const amountEpsilon = 1e-9
+
+// amountChanged 判断持仓数量是否发生变化
+func amountChanged(old, current float64) bool {
+	return abs(current-old) > amountEpsilon*abs(old)
+}
+
 // takeSnapshot 获取当前代币状态快照
-func (m *TokenMonitor) takeSnapshot() {
-	// 将 []*TokenData 转换为 map[string][]*TokenData
-	tokenMap := make(map[string][]*TokenData)
-	tokenMap["default"] = m.tokens
+func (m *TokenMonitor) takeSnapshot(ctx context.Context) {
+	defer recordTiming("snapshot", time.Now())
+
+	// 静默时段结束后发送排队的报警
+	m.flushDigest()
+
+	// 按钱包拆分聚合后的代币，保留各钱包的持仓
+	tokenMap := splitByWallet(m.Tokens())
 
 	// 获取最新价格
-	validTokens, err := UpdateTokenPrices(tokenMap, m)
+	validTokens, err := UpdateTokenPrices(ctx, tokenMap, m)
 	if err != nil {
-		log.Printf("更新价格失败: %v", err)
+		// 退出时取消的请求不记录为错误
+		if ctx.Err() == nil {
+			monitorLog.Error("更新价格失败", "error", err)
+		}
 		return
 	}
 
@@ -265,28 +690,47 @@ func (m *TokenMonitor) takeSnapshot() {
 	// 将当前快照添加到环形缓冲区
 	if len(tokenDataMap) > 0 {
 		// 先移动到下一个位置，再设置值
+		m.historyMu.Lock()
 		m.priceHistory = m.priceHistory.Next()
 		m.priceHistory.Value = currentSnapshot
+		m.historyMu.Unlock()
 
-		// 添加调试日志
-		log.Printf("添加新的价格快照: 时间=%s, 代币数=%d, 总价值=$%.2f",
-			now.Format("15:04:05"),
-			len(tokenDataMap),
-			totalValue)
+		monitorLog.Debug("添加新的价格快照", "tokens", len(tokenDataMap), "value", totalValue)
+
+		// 持久化快照
+		if m.store != nil {
+			if err := m.store.SaveSnapshot(ctx, currentSnapshot); err != nil {
+				monitorLog.Error("保存快照到存储失败", "error", err)
+			}
+		}
 	}
 
 	// 检查价格报警
-	m.checkPriceAlert(currentSnapshot)
+	if m.builtinAlertsEnabled() {
+		m.checkPriceAlert(currentSnapshot)
+	}
+	m.checkPortfolioAlert(currentSnapshot)
+	m.checkRules(currentSnapshot)
+	m.checkDegraded(currentSnapshot)
+
+	// 检查数据源偏离报警
+	m.checkSourceDivergence(currentSnapshot)
 
 	// 生成状态消息
 	var statusMsg string
+	var percentageChange float64
 
 	// 查找上一个快照用于计算变化率
 	var previousSnapshot *PriceSnapshot
+	m.historyMu.RLock()
 	r := m.priceHistory.Prev()
 	if r.Value != nil {
 		previousSnapshot = r.Value.(*PriceSnapshot)
 	}
+	m.historyMu.RUnlock()
+
+	// 根据本次快照的波动调整下一次快照的间隔
+	m.adjustInterval(previousSnapshot, currentSnapshot)
 
 	if previousSnapshot != nil {
 		// 使用快照时间计算时间差
@@ -294,10 +738,10 @@ func (m *TokenMonitor) takeSnapshot() {
 		if timeDiff > 0 {
 			// 计算变化率
 			absoluteChange := currentSnapshot.Value - previousSnapshot.Value
-			percentageChange := (absoluteChange / previousSnapshot.Value) * 100
+			percentageChange = (absoluteChange / previousSnapshot.Value) * 100
 			changePerSecond := percentageChange / timeDiff
 
-			statusMsg = fmt.Sprintf("$%.2f (%.4f%%/s | 总变化: %.4f%% | 间隔: %.1fs) [%s]",
+			statusMsg = i18n.Sprintf("$%.2f (%.4f%%/s | 总变化: %.4f%% | 间隔: %.1fs) [%s]",
 				totalValue,
 				changePerSecond,
 				percentageChange,
@@ -311,17 +755,36 @@ func (m *TokenMonitor) takeSnapshot() {
 	}
 
 	// 输出状态
-	fmt.Println(statusMsg)
+	if !m.quiet {
+		fmt.Println(statusMsg)
+	}
 
 	// 更新状态
-	m.lastTotalValue = totalValue
-	m.lastUpdateTime = now
+	m.recordSnapshot(now, totalValue)
 
-	// 写入CSV文件
-	if m.csvFile != nil {
+	// 写入CSV文件（未配置持久化存储时）
+	if m.csvFile != nil && m.store == nil {
 		csvReport := GenerateCSVReport(validTokens)
 		if _, err := m.csvFile.WriteString(csvReport); err != nil {
-			log.Printf("写入CSV报告失败: %v", err)
+			monitorLog.Error("写入CSV报告失败", "error", err)
+		}
+	}
+
+	// 推送快照事件
+	m.snapshots.publish(SnapshotEvent{
+		Timestamp:  now,
+		TotalValue: totalValue,
+		ChangePct:  percentageChange,
+		Tokens:     newHoldingResponses(validTokens),
+		Allocation: AnalyzeAllocation(validTokens),
+		Degraded:   degradedWallets(),
+	})
+
+	// 写入组合价值序列
+	if m.portfolioFile != nil {
+		row := GeneratePortfolioCSVRow(now, totalValue, len(tokenDataMap), percentageChange)
+		if _, err := m.portfolioFile.WriteString(row); err != nil {
+			monitorLog.Error("写入组合CSV失败", "error", err)
 		}
 	}
 
@@ -341,6 +804,6 @@ func (m *TokenMonitor) writeAlertLog(msg string) {
 	alertMsg := fmt.Sprintf("[%s] %s\n", timestamp, msg)
 
 	if _, err := m.alertFile.WriteString(alertMsg); err != nil {
-		log.Printf("写入报警日志失败: %v", err)
+		monitorLog.Error("写入报警日志失败", "error", err)
 	}
 }
@@ -0,0 +1,155 @@
+package tracker
+
+import (
+	"container/ring"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestMonitor 在临时目录中创建监控器，测试结束时关闭文件并恢复数据目录
+func newTestMonitor(t *testing.T, interval time.Duration) *TokenMonitor {
+	t.Helper()
+	previous := dataDir
+	SetDataDir(t.TempDir())
+	m := NewTokenMonitor(interval, nil)
+	t.Cleanup(func() {
+		m.Stop()
+		SetDataDir(previous)
+	})
+	return m
+}
+
+// pushSnapshot 按 takeSnapshot 的方式将快照写入环形缓冲区
+func pushSnapshot(m *TokenMonitor, snapshot *PriceSnapshot) {
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	m.priceHistory = m.priceHistory.Next()
+	m.priceHistory.Value = snapshot
+}
+
+func TestFindSnapshotAt(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) time.Time { return base.Add(offset) }
+
+	// 不规则间隔的快照：0s, 50s, 130s, 170s, 300s, 305s
+	offsets := []time.Duration{0, 50 * time.Second, 130 * time.Second, 170 * time.Second, 300 * time.Second, 305 * time.Second}
+
+	tests := []struct {
+		name      string
+		target    time.Duration
+		tolerance time.Duration
+		want      time.Duration // 期望快照的偏移，-1 表示期望nil
+	}{
+		{name: "精确命中", target: 130 * time.Second, tolerance: time.Minute, want: 130 * time.Second},
+		{name: "选择最近的快照", target: 60 * time.Second, tolerance: time.Minute, want: 50 * time.Second},
+		{name: "间隔不均匀时选择最近的快照", target: 160 * time.Second, tolerance: time.Minute, want: 170 * time.Second},
+		{name: "距离相同时选择较早的快照", target: 150 * time.Second, tolerance: time.Minute, want: 130 * time.Second},
+		{name: "相邻快照距离相同时选择较早的快照", target: 302*time.Second + 500*time.Millisecond, tolerance: time.Minute, want: 300 * time.Second},
+		{name: "恰好等于容差", target: 240 * time.Second, tolerance: time.Minute, want: 300 * time.Second},
+		{name: "超出容差返回nil", target: 240 * time.Second, tolerance: 30 * time.Second, want: -1},
+		{name: "早于所有快照且超出容差", target: -2 * time.Minute, tolerance: time.Minute, want: -1},
+	}
+
+	m := newTestMonitor(t, time.Minute)
+	m.priceHistory = ring.New(10)
+	for _, offset := range offsets {
+		pushSnapshot(m, &PriceSnapshot{Timestamp: at(offset)})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.findSnapshotAt(at(tt.target), tt.tolerance)
+			if tt.want < 0 {
+				if got != nil {
+					t.Fatalf("期望nil，得到 %v 的快照", got.Timestamp.Sub(base))
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("期望 %v 的快照，得到nil", tt.want)
+			}
+			if !got.Timestamp.Equal(at(tt.want)) {
+				t.Errorf("选择了 %v 的快照，期望 %v", got.Timestamp.Sub(base), tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSnapshotAtEmptyRing(t *testing.T) {
+	m := newTestMonitor(t, time.Minute)
+	if got := m.findSnapshotAt(time.Now(), time.Hour); got != nil {
+		t.Errorf("空缓冲区返回了快照 %+v", got)
+	}
+}
+
+func TestFindSnapshotAtOverwrittenRing(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestMonitor(t, time.Minute)
+	m.priceHistory = ring.New(3)
+	// 写入5个快照，最早的两个被覆盖
+	for i := 0; i < 5; i++ {
+		pushSnapshot(m, &PriceSnapshot{Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	if got := m.findSnapshotAt(base, 30*time.Second); got != nil {
+		t.Errorf("已被覆盖的快照仍被返回: %v", got.Timestamp)
+	}
+	if got := m.findSnapshotAt(base, 2*time.Minute); got == nil || !got.Timestamp.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("期望最早保留的快照 %v，得到 %+v", base.Add(2*time.Minute), got)
+	}
+}
+
+// countingStore 记录 SnapshotNear 的调用次数，其余方法不应被调用
+type countingStore struct {
+	SnapshotStore
+	nearCalls int
+	snapshot  *PriceSnapshot
+}
+
+func (s *countingStore) SnapshotNear(ctx context.Context, target time.Time, tolerance time.Duration) (*PriceSnapshot, error) {
+	s.nearCalls++
+	return s.snapshot, nil
+}
+
+func TestWindowSnapshotsQueryStoreOncePerWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := make(map[string]*TokenData)
+	var fastTokens []*TokenData
+	prices := make(map[string]*TokenPrice)
+	for i := 0; i < 5; i++ {
+		mint := fmt.Sprintf("Mint%d", i)
+		token := &TokenData{MintAddr: mint, Symbol: mint, Amount: 1, Price: 1, Value: 1}
+		tokens[mint] = token
+		fastTokens = append(fastTokens, token)
+		prices[mint] = &TokenPrice{Price: 1}
+	}
+	old := &PriceSnapshot{Timestamp: now.Add(-time.Minute), TokenData: tokens}
+
+	tests := []struct {
+		name  string
+		check func(m *TokenMonitor)
+	}{
+		{name: "快照检查", check: func(m *TokenMonitor) {
+			m.checkPriceAlert(&PriceSnapshot{Timestamp: now, TokenData: tokens})
+		}},
+		{name: "快速价格轮询", check: func(m *TokenMonitor) {
+			m.checkFastPrices(fastTokens, prices, now)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMonitor(t, time.Minute)
+			store := &countingStore{snapshot: old}
+			m.SetStore(store)
+
+			// 重启后环形缓冲区为空，每个窗口都需从存储中查找
+			tt.check(m)
+			if want := len(m.windows()); store.nearCalls != want {
+				t.Errorf("%d 个代币查询了 %d 次存储, 期望每个窗口一次共 %d 次", len(tokens), store.nearCalls, want)
+			}
+		})
+	}
+}
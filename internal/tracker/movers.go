@@ -0,0 +1,167 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// Mover 时间窗口内价格变化最大的代币
+type Mover struct {
+	MintAddr  string
+	Symbol    string
+	OldPrice  float64
+	Price     float64
+	ChangePct float64
+}
+
+// MoverWindow 单个时间窗口内涨幅和跌幅最大的代币
+type MoverWindow struct {
+	Window  time.Duration
+	Gainers []Mover // 按涨幅从大到小
+	Losers  []Mover // 按跌幅从大到小
+}
+
+var (
+	reportMonitorMu sync.RWMutex
+	reportMonitor   *TokenMonitor // 为报告的涨跌榜和变化率列提供历史快照的监控器，为nil表示不显示
+)
+
+// setReportMonitor 设置为报告提供历史快照的监控器
+func setReportMonitor(m *TokenMonitor) {
+	reportMonitorMu.Lock()
+	defer reportMonitorMu.Unlock()
+	reportMonitor = m
+}
+
+// currentReportMonitor 返回为报告提供历史快照的监控器，未设置时返回nil
+func currentReportMonitor() *TokenMonitor {
+	reportMonitorMu.RLock()
+	defer reportMonitorMu.RUnlock()
+	return reportMonitor
+}
+
+// SetTopMovers 设置报告中涨跌榜的时间窗口和每个榜单显示的代币数，count<=0 时关闭；
+// 窗口超出历史快照缓冲区时自动扩容
+func (m *TokenMonitor) SetTopMovers(windows []time.Duration, count int) {
+	if count <= 0 {
+		windows = nil
+	}
+
+	m.historyMu.Lock()
+	m.moverWindows = windows
+	m.moverCount = count
+	size := m.priceHistory.Len()
+	m.historyMu.Unlock()
+
+	if needed := historySizeFor(windows, m.shortestInterval()); len(windows) > 0 && needed > size {
+		m.resizeHistory(needed)
+	}
+	setReportMonitor(m)
+}
+
+// TopMovers 根据历史快照计算各时间窗口内价格涨跌最大的代币；找不到窗口起点快照的窗口不返回
+func (m *TokenMonitor) TopMovers(tokens []*TokenData, now time.Time) []MoverWindow {
+	m.historyMu.RLock()
+	windows := m.moverWindows
+	count := m.moverCount
+	m.historyMu.RUnlock()
+
+	var result []MoverWindow
+	for _, window := range windows {
+		old := m.findSnapshotAt(now.Add(-window), m.longestInterval())
+		if old == nil {
+			continue
+		}
+
+		var movers []Mover
+		for _, token := range tokens {
+			if token.Price <= 0 {
+				continue
+			}
+			past, ok := old.TokenData[token.MintAddr]
+			if !ok || past.Price <= 0 {
+				continue
+			}
+			movers = append(movers, Mover{
+				MintAddr:  token.MintAddr,
+				Symbol:    displaySymbol(token),
+				OldPrice:  past.Price,
+				Price:     token.Price,
+				ChangePct: (token.Price - past.Price) / past.Price * 100,
+			})
+		}
+		sort.Slice(movers, func(i, j int) bool {
+			return movers[i].ChangePct > movers[j].ChangePct
+		})
+
+		entry := MoverWindow{Window: window}
+		for i := 0; i < len(movers) && len(entry.Gainers) < count && movers[i].ChangePct > 0; i++ {
+			entry.Gainers = append(entry.Gainers, movers[i])
+		}
+		for i := len(movers) - 1; i >= 0 && len(entry.Losers) < count && movers[i].ChangePct < 0; i-- {
+			entry.Losers = append(entry.Losers, movers[i])
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// generateMoversSection 生成涨跌榜部分，未启用或历史快照不足时返回空字符串
+func generateMoversSection(tokens []*TokenData) string {
+	monitor := currentReportMonitor()
+	if monitor == nil {
+		return ""
+	}
+
+	windows := monitor.TopMovers(tokens, time.Now())
+	if len(windows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T("\n涨跌榜 (Top movers)\n"))
+	sb.WriteString(strings.Repeat("-", 80) + "\n")
+	for _, w := range windows {
+		sb.WriteString(fmt.Sprintf("[%s]\n", formatWindow(w.Window)))
+		if len(w.Gainers) == 0 && len(w.Losers) == 0 {
+			sb.WriteString(i18n.T("  无价格变化\n"))
+			continue
+		}
+		writeMovers(&sb, i18n.T("涨幅"), w.Gainers)
+		writeMovers(&sb, i18n.T("跌幅"), w.Losers)
+	}
+	return sb.String()
+}
+
+// writeMovers 输出一个榜单
+func writeMovers(sb *strings.Builder, title string, movers []Mover) {
+	for i, mover := range movers {
+		label := ""
+		if i == 0 {
+			label = title
+		}
+		symbol := mover.Symbol
+		if len(symbol) > 16 {
+			symbol = symbol[:16]
+		}
+		sb.WriteString(fmt.Sprintf("  %-6s %-16s %+9.2f%%  %14.6f -> %.6f\n",
+			label, symbol, mover.ChangePct, mover.OldPrice, mover.Price))
+	}
+}
+
+// formatWindow 将时间窗口格式化为 5m、1h、24h 这样的简短形式
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,251 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+)
+
+const magicEdenAPIEndpoint = "https://api-mainnet.magiceden.dev/v2"
+
+// NFTHolding 钱包持有的单个NFT
+type NFTHolding struct {
+	MintAddr   string
+	Name       string
+	Collection string // 集合地址（未验证集合的NFT为空）
+	Compressed bool   // 是否为压缩NFT
+	Wallet     string
+}
+
+// NFTCollectionValue 按集合汇总的NFT估值
+type NFTCollectionValue struct {
+	Collection string
+	Symbol     string // Magic Eden 集合符号（未配置时为空，无法估值）
+	Count      int
+	Compressed int     // 其中压缩NFT数量
+	FloorSOL   float64 // 地板价（SOL）
+	ValueUSD   float64 // 数量 * 地板价 * SOL价格
+}
+
+// FetchWalletNFTs 使用DAS API获取钱包持有的NFT和压缩NFT
+func (s *HeliusService) FetchWalletNFTs(ctx context.Context, walletAddr string) ([]*NFTHolding, error) {
+	var nfts []*NFTHolding
+
+	for page := 1; ; page++ {
+		result, err := s.searchAssetsPage(ctx, walletAddr, "nonFungible", page)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			walletLog.Warn("获取NFT分页失败，返回已获取的NFT", "page", page, "fetched", len(nfts), "error", err)
+			return nfts, nil
+		}
+
+		for _, item := range result.Items {
+			nft := &NFTHolding{
+				MintAddr:   item.ID,
+				Name:       item.Content.Metadata.Name,
+				Compressed: item.Compression.Compressed,
+				Wallet:     walletAddr,
+			}
+			for _, group := range item.Grouping {
+				if group.GroupKey == "collection" {
+					nft.Collection = group.GroupValue
+					break
+				}
+			}
+			nfts = append(nfts, nft)
+		}
+
+		if len(nfts) >= maxTokenAccounts {
+			walletLog.Warn("NFT数量达到上限，停止分页", "limit", maxTokenAccounts)
+			return nfts[:maxTokenAccounts], nil
+		}
+		if len(result.Items) < dasPageLimit {
+			break
+		}
+	}
+
+	return nfts, nil
+}
+
+// MagicEdenFloorService 从 Magic Eden 查询集合地板价
+type MagicEdenFloorService struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewMagicEdenFloorService 创建 Magic Eden 地板价服务
+func NewMagicEdenFloorService() *MagicEdenFloorService {
+	return NewMagicEdenFloorServiceWithConfig(magicEdenAPIEndpoint, nil)
+}
+
+// NewMagicEdenFloorServiceWithConfig 使用指定的端点和HTTP客户端创建 Magic Eden 地板价服务，client 为nil时使用共享客户端
+func NewMagicEdenFloorServiceWithConfig(baseURL string, client *http.Client) *MagicEdenFloorService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &MagicEdenFloorService{
+		client:  client,
+		baseURL: baseURL,
+	}
+}
+
+// FloorPrice 返回集合的地板价（SOL）
+func (s *MagicEdenFloorService) FloorPrice(ctx context.Context, symbol string) (float64, error) {
+	url := fmt.Sprintf("%s/collections/%s/stats", s.baseURL, symbol)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Magic Eden 返回状态码 %d", resp.StatusCode)
+	}
+
+	var stats struct {
+		FloorPrice float64 `json:"floorPrice"` // lamports
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return stats.FloorPrice / lamportsPerSOL, nil
+}
+
+// ValueNFTs 按集合汇总NFT并使用地板价估值，collections 为集合地址到 Magic Eden 符号的映射
+func ValueNFTs(ctx context.Context, nfts []*NFTHolding, collections map[string]string, floors *MagicEdenFloorService, solPrice float64) []*NFTCollectionValue {
+	grouped := make(map[string]*NFTCollectionValue)
+	for _, nft := range nfts {
+		value, ok := grouped[nft.Collection]
+		if !ok {
+			value = &NFTCollectionValue{
+				Collection: nft.Collection,
+				Symbol:     collections[nft.Collection],
+			}
+			grouped[nft.Collection] = value
+		}
+		value.Count++
+		if nft.Compressed {
+			value.Compressed++
+		}
+	}
+
+	values := make([]*NFTCollectionValue, 0, len(grouped))
+	for _, value := range grouped {
+		if value.Symbol != "" {
+			floor, err := floors.FloorPrice(ctx, value.Symbol)
+			if err != nil {
+				walletLog.Warn("获取集合地板价失败", "collection", value.Symbol, "error", err)
+			} else {
+				value.FloorSOL = floor
+				value.ValueUSD = float64(value.Count) * floor * solPrice
+			}
+		}
+		values = append(values, value)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].ValueUSD != values[j].ValueUSD {
+			return values[i].ValueUSD > values[j].ValueUSD
+		}
+		return values[i].Count > values[j].Count
+	})
+	return values
+}
+
+var (
+	nftPortfolioMu sync.RWMutex
+	nftPortfolio   []*NFTCollectionValue
+)
+
+// RefreshNFTPortfolio 获取所有钱包的NFT并按地板价估值，结果用于报告中的NFT部分
+func RefreshNFTPortfolio(ctx context.Context, walletAddrs []string, collections map[string]string, tokens []*TokenData) error {
+	helius, err := NewHeliusService()
+	if err != nil {
+		return err
+	}
+
+	var nfts []*NFTHolding
+	for _, addr := range walletAddrs {
+		// 只有 Solana 钱包支持NFT估值
+		if config.DetectChain(addr) != config.ChainSolana {
+			continue
+		}
+		walletNFTs, err := helius.FetchWalletNFTs(ctx, addr)
+		if err != nil {
+			walletLog.Error("获取钱包NFT失败", "wallet", addr, "error", err)
+			continue
+		}
+		nfts = append(nfts, walletNFTs...)
+	}
+
+	var solPrice float64
+	for _, token := range tokens {
+		if token.MintAddr == nativeSOLMint {
+			solPrice = token.Price
+			break
+		}
+	}
+
+	values := ValueNFTs(ctx, nfts, collections, NewMagicEdenFloorService(), solPrice)
+
+	nftPortfolioMu.Lock()
+	nftPortfolio = values
+	nftPortfolioMu.Unlock()
+
+	walletLog.Info("NFT估值完成", "nfts", len(nfts), "collections", len(values))
+	return nil
+}
+
+// generateNFTSection 生成NFT估值报告段落，未启用NFT时为空
+func generateNFTSection() string {
+	nftPortfolioMu.RLock()
+	values := nftPortfolio
+	nftPortfolioMu.RUnlock()
+	if len(values) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	var total float64
+	var unvalued int
+	sb.WriteString(fmt.Sprintf("\n%-46s %6s %8s %12s %14s\n", i18n.T("NFT集合"), i18n.T("数量"), i18n.T("压缩"), i18n.T("地板价(SOL)"), i18n.T("估值")))
+	sb.WriteString(strings.Repeat("-", 90) + "\n")
+	for _, value := range values {
+		name := value.Symbol
+		if name == "" {
+			name = value.Collection
+		}
+		if name == "" {
+			name = i18n.T("(无集合)")
+		}
+		valueText := "N/A"
+		if value.ValueUSD > 0 {
+			valueText = fmt.Sprintf("%.2f", value.ValueUSD)
+			total += value.ValueUSD
+		} else {
+			unvalued += value.Count
+		}
+		sb.WriteString(fmt.Sprintf("%-46s %6d %8d %12.4f %14s\n",
+			name, value.Count, value.Compressed, value.FloorSOL, valueText))
+	}
+	sb.WriteString(i18n.Sprintf("NFT估值: $%.2f", total))
+	if unvalued > 0 {
+		sb.WriteString(i18n.Sprintf(" (%d 个NFT无地板价)", unvalued))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,364 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
+)
+
+// AlertType 报警类型
+type AlertType string
+
+const (
+	AlertTypePrice           AlertType = "price"            // 单币价格变化
+	AlertTypeValue           AlertType = "value"            // 单币价值变化（持仓数量不变，仅由价格引起）
+	AlertTypeAmount          AlertType = "amount"           // 单币持仓数量变化（转入、转出或兑换）
+	AlertTypePortfolio       AlertType = "portfolio"        // 组合总价值变化
+	AlertTypeDivergence      AlertType = "divergence"       // 价格数据源偏离
+	AlertTypeNewToken        AlertType = "new_token"        // 钱包买入新代币
+	AlertTypePositionReduced AlertType = "position_reduced" // 钱包减仓或清仓
+	AlertTypeActivity        AlertType = "activity"         // 钱包链上交易活动
+	AlertTypeLiquidity       AlertType = "liquidity"        // 持仓代币流动性过低或骤降
+	AlertTypeRisk            AlertType = "risk"             // 持仓中发现高风险代币（可增发、可冻结、LP未锁定等）
+	AlertTypeSummary         AlertType = "summary"          // 每日/每周汇总
+	AlertTypeDepeg           AlertType = "depeg"            // 稳定币市场价格偏离 $1
+	AlertTypePegRestored     AlertType = "peg_restored"     // 稳定币恢复锚定
+	AlertTypePriceTarget     AlertType = "price_target"     // 代币价格达到配置的绝对价格目标
+	AlertTypeTrailingStop    AlertType = "trailing_stop"    // 代币价格从开始监控以来的最高价回撤
+	AlertTypeRule            AlertType = "rule"             // 自定义报警规则触发
+	AlertTypeDigest          AlertType = "digest"           // 静默时段结束后发送的报警汇总
+	AlertTypeDegraded        AlertType = "degraded"         // 部分钱包获取失败，组合数据不完整
+	AlertTypeLiquidation     AlertType = "liquidation"      // 永续合约仓位接近强平价
+	AlertTypeImpermanentLoss AlertType = "impermanent_loss" // LP仓位相对持有不动的损失超过阈值
+	AlertTypeAirdrop         AlertType = "airdrop"          // 钱包收到未经买入的有价值代币
+)
+
+// notifyTimeout 单个通知渠道的发送超时
+const notifyTimeout = 10 * time.Second
+
+// Alert 报警事件
+type Alert struct {
+	Type      AlertType
+	Wallet    string        // 相关钱包地址（聚合报警为空）
+	MintAddr  string        // 相关代币mint地址（组合报警为空）
+	Chain     string        // 代币所在的链，为空时使用当前持仓中记录的链或根据地址推断
+	Signature string        // 相关交易签名（活动报警）
+	Rule      string        // 触发的自定义规则名称（规则报警）
+	Notify    []string      // 只发送到这些名称的通知渠道，为空时发送到所有渠道
+	Severity  AlertSeverity // 报警级别，为空时按类型和变化幅度确定
+	Symbol    string        // 代币符号
+	Window    time.Duration // 时间窗口
+	ChangePct float64       // 变化率（%）
+	OldValue  float64       // 窗口起点的价格/价值
+	NewValue  float64       // 当前价格/价值
+	Message   string        // 格式化后的报警文本
+	Timestamp time.Time
+}
+
+// alertTitles 各类报警在通知中的标题
+var alertTitles = map[AlertType]string{
+	AlertTypePrice:           "代币价格报警",
+	AlertTypeValue:           "代币价值报警",
+	AlertTypeAmount:          "持仓数量变化",
+	AlertTypePortfolio:       "组合价值报警",
+	AlertTypeDivergence:      "数据源偏离报警",
+	AlertTypeNewToken:        "新代币买入",
+	AlertTypePositionReduced: "持仓减少",
+	AlertTypeActivity:        "钱包交易活动",
+	AlertTypeLiquidity:       "流动性报警",
+	AlertTypeRisk:            "高风险代币",
+	AlertTypeSummary:         "组合汇总",
+	AlertTypeDepeg:           "稳定币脱锚",
+	AlertTypePegRestored:     "稳定币恢复锚定",
+	AlertTypePriceTarget:     "价格目标",
+	AlertTypeTrailingStop:    "回撤报警",
+	AlertTypeRule:            "规则报警",
+	AlertTypeDigest:          "静默时段报警汇总",
+	AlertTypeDegraded:        "数据不完整",
+	AlertTypeLiquidation:     "强平风险",
+	AlertTypeImpermanentLoss: "无常损失报警",
+	AlertTypeAirdrop:         "疑似空投",
+}
+
+// alertTitle 返回报警的标题，包含代币符号
+func alertTitle(alert Alert) string {
+	title, ok := alertTitles[alert.Type]
+	if !ok {
+		title = string(alert.Type)
+	}
+	title = i18n.T(title)
+	if alert.Symbol != "" {
+		title += " - " + alert.Symbol
+	}
+	return title
+}
+
+// tokenExplorers 各链的代币区块浏览器链接前缀
+var tokenExplorers = map[string]string{
+	config.ChainSolana:   "https://solscan.io/token/",
+	config.ChainEthereum: "https://etherscan.io/token/",
+	config.ChainBase:     "https://basescan.org/token/",
+}
+
+// tokenURL 返回代币在所在链的区块浏览器上的链接，chain 为空时根据地址推断；交易所资产和未知的链没有链接
+func tokenURL(chain, mintAddr string) string {
+	if mintAddr == "" {
+		return ""
+	}
+	if chain == "" {
+		chain = config.DetectChain(mintAddr)
+	}
+	if prefix, ok := tokenExplorers[chain]; ok {
+		return prefix + mintAddr
+	}
+	return ""
+}
+
+// Notifier 报警通知渠道
+type Notifier interface {
+	// Notify 发送一条报警
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierRegistry 管理全局和按钱包配置的通知渠道
+type NotifierRegistry struct {
+	mu        sync.RWMutex
+	global    []Notifier
+	names     []string // 与 global 一一对应的渠道名称，未命名为空
+	perWallet map[string][]Notifier
+}
+
+// NewNotifierRegistry 创建通知渠道注册表
+func NewNotifierRegistry() *NotifierRegistry {
+	return &NotifierRegistry{
+		perWallet: make(map[string][]Notifier),
+	}
+}
+
+// Register 注册全局通知渠道，接收所有报警
+func (r *NotifierRegistry) Register(n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global = append(r.global, n)
+	r.names = append(r.names, "")
+}
+
+// RegisterNamed 注册带名称的全局通知渠道，报警规则可按名称指定发送渠道
+func (r *NotifierRegistry) RegisterNamed(name string, n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.global = append(r.global, n)
+	r.names = append(r.names, name)
+}
+
+// RegisterForWallet 注册只接收指定钱包报警的通知渠道
+func (r *NotifierRegistry) RegisterForWallet(wallet string, n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.perWallet[wallet] = append(r.perWallet[wallet], n)
+}
+
+// Notifiers 返回应接收该钱包报警的通知渠道（全局 + 钱包专属）
+func (r *NotifierRegistry) Notifiers(wallet string) []Notifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	notifiers := make([]Notifier, 0, len(r.global)+len(r.perWallet[wallet]))
+	notifiers = append(notifiers, r.global...)
+	if wallet != "" {
+		notifiers = append(notifiers, r.perWallet[wallet]...)
+	}
+	return notifiers
+}
+
+// routed 返回报警指定名称的通知渠道
+func (r *NotifierRegistry) routed(names []string) []Notifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var notifiers []Notifier
+	for i, n := range r.global {
+		for _, name := range names {
+			if r.names[i] != "" && r.names[i] == name {
+				notifiers = append(notifiers, n)
+				break
+			}
+		}
+	}
+	return notifiers
+}
+
+// Dispatch 将报警并发发送到所有匹配的通知渠道，发送失败只记录日志；报警指定了渠道名称时只发送到这些渠道
+func (r *NotifierRegistry) Dispatch(ctx context.Context, alert Alert) {
+	notifiers := r.Notifiers(alert.Wallet)
+	if len(alert.Notify) > 0 {
+		notifiers = r.routed(alert.Notify)
+	}
+	for _, n := range notifiers {
+		go func(n Notifier) {
+			notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			defer cancel()
+			if err := n.Notify(notifyCtx, alert); err != nil {
+				notifyLog.Error("发送报警通知失败", "notifier", fmt.Sprintf("%T", n), "error", err)
+			}
+		}(n)
+	}
+}
+
+// Notifiers 返回监控器的通知渠道注册表
+func (m *TokenMonitor) Notifiers() *NotifierRegistry {
+	return m.notifiers
+}
+
+// alertDeduper 按 (类型, 钱包, 代币, 窗口, 方向) 对报警去重，冷却期内的重复报警被抑制
+type alertDeduper struct {
+	mu       sync.Mutex
+	cooldown time.Duration
+	lastSent map[string]time.Time
+}
+
+// newAlertDeduper 创建报警去重器，cooldown<=0 表示不去重
+func newAlertDeduper(cooldown time.Duration) *alertDeduper {
+	return &alertDeduper{
+		cooldown: cooldown,
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// alertKey 生成报警去重键
+func alertKey(alert Alert) string {
+	// 每笔交易的活动报警互不相同
+	if alert.Signature != "" {
+		return fmt.Sprintf("%s|%s|%s", alert.Type, alert.Wallet, alert.Signature)
+	}
+	// 不同规则的报警互不影响
+	if alert.Rule != "" {
+		return fmt.Sprintf("%s|%s|%s", alert.Type, alert.Rule, alert.MintAddr)
+	}
+	direction := "up"
+	if alert.ChangePct < 0 {
+		direction = "down"
+	}
+	// 没有 mint 地址的报警（如永续合约仓位）按符号区分
+	subject := alert.MintAddr
+	if subject == "" {
+		subject = alert.Symbol
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s", alert.Type, alert.Wallet, subject, alert.Window, direction)
+}
+
+// allow 判断报警是否应当发送，发送时记录时间
+func (d *alertDeduper) allow(alert Alert) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cooldown <= 0 {
+		return true
+	}
+
+	key := alertKey(alert)
+	if last, ok := d.lastSent[key]; ok && alert.Timestamp.Sub(last) < d.cooldown {
+		return false
+	}
+	d.lastSent[key] = alert.Timestamp
+
+	// 清理已过冷却期的记录
+	for k, t := range d.lastSent {
+		if alert.Timestamp.Sub(t) >= d.cooldown {
+			delete(d.lastSent, k)
+		}
+	}
+	return true
+}
+
+// window 返回当前的抑制时长
+func (d *alertDeduper) window() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cooldown
+}
+
+// setCooldown 修改抑制时长，保留已记录的发送时间
+func (d *alertDeduper) setCooldown(cooldown time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cooldown = cooldown
+}
+
+// SetAlertCooldown 设置同一报警（代币、窗口、方向相同）的抑制时长，0表示不抑制，运行中可重复调用
+func (m *TokenMonitor) SetAlertCooldown(cooldown time.Duration) {
+	m.deduper.setCooldown(cooldown)
+}
+
+// AlertCoordinator 在多个实例之间协调报警，同一报警只由一个实例发送
+type AlertCoordinator interface {
+	// ClaimAlert 尝试取得在 ttl 内发送 key 对应报警的权利，其他实例已取得时返回 false
+	ClaimAlert(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// SetAlertCoordinator 设置多实例报警去重，需在 Start 之前调用
+func (m *TokenMonitor) SetAlertCoordinator(coordinator AlertCoordinator) {
+	m.coordinator = coordinator
+}
+
+// claimAlert 判断本实例是否应当发送报警：抑制时长内同一报警只由最先触发的实例发送，
+// 未设置抑制时长时在一个监控间隔内去重；协调失败时照常发送
+func (m *TokenMonitor) claimAlert(alert Alert) bool {
+	if m.coordinator == nil {
+		return true
+	}
+	ttl := m.deduper.window()
+	if ttl <= 0 {
+		ttl = m.interval
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	claimed, err := m.coordinator.ClaimAlert(ctx, alertKey(alert), ttl)
+	if err != nil {
+		notifyLog.Warn("多实例报警去重失败，照常发送", "error", err)
+		return true
+	}
+	return claimed
+}
+
+// tokenChain 返回当前监控的代币所在的链，未记录时为空
+func (m *TokenMonitor) tokenChain(mintAddr string) string {
+	for _, token := range m.Tokens() {
+		if token.MintAddr == mintAddr {
+			return token.Chain
+		}
+	}
+	return ""
+}
+
+// emitAlert 写入报警日志并分发到所有通知渠道
+func (m *TokenMonitor) emitAlert(alert Alert) {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now()
+	}
+	if alert.Chain == "" && alert.MintAddr != "" {
+		alert.Chain = m.tokenChain(alert.MintAddr)
+	}
+
+	if !m.deduper.allow(alert) {
+		notifyLog.Debug("报警处于冷却期，已抑制", "key", alertKey(alert))
+		return
+	}
+
+	m.writeAlertLog(alert.Message)
+	notifyLog.Log(context.Background(), logging.LevelAlert, alert.Message,
+		"type", alert.Type, "mint", alert.MintAddr, "wallet", alert.Wallet)
+
+	if !m.claimAlert(alert) {
+		notifyLog.Debug("报警已由其他实例发送", "key", alertKey(alert))
+		return
+	}
+
+	// 静默时段内的非 critical 报警排队，结束后合并发送
+	if m.notifiers != nil && m.route(&alert) {
+		m.notifiers.Dispatch(m.ctx, alert)
+	}
+}
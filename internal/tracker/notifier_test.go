@@ -0,0 +1,71 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wallet-tracker/config"
+)
+
+func TestTokenURL(t *testing.T) {
+	const (
+		solMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+		evmMint = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	)
+
+	tests := []struct {
+		name  string
+		chain string
+		mint  string
+		want  string
+	}{
+		{name: "Solana代币", mint: solMint, want: "https://solscan.io/token/" + solMint},
+		{name: "未设置链的EVM代币按以太坊处理", mint: evmMint, want: "https://etherscan.io/token/" + evmMint},
+		{name: "以太坊代币", chain: config.ChainEthereum, mint: evmMint, want: "https://etherscan.io/token/" + evmMint},
+		{name: "Base代币", chain: config.ChainBase, mint: evmMint, want: "https://basescan.org/token/" + evmMint},
+		{name: "交易所资产没有链接", mint: "binance"},
+		{name: "未知的链没有链接", chain: "polygon", mint: evmMint},
+		{name: "没有代币", chain: config.ChainBase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenURL(tt.chain, tt.mint); got != tt.want {
+				t.Errorf("tokenURL(%q, %q) = %q, 期望 %q", tt.chain, tt.mint, got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingNotifier 记录收到的报警
+type recordingNotifier struct {
+	alerts chan Alert
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alert Alert) error {
+	r.alerts <- alert
+	return nil
+}
+
+func TestEmitAlertFillsChain(t *testing.T) {
+	const baseMint = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	m := newTestMonitor(t, time.Minute)
+	m.UpdateTokens([]*TokenData{{MintAddr: baseMint, Chain: config.ChainBase, Symbol: "USDC"}})
+	recorder := &recordingNotifier{alerts: make(chan Alert, 1)}
+	m.Notifiers().Register(recorder)
+
+	m.emitAlert(Alert{Type: AlertTypePrice, MintAddr: baseMint, Symbol: "USDC", Message: "test"})
+
+	select {
+	case alert := <-recorder.alerts:
+		if alert.Chain != config.ChainBase {
+			t.Errorf("报警的链 = %q, 期望 %q", alert.Chain, config.ChainBase)
+		}
+		if got := tokenURL(alert.Chain, alert.MintAddr); got != "https://basescan.org/token/"+baseMint {
+			t.Errorf("报警的链接 = %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到报警")
+	}
+}
@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxCandles 单次查询最多返回的K线数量
+const maxCandles = 10000
+
+// candleIntervals 支持的K线周期
+var candleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// Candle 组合价值在一个时间桶内的 OHLC 数据
+type Candle struct {
+	Time    time.Time `json:"time"` // 时间桶起点（UTC对齐）
+	Open    float64   `json:"open"`
+	High    float64   `json:"high"`
+	Low     float64   `json:"low"`
+	Close   float64   `json:"close"`
+	Samples int       `json:"samples"` // 桶内的快照数
+}
+
+// ParseCandleInterval 解析K线周期：1m/5m/1h/1d
+func ParseCandleInterval(s string) (time.Duration, error) {
+	interval, ok := candleIntervals[s]
+	if !ok {
+		return 0, fmt.Errorf("不支持的K线周期: %s（可选 1m/5m/1h/1d）", s)
+	}
+	return interval, nil
+}
+
+// BucketCandles 将按时间升序的价值序列按 interval 聚合为K线，没有快照的时间桶不输出
+func BucketCandles(points []ValuePoint, interval time.Duration) []Candle {
+	var candles []Candle
+	for _, p := range points {
+		bucket := p.Timestamp.UTC().Truncate(interval)
+		if n := len(candles); n > 0 && candles[n-1].Time.Equal(bucket) {
+			c := &candles[n-1]
+			if p.Value > c.High {
+				c.High = p.Value
+			}
+			if p.Value < c.Low {
+				c.Low = p.Value
+			}
+			c.Close = p.Value
+			c.Samples++
+			continue
+		}
+		candles = append(candles, Candle{
+			Time:    bucket,
+			Open:    p.Value,
+			High:    p.Value,
+			Low:     p.Value,
+			Close:   p.Value,
+			Samples: 1,
+		})
+	}
+	return candles
+}
+
+// PortfolioCandles 从持久化存储查询 [since, until] 内组合价值的K线
+func PortfolioCandles(ctx context.Context, store SnapshotStore, interval time.Duration, since, until time.Time) ([]Candle, error) {
+	if err := checkCandleRange(interval, since, until); err != nil {
+		return nil, err
+	}
+	records, err := store.PortfolioHistory(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ValuePoint, 0, len(records))
+	for _, r := range records {
+		points = append(points, ValuePoint{Timestamp: r.Timestamp, Value: r.TotalValue})
+	}
+	return BucketCandles(points, interval), nil
+}
+
+// ValueCandles 返回 [since, until] 内组合价值的K线：优先使用持久化存储，否则使用内存中的快照
+func (m *TokenMonitor) ValueCandles(ctx context.Context, interval time.Duration, since, until time.Time) ([]Candle, error) {
+	if m.store != nil {
+		return PortfolioCandles(ctx, m.store, interval, since, until)
+	}
+	if err := checkCandleRange(interval, since, until); err != nil {
+		return nil, err
+	}
+
+	var points []ValuePoint
+	for _, snapshot := range m.snapshotsSince(since) {
+		if snapshot.Timestamp.After(until) {
+			break
+		}
+		points = append(points, ValuePoint{Timestamp: snapshot.Timestamp, Value: snapshot.Value})
+	}
+	return BucketCandles(points, interval), nil
+}
+
+// checkCandleRange 校验查询范围，避免一次返回过多K线
+func checkCandleRange(interval time.Duration, since, until time.Time) error {
+	if interval <= 0 {
+		return fmt.Errorf("K线周期必须为正数: %v", interval)
+	}
+	if !until.After(since) {
+		return fmt.Errorf("查询范围无效: %s ~ %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	if until.Sub(since)/interval > maxCandles {
+		return fmt.Errorf("查询范围过大，最多返回 %d 根K线", maxCandles)
+	}
+	return nil
+}
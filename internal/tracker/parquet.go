@@ -0,0 +1,183 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRow Parquet 快照文件中的一行：某次快照时某个钱包持有的一种代币
+type parquetRow struct {
+	Timestamp  time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	Wallet     string    `parquet:"wallet,dict"` // 钱包地址，没有钱包明细时为空
+	Label      string    `parquet:"label,dict"`  // 钱包标签
+	Mint       string    `parquet:"mint,dict"`
+	Symbol     string    `parquet:"symbol,dict"`
+	Price      float64   `parquet:"price"`
+	Amount     float64   `parquet:"amount"`
+	Value      float64   `parquet:"value"`
+	Confidence string    `parquet:"confidence,dict"` // 价格可信度: high/medium/low
+}
+
+// ParquetExporter 订阅监控器的快照，按天写入 Parquet 文件（snapshots-2006-01-02.parquet），便于用 DuckDB/pandas 分析长期数据。
+// 写入中的文件带 .tmp 后缀，跨天或退出时写入文件尾并去掉后缀，读取 *.parquet 时不会读到未完成的文件
+type ParquetExporter struct {
+	dir           string
+	flushInterval time.Duration
+	day           time.Time // 当前文件的日期（本地时间零点）
+	path          string    // 当前文件完成后的路径
+	file          *os.File
+	writer        *parquet.GenericWriter[parquetRow]
+	done          chan struct{}
+}
+
+// NewParquetExporter 创建 Parquet 导出器，flushInterval 为将缓存的行写入文件的间隔
+func NewParquetExporter(dir string, flushInterval time.Duration) (*ParquetExporter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建Parquet目录失败: %v", err)
+	}
+	// 上次异常退出时未写入文件尾的文件无法读取
+	if partial, _ := filepath.Glob(filepath.Join(dir, "*.parquet.tmp")); len(partial) > 0 {
+		exportLog.Warn("发现未完成的Parquet文件（上次异常退出），其中的数据无法读取", "files", partial)
+	}
+	return &ParquetExporter{
+		dir:           dir,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}, nil
+}
+
+// Run 订阅快照并在后台写入，直到 ctx 结束；结束时完成当前文件
+func (e *ParquetExporter) Run(ctx context.Context, monitor *TokenMonitor) {
+	events, unsubscribe := monitor.SubscribeSnapshots()
+	go func() {
+		defer close(e.done)
+		defer unsubscribe()
+		ticker := time.NewTicker(e.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := e.finish(); err != nil {
+					exportLog.Error("完成Parquet文件失败", "file", e.path, "error", err)
+				}
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := e.write(event); err != nil {
+					exportLog.Error("写入Parquet文件失败", "file", e.path, "error", err)
+				}
+			case <-ticker.C:
+				if e.writer != nil {
+					if err := e.writer.Flush(); err != nil {
+						exportLog.Error("写入Parquet文件失败", "file", e.path, "error", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Close 等待当前文件完成，需在 Run 的 ctx 结束后调用
+func (e *ParquetExporter) Close() {
+	<-e.done
+}
+
+// write 写入一次快照，日期变化时先完成前一天的文件
+func (e *ParquetExporter) write(event SnapshotEvent) error {
+	local := event.Timestamp.Local()
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if e.writer != nil && !day.Equal(e.day) {
+		if err := e.finish(); err != nil {
+			return err
+		}
+	}
+	if e.writer == nil {
+		if err := e.open(day); err != nil {
+			return err
+		}
+	}
+	_, err := e.writer.Write(parquetRows(event))
+	return err
+}
+
+// open 创建当天的文件；同一天重启时已完成的文件不能追加，依次使用 -1、-2 等后缀
+func (e *ParquetExporter) open(day time.Time) error {
+	base := "snapshots-" + day.Format("2006-01-02")
+	path := filepath.Join(e.dir, base+".parquet")
+	for i := 1; fileExists(path) || fileExists(path+".tmp"); i++ {
+		path = filepath.Join(e.dir, fmt.Sprintf("%s-%d.parquet", base, i))
+	}
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return fmt.Errorf("创建Parquet文件失败: %v", err)
+	}
+	e.file = file
+	e.path = path
+	e.day = day
+	e.writer = parquet.NewGenericWriter[parquetRow](file, parquet.Compression(&parquet.Zstd))
+	exportLog.Info("开始写入Parquet文件", "file", path)
+	return nil
+}
+
+// finish 写入文件尾并去掉 .tmp 后缀
+func (e *ParquetExporter) finish() error {
+	if e.writer == nil {
+		return nil
+	}
+	writer, file := e.writer, e.file
+	e.writer, e.file = nil, nil
+
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return fmt.Errorf("写入文件尾失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("关闭文件失败: %v", err)
+	}
+	if err := os.Rename(file.Name(), e.path); err != nil {
+		return fmt.Errorf("重命名文件失败: %v", err)
+	}
+	return nil
+}
+
+// parquetRows 将快照展开为按钱包拆分的行；没有钱包明细的代币输出一行，钱包为空
+func parquetRows(event SnapshotEvent) []parquetRow {
+	rows := make([]parquetRow, 0, len(event.Tokens))
+	for _, token := range event.Tokens {
+		row := parquetRow{
+			Timestamp:  event.Timestamp,
+			Mint:       token.Mint,
+			Symbol:     token.Symbol,
+			Price:      token.Price,
+			Amount:     token.Amount,
+			Value:      token.Value,
+			Confidence: token.ConfidenceLevel,
+		}
+		if len(token.Holders) == 0 {
+			rows = append(rows, row)
+			continue
+		}
+		for _, holder := range token.Holders {
+			row.Wallet = holder.Wallet
+			row.Label = holder.Label
+			row.Amount = holder.Amount
+			row.Value = holder.Value
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
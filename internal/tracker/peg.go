@@ -0,0 +1,111 @@
+package tracker
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// StablecoinConfig 稳定币设置：按 $1 估值，同时监控实际市场价格是否脱锚
+type StablecoinConfig struct {
+	Mints           []string // 视为稳定币的 mint 地址
+	PegBand         float64  // 市场价格偏离 $1 超过该比例（%）时报警，<=0 表示不报警
+	MarketValuation bool     // 按实际市场价格估值，而不是按 $1
+}
+
+var (
+	stablecoinMu  sync.RWMutex
+	stablecoinCfg StablecoinConfig
+	stablecoinSet = make(map[string]bool) // 配置的稳定币 mint 地址
+)
+
+// SetStablecoinConfig 设置稳定币列表和脱锚报警范围，运行中可重复调用
+func SetStablecoinConfig(cfg StablecoinConfig) {
+	mints := make(map[string]bool, len(cfg.Mints))
+	for _, mint := range cfg.Mints {
+		mints[mint] = true
+	}
+	stablecoinMu.Lock()
+	defer stablecoinMu.Unlock()
+	stablecoinCfg = cfg
+	stablecoinSet = mints
+}
+
+// configuredStablecoin 判断 mint 是否为配置的稳定币
+func configuredStablecoin(mintAddr string) bool {
+	stablecoinMu.RLock()
+	defer stablecoinMu.RUnlock()
+	return stablecoinSet[mintAddr]
+}
+
+func currentStablecoinConfig() StablecoinConfig {
+	stablecoinMu.RLock()
+	defer stablecoinMu.RUnlock()
+	return stablecoinCfg
+}
+
+// applyStablecoinPrice 记录稳定币的实际市场价格，未开启按市价估值时按 $1 计价
+func applyStablecoinPrice(token *TokenData, cfg StablecoinConfig) {
+	if !configuredStablecoin(token.MintAddr) {
+		return
+	}
+	token.MarketPrice = token.Price
+	if !cfg.MarketValuation {
+		token.Price = 1
+	}
+}
+
+// pegWatch 记录已脱锚的稳定币，脱锚和恢复各报警一次
+type pegWatch struct {
+	mu       sync.Mutex
+	depegged map[string]bool
+}
+
+// checkPeg 检查持仓稳定币的市场价格，偏离 $1 超出范围时报警，恢复后再次通知
+func (m *TokenMonitor) checkPeg(tokens []*TokenData, band float64) {
+	if band <= 0 {
+		return
+	}
+
+	m.peg.mu.Lock()
+	defer m.peg.mu.Unlock()
+	if m.peg.depegged == nil {
+		m.peg.depegged = make(map[string]bool)
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		if token.MarketPrice <= 0 {
+			continue
+		}
+		deviation := (token.MarketPrice - 1) * 100
+		depegged := math.Abs(deviation) > band
+		if depegged == m.peg.depegged[token.MintAddr] {
+			continue
+		}
+		m.peg.depegged[token.MintAddr] = depegged
+
+		alertType := AlertTypeDepeg
+		var message string
+		if depegged {
+			message = i18n.Sprintf("稳定币脱锚 - %s (%s) 市场价格 $%.4f，偏离 %+.2f%% 超出 ±%.2f%%，持仓 %.2f",
+				displaySymbol(token), token.MintAddr, token.MarketPrice, deviation, band, token.Amount)
+		} else {
+			alertType = AlertTypePegRestored
+			message = i18n.Sprintf("稳定币恢复锚定 - %s (%s) 市场价格 $%.4f，偏离 %+.2f%%",
+				displaySymbol(token), token.MintAddr, token.MarketPrice, deviation)
+		}
+		m.emitAlert(Alert{
+			Type:      alertType,
+			MintAddr:  token.MintAddr,
+			Symbol:    token.Symbol,
+			ChangePct: deviation,
+			OldValue:  1,
+			NewValue:  token.MarketPrice,
+			Message:   message,
+			Timestamp: now,
+		})
+	}
+}
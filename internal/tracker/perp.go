@@ -0,0 +1,293 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+const hyperliquidAPIEndpoint = "https://api.hyperliquid.xyz"
+
+// PerpPosition 永续合约账户中的一个未平仓仓位
+type PerpPosition struct {
+	Account          string
+	Coin             string
+	Size             float64 // 合约数量，空头为负数
+	EntryPrice       float64
+	MarkPrice        float64
+	LiquidationPrice float64 // 强平价，0表示没有强平价（保证金充足的全仓仓位）
+	PositionValue    float64 // 按标记价格计算的名义价值（美元）
+	UnrealizedPnL    float64
+	Leverage         float64
+}
+
+// Side 返回仓位方向
+func (p *PerpPosition) Side() string {
+	if p.Size < 0 {
+		return i18n.T("空")
+	}
+	return i18n.T("多")
+}
+
+// LiquidationDistance 返回标记价格距强平价的比例（%），没有强平价时返回 false
+func (p *PerpPosition) LiquidationDistance() (float64, bool) {
+	if p.LiquidationPrice <= 0 || p.MarkPrice <= 0 {
+		return 0, false
+	}
+	if p.Size < 0 {
+		return (p.LiquidationPrice - p.MarkPrice) / p.MarkPrice * 100, true
+	}
+	return (p.MarkPrice - p.LiquidationPrice) / p.MarkPrice * 100, true
+}
+
+// PerpAccount 永续合约账户的仓位和权益
+type PerpAccount struct {
+	Account   string
+	Value     float64 // 账户权益（美元）
+	Positions []*PerpPosition
+}
+
+// HyperliquidService 通过 Hyperliquid info 接口查询账户的永续合约仓位，无需API密钥
+type HyperliquidService struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewHyperliquidService 创建 Hyperliquid 服务
+func NewHyperliquidService() *HyperliquidService {
+	return NewHyperliquidServiceWithConfig(hyperliquidAPIEndpoint, nil)
+}
+
+// NewHyperliquidServiceWithConfig 使用指定的端点和HTTP客户端创建 Hyperliquid 服务，client 为nil时使用共享客户端
+func NewHyperliquidServiceWithConfig(baseURL string, client *http.Client) *HyperliquidService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	return &HyperliquidService{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// FetchAccount 获取账户的权益和所有未平仓仓位
+func (s *HyperliquidService) FetchAccount(ctx context.Context, account string) (*PerpAccount, error) {
+	jsonData, _ := json.Marshal(map[string]string{"type": "clearinghouseState", "user": account})
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/info", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	observeSourceResponse("hyperliquid", resp, err)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Hyperliquid 返回状态码 %d", resp.StatusCode)
+	}
+
+	var state struct {
+		AssetPositions []struct {
+			Position struct {
+				Coin          string  `json:"coin"`
+				Size          string  `json:"szi"`
+				EntryPrice    string  `json:"entryPx"`
+				Liquidation   *string `json:"liquidationPx"`
+				PositionValue string  `json:"positionValue"`
+				UnrealizedPnL string  `json:"unrealizedPnl"`
+				Leverage      struct {
+					Value float64 `json:"value"`
+				} `json:"leverage"`
+			} `json:"position"`
+		} `json:"assetPositions"`
+		MarginSummary struct {
+			AccountValue string `json:"accountValue"`
+		} `json:"marginSummary"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	result := &PerpAccount{Account: account, Value: parseNumber(state.MarginSummary.AccountValue)}
+	for _, item := range state.AssetPositions {
+		p := item.Position
+		size := parseNumber(p.Size)
+		if size == 0 {
+			continue
+		}
+		position := &PerpPosition{
+			Account:       account,
+			Coin:          p.Coin,
+			Size:          size,
+			EntryPrice:    parseNumber(p.EntryPrice),
+			PositionValue: parseNumber(p.PositionValue),
+			UnrealizedPnL: parseNumber(p.UnrealizedPnL),
+			Leverage:      p.Leverage.Value,
+		}
+		// 接口不直接返回标记价格，由名义价值折算
+		position.MarkPrice = position.PositionValue / math.Abs(size)
+		if p.Liquidation != nil {
+			position.LiquidationPrice = parseNumber(*p.Liquidation)
+		}
+		result.Positions = append(result.Positions, position)
+	}
+	return result, nil
+}
+
+var (
+	perpAccountsMu sync.RWMutex
+	perpAccounts   []*PerpAccount
+)
+
+// RefreshPerpPositions 获取各账户的永续合约仓位，结果用于报告中的合约部分；monitor 不为nil时检查强平距离
+func RefreshPerpPositions(ctx context.Context, accounts []string, monitor *TokenMonitor) error {
+	if len(accounts) == 0 {
+		perpAccountsMu.Lock()
+		perpAccounts = nil
+		perpAccountsMu.Unlock()
+		return nil
+	}
+	service := NewHyperliquidService()
+
+	var results []*PerpAccount
+	var lastErr error
+	for _, account := range accounts {
+		result, err := service.FetchAccount(ctx, account)
+		if err != nil {
+			walletLog.Error("获取合约仓位失败", "account", account, "error", err)
+			lastErr = err
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	perpAccountsMu.Lock()
+	perpAccounts = results
+	perpAccountsMu.Unlock()
+
+	var positions []*PerpPosition
+	for _, result := range results {
+		positions = append(positions, result.Positions...)
+	}
+	if monitor != nil {
+		monitor.checkLiquidation(positions)
+	}
+	walletLog.Info("获取合约仓位完成", "accounts", len(results), "positions", len(positions))
+	return nil
+}
+
+// liquidationWatch 记录已报警的接近强平的仓位
+type liquidationWatch struct {
+	mu      sync.Mutex
+	pct     float64         // 标记价格距强平价小于该比例（%）时报警，<=0 表示关闭
+	alerted map[string]bool // 账户|合约 -> 已报警，距离恢复到阈值以上后重新生效
+}
+
+// SetLiquidationAlert 设置强平报警：永续合约标记价格距强平价小于 pct(%) 时报警，<=0 表示关闭
+func (m *TokenMonitor) SetLiquidationAlert(pct float64) {
+	m.liquidation.mu.Lock()
+	defer m.liquidation.mu.Unlock()
+	m.liquidation.pct = pct
+}
+
+// checkLiquidation 检查各仓位距强平价的比例，进入阈值时报警一次
+func (m *TokenMonitor) checkLiquidation(positions []*PerpPosition) {
+	m.liquidation.mu.Lock()
+	defer m.liquidation.mu.Unlock()
+
+	if m.liquidation.pct <= 0 {
+		return
+	}
+	previous := m.liquidation.alerted
+	m.liquidation.alerted = make(map[string]bool)
+
+	now := time.Now()
+	for _, p := range positions {
+		distance, ok := p.LiquidationDistance()
+		if !ok || distance >= m.liquidation.pct {
+			continue
+		}
+		key := p.Account + "|" + p.Coin
+		m.liquidation.alerted[key] = true
+		if previous[key] {
+			continue
+		}
+
+		m.emitAlert(Alert{
+			Type:      AlertTypeLiquidation,
+			Wallet:    p.Account,
+			Symbol:    p.Coin,
+			ChangePct: -distance,
+			OldValue:  p.LiquidationPrice,
+			NewValue:  p.MarkPrice,
+			Message: i18n.Sprintf("强平风险 - 账户 %s 的 %s %s仓 标记价格 $%.4f 距强平价 $%.4f 仅 %.2f%%（阈值 %.2f%%），仓位价值 $%.2f，未实现盈亏 %+.2f",
+				WalletLabel(p.Account), p.Coin, p.Side(), p.MarkPrice, p.LiquidationPrice, distance, m.liquidation.pct, p.PositionValue, p.UnrealizedPnL),
+			Timestamp: now,
+		})
+	}
+}
+
+// generateDerivativesSection 生成永续合约仓位报告段落，没有跟踪的账户时为空
+func generateDerivativesSection() string {
+	perpAccountsMu.RLock()
+	accounts := perpAccounts
+	perpAccountsMu.RUnlock()
+	if len(accounts) == 0 {
+		return ""
+	}
+
+	var positions []*PerpPosition
+	var equity, pnl float64
+	for _, account := range accounts {
+		positions = append(positions, account.Positions...)
+		equity += account.Value
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].PositionValue > positions[j].PositionValue
+	})
+
+	var sb strings.Builder
+	sb.WriteString("\n" + i18n.T("合约仓位") + "\n")
+	if len(positions) > 0 {
+		table := &reportTable{columns: []tableColumn{
+			{header: i18n.T("账户"), width: 12, left: true},
+			{header: i18n.T("合约"), width: 8, left: true},
+			{header: i18n.T("方向"), width: 4, left: true},
+			{header: i18n.T("数量"), width: 12},
+			{header: i18n.T("开仓价"), width: 12},
+			{header: i18n.T("标记价"), width: 12},
+			{header: i18n.T("强平价"), width: 12},
+			{header: i18n.T("距强平"), width: 8},
+			{header: i18n.T("杠杆"), width: 6},
+			{header: i18n.T("未实现盈亏"), width: 12},
+		}}
+		for _, p := range positions {
+			liquidation, distance := "-", "-"
+			if d, ok := p.LiquidationDistance(); ok {
+				liquidation = fmt.Sprintf("%.4f", p.LiquidationPrice)
+				distance = fmt.Sprintf("%.2f%%", d)
+			}
+			table.addRow(WalletLabel(p.Account), p.Coin, p.Side(), fmt.Sprintf("%.4f", math.Abs(p.Size)),
+				fmt.Sprintf("%.4f", p.EntryPrice), fmt.Sprintf("%.4f", p.MarkPrice), liquidation, distance,
+				fmt.Sprintf("%gx", p.Leverage), colorChange(fmt.Sprintf("%+.2f", p.UnrealizedPnL), p.UnrealizedPnL))
+			pnl += p.UnrealizedPnL
+		}
+		sb.WriteString(table.render())
+	}
+	sb.WriteString(colorChange(i18n.Sprintf("合约账户权益: $%.2f，未实现盈亏: %+.2f", equity, pnl), pnl) + "\n")
+	return sb.String()
+}
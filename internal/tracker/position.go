@@ -0,0 +1,211 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// TradeSide 交易方向
+type TradeSide string
+
+const (
+	TradeSideBuy  TradeSide = "buy"
+	TradeSideSell TradeSide = "sell"
+)
+
+// Trade 一笔买入或卖出记录
+type Trade struct {
+	MintAddr  string
+	Side      TradeSide
+	Amount    float64 // 代币数量
+	Price     float64 // 成交单价（美元）
+	Timestamp time.Time
+	Wallet    string // 钱包地址（可选）
+	Signature string // 交易签名（来自链上记录时）
+}
+
+// Position 单个代币的持仓成本（平均成本法）
+type Position struct {
+	MintAddr    string
+	Amount      float64 // 按交易记录推算的持有数量
+	AvgCost     float64 // 平均成本（美元/个）
+	Invested    float64 // 累计买入金额
+	RealizedPnL float64 // 已实现盈亏
+}
+
+// PositionReport 持仓在当前价格下的盈亏
+type PositionReport struct {
+	Position
+	Symbol        string
+	Price         float64
+	UnrealizedPnL float64
+	ROI           float64 // (已实现 + 未实现) / 累计买入 * 100
+}
+
+// PositionLedger 按代币记录交易并计算持仓成本
+type PositionLedger struct {
+	mu         sync.RWMutex
+	positions  map[string]*Position
+	signatures map[string]bool // 已记录的链上交易，避免重复
+}
+
+// NewPositionLedger 创建持仓账本
+func NewPositionLedger() *PositionLedger {
+	return &PositionLedger{
+		positions:  make(map[string]*Position),
+		signatures: make(map[string]bool),
+	}
+}
+
+// AddTrades 按时间顺序记录多笔交易
+func (l *PositionLedger) AddTrades(trades []Trade) error {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	for _, trade := range sorted {
+		if err := l.AddTrade(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddTrade 记录一笔交易，卖出时按平均成本计算已实现盈亏
+func (l *PositionLedger) AddTrade(trade Trade) error {
+	if trade.Amount <= 0 || trade.Price < 0 {
+		return fmt.Errorf("无效的交易记录: %s 数量 %.8f 价格 %.8f", trade.MintAddr, trade.Amount, trade.Price)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if trade.Signature != "" {
+		key := trade.Signature + "|" + trade.MintAddr
+		if l.signatures[key] {
+			return nil
+		}
+		l.signatures[key] = true
+	}
+
+	pos, ok := l.positions[trade.MintAddr]
+	if !ok {
+		pos = &Position{MintAddr: trade.MintAddr}
+		l.positions[trade.MintAddr] = pos
+	}
+
+	switch trade.Side {
+	case TradeSideBuy:
+		cost := pos.AvgCost*pos.Amount + trade.Amount*trade.Price
+		pos.Amount += trade.Amount
+		pos.AvgCost = cost / pos.Amount
+		pos.Invested += trade.Amount * trade.Price
+	case TradeSideSell:
+		// 卖出数量超过记录的持仓时，只对有成本的部分计算盈亏
+		sold := trade.Amount
+		if sold > pos.Amount {
+			sold = pos.Amount
+		}
+		pos.RealizedPnL += sold * (trade.Price - pos.AvgCost)
+		pos.Amount -= sold
+		if pos.Amount <= 0 {
+			pos.Amount = 0
+			pos.AvgCost = 0
+		}
+	default:
+		return fmt.Errorf("未知的交易方向: %s", trade.Side)
+	}
+	return nil
+}
+
+// Position 返回代币持仓的副本
+func (l *PositionLedger) Position(mintAddr string) (Position, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	pos, ok := l.positions[mintAddr]
+	if !ok {
+		return Position{}, false
+	}
+	return *pos, true
+}
+
+// Report 根据当前价格计算每个有交易记录的代币的盈亏，按总盈亏降序排列
+func (l *PositionLedger) Report(tokens []*TokenData) []PositionReport {
+	current := make(map[string]*TokenData, len(tokens))
+	for _, token := range tokens {
+		current[token.MintAddr] = token
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	reports := make([]PositionReport, 0, len(l.positions))
+	for mintAddr, pos := range l.positions {
+		r := PositionReport{Position: *pos, Symbol: mintAddr}
+		if token, ok := current[mintAddr]; ok {
+			r.Symbol = token.Symbol
+			r.Price = token.Price
+			r.UnrealizedPnL = pos.Amount * (token.Price - pos.AvgCost)
+		}
+		if pos.Invested > 0 {
+			r.ROI = (r.RealizedPnL + r.UnrealizedPnL) / pos.Invested * 100
+		}
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].RealizedPnL+reports[i].UnrealizedPnL > reports[j].RealizedPnL+reports[j].UnrealizedPnL
+	})
+	return reports
+}
+
+var (
+	positionLedgerMu sync.RWMutex
+	positionLedger   *PositionLedger
+)
+
+// SetPositionLedger 设置报告中使用的持仓账本
+func SetPositionLedger(ledger *PositionLedger) {
+	positionLedgerMu.Lock()
+	defer positionLedgerMu.Unlock()
+	positionLedger = ledger
+}
+
+// currentPositionLedger 返回当前持仓账本，未设置时返回nil
+func currentPositionLedger() *PositionLedger {
+	positionLedgerMu.RLock()
+	defer positionLedgerMu.RUnlock()
+	return positionLedger
+}
+
+// generatePositionSection 生成持仓成本与盈亏报告段落
+func generatePositionSection(tokens []*TokenData) string {
+	ledger := currentPositionLedger()
+	if ledger == nil {
+		return ""
+	}
+	reports := ledger.Report(tokens)
+	if len(reports) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%-16s %14s %14s %14s %14s %10s\n",
+		i18n.T("代币"), i18n.T("平均成本"), i18n.T("当前价格"), i18n.T("已实现"), i18n.T("未实现"), "ROI"))
+	sb.WriteString(strings.Repeat("-", 87) + "\n")
+	for _, r := range reports {
+		symbol := r.Symbol
+		if len(symbol) > 16 {
+			symbol = symbol[:16]
+		}
+		sb.WriteString(fmt.Sprintf("%-16s %14.6f %14.6f %+14.2f %+14.2f %+9.2f%%\n",
+			symbol, r.AvgCost, r.Price, r.RealizedPnL, r.UnrealizedPnL, r.ROI))
+	}
+	return sb.String()
+}
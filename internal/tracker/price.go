@@ -3,19 +3,21 @@ package tracker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
 	maxRetries         = 5
 	jupiterAPIEndpoint = "https://api.jup.ag/price/v2"
-	batchSize          = 100                 // Jupiter API批量处理大小
+	batchSize          = 100                 // Jupiter API默认批量处理大小
 	maxPriceUSD        = 1_000_000_000_000.0 // 最大价格阈值
 	minPriceUSD        = 0.000000001         // 最小价格阈值
 )
@@ -25,14 +27,43 @@ type PriceSource int
 
 const (
 	PriceSourceJupiter PriceSource = iota
+	PriceSourceDexScreener
+	PriceSourceAggregated
+	PriceSourceBirdeye
+	PriceSourcePyth
+	PriceSourceCoinGecko
+	PriceSourceExchange
 )
 
+// String 返回价格数据源名称
+func (s PriceSource) String() string {
+	switch s {
+	case PriceSourceJupiter:
+		return "Jupiter"
+	case PriceSourceDexScreener:
+		return "DexScreener"
+	case PriceSourceAggregated:
+		return "Aggregated"
+	case PriceSourceBirdeye:
+		return "Birdeye"
+	case PriceSourcePyth:
+		return "Pyth"
+	case PriceSourceCoinGecko:
+		return "CoinGecko"
+	case PriceSourceExchange:
+		return "Exchange"
+	default:
+		return fmt.Sprintf("PriceSource(%d)", int(s))
+	}
+}
+
 // TokenPrice 代币价格信息
 type TokenPrice struct {
 	Price           float64
 	Source          PriceSource
 	Timestamp       time.Time
-	ConfidenceLevel string // 价格可信度
+	ConfidenceLevel string  // 价格可信度
+	Liquidity       float64 // 交易对流动性（美元），数据源未提供时为0
 }
 
 // TokenDepth 代币深度信息
@@ -43,14 +74,37 @@ type TokenDepth struct {
 
 // JupiterPriceService Jupiter价格服务
 type JupiterPriceService struct {
-	client *http.Client
+	client    *http.Client
+	baseURL   string
+	batchSize int
 }
 
+// priceBatchSize 价格查询的批量大小
+var priceBatchSize = batchSize
+
+// SetPriceBatchSize 设置价格查询的批量大小
+func SetPriceBatchSize(size int) {
+	if size < 1 {
+		size = batchSize
+	}
+	priceBatchSize = size
+}
+
+// NewJupiterPriceService 使用默认端点创建 Jupiter 价格服务
 func NewJupiterPriceService() *JupiterPriceService {
+	return NewJupiterPriceServiceWithConfig(jupiterAPIEndpoint, nil)
+}
+
+// NewJupiterPriceServiceWithConfig 使用指定的端点和HTTP客户端创建 Jupiter 价格服务，client 为nil时使用共享客户端
+func NewJupiterPriceServiceWithConfig(baseURL string, client *http.Client) *JupiterPriceService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+
 	return &JupiterPriceService{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:    client,
+		baseURL:   baseURL,
+		batchSize: priceBatchSize,
 	}
 }
 
@@ -61,6 +115,8 @@ func formatPrice(price float64) string {
 
 // GetTokenPrices 批量获取代币价格
 func (s *JupiterPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	// Jupiter 只支持 Solana 代币
+	mintAddrs = solanaMints(mintAddrs)
 	if len(mintAddrs) == 0 {
 		return make(map[string]*TokenPrice), nil
 	}
@@ -68,176 +124,300 @@ func (s *JupiterPriceService) GetTokenPrices(ctx context.Context, mintAddrs []st
 	prices := make(map[string]*TokenPrice)
 
 	// 按批次处理mint地址
-	for i := 0; i < len(mintAddrs); i += batchSize {
+	for i := 0; i < len(mintAddrs); i += s.batchSize {
 		select {
 		case <-ctx.Done():
 			return prices, ctx.Err()
 		default:
-			end := i + batchSize
+			end := i + s.batchSize
 			if end > len(mintAddrs) {
 				end = len(mintAddrs)
 			}
 
 			batch := mintAddrs[i:end]
-			log.Printf("处理Jupiter价格批次 %d-%d，共 %d 个代币", i+1, end, len(batch))
-
-			// 构建请求URL
-			url := fmt.Sprintf("%s?ids=%s&vsToken=EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v&showExtraInfo=true",
-				jupiterAPIEndpoint, strings.Join(batch, ","))
+			priceLog.Debug("处理Jupiter价格批次", "from", i+1, "to", end, "count", len(batch))
+			// 熔断期间不再请求后续批次，ctx 取消由下面的 select 处理
+			if err := s.fetchBatch(ctx, batch, prices); errors.Is(err, ErrCircuitOpen) {
+				return prices, err
+			}
 
-			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-			if err != nil {
-				log.Printf("创建请求失败: %v", err)
-				continue
+			// 添加短暂延迟避免请求过快
+			select {
+			case <-ctx.Done():
+				return prices, ctx.Err()
+			case <-time.After(100 * time.Millisecond):
 			}
+		}
+	}
 
-			var lastErr error
-			for retry := 0; retry < maxRetries; retry++ {
-				if retry > 0 {
-					backoff := time.Duration(2<<uint(retry-1)) * time.Second
-					log.Printf("重试获取价格 (第 %d 次)，等待 %v...", retry+1, backoff)
-					time.Sleep(backoff)
-				}
+	priceLog.Info("从Jupiter获取价格完成", "priced", len(prices), "requested", len(mintAddrs))
+	return prices, nil
+}
 
-				resp, err := s.client.Do(req)
-				if err != nil {
-					lastErr = fmt.Errorf("请求失败: %v", err)
-					continue
-				}
+// fetchBatch 获取一批代币的价格写入 prices，失败时按 Retry-After 或指数退避重试；
+// ctx 取消或熔断时立即返回，其他错误只影响本批次
+func (s *JupiterPriceService) fetchBatch(ctx context.Context, batch []string, prices map[string]*TokenPrice) (err error) {
+	priced := len(prices)
+	ctx, span := StartSpan(ctx, "jupiter.batch", attribute.Int("batch.size", len(batch)))
+	defer func() {
+		span.SetAttributes(attribute.Int("batch.priced", len(prices)-priced))
+		EndSpan(span, err)
+	}()
+
+	// 构建请求URL
+	url := fmt.Sprintf("%s?ids=%s&vsToken=EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v&showExtraInfo=true",
+		s.baseURL, strings.Join(batch, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
 
-				var result struct {
-					Data map[string]struct {
-						Price     string `json:"price"`
-						ExtraInfo struct {
-							ConfidenceLevel string `json:"confidenceLevel"`
-						} `json:"extraInfo"`
-					} `json:"data"`
-				}
+	var lastErr error
+	var retryAfter time.Duration
+	for retry := 0; retry < maxRetries; retry++ {
+		if retry > 0 {
+			// 限流时按 Retry-After 等待，否则指数退避加抖动
+			backoff := retryBackoff(retry)
+			if retryAfter > backoff {
+				backoff = retryAfter
+			}
+			observeSourceRetry("jupiter")
+			priceLog.Warn("重试获取价格", "attempt", retry+1, "backoff", backoff, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+		retryAfter = 0
+		span.SetAttributes(attribute.Int("batch.attempts", retry+1))
 
-				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-					resp.Body.Close()
-					lastErr = fmt.Errorf("解析响应失败: %v", err)
-					continue
-				}
-				resp.Body.Close()
+		resp, err := jupiterBreaker.Do(s.client, req)
+		if !errors.Is(err, ErrCircuitOpen) {
+			observeSourceResponse("jupiter", resp, err)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// 熔断期间不再重试
+			if errors.Is(err, ErrCircuitOpen) {
+				return err
+			}
+			lastErr = fmt.Errorf("请求失败: %v", err)
+			continue
+		}
 
-				for mintAddr, data := range result.Data {
-					price, err := strconv.ParseFloat(data.Price, 64)
-					if err != nil {
-						log.Printf("解析价格失败: %v", err)
-						continue
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("Jupiter 返回状态码 %d", resp.StatusCode)
+			if resp.StatusCode == http.StatusTooManyRequests {
+				if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+					if wait > retryAfterMax {
+						priceLog.Warn("Jupiter 要求的等待时间过长，放弃本批次", "retry_after", wait)
+						break
 					}
+					retryAfter = wait
+				}
+				continue
+			}
+			// 其他4xx错误重试无意义
+			if resp.StatusCode < 500 {
+				break
+			}
+			continue
+		}
+		lastErr = nil
+
+		var result struct {
+			Data map[string]struct {
+				Price     string `json:"price"`
+				ExtraInfo struct {
+					ConfidenceLevel string `json:"confidenceLevel"`
+				} `json:"extraInfo"`
+			} `json:"data"`
+		}
 
-					// 验证价格是否在合理范围内
-					if price < minPriceUSD || price > maxPriceUSD {
-						log.Printf("mint地址 %s: 价格 %f 超出合理范围", mintAddr, price)
-						continue
-					}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("解析响应失败: %v", err)
+			continue
+		}
+		resp.Body.Close()
 
-					prices[mintAddr] = &TokenPrice{
-						Price:           price,
-						Source:          PriceSourceJupiter,
-						Timestamp:       time.Now(),
-						ConfidenceLevel: data.ExtraInfo.ConfidenceLevel,
-					}
-					log.Printf("获取到代币 %s 的价格: %s (可信度: %s)",
-						mintAddr, formatPrice(price), data.ExtraInfo.ConfidenceLevel)
-				}
+		for mintAddr, data := range result.Data {
+			price, err := strconv.ParseFloat(data.Price, 64)
+			if err != nil {
+				priceLog.Warn("解析价格失败", "mint", mintAddr, "error", err)
+				continue
+			}
 
-				// 如果成功获取了数据，跳出重试循环
-				if len(result.Data) > 0 {
-					break
-				}
+			// 验证价格是否在合理范围内
+			if price < minPriceUSD || price > maxPriceUSD {
+				priceLog.Warn("价格超出合理范围", "mint", mintAddr, "price", price)
+				continue
 			}
 
-			if lastErr != nil {
-				log.Printf("批次处理失败: %v", lastErr)
+			prices[mintAddr] = &TokenPrice{
+				Price:           price,
+				Source:          PriceSourceJupiter,
+				Timestamp:       time.Now(),
+				ConfidenceLevel: data.ExtraInfo.ConfidenceLevel,
 			}
+			priceLog.Debug("获取到代币价格", "mint", mintAddr, "price", price, "confidence", data.ExtraInfo.ConfidenceLevel)
+		}
 
-			// 添加短暂延迟避免请求过快
-			time.Sleep(100 * time.Millisecond)
+		// 如果成功获取了数据，跳出重试循环
+		if len(result.Data) > 0 {
+			break
 		}
 	}
 
-	log.Printf("成功从Jupiter获取 %d/%d 个代币的价格信息",
-		len(prices), len(mintAddrs))
-	return prices, nil
+	if lastErr != nil {
+		priceLog.Error("批次处理失败", "error", lastErr)
+	}
+	return lastErr
 }
 
-func UpdateTokenPrices(tokens map[string][]*TokenData, monitor *TokenMonitor) ([]*TokenData, error) {
-	log.Println("\n开始更新所有代币价格...")
+// UpdateTokenPrices 获取所有代币的最新价格并计算价值，ctx 取消时立即返回
+func UpdateTokenPrices(ctx context.Context, tokens map[string][]*TokenData, monitor *TokenMonitor) ([]*TokenData, error) {
+	priceLog.Debug("开始更新所有代币价格")
+	defer recordTiming("update_prices", time.Now())
 
 	// 获取上一次的价值数据（如果monitor存在）
 	var lastTokenValues map[string]float64
 	var lastUpdateTime time.Time
 	if monitor != nil {
 		lastTokenValues = make(map[string]float64)
-		for _, token := range monitor.tokens {
+		for _, token := range monitor.Tokens() {
 			lastTokenValues[token.MintAddr] = token.Value
 		}
-		lastUpdateTime = monitor.lastUpdateTime
+		lastUpdateTime = monitor.lastUpdate()
 	}
 
 	// 收集所有唯一的mint地址
 	mintMap := make(map[string]*TokenData)
 	validTokens := make([]*TokenData, 0)
-	for _, walletTokens := range tokens {
+	for wallet, walletTokens := range tokens {
 		for _, token := range walletTokens {
-			if existing, ok := mintMap[token.MintAddr]; ok {
+			existing, ok := mintMap[token.MintAddr]
+			if ok {
 				// 如果mint已存在，累加数量
 				existing.Amount += token.Amount
+				existing.Staked += token.Staked
+				existing.DeFi += token.DeFi
 			} else {
 				// 新的mint，复制token数据
-				mintMap[token.MintAddr] = &TokenData{
-					MintAddr: token.MintAddr,
-					Amount:   token.Amount,
-					Decimals: token.Decimals,
-					Symbol:   token.Symbol,
-					Name:     token.Name,
+				existing = &TokenData{
+					MintAddr:       token.MintAddr,
+					Chain:          token.Chain,
+					Amount:         token.Amount,
+					Decimals:       token.Decimals,
+					Symbol:         token.Symbol,
+					Name:           token.Name,
+					WalletAmounts:  make(map[string]float64),
+					TransferFeeBps: token.TransferFeeBps,
+					MaxTransferFee: token.MaxTransferFee,
+					Staked:         token.Staked,
+					DeFi:           token.DeFi,
 				}
+				mintMap[token.MintAddr] = existing
 			}
+			// 记录各钱包的持有数量
+			existing.WalletAmounts[wallet] += token.Amount
 		}
 	}
 
-	// 获取所有mint地址
+	// 获取所有mint地址，交易所资产只使用交易所的报价
 	mintAddrs := make([]string, 0, len(mintMap))
 	for mintAddr := range mintMap {
 		mintAddrs = append(mintAddrs, mintAddr)
 	}
+	mintAddrs, exchangeAssets := splitExchangeAssets(mintAddrs)
 
-	// 从Jupiter获取价格
-	jupiterService := NewJupiterPriceService()
-	jupiterPrices, err := jupiterService.GetTokenPrices(context.Background(), mintAddrs)
+	// 从多个数据源聚合获取价格
+	priceCtx, span := StartSpan(ctx, "GetTokenPrices", attribute.Int("mint.count", len(mintAddrs)))
+	prices, err := currentPriceService().GetTokenPrices(priceCtx, mintAddrs)
+	span.SetAttributes(attribute.Int("mint.priced", len(prices)))
+	EndSpan(span, err)
 	if err != nil {
-		log.Printf("从Jupiter获取价格失败: %v", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		priceLog.Error("获取价格失败", "error", err)
+	}
+	if len(exchangeAssets) > 0 {
+		if prices == nil {
+			prices = make(map[string]*TokenPrice)
+		}
+		for mintAddr, price := range exchangeQuotes(exchangeAssets) {
+			prices[mintAddr] = price
+		}
+	}
+
+	// 从交叉验证数据源获取价格（如果已配置）
+	var secondaryPrices map[string]float64
+	if monitor != nil && monitor.secondaryPriceService != nil {
+		secondaryPrices, err = monitor.secondaryPriceService.GetTokenPrices(ctx, mintAddrs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			priceLog.Warn("从交叉验证数据源获取价格失败", "error", err)
+		}
+	}
+
+	// 检查各钱包的持仓变化
+	if monitor != nil {
+		monitor.checkHoldingChanges(tokens, prices)
 	}
 
 	var totalValue float64
 	var updatedCount int
 	currentTime := time.Now()
 
+	filter := currentTokenFilter()
+	stablecoins := currentStablecoinConfig()
+
 	// 处理每个mint的代币
 	for mintAddr, token := range mintMap {
-		log.Printf("\n代币计算详情: %s (%s)", token.Symbol, token.Name)
-		log.Printf("1. 基本信息:")
-		log.Printf("   - Mint地址: %s", mintAddr)
-		log.Printf("   - 原始数量: %.8f", token.Amount)
-		log.Printf("   - 小数位数: %d", token.Decimals)
-
-		if price, ok := jupiterPrices[mintAddr]; ok {
-			if price.Price <= 0 || price.ConfidenceLevel == "low" {
-				log.Printf("2. Jupiter价格: 无效 (价格: %.8f, 可信度: %s)",
-					price.Price, price.ConfidenceLevel)
-				continue
+		tokenLog := priceLog.With("symbol", token.Symbol, "mint", mintAddr)
+
+		price, ok := prices[mintAddr]
+		if ok && price.Price > 0 {
+			rememberPrice(mintAddr, price, currentTime)
+		} else if !ok {
+			// 之前有价格的代币本次没有报价时沿用上次价格，避免组合价值跳变
+			var age time.Duration
+			if price, age, ok = stalePrice(mintAddr, currentTime); ok {
+				token.StaleFor = age
+				tokenLog.Info("数据源未返回价格，沿用上次价格", "price", price.Price, "age", age.Round(time.Second))
+			} else if age > 0 {
+				tokenLog.Info("上次价格已超过最长沿用时间，隐藏代币", "age", age.Round(time.Second))
 			}
+		}
 
-			log.Printf("2. Jupiter价格数据:")
-			log.Printf("   - 当前价格: $%.8f", price.Price)
-			log.Printf("   - 可信度: %s", price.ConfidenceLevel)
+		if ok {
+			if price.Price <= 0 || (filter.HideLowConfidence && price.ConfidenceLevel == "low") {
+				tokenLog.Debug("价格无效", "source", price.Source, "price", price.Price, "confidence", price.ConfidenceLevel)
+				continue
+			}
 
 			token.Price = price.Price
-			token.Value = token.Amount * price.Price
+			applyStablecoinPrice(token, stablecoins)
+			// Token-2022 转账手续费代币按扣除手续费后的数量计价
+			token.Value = netAmount(token) * token.Price
 			token.ConfidenceLevel = price.ConfidenceLevel
+			token.Liquidity = price.Liquidity
+			if secondary, ok := secondaryPrices[mintAddr]; ok && secondary > 0 {
+				token.SecondaryPrice = secondary
+				// 与交叉验证数据源偏离过大时降低可信度
+				if divergence, ok := sourceDivergence(token); ok && divergence > monitor.crossCheckThreshold() {
+					token.ConfidenceLevel = downgradeConfidence(token.ConfidenceLevel)
+					tokenLog.Debug("价格与交叉验证数据源偏离过大，降低可信度", "divergence_pct", divergence, "secondary_price", secondary)
+				}
+			}
 
 			// 计算变化率
 			if lastValue, ok := lastTokenValues[mintAddr]; ok && !lastUpdateTime.IsZero() {
@@ -248,50 +428,75 @@ func UpdateTokenPrices(tokens map[string][]*TokenData, monitor *TokenMonitor) ([
 				}
 			}
 
-			log.Printf("3. 价值计算:")
-			log.Printf("   - 计算公式: %.8f * $%.8f", token.Amount, price.Price)
-			log.Printf("   - 计算结果: %s", formatPrice(token.Value))
-			log.Printf("   - 变化率: %.2f%%/s", token.Change)
+			tokenLog.Debug("代币价值计算",
+				"amount", token.Amount,
+				"net_amount", netAmount(token),
+				"decimals", token.Decimals,
+				"source", price.Source,
+				"price", price.Price,
+				"confidence", price.ConfidenceLevel,
+				"secondary_price", token.SecondaryPrice,
+				"value", token.Value,
+				"change_per_sec", token.Change)
 
 			validTokens = append(validTokens, token)
 			totalValue += token.Value
 			updatedCount++
 		} else {
-			log.Printf("2. Jupiter价格: 未找到")
+			tokenLog.Debug("未找到价格", "amount", token.Amount, "decimals", token.Decimals)
 		}
 	}
 
-	// 按价值排序
-	sort.Slice(validTokens, func(i, j int) bool {
-		return validTokens[i].Value > validTokens[j].Value
-	})
+	// 获取流动性和市场深度（覆盖价格数据源中单个交易对的流动性）
+	if service := currentLiquidityService(); service != nil {
+		enrichLiquidity(ctx, service, validTokens)
+	}
+
+	// 检查新出现代币的增发/冻结权限和LP锁定情况
+	safety := currentSafetyService()
+	if safety != nil {
+		enrichSafety(ctx, safety, validTokens)
+	}
+
+	// 计算相对基准的未实现盈亏
+	applyBaseline(validTokens)
+
+	// 价格目标和无常损失在过滤前检查，被过滤隐藏的小额持仓也能触发
+	if monitor != nil {
+		monitor.checkPriceTargets(validTokens)
+		monitor.checkImpermanentLoss(validTokens)
+	}
+
+	// 按过滤规则隐藏代币，按价值排序并只保留前 MaxTokens 个
+	validTokens = filter.Apply(validTokens)
 
-	// 只保留前50个
-	if len(validTokens) > 50 {
-		validTokens = validTokens[:50]
+	// 检查持仓代币的流动性
+	if monitor != nil {
+		monitor.checkPeg(validTokens, stablecoins.PegBand)
+		monitor.checkLiquidity(validTokens)
+		monitor.checkTrailingStop(validTokens)
+		if safety != nil {
+			monitor.checkSafety(validTokens, safety.Threshold())
+		}
 	}
 
-	log.Printf("\nJupiter更新汇总:")
-	log.Printf("- 成功: %d个", updatedCount)
-	log.Printf("- 总代币数: %d个", len(mintMap))
-	log.Printf("- 当前总价值: %s", formatPrice(totalValue))
-	log.Println("----------------------------------------")
+	priceLog.Info("价格更新完成", "updated", updatedCount, "total", len(mintMap), "value", totalValue)
 
 	// 更新监控器的代币列表
 	if monitor != nil {
 		monitor.UpdateTokens(validTokens)
-		monitor.lastUpdateTime = currentTime
+		monitor.setLastUpdate(currentTime)
 	}
 
 	return validTokens, nil
 }
 
-// FilterTopTokensByValue 筛选价值最高的代币
-func FilterTopTokensByValue(tokens []*TokenData, limit int) []*TokenData {
-	// 首先过滤掉无效的代币
+// FilterTopTokensByValue 筛选价值不低于 minValue 且价值最高的代币
+func FilterTopTokensByValue(tokens []*TokenData, limit int, minValue float64) []*TokenData {
+	// 首先过滤掉无效的代币和小额代币
 	validTokens := make([]*TokenData, 0)
 	for _, token := range tokens {
-		if token.Price > 0 && token.Value > 0 {
+		if token.Price > 0 && token.Value > 0 && token.Value >= minValue {
 			validTokens = append(validTokens, token)
 		}
 	}
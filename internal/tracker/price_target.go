@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// priceTargetRearmPct 重复触发的目标在价格回到目标另一侧超过该比例（%）后才重新生效，避免在目标附近反复报警
+const priceTargetRearmPct = 1.0
+
+// PriceTarget 单个代币的绝对价格目标
+type PriceTarget struct {
+	MintAddr string
+	Above    float64 // 价格涨到该值及以上时报警，0表示不设置
+	Below    float64 // 价格跌到该值及以下时报警，0表示不设置
+	Repeat   bool    // 价格回到目标另一侧后重新生效，否则只触发一次
+}
+
+var (
+	priceTargetMu sync.RWMutex
+	priceTargets  map[string][]PriceTarget // mint地址 -> 价格目标
+)
+
+// SetPriceTargets 设置价格目标，运行中可重复调用；已触发的一次性目标在价格和方向不变时不会再次触发
+func SetPriceTargets(targets []PriceTarget) {
+	byMint := make(map[string][]PriceTarget, len(targets))
+	for _, target := range targets {
+		byMint[target.MintAddr] = append(byMint[target.MintAddr], target)
+	}
+	priceTargetMu.Lock()
+	defer priceTargetMu.Unlock()
+	priceTargets = byMint
+}
+
+func currentPriceTargets() map[string][]PriceTarget {
+	priceTargetMu.RLock()
+	defer priceTargetMu.RUnlock()
+	return priceTargets
+}
+
+// targetWatch 记录已触发的价格目标，键为 mint|方向|目标价格
+type targetWatch struct {
+	mu        sync.Mutex
+	triggered map[string]bool
+}
+
+// checkPriceTargets 检查持仓代币是否达到配置的价格目标，稳定币使用实际市场价格
+func (m *TokenMonitor) checkPriceTargets(tokens []*TokenData) {
+	targets := currentPriceTargets()
+	if len(targets) == 0 {
+		return
+	}
+
+	m.targets.mu.Lock()
+	defer m.targets.mu.Unlock()
+	if m.targets.triggered == nil {
+		m.targets.triggered = make(map[string]bool)
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		price := token.Price
+		if token.MarketPrice > 0 {
+			price = token.MarketPrice
+		}
+		if price <= 0 {
+			continue
+		}
+		for _, target := range targets[token.MintAddr] {
+			if target.Above > 0 {
+				m.checkTarget(token, price, target.Above, true, target.Repeat, now)
+			}
+			if target.Below > 0 {
+				m.checkTarget(token, price, target.Below, false, target.Repeat, now)
+			}
+		}
+	}
+}
+
+// checkTarget 检查单个方向的价格目标，调用方需持有 m.targets.mu
+func (m *TokenMonitor) checkTarget(token *TokenData, price, level float64, above, repeat bool, now time.Time) {
+	direction, verb := "below", i18n.T("跌破")
+	reached := price <= level
+	rearm := price > level*(1+priceTargetRearmPct/100)
+	if above {
+		direction, verb = "above", i18n.T("突破")
+		reached = price >= level
+		rearm = price < level*(1-priceTargetRearmPct/100)
+	}
+	key := fmt.Sprintf("%s|%s|%g", token.MintAddr, direction, level)
+
+	if m.targets.triggered[key] {
+		if repeat && rearm {
+			delete(m.targets.triggered, key)
+		}
+		return
+	}
+	if !reached {
+		return
+	}
+	m.targets.triggered[key] = true
+
+	note := i18n.T("一次性目标，已停用")
+	if repeat {
+		note = i18n.T("价格回到目标另一侧后重新生效")
+	}
+	m.emitAlert(Alert{
+		Type:      AlertTypePriceTarget,
+		MintAddr:  token.MintAddr,
+		Symbol:    token.Symbol,
+		ChangePct: (price - level) / level * 100,
+		OldValue:  level,
+		NewValue:  price,
+		Message: i18n.Sprintf("价格目标 - %s (%s) %s $%.6f，当前价格 $%.6f，持仓 %.2f（%s）",
+			displaySymbol(token), token.MintAddr, verb, level, price, token.Amount, note),
+		Timestamp: now,
+	})
+}
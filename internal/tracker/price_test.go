@@ -0,0 +1,149 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJupiterGetTokenPrices(t *testing.T) {
+	tests := []struct {
+		name       string
+		mints      []string
+		body       string
+		want       map[string]float64
+		confidence map[string]string
+	}{
+		{
+			name:  "解析价格和可信度",
+			mints: []string{"MintA", "MintB"},
+			body: `{"data":{
+				"MintA":{"id":"MintA","price":"1.25","extraInfo":{"confidenceLevel":"high"}},
+				"MintB":{"id":"MintB","price":"0.0005"}
+			}}`,
+			want:       map[string]float64{"MintA": 1.25, "MintB": 0.0005},
+			confidence: map[string]string{"MintA": "high", "MintB": ""},
+		},
+		{
+			name:  "跳过无法解析和超出范围的价格",
+			mints: []string{"MintA", "MintBad", "MintZero", "MintHuge"},
+			body: `{"data":{
+				"MintA":{"price":"2"},
+				"MintBad":{"price":"abc"},
+				"MintZero":{"price":"0"},
+				"MintHuge":{"price":"1e13"}
+			}}`,
+			want: map[string]float64{"MintA": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIDs = r.URL.Query().Get("ids")
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			service := NewJupiterPriceServiceWithConfig(srv.URL, srv.Client())
+			prices, err := service.GetTokenPrices(context.Background(), tt.mints)
+			if err != nil {
+				t.Fatalf("GetTokenPrices 返回错误: %v", err)
+			}
+			if gotIDs != strings.Join(tt.mints, ",") {
+				t.Errorf("请求的 ids = %q, 期望 %q", gotIDs, strings.Join(tt.mints, ","))
+			}
+			if len(prices) != len(tt.want) {
+				t.Fatalf("价格数量 = %d, 期望 %d", len(prices), len(tt.want))
+			}
+			for mint, want := range tt.want {
+				price := prices[mint]
+				if price == nil {
+					t.Errorf("缺少 %s 的价格", mint)
+					continue
+				}
+				if !almostEqual(price.Price, want) || price.Source != PriceSourceJupiter {
+					t.Errorf("%s = %+v, 期望价格 %v", mint, price, want)
+				}
+			}
+			for mint, want := range tt.confidence {
+				if got := prices[mint].ConfidenceLevel; got != want {
+					t.Errorf("%s 可信度 = %q, 期望 %q", mint, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJupiterGetTokenPricesBatches(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var entries []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			entries = append(entries, `"`+id+`":{"price":"1"}`)
+		}
+		w.Write([]byte(`{"data":{` + strings.Join(entries, ",") + `}}`))
+	}))
+	defer srv.Close()
+
+	service := NewJupiterPriceServiceWithConfig(srv.URL, srv.Client())
+	service.batchSize = 2
+	prices, err := service.GetTokenPrices(context.Background(), []string{"MintA", "MintB", "MintC"})
+	if err != nil {
+		t.Fatalf("GetTokenPrices 返回错误: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("请求次数 = %d, 期望 2", requests)
+	}
+	if len(prices) != 3 {
+		t.Errorf("价格数量 = %d, 期望 3", len(prices))
+	}
+}
+
+func TestFilterTopTokensByValue(t *testing.T) {
+	tokens := []*TokenData{
+		{MintAddr: "Below", Price: 1, Value: 9.99},
+		{MintAddr: "Equal", Price: 1, Value: 10},
+		{MintAddr: "Above", Price: 1, Value: 50},
+		{MintAddr: "Top", Price: 2, Value: 100},
+		{MintAddr: "NoPrice", Price: 0, Value: 500},
+		{MintAddr: "Zero", Price: 1, Value: 0},
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		minValue float64
+		want     []string
+	}{
+		{name: "等于最小价值的代币保留", limit: 10, minValue: 10, want: []string{"Top", "Above", "Equal"}},
+		{name: "低于最小价值的代币过滤", limit: 10, minValue: 10.01, want: []string{"Top", "Above"}},
+		{name: "最小价值为0时过滤无价格和零价值代币", limit: 10, minValue: 0, want: []string{"Top", "Above", "Equal", "Below"}},
+		{name: "按价值截断到limit", limit: 2, minValue: 0, want: []string{"Top", "Above"}},
+		{name: "limit恰好等于数量", limit: 3, minValue: 10, want: []string{"Top", "Above", "Equal"}},
+		{name: "全部低于最小价值", limit: 10, minValue: 1000, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterTopTokensByValue(tokens, tt.limit, tt.minValue)
+			var mints []string
+			for _, token := range got {
+				mints = append(mints, token.MintAddr)
+			}
+			if strings.Join(mints, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FilterTopTokensByValue(limit=%d, minValue=%v) = %v, 期望 %v", tt.limit, tt.minValue, mints, tt.want)
+			}
+		})
+	}
+
+	// 不修改输入的顺序
+	if tokens[0].MintAddr != "Below" || tokens[3].MintAddr != "Top" {
+		t.Error("输入切片的顺序被修改")
+	}
+}
@@ -0,0 +1,175 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// priceRevalidateTimeout 后台刷新过期价格的超时时间
+const priceRevalidateTimeout = 30 * time.Second
+
+// PriceCacheConfig 价格缓存设置
+type PriceCacheConfig struct {
+	TTL               time.Duration // 价格的新鲜期，期内直接使用缓存
+	LongTailTTL       time.Duration // 长尾代币（流动性低或没有价格）的新鲜期
+	StaleTTL          time.Duration // 过期后仍先返回旧价格、同时在后台刷新的时长
+	LongTailLiquidity float64       // 流动性低于该值（美元）的代币视为长尾代币
+}
+
+// normalize 长尾代币的新鲜期不短于普通代币
+func (c PriceCacheConfig) normalize() PriceCacheConfig {
+	if c.LongTailTTL < c.TTL {
+		c.LongTailTTL = c.TTL
+	}
+	return c
+}
+
+// ttlFor 根据流动性决定价格的新鲜期
+func (c PriceCacheConfig) ttlFor(price *TokenPrice) time.Duration {
+	if price.Liquidity > 0 && price.Liquidity < c.LongTailLiquidity {
+		return c.LongTailTTL
+	}
+	return c.TTL
+}
+
+// priceCacheEntry 单个代币的缓存价格，price 为nil表示数据源没有该代币的价格
+type priceCacheEntry struct {
+	price     *TokenPrice
+	fetchedAt time.Time
+	ttl       time.Duration
+}
+
+// CachedPriceService 带TTL缓存的价格服务：新鲜的价格直接返回，过期但仍在 StaleTTL 内的价格先返回旧值并在后台刷新
+type CachedPriceService struct {
+	service QuotePriceService
+	cfg     PriceCacheConfig
+
+	mu       sync.Mutex
+	entries  map[string]*priceCacheEntry
+	inflight map[string]bool // 正在后台刷新的代币
+}
+
+// NewCachedPriceService 为价格服务添加缓存
+func NewCachedPriceService(service QuotePriceService, cfg PriceCacheConfig) *CachedPriceService {
+	return &CachedPriceService{
+		service:  service,
+		cfg:      cfg.normalize(),
+		entries:  make(map[string]*priceCacheEntry),
+		inflight: make(map[string]bool),
+	}
+}
+
+// GetTokenPrices 返回代币价格，只向数据源查询缓存中没有或已完全过期的代币
+func (c *CachedPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	prices := make(map[string]*TokenPrice, len(mintAddrs))
+	var missing, revalidate []string
+	var hits int
+
+	now := time.Now()
+	c.mu.Lock()
+	for _, mintAddr := range mintAddrs {
+		entry, ok := c.entries[mintAddr]
+		if !ok {
+			missing = append(missing, mintAddr)
+			continue
+		}
+		age := now.Sub(entry.fetchedAt)
+		switch {
+		case age < entry.ttl:
+			hits++
+		case age < entry.ttl+c.cfg.StaleTTL:
+			if !c.inflight[mintAddr] {
+				c.inflight[mintAddr] = true
+				revalidate = append(revalidate, mintAddr)
+			}
+		default:
+			missing = append(missing, mintAddr)
+			continue
+		}
+		if entry.price != nil {
+			copied := *entry.price
+			prices[mintAddr] = &copied
+		}
+	}
+	c.mu.Unlock()
+
+	priceLog.Debug("价格缓存", "hits", hits, "stale", len(revalidate), "misses", len(missing))
+
+	if len(revalidate) > 0 {
+		go c.revalidate(context.WithoutCancel(ctx), revalidate)
+	}
+	if len(missing) == 0 {
+		return prices, nil
+	}
+
+	fetched, err := c.service.GetTokenPrices(ctx, missing)
+	// 查询失败或被取消时不记录缺失的价格，避免把未查询到的代币缓存为无价格
+	c.store(missing, fetched, err == nil && ctx.Err() == nil)
+	for mintAddr, price := range fetched {
+		copied := *price
+		prices[mintAddr] = &copied
+	}
+	if err != nil {
+		// 数据源不可用（如熔断）时使用已过期的缓存价格
+		if n := c.fillExpired(prices, missing); n > 0 {
+			priceLog.Warn("价格数据源不可用，使用过期的缓存价格", "tokens", n, "error", err)
+			return prices, nil
+		}
+	}
+	return prices, err
+}
+
+// fillExpired 为数据源没有返回价格的代币填入已过期的缓存价格，返回填入的数量
+func (c *CachedPriceService) fillExpired(prices map[string]*TokenPrice, mintAddrs []string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, mintAddr := range mintAddrs {
+		if _, ok := prices[mintAddr]; ok {
+			continue
+		}
+		if entry, ok := c.entries[mintAddr]; ok && entry.price != nil {
+			copied := *entry.price
+			prices[mintAddr] = &copied
+			n++
+		}
+	}
+	return n
+}
+
+// revalidate 在后台重新获取过期的价格
+func (c *CachedPriceService) revalidate(ctx context.Context, mintAddrs []string) {
+	ctx, cancel := context.WithTimeout(ctx, priceRevalidateTimeout)
+	defer cancel()
+
+	fetched, err := c.service.GetTokenPrices(ctx, mintAddrs)
+	if err != nil {
+		priceLog.Warn("后台刷新价格失败", "mints", len(mintAddrs), "error", err)
+	}
+	c.store(mintAddrs, fetched, err == nil)
+
+	c.mu.Lock()
+	for _, mintAddr := range mintAddrs {
+		delete(c.inflight, mintAddr)
+	}
+	c.mu.Unlock()
+}
+
+// store 保存查询结果；complete 为true时，数据源没有返回价格的代币按长尾代币缓存为无价格
+func (c *CachedPriceService) store(requested []string, fetched map[string]*TokenPrice, complete bool) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, mintAddr := range requested {
+		price, ok := fetched[mintAddr]
+		if !ok {
+			if complete {
+				c.entries[mintAddr] = &priceCacheEntry{fetchedAt: now, ttl: c.cfg.LongTailTTL}
+			}
+			continue
+		}
+		copied := *price
+		c.entries[mintAddr] = &priceCacheEntry{price: &copied, fetchedAt: now, ttl: c.cfg.ttlFor(price)}
+	}
+}
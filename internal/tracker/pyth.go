@@ -0,0 +1,154 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pythAPIEndpoint = "https://hermes.pyth.network/v2/updates/price/latest"
+	pythMaxStale    = 60 * time.Second // 超过该时间未更新的预言机价格视为低可信度
+)
+
+// defaultPythFeeds 主流代币 mint 地址到 Pyth 价格源ID的映射
+var defaultPythFeeds = map[string]string{
+	"So11111111111111111111111111111111111111111":  "ef0d8b6fda2ceba41da15d4095d1da392a0d2f8ed0c6c7bc0f4cfac8c280b56d", // SOL（原生余额）
+	"So11111111111111111111111111111111111111112":  "ef0d8b6fda2ceba41da15d4095d1da392a0d2f8ed0c6c7bc0f4cfac8c280b56d", // wSOL
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": "eaa020c61cc479712813461ce153894a96a6c00b21ed0cfc2798d1f9a9e9c94a", // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": "2b89b9dc8fdf9f34709a5b106b472f0f39bb6ca9ce04b0fd7f2e971688e2e53b", // USDT
+}
+
+// PythPriceService 基于 Pyth 预言机的价格服务，只覆盖配置了价格源的主流代币
+type PythPriceService struct {
+	client  *http.Client
+	baseURL string
+	feeds   map[string]string // mint地址 -> 价格源ID
+}
+
+// NewPythPriceService 使用默认端点创建 Pyth 价格服务，extraFeeds 可追加或覆盖默认映射
+func NewPythPriceService(extraFeeds map[string]string) *PythPriceService {
+	return NewPythPriceServiceWithConfig(pythAPIEndpoint, extraFeeds, nil)
+}
+
+// NewPythPriceServiceWithConfig 使用指定的端点、价格源映射和HTTP客户端创建 Pyth 价格服务
+func NewPythPriceServiceWithConfig(baseURL string, extraFeeds map[string]string, client *http.Client) *PythPriceService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+
+	feeds := make(map[string]string, len(defaultPythFeeds)+len(extraFeeds))
+	for mint, feed := range defaultPythFeeds {
+		feeds[mint] = feed
+	}
+	for mint, feed := range extraFeeds {
+		feeds[mint] = strings.TrimPrefix(strings.ToLower(feed), "0x")
+	}
+
+	return &PythPriceService{
+		client:  client,
+		baseURL: baseURL,
+		feeds:   feeds,
+	}
+}
+
+// pythConfidence 根据置信区间占价格的比例和更新时间估算可信度
+func pythConfidence(price, conf float64, publishTime time.Time) string {
+	if time.Since(publishTime) > pythMaxStale {
+		return "low"
+	}
+	ratio := conf / price * 100
+	switch {
+	case ratio < 0.5:
+		return "high"
+	case ratio < 2:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// GetTokenPrices 获取已配置价格源的代币价格，未配置的代币直接忽略
+func (s *PythPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	prices := make(map[string]*TokenPrice)
+
+	// 价格源ID -> 使用该价格源的mint地址
+	feedMints := make(map[string][]string)
+	query := url.Values{}
+	for _, mintAddr := range mintAddrs {
+		feed, ok := s.feeds[mintAddr]
+		if !ok {
+			continue
+		}
+		if _, seen := feedMints[feed]; !seen {
+			query.Add("ids[]", feed)
+		}
+		feedMints[feed] = append(feedMints[feed], mintAddr)
+	}
+	if len(feedMints) == 0 {
+		return prices, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return prices, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	observeSourceResponse("pyth", resp, err)
+	if err != nil {
+		return prices, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return prices, fmt.Errorf("Pyth 返回状态码 %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Parsed []struct {
+			ID    string `json:"id"`
+			Price struct {
+				Price       string `json:"price"`
+				Conf        string `json:"conf"`
+				Expo        int    `json:"expo"`
+				PublishTime int64  `json:"publish_time"`
+			} `json:"price"`
+		} `json:"parsed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return prices, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	for _, feed := range result.Parsed {
+		rawPrice, err := strconv.ParseInt(feed.Price.Price, 10, 64)
+		if err != nil {
+			continue
+		}
+		rawConf, _ := strconv.ParseInt(feed.Price.Conf, 10, 64)
+		scale := math.Pow10(feed.Price.Expo)
+		price := float64(rawPrice) * scale
+		if price < minPriceUSD || price > maxPriceUSD {
+			continue
+		}
+
+		publishTime := time.Unix(feed.Price.PublishTime, 0)
+		confidence := pythConfidence(price, float64(rawConf)*scale, publishTime)
+		for _, mintAddr := range feedMints[strings.TrimPrefix(feed.ID, "0x")] {
+			prices[mintAddr] = &TokenPrice{
+				Price:           price,
+				Source:          PriceSourcePyth,
+				Timestamp:       publishTime,
+				ConfidenceLevel: confidence,
+			}
+		}
+	}
+
+	priceLog.Info("从Pyth获取价格完成", "priced", len(prices), "requested", len(mintAddrs))
+	return prices, nil
+}
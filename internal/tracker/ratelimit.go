@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter 令牌桶限流器：每秒补充 rate 个令牌，最多积累 burst 个，空闲后允许短时突发
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数，<=0 表示不限流
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter 创建每秒最多 rps 次请求、最多突发 burst 次的限流器，rps<=0 表示不限流，burst<1 时为1
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst)}
+}
+
+// Wait 阻塞直到取得令牌或 ctx 被取消
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	// 先预留令牌（可以为负），再按欠缺的令牌数计算等待时间，保证并发请求按顺序排队
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// 取消时归还预留的令牌
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var (
+	walletFetchMu      sync.RWMutex
+	walletFetchDelay   time.Duration
+	walletFetchLimiter = newRateLimiter(0, 1)
+)
+
+// SetWalletFetchDelay 设置相邻两个钱包开始获取之间的最小间隔，<=0 表示不限制；间隔不变时保留限流状态
+func SetWalletFetchDelay(delay time.Duration) {
+	walletFetchMu.Lock()
+	defer walletFetchMu.Unlock()
+	if delay == walletFetchDelay {
+		return
+	}
+	walletFetchDelay = delay
+	if delay <= 0 {
+		walletFetchLimiter = newRateLimiter(0, 1)
+		return
+	}
+	walletFetchLimiter = newRateLimiter(float64(time.Second)/float64(delay), 1)
+}
+
+// currentWalletFetchLimiter 返回钱包获取的限流器
+func currentWalletFetchLimiter() *rateLimiter {
+	walletFetchMu.RLock()
+	defer walletFetchMu.RUnlock()
+	return walletFetchLimiter
+}
@@ -0,0 +1,179 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"wallet-tracker/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisConnectTimeout 连接 Redis 时检查可用性的超时
+const redisConnectTimeout = 5 * time.Second
+
+// RedisClient 多个实例共用的 Redis：共享价格缓存、发布报警事件和跨实例报警去重
+type RedisClient struct {
+	client   *redis.Client
+	prefix   string
+	instance string // 本实例标识（主机名:进程号），记录在报警去重键中便于排查
+}
+
+// NewRedisClient 连接 Redis，地址中的 ${ENV} 替换为环境变量
+func NewRedisClient(cfg config.Redis) (*RedisClient, error) {
+	opts, err := redis.ParseURL(os.ExpandEnv(cfg.URL))
+	if err != nil {
+		return nil, fmt.Errorf("解析Redis地址失败: %v", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %v", err)
+	}
+
+	host, _ := os.Hostname()
+	return &RedisClient{
+		client:   client,
+		prefix:   cfg.KeyPrefix,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}, nil
+}
+
+// key 生成带前缀的键名
+func (r *RedisClient) key(kind, id string) string {
+	return r.prefix + ":" + kind + ":" + id
+}
+
+// Close 关闭连接
+func (r *RedisClient) Close() error {
+	return r.client.Close()
+}
+
+// ClaimAlert 尝试取得在 ttl 内发送 key 对应报警的权利，其他实例已取得时返回 false
+func (r *RedisClient) ClaimAlert(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.key("alert", key), r.instance, ttl).Result()
+}
+
+// redisPriceEntry Redis 中缓存的价格，Price 为nil表示数据源没有该代币的价格
+type redisPriceEntry struct {
+	Price *TokenPrice `json:"price"`
+}
+
+// RedisPriceService 以 Redis 作为多个实例共享的价格缓存：缓存中没有的代币才向数据源查询，查询结果写回 Redis；
+// Redis 不可用时直接查询数据源
+type RedisPriceService struct {
+	service QuotePriceService
+	redis   *RedisClient
+	cfg     PriceCacheConfig
+}
+
+// NewRedisPriceService 为价格服务添加共享缓存，新鲜期与本地价格缓存相同
+func NewRedisPriceService(service QuotePriceService, client *RedisClient, cfg PriceCacheConfig) *RedisPriceService {
+	return &RedisPriceService{
+		service: service,
+		redis:   client,
+		cfg:     cfg.normalize(),
+	}
+}
+
+// GetTokenPrices 返回代币价格
+func (s *RedisPriceService) GetTokenPrices(ctx context.Context, mintAddrs []string) (map[string]*TokenPrice, error) {
+	prices := make(map[string]*TokenPrice, len(mintAddrs))
+	if len(mintAddrs) == 0 {
+		return prices, nil
+	}
+
+	keys := make([]string, len(mintAddrs))
+	for i, mintAddr := range mintAddrs {
+		keys[i] = s.redis.key("price", mintAddr)
+	}
+	missing := mintAddrs
+	values, err := s.redis.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		priceLog.Warn("读取Redis价格缓存失败，直接查询数据源", "error", err)
+	} else {
+		missing = nil
+		for i, value := range values {
+			text, ok := value.(string)
+			var entry redisPriceEntry
+			if !ok || json.Unmarshal([]byte(text), &entry) != nil {
+				missing = append(missing, mintAddrs[i])
+				continue
+			}
+			if entry.Price != nil {
+				prices[mintAddrs[i]] = entry.Price
+			}
+		}
+		priceLog.Debug("Redis价格缓存", "hits", len(mintAddrs)-len(missing), "misses", len(missing))
+	}
+	if len(missing) == 0 {
+		return prices, nil
+	}
+
+	fetched, err := s.service.GetTokenPrices(ctx, missing)
+	// 与本地缓存相同，查询失败或被取消时不缓存缺失的价格
+	s.store(ctx, missing, fetched, err == nil && ctx.Err() == nil)
+	for mintAddr, price := range fetched {
+		prices[mintAddr] = price
+	}
+	return prices, err
+}
+
+// store 将查询结果写入 Redis；complete 为true时，数据源没有返回价格的代币按长尾代币缓存为无价格
+func (s *RedisPriceService) store(ctx context.Context, requested []string, fetched map[string]*TokenPrice, complete bool) {
+	pipe := s.redis.client.Pipeline()
+	for _, mintAddr := range requested {
+		price, ok := fetched[mintAddr]
+		if !ok && !complete {
+			continue
+		}
+		ttl := s.cfg.LongTailTTL
+		if ok {
+			ttl = s.cfg.ttlFor(price)
+		}
+		data, err := json.Marshal(redisPriceEntry{Price: price})
+		if err != nil {
+			continue
+		}
+		pipe.Set(ctx, s.redis.key("price", mintAddr), data, ttl)
+	}
+	if pipe.Len() == 0 {
+		return
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		priceLog.Warn("写入Redis价格缓存失败", "error", err)
+	}
+}
+
+// RedisNotifier 将报警以JSON（与 webhook 相同的事件格式）发布到 Redis 频道
+type RedisNotifier struct {
+	redis   *RedisClient
+	channel string
+}
+
+// NewRedisNotifier 创建 Redis 报警通知渠道
+func NewRedisNotifier(client *RedisClient, channel string) *RedisNotifier {
+	return &RedisNotifier{redis: client, channel: channel}
+}
+
+// Notify 发布报警事件
+func (n *RedisNotifier) Notify(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(WebhookEvent{
+		Event:     "alert",
+		Timestamp: alert.Timestamp,
+		Data:      newAlertPayload(alert),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %v", err)
+	}
+	if err := n.redis.client.Publish(ctx, n.channel, payload).Err(); err != nil {
+		return fmt.Errorf("发布到Redis频道 %s 失败: %v", n.channel, err)
+	}
+	return nil
+}
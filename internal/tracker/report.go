@@ -1,11 +1,17 @@
 package tracker
 
 import (
+	"encoding/csv"
 	"fmt"
-	"os"
+	"log/slog"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
 )
 
 // TokenReport 代币报告数据
@@ -19,22 +25,24 @@ type TokenReport struct {
 
 // GenerateReport 生成代币持仓报告
 func GenerateReport(tokens []*TokenData) string {
+	// 复制tokens切片以避免修改监控器共享的数据
+	sortedTokens := make([]*TokenData, len(tokens))
+	copy(sortedTokens, tokens)
+	tokens = sortedTokens
+
 	// 按价值排序
 	sort.Slice(tokens, func(i, j int) bool {
 		return tokens[i].Value > tokens[j].Value
 	})
 
-	// 获取日志级别
-	logLevel := os.Getenv("LOG_LEVEL")
-
 	// 根据日志级别生成不同格式的报告
-	switch logLevel {
-	case "DEBUG":
-		return generateDebugReport(tokens)
-	case "WARN", "ALERT":
+	switch level := logging.Level(); {
+	case level <= slog.LevelDebug:
+		return generateDebugReport(tokens) + generateGroupSections(tokens) + generateWalletSections(tokens) + generatePositionSection(tokens) + generateMoversSection(tokens) + generateAllocationSection(tokens) + generateRiskSection(tokens) + generateNFTSection() + generateDeFiSection(tokens) + generateDerivativesSection()
+	case level >= slog.LevelWarn:
 		return "" // 警告和报警模式不生成报告
 	default:
-		return generateSimpleReport(tokens)
+		return generateSimpleReport(tokens) + generateGroupSections(tokens) + generateWalletSections(tokens) + generatePositionSection(tokens) + generateMoversSection(tokens) + generateAllocationSection(tokens) + generateRiskSection(tokens) + generateNFTSection() + generateDeFiSection(tokens) + generateDerivativesSection()
 	}
 }
 
@@ -49,10 +57,30 @@ func generateSimpleReport(tokens []*TokenData) string {
 		maxTokens = len(tokens)
 	}
 
+	sb.WriteString(degradedNotice())
+
+	// 价格变化率列（需要监控器的历史快照）
+	changes := reportChangeColumns()
+
 	// 生成表格
-	sb.WriteString(fmt.Sprintf("\n%-4s %-16s %16s %16s %10s\n",
-		"#", "代币", "价格", "价值", "占比"))
-	sb.WriteString(strings.Repeat("-", 66) + "\n")
+	table := &reportTable{columns: []tableColumn{
+		{header: "#", width: 4, left: true},
+		{header: i18n.T("代币"), width: 16, left: true},
+		{header: i18n.T("价格"), width: 16},
+	}}
+	table.columns = append(table.columns, changes.columns()...)
+	table.columns = append(table.columns,
+		tableColumn{header: i18n.T("价值"), width: 16},
+		tableColumn{header: i18n.T("流动性"), width: 12},
+		tableColumn{header: i18n.T("质押"), width: 14},
+		tableColumn{header: i18n.T("占比"), width: 10},
+		tableColumn{header: i18n.T("盈亏"), width: 24},
+	)
+	// 跟踪多个钱包时显示各代币由哪些钱包持有
+	showHolders := walletCount(tokens) > 1
+	if showHolders {
+		table.columns = append(table.columns, tableColumn{header: i18n.T("钱包"), left: true})
+	}
 
 	// 先计算总值用于计算占比
 	for _, token := range tokens[:maxTokens] {
@@ -72,21 +100,52 @@ func generateSimpleReport(tokens []*TokenData) string {
 				symbol = "Unknown"
 			}
 		}
+		if token.StaleFor > 0 {
+			symbol += "*"
+		}
 
 		// 计算该代币占总值的百分比
 		percentage := (token.Value / totalValue) * 100
 
-		sb.WriteString(fmt.Sprintf("%-4d %-16s %16.4f %16.2f %9.2f%%\n",
-			i+1,
-			symbol,
-			token.Price,
-			token.Value,
-			percentage))
+		cells := []string{strconv.Itoa(i + 1), symbol, fmt.Sprintf("%.4f", token.Price)}
+		cells = append(cells, changes.cells(token)...)
+		cells = append(cells,
+			fmt.Sprintf("%.2f", token.Value),
+			formatUSDCompact(token.Liquidity),
+			formatStaked(token),
+			fmt.Sprintf("%.2f%%", percentage),
+			colorChange(formatPnL(token), token.PnL))
+		if showHolders {
+			cells = append(cells, holderLabels(token))
+		}
+		table.addRow(cells...)
 	}
+	sb.WriteString("\n" + table.render())
 
-	sb.WriteString(fmt.Sprintf("总值: $%.2f [%s]\n",
+	sb.WriteString(staleFootnote(tokens[:maxTokens]))
+	sb.WriteString(i18n.Sprintf("总值: $%.2f [%s]\n",
 		totalValue,
 		time.Now().Format("15:04:05")))
+	var totalStaked float64
+	for _, token := range tokens[:maxTokens] {
+		totalStaked += stakedValue(token)
+	}
+	if totalStaked > 0 {
+		sb.WriteString(i18n.Sprintf("其中质押: $%.2f\n", totalStaked))
+	}
+	var totalDeFi float64
+	for _, token := range tokens[:maxTokens] {
+		totalDeFi += defiValue(token)
+	}
+	if totalDeFi > 0 {
+		sb.WriteString(i18n.Sprintf("其中DeFi: $%.2f\n", totalDeFi))
+	}
+	if pnl, pnlPct, ok := summarizePnL(tokens[:maxTokens]); ok {
+		sb.WriteString(colorChange(i18n.Sprintf("未实现盈亏: $%+.2f (%+.2f%%)", pnl, pnlPct), pnl) + "\n")
+	}
+	if missing := missingBaselineTokens(tokens); missing > 0 {
+		sb.WriteString(i18n.Sprintf("基准中已不在持仓的代币: %d个\n", missing))
+	}
 
 	return sb.String()
 }
@@ -96,30 +155,73 @@ func generateDebugReport(tokens []*TokenData) string {
 	var sb strings.Builder
 	var totalValue float64
 
-	sb.WriteString("\n详细代币报告\n")
-	sb.WriteString("时间: " + time.Now().Format("15:04:05") + "\n")
+	sb.WriteString(i18n.T("\n详细代币报告\n"))
+	sb.WriteString(i18n.T("时间: ") + time.Now().Format("15:04:05") + "\n")
+	sb.WriteString(strings.TrimPrefix(degradedNotice(), "\n"))
 	sb.WriteString(strings.Repeat("-", 80) + "\n")
 
 	// 显示所有代币的详细信息
 	for i, token := range tokens {
-		sb.WriteString(fmt.Sprintf("代币 #%d: %s\n", i+1, token.Symbol))
-		sb.WriteString(fmt.Sprintf("  Mint地址: %s\n", token.MintAddr))
-		sb.WriteString(fmt.Sprintf("  价格: $%.8f\n", token.Price))
-		sb.WriteString(fmt.Sprintf("  数量: %.8f\n", token.Amount))
-		sb.WriteString(fmt.Sprintf("  价值: $%.2f\n", token.Value))
-		sb.WriteString(fmt.Sprintf("  可信度: %s\n", token.ConfidenceLevel))
+		sb.WriteString(i18n.Sprintf("代币 #%d: %s\n", i+1, token.Symbol))
+		sb.WriteString(i18n.Sprintf("  Mint地址: %s\n", token.MintAddr))
+		sb.WriteString(i18n.Sprintf("  价格: $%.8f\n", token.Price))
+		if token.MarketPrice > 0 {
+			sb.WriteString(i18n.Sprintf("  市场价格: $%.6f (偏离 %+.2f%%)\n", token.MarketPrice, (token.MarketPrice-1)*100))
+		}
+		sb.WriteString(i18n.Sprintf("  数量: %.8f\n", token.Amount))
+		sb.WriteString(i18n.Sprintf("  价值: $%.2f\n", token.Value))
+		sb.WriteString(i18n.Sprintf("  可信度: %s\n", token.ConfidenceLevel))
+		if token.StaleFor > 0 {
+			sb.WriteString(i18n.Sprintf("  价格过期: 数据源未返回，沿用 %s 前的价格\n", token.StaleFor.Round(time.Second)))
+		}
+		if token.Liquidity > 0 {
+			sb.WriteString(i18n.Sprintf("  流动性: $%.2f\n", token.Liquidity))
+		}
+		if token.BuyDepth > 0 || token.SellDepth > 0 {
+			sb.WriteString(i18n.Sprintf("  深度(±2%%): 买入 $%.2f / 卖出 $%.2f\n", token.BuyDepth, token.SellDepth))
+		}
+		if token.Safety != nil {
+			risks := i18n.T("无")
+			if len(token.Safety.Risks) > 0 {
+				risks = strings.Join(displayRisks(token.Safety.Risks), ", ")
+			}
+			sb.WriteString(i18n.Sprintf("  风险分: %d (%s)\n", token.Safety.Score, risks))
+		}
+		if token.Staked > 0 {
+			sb.WriteString(i18n.Sprintf("  质押: %.8f ($%.2f)\n", token.Staked, stakedValue(token)))
+		}
+		if token.DeFi > 0 {
+			sb.WriteString(i18n.Sprintf("  DeFi: %.8f ($%.2f)\n", token.DeFi, defiValue(token)))
+		}
+		if rate, ok := lstExchangeRate(token, tokens); ok {
+			sb.WriteString(i18n.Sprintf("  兑换率: 1 %s = %.6f SOL\n", token.Symbol, rate))
+		}
+		sb.WriteString(i18n.Sprintf("  盈亏: %s\n", formatPnL(token)))
 		sb.WriteString(strings.Repeat("-", 80) + "\n")
 
 		totalValue += token.Value
 	}
 
-	sb.WriteString(fmt.Sprintf("总资产价值: $%.2f\n", totalValue))
+	sb.WriteString(i18n.Sprintf("总资产价值: $%.2f\n", totalValue))
+	return sb.String()
+}
+
+// csvHeader CSV报告的表头
+var csvHeader = []string{"Mint地址", "代币", "数量", "价格(USD)", "价值(USD)", "变化额(USD)", "变化率(%)", "盈亏(USD)", "盈亏率(%)", "时间戳"}
+
+// GenerateCSVHeader 生成CSV报告的表头行
+func GenerateCSVHeader() string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write(translateHeader(csvHeader))
+	w.Flush()
 	return sb.String()
 }
 
-// GenerateCSVReport 生成CSV格式的报告
+// GenerateCSVReport 生成CSV格式的报告数据行（不含表头）
 func GenerateCSVReport(tokens []*TokenData) string {
 	var sb strings.Builder
+	w := csv.NewWriter(&sb)
 
 	// 复制tokens切片以避免修改原始数据
 	sortedTokens := make([]*TokenData, len(tokens))
@@ -130,13 +232,10 @@ func GenerateCSVReport(tokens []*TokenData) string {
 		return sortedTokens[i].Value > sortedTokens[j].Value
 	})
 
-	// 写入CSV头部（如果文件为空的话）
-	if sb.Len() == 0 {
-		sb.WriteString("Mint地址,价格(USD),价值(USD),变化额(USD),变化率(%),时间戳\n")
-	}
-
 	// 写入数据行
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	lastTokenValuesMu.Lock()
+	defer lastTokenValuesMu.Unlock()
 	for _, token := range sortedTokens {
 		// 获取上一次记录的价值（如果有的话）
 		var changeAmount, changeRate float64
@@ -150,18 +249,69 @@ func GenerateCSVReport(tokens []*TokenData) string {
 		// 更新最后一次记录的价值
 		lastTokenValues[token.MintAddr] = token.Value
 
+		// 没有基准成本的代币盈亏列留空
+		var pnl, pnlPct string
+		if token.HasBasis {
+			pnl = strconv.FormatFloat(token.PnL, 'f', 2, 64)
+			pnlPct = strconv.FormatFloat(token.PnLPct, 'f', 2, 64)
+		}
+
 		// 写入CSV行
-		sb.WriteString(fmt.Sprintf("%s,%.8f,%.2f,%.2f,%.2f,%s\n",
+		w.Write([]string{
 			token.MintAddr,
-			token.Price,
-			token.Value,
-			changeAmount,
-			changeRate,
-			timestamp))
+			token.Symbol,
+			strconv.FormatFloat(token.Amount, 'f', 8, 64),
+			strconv.FormatFloat(token.Price, 'f', 8, 64),
+			strconv.FormatFloat(token.Value, 'f', 2, 64),
+			strconv.FormatFloat(changeAmount, 'f', 2, 64),
+			strconv.FormatFloat(changeRate, 'f', 2, 64),
+			pnl,
+			pnlPct,
+			timestamp,
+		})
 	}
 
+	w.Flush()
+	return sb.String()
+}
+
+// portfolioCSVHeader 组合价值序列CSV的表头
+var portfolioCSVHeader = []string{"时间戳", "总价值(USD)", "代币数", "变化率(%)"}
+
+// GeneratePortfolioCSVHeader 生成组合价值序列CSV的表头行
+func GeneratePortfolioCSVHeader() string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write(translateHeader(portfolioCSVHeader))
+	w.Flush()
+	return sb.String()
+}
+
+// translateHeader 按当前语言翻译表头
+func translateHeader(header []string) []string {
+	translated := make([]string, len(header))
+	for i, column := range header {
+		translated[i] = i18n.T(column)
+	}
+	return translated
+}
+
+// GeneratePortfolioCSVRow 生成组合价值序列CSV的单行数据
+func GeneratePortfolioCSVRow(timestamp time.Time, totalValue float64, tokenCount int, changePct float64) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write([]string{
+		timestamp.Format("2006-01-02 15:04:05"),
+		strconv.FormatFloat(totalValue, 'f', 2, 64),
+		strconv.Itoa(tokenCount),
+		strconv.FormatFloat(changePct, 'f', 4, 64),
+	})
+	w.Flush()
 	return sb.String()
 }
 
 // 用于存储每个代币的上一次价值
-var lastTokenValues = make(map[string]float64)
+var (
+	lastTokenValuesMu sync.Mutex
+	lastTokenValues   = make(map[string]float64)
+)
@@ -0,0 +1,110 @@
+package tracker
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+func TestGenerateCSVReportRoundTrip(t *testing.T) {
+	previous := dataDir
+	dir := t.TempDir()
+	SetDataDir(dir)
+	defer SetDataDir(previous)
+
+	snapshots := [][]*TokenData{
+		{
+			{MintAddr: "MintA", Symbol: "AAA", Amount: 10, Price: 2, Value: 20},
+			{MintAddr: "MintQuote", Symbol: `Say "hi", friend`, Amount: 1.5, Price: 4, Value: 6, HasBasis: true, PnL: -1.25, PnLPct: -17.24},
+		},
+		{
+			{MintAddr: "MintA", Symbol: "AAA", Amount: 10, Price: 2.5, Value: 25},
+			{MintAddr: "MintQuote", Symbol: `Say "hi", friend`, Amount: 1.5, Price: 4, Value: 6, HasBasis: true, PnL: -1.25, PnLPct: -17.24},
+		},
+		{
+			{MintAddr: "MintNewline", Symbol: "LINE\nBREAK", Amount: 3, Price: 1, Value: 3},
+		},
+	}
+
+	// 每个快照都通过监控器的CSV文件写入，中途重新打开模拟重启，表头只应出现一次
+	m := NewTokenMonitor(time.Minute, nil)
+	for _, tokens := range snapshots[:2] {
+		if _, err := m.csvFile.WriteString(GenerateCSVReport(tokens)); err != nil {
+			t.Fatalf("写入CSV失败: %v", err)
+		}
+	}
+	m.Stop()
+	m = NewTokenMonitor(time.Minute, nil)
+	if _, err := m.csvFile.WriteString(GenerateCSVReport(snapshots[2])); err != nil {
+		t.Fatalf("写入CSV失败: %v", err)
+	}
+	m.Stop()
+
+	f, err := os.Open(filepath.Join(dir, "monitor.csv"))
+	if err != nil {
+		t.Fatalf("打开CSV失败: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV格式错误: %v", err)
+	}
+
+	header := translateHeader(csvHeader)
+	if len(records) != 6 {
+		t.Fatalf("行数 = %d, 期望 1 行表头 + 5 行数据: %q", len(records), records)
+	}
+	for i, record := range records {
+		if len(record) != len(header) {
+			t.Fatalf("第 %d 行有 %d 列, 期望 %d: %q", i+1, len(record), len(header), record)
+		}
+		isHeader := record[0] == header[0]
+		if isHeader != (i == 0) {
+			t.Fatalf("第 %d 行表头位置错误: %q", i+1, record)
+		}
+	}
+
+	rows := records[1:]
+	// 按价值降序写入，价值变化按同一代币的上一次记录计算
+	want := []struct {
+		mint, symbol, value, change, pnl string
+	}{
+		{"MintA", "AAA", "20.00", "0.00", ""},
+		{"MintQuote", `Say "hi", friend`, "6.00", "0.00", "-1.25"},
+		{"MintA", "AAA", "25.00", "5.00", ""},
+		{"MintQuote", `Say "hi", friend`, "6.00", "0.00", "-1.25"},
+		{"MintNewline", "LINE\nBREAK", "3.00", "0.00", ""},
+	}
+	for i, w := range want {
+		row := rows[i]
+		if row[0] != w.mint || row[1] != w.symbol || row[4] != w.value || row[5] != w.change || row[7] != w.pnl {
+			t.Errorf("第 %d 行数据 = %q, 期望 mint=%s symbol=%q value=%s change=%s pnl=%q",
+				i+1, row, w.mint, w.symbol, w.value, w.change, w.pnl)
+		}
+		if _, err := time.Parse("2006-01-02 15:04:05", row[9]); err != nil {
+			t.Errorf("第 %d 行时间戳无法解析: %q", i+1, row[9])
+		}
+	}
+	if rate, err := strconv.ParseFloat(rows[2][6], 64); err != nil || rate != 25 {
+		t.Errorf("MintA 变化率 = %q, 期望 25.00", rows[2][6])
+	}
+}
+
+func TestGenerateCSVHeader(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(GenerateCSVHeader())).ReadAll()
+	if err != nil {
+		t.Fatalf("表头格式错误: %v", err)
+	}
+	if len(records) != 1 || len(records[0]) != len(csvHeader) {
+		t.Fatalf("表头 = %q", records)
+	}
+	if records[0][0] != i18n.T(csvHeader[0]) {
+		t.Errorf("第一列 = %q, 期望 %q", records[0][0], i18n.T(csvHeader[0]))
+	}
+}
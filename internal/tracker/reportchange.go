@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetChangeColumns 设置报告中价格变化率列的时间窗口，为空时不显示；窗口超出历史快照缓冲区时自动扩容
+func (m *TokenMonitor) SetChangeColumns(windows []time.Duration) {
+	m.historyMu.Lock()
+	m.changeWindows = windows
+	size := m.priceHistory.Len()
+	m.historyMu.Unlock()
+
+	if needed := historySizeFor(windows, m.shortestInterval()); len(windows) > 0 && needed > size {
+		m.resizeHistory(needed)
+	}
+	setReportMonitor(m)
+}
+
+// pastPrices 返回变化率列的时间窗口，以及每个窗口起点各代币的价格（mint地址 -> 价格），
+// 没有窗口起点快照（内存和持久化存储中都没有）时该窗口为空
+func (m *TokenMonitor) pastPrices(now time.Time) ([]time.Duration, []map[string]float64) {
+	m.historyMu.RLock()
+	windows := m.changeWindows
+	m.historyMu.RUnlock()
+
+	prices := make([]map[string]float64, len(windows))
+	for i, window := range windows {
+		prices[i] = make(map[string]float64)
+		old := m.findSnapshotAt(now.Add(-window), m.longestInterval())
+		if old == nil {
+			continue
+		}
+		for mint, past := range old.TokenData {
+			if past.Price > 0 {
+				prices[i][mint] = past.Price
+			}
+		}
+	}
+	return windows, prices
+}
+
+// changeColumns 报告中各代币的价格变化率列
+type changeColumns struct {
+	windows   []time.Duration
+	oldPrices []map[string]float64 // 每个窗口起点各代币的价格
+}
+
+// reportChangeColumns 返回报告的变化率列，未启用时返回nil
+func reportChangeColumns() *changeColumns {
+	monitor := currentReportMonitor()
+	if monitor == nil {
+		return nil
+	}
+	windows, oldPrices := monitor.pastPrices(time.Now())
+	if len(windows) == 0 {
+		return nil
+	}
+	return &changeColumns{windows: windows, oldPrices: oldPrices}
+}
+
+// columns 返回变化率列
+func (c *changeColumns) columns() []tableColumn {
+	if c == nil {
+		return nil
+	}
+	columns := make([]tableColumn, len(c.windows))
+	for i, window := range c.windows {
+		columns[i] = tableColumn{header: formatWindow(window), width: 8}
+	}
+	return columns
+}
+
+// cells 生成代币的变化率单元格，没有历史价格时显示 -
+func (c *changeColumns) cells(token *TokenData) []string {
+	if c == nil {
+		return nil
+	}
+	cells := make([]string, len(c.windows))
+	for i := range c.windows {
+		oldPrice, ok := c.oldPrices[i][token.MintAddr]
+		if !ok || token.Price <= 0 {
+			cells[i] = "-"
+			continue
+		}
+		pct := (token.Price - oldPrice) / oldPrice * 100
+		cells[i] = colorChange(fmt.Sprintf("%+.2f%%", pct), pct)
+	}
+	return cells
+}
@@ -0,0 +1,133 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// diffValueChangePct 数量未变化时，价值变化超过该比例（%）才列出
+const diffValueChangePct = 1.0
+
+// DiffReporter 输出与上一次报告相比的变化：新增和清仓的代币、数量变化以及明显的价值变化，
+// 代替每次重新输出完整的持仓表；第一次调用时输出完整报告
+type DiffReporter struct {
+	mu       sync.Mutex
+	previous map[string]TokenData // mint地址 -> 上一次报告时的代币数据（值拷贝，不受后续更新影响）
+	total    float64
+}
+
+// NewDiffReporter 创建变化报告生成器
+func NewDiffReporter() *DiffReporter {
+	return &DiffReporter{}
+}
+
+// diffRow 一个代币的变化
+type diffRow struct {
+	kind        string
+	symbol      string
+	amount      float64
+	amountDelta float64
+	value       float64
+	valueDelta  float64
+}
+
+// Report 返回与上一次调用相比的变化报告，并记录本次的持仓作为下一次比较的基准
+func (d *DiffReporter) Report(tokens []*TokenData) string {
+	current := make(map[string]TokenData, len(tokens))
+	var total float64
+	for _, token := range tokens {
+		current[token.MintAddr] = *token
+		total += token.Value
+	}
+
+	d.mu.Lock()
+	previous, previousTotal := d.previous, d.total
+	d.previous, d.total = current, total
+	d.mu.Unlock()
+
+	if previous == nil {
+		return GenerateReport(tokens)
+	}
+	return generateDiffReport(previous, current, previousTotal, total)
+}
+
+// generateDiffReport 生成两次持仓之间的变化表和总值变化
+func generateDiffReport(previous, current map[string]TokenData, previousTotal, total float64) string {
+	var rows []diffRow
+	for mintAddr, token := range current {
+		old, existed := previous[mintAddr]
+		switch {
+		case !existed:
+			rows = append(rows, diffRow{kind: i18n.T("新增"), symbol: diffSymbol(token), amount: token.Amount,
+				amountDelta: token.Amount, value: token.Value, valueDelta: token.Value})
+		case amountChanged(old.Amount, token.Amount):
+			rows = append(rows, diffRow{kind: i18n.T("数量"), symbol: diffSymbol(token), amount: token.Amount,
+				amountDelta: token.Amount - old.Amount, value: token.Value, valueDelta: token.Value - old.Value})
+		case old.Value > 0 && abs(token.Value-old.Value)/old.Value*100 >= diffValueChangePct:
+			rows = append(rows, diffRow{kind: i18n.T("价值"), symbol: diffSymbol(token), amount: token.Amount,
+				value: token.Value, valueDelta: token.Value - old.Value})
+		}
+	}
+	for mintAddr, old := range previous {
+		if _, ok := current[mintAddr]; !ok {
+			rows = append(rows, diffRow{kind: i18n.T("清仓"), symbol: diffSymbol(old),
+				amountDelta: -old.Amount, valueDelta: -old.Value})
+		}
+	}
+	// 价值变化最大的排在前面
+	sort.Slice(rows, func(i, j int) bool {
+		if abs(rows[i].valueDelta) != abs(rows[j].valueDelta) {
+			return abs(rows[i].valueDelta) > abs(rows[j].valueDelta)
+		}
+		return rows[i].symbol < rows[j].symbol
+	})
+
+	var sb strings.Builder
+	if len(rows) == 0 {
+		sb.WriteString(i18n.T("持仓无变化") + "\n")
+	} else {
+		table := &reportTable{columns: []tableColumn{
+			{header: i18n.T("变化"), width: 6, left: true},
+			{header: i18n.T("代币"), width: 16, left: true},
+			{header: i18n.T("数量"), width: 14},
+			{header: i18n.T("数量变化"), width: 14},
+			{header: i18n.T("价值"), width: 12},
+			{header: i18n.T("价值变化"), width: 12},
+		}}
+		for _, row := range rows {
+			amountDelta := ""
+			if row.amountDelta != 0 {
+				amountDelta = fmt.Sprintf("%+.4f", row.amountDelta)
+			}
+			table.addRow(row.kind, row.symbol, fmt.Sprintf("%.4f", row.amount), amountDelta,
+				fmt.Sprintf("%.2f", row.value), colorChange(fmt.Sprintf("%+.2f", row.valueDelta), row.valueDelta))
+		}
+		sb.WriteString("\n" + table.render())
+	}
+
+	change := total - previousTotal
+	changePct := 0.0
+	if previousTotal > 0 {
+		changePct = change / previousTotal * 100
+	}
+	sb.WriteString(colorChange(i18n.Sprintf("总值: $%.2f (%+.2f, %+.2f%%) [%s]",
+		total, change, changePct, time.Now().Format("15:04:05")), change) + "\n")
+	return sb.String()
+}
+
+// diffSymbol 返回变化表中显示的代币名称
+func diffSymbol(token TokenData) string {
+	symbol := token.Symbol
+	if symbol == "" || symbol == "UNKNOWN" {
+		symbol = token.Name
+	}
+	if symbol == "" {
+		symbol = "Unknown"
+	}
+	return truncateSymbol(symbol)
+}
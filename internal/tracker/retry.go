@@ -0,0 +1,122 @@
+package tracker
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	retryBaseDelay = time.Second      // 第一次重试的退避时长
+	retryMaxDelay  = 30 * time.Second // 指数退避的上限
+	retryAfterMax  = 2 * time.Minute  // Retry-After 超过该时长时放弃本次请求，不再等待
+)
+
+// retryBackoff 返回第 attempt 次重试（从1开始）的等待时长：指数退避，在 [d/2, d) 内随机抖动，避免多个请求同时重试
+func retryBackoff(attempt int) time.Duration {
+	d := retryBaseDelay << uint(attempt-1)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// SourceStats 单个数据源的请求统计
+type SourceStats struct {
+	Source      string    `json:"source"`
+	Requests    int64     `json:"requests"`
+	Retries     int64     `json:"retries"`
+	Errors      int64     `json:"errors"`
+	RateLimited int64     `json:"rate_limited"` // 返回429的次数
+	ErrorRate   float64   `json:"error_rate"`   // 错误数占请求数的比例
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+var (
+	sourceStatsMu sync.Mutex
+	sourceStats   = make(map[string]*SourceStats)
+)
+
+// sourceStatsFor 返回数据源的统计，调用方需持有 sourceStatsMu
+func sourceStatsFor(source string) *SourceStats {
+	stats, ok := sourceStats[source]
+	if !ok {
+		stats = &SourceStats{Source: source}
+		sourceStats[source] = stats
+	}
+	return stats
+}
+
+// observeSourceResponse 记录一次请求的结果：请求失败或非2xx状态码计为错误，429另外计为限流
+func observeSourceResponse(source string, resp *http.Response, err error) {
+	sourceStatsMu.Lock()
+	defer sourceStatsMu.Unlock()
+	stats := sourceStatsFor(source)
+	stats.Requests++
+
+	var errText string
+	switch {
+	case err != nil:
+		errText = err.Error()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		stats.RateLimited++
+		errText = resp.Status
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		errText = resp.Status
+	default:
+		return
+	}
+	stats.Errors++
+	stats.LastError = errText
+	stats.LastErrorAt = time.Now()
+}
+
+// observeSourceRetry 记录一次重试
+func observeSourceRetry(source string) {
+	sourceStatsMu.Lock()
+	defer sourceStatsMu.Unlock()
+	sourceStatsFor(source).Retries++
+}
+
+// AllSourceStats 返回各数据源的请求、重试、错误、限流统计和错误率，按名称排序
+func AllSourceStats() []SourceStats {
+	sourceStatsMu.Lock()
+	defer sourceStatsMu.Unlock()
+	stats := make([]SourceStats, 0, len(sourceStats))
+	for _, s := range sourceStats {
+		copied := *s
+		if copied.Requests > 0 {
+			copied.ErrorRate = float64(copied.Errors) / float64(copied.Requests)
+		}
+		stats = append(stats, copied)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Source < stats[j].Source
+	})
+	return stats
+}
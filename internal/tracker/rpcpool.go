@@ -0,0 +1,248 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 备用端点连续失败达到阈值后暂时跳过，冷却后由下一次请求或健康检查探测
+const (
+	rpcFailureThreshold = 2
+	rpcFailureCooldown  = 30 * time.Second
+)
+
+// rpcEndpoint 一个 Solana RPC 端点
+type rpcEndpoint struct {
+	name      string // 主机名，用于日志（地址中可能包含API密钥）
+	url       string
+	paginated bool // 是否支持 Helius 的 getTokenAccountsByOwnerV2 分页
+	breaker   *CircuitBreaker
+}
+
+var (
+	rpcMu         sync.RWMutex
+	rpcFallbacks  []*rpcEndpoint // 配置的备用端点，Helius 端点总是排在它们前面
+	rpcRoundRobin bool
+	rpcNext       uint64 // 轮询计数
+)
+
+// SetRPCEndpoints 设置备用 Solana RPC 端点和选择策略，地址中的 ${ENV} 替换为环境变量；
+// 运行中可重复调用，地址不变的端点保留其健康状态
+func SetRPCEndpoints(urls []string, roundRobin bool) {
+	rpcMu.Lock()
+	defer rpcMu.Unlock()
+
+	existing := make(map[string]*rpcEndpoint, len(rpcFallbacks))
+	for _, endpoint := range rpcFallbacks {
+		existing[endpoint.url] = endpoint
+	}
+
+	endpoints := make([]*rpcEndpoint, 0, len(urls))
+	for _, raw := range urls {
+		endpointURL := os.ExpandEnv(raw)
+		if endpoint, ok := existing[endpointURL]; ok {
+			endpoints = append(endpoints, endpoint)
+			continue
+		}
+		name := endpointURL
+		if u, err := url.Parse(endpointURL); err == nil && u.Host != "" {
+			name = u.Host
+		}
+		endpoints = append(endpoints, &rpcEndpoint{
+			name:    name,
+			url:     endpointURL,
+			breaker: NewCircuitBreaker("rpc:"+name, rpcFailureThreshold, rpcFailureCooldown),
+		})
+	}
+	rpcFallbacks = endpoints
+	rpcRoundRobin = roundRobin
+}
+
+// rpcEndpoint 返回 Helius 的 RPC 端点
+func (s *HeliusService) rpcEndpoint() *rpcEndpoint {
+	return &rpcEndpoint{
+		name:      "helius",
+		url:       fmt.Sprintf("%s/?api-key=%s", s.endpoint, s.apiKey),
+		paginated: true,
+		breaker:   heliusBreaker,
+	}
+}
+
+// rpcCandidates 返回本次请求依次尝试的端点：failover 时 Helius 优先，round_robin 时每次从下一个端点开始
+func rpcCandidates(helius *HeliusService) []*rpcEndpoint {
+	rpcMu.RLock()
+	defer rpcMu.RUnlock()
+
+	var endpoints []*rpcEndpoint
+	if helius != nil {
+		endpoints = append(endpoints, helius.rpcEndpoint())
+	}
+	endpoints = append(endpoints, rpcFallbacks...)
+	if !rpcRoundRobin || len(endpoints) < 2 {
+		return endpoints
+	}
+
+	start := int(atomic.AddUint64(&rpcNext, 1) % uint64(len(endpoints)))
+	rotated := make([]*rpcEndpoint, 0, len(endpoints))
+	rotated = append(rotated, endpoints[start:]...)
+	return append(rotated, endpoints[:start]...)
+}
+
+// withRPCFailover 依次在各端点上执行 fn 直到成功，熔断中的端点直接跳过；
+// 全部失败时返回最后一个错误，所有端点都在熔断中时返回的错误满足 errors.Is(err, ErrCircuitOpen)
+func withRPCFailover(ctx context.Context, helius *HeliusService, fn func(endpoint *rpcEndpoint) error) error {
+	var lastErr, openErr error
+	for i, endpoint := range rpcCandidates(helius) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn(endpoint)
+		if err == nil {
+			if i > 0 {
+				walletLog.Debug("使用备用RPC端点", "endpoint", endpoint.name)
+			}
+			return nil
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			openErr = err
+			continue
+		}
+		walletLog.Warn("RPC端点请求失败，尝试下一个端点", "endpoint", endpoint.name, "error", err)
+		lastErr = err
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	if openErr != nil {
+		return fmt.Errorf("所有RPC端点均不可用: %w", openErr)
+	}
+	return fmt.Errorf("没有可用的RPC端点")
+}
+
+// call 发送 JSON-RPC 请求并将 result 解析到 out
+func (e *rpcEndpoint) call(ctx context.Context, client *http.Client, method string, params interface{}, out interface{}) (err error) {
+	ctx, span := StartSpan(ctx, "rpc."+method, attribute.String("rpc.endpoint", e.name), attribute.String("rpc.method", method))
+	defer func() { EndSpan(span, err) }()
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      fmt.Sprintf("rpc-query-%d", rand.Int()),
+		"method":  method,
+		"params":  params,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.breaker.Do(client, req)
+	if !errors.Is(err, ErrCircuitOpen) {
+		observeSourceResponse("rpc:"+e.name, resp, err)
+	}
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("RPC返回状态码 %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	if result.Error != nil {
+		return &rpcError{message: result.Error.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(result.Result, out); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// rpcError 端点返回的 JSON-RPC 错误
+type rpcError struct {
+	message string
+}
+
+func (e *rpcError) Error() string {
+	return "RPC错误: " + e.message
+}
+
+// fetchNativeBalanceByRPC 使用 getBalance 获取钱包的 SOL 余额（lamports），DAS 不可用时使用
+func fetchNativeBalanceByRPC(ctx context.Context, walletAddr string, helius *HeliusService) (uint64, error) {
+	var balance uint64
+	err := withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+		var result struct {
+			Value uint64 `json:"value"`
+		}
+		if err := endpoint.call(ctx, helius.client, "getBalance", []interface{}{walletAddr}, &result); err != nil {
+			return err
+		}
+		balance = result.Value
+		return nil
+	})
+	return balance, err
+}
+
+// StartRPCHealthCheck 定期对各RPC端点发送 getHealth 请求，结果计入端点的熔断器，使故障端点恢复后能及时重新使用
+func StartRPCHealthCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkRPCHealth(ctx)
+			}
+		}
+	}()
+}
+
+// checkRPCHealth 检查所有端点一次
+func checkRPCHealth(ctx context.Context) {
+	helius, _ := NewHeliusService()
+	client := apiHTTPClient()
+	for _, endpoint := range rpcCandidates(helius) {
+		checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := endpoint.call(checkCtx, client, "getHealth", []interface{}{}, nil)
+		cancel()
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			continue
+		}
+		// 节点落后等情况返回 JSON-RPC 错误，HTTP层面是成功的，需要单独计为失败
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			endpoint.breaker.Record(false)
+		}
+		walletLog.Debug("RPC端点健康检查失败", "endpoint", endpoint.name, "error", err)
+	}
+}
@@ -0,0 +1,330 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// AlertRule 编译后的自定义报警规则。引用代币变量的规则对每个代币分别求值，
+// 只引用组合变量（portfolio.*）的规则每个快照求值一次；条件由不满足变为满足时报警
+type AlertRule struct {
+	Name     string
+	When     *Expr
+	Message  string        // 报警文本，为空时只显示规则名称
+	Notify   []string      // 发送到的通知渠道名称，为空时按级别路由
+	Severity AlertSeverity // 报警级别
+	perToken bool
+	windows  []time.Duration
+}
+
+// 规则中可用的代币变量，另外支持 change_<窗口>（价格变化率%）和 value_change_<窗口>（价值变化率%），如 change_5m
+var ruleTokenVars = map[string]bool{
+	"token.symbol": true,
+	"token.mint":   true,
+	"token.name":   true,
+	"price":        true,
+	"value":        true,
+	"amount":       true,
+	"liquidity":    true,
+	"confidence":   true,
+	"risk_score":   true,
+	"pnl":          true,
+	"pnl_pct":      true,
+}
+
+// parseRuleVar 解析规则变量名，返回是否为代币变量和引用的时间窗口
+func parseRuleVar(name string) (perToken bool, window time.Duration, ok bool) {
+	if ruleTokenVars[name] {
+		return true, 0, true
+	}
+	if name == "portfolio.value" {
+		return false, 0, true
+	}
+	if w, ok := strings.CutPrefix(name, "portfolio.change_"); ok {
+		window, err := parseRuleWindow(w)
+		return false, window, err == nil
+	}
+	for _, prefix := range []string{"value_change_", "change_"} {
+		if w, ok := strings.CutPrefix(name, prefix); ok {
+			window, err := parseRuleWindow(w)
+			return true, window, err == nil
+		}
+	}
+	return false, 0, false
+}
+
+// parseRuleWindow 解析变量名中的时间窗口，如 30s、5m、1h、1d
+func parseRuleWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("时间窗口无效: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	window, err := time.ParseDuration(s)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("时间窗口无效: %s", s)
+	}
+	return window, nil
+}
+
+// CompileRule 编译报警规则，表达式语法错误或引用未知变量时返回错误；severity 为空时为 warn
+func CompileRule(name, when, message string, notify []string, severity string) (*AlertRule, error) {
+	level := SeverityWarn
+	if severity != "" {
+		var err error
+		if level, err = ParseSeverity(severity); err != nil {
+			return nil, fmt.Errorf("规则 %s 的级别无效: %v", name, err)
+		}
+	}
+
+	expr, err := CompileExpr(when, func(v string) bool {
+		_, _, ok := parseRuleVar(v)
+		return ok
+	})
+	if err != nil {
+		return nil, fmt.Errorf("规则 %s 的条件无效: %v", name, err)
+	}
+
+	rule := &AlertRule{Name: name, When: expr, Message: message, Notify: notify, Severity: level}
+	for _, v := range expr.Vars() {
+		perToken, window, _ := parseRuleVar(v)
+		rule.perToken = rule.perToken || perToken
+		if window > 0 {
+			rule.windows = append(rule.windows, window)
+		}
+	}
+	return rule, nil
+}
+
+// ruleWatch 自定义规则及其触发状态
+type ruleWatch struct {
+	mu             sync.Mutex
+	rules          []*AlertRule
+	active         map[string]bool // 条件当前满足的 规则|条件|mint，用于只在变为满足时报警
+	failed         map[string]bool // 已记录过求值错误的规则
+	builtinEnabled bool            // 是否启用内置的价格/价值变化报警
+}
+
+// SetRules 设置自定义报警规则，运行中可重复调用；必要时扩大历史快照缓冲区以覆盖规则中的时间窗口
+func (m *TokenMonitor) SetRules(rules []*AlertRule) {
+	var windows []time.Duration
+	for _, rule := range rules {
+		windows = append(windows, rule.windows...)
+	}
+
+	m.rules.mu.Lock()
+	m.rules.rules = rules
+	m.rules.failed = make(map[string]bool)
+	m.rules.mu.Unlock()
+
+	m.historyMu.Lock()
+	m.ruleWindows = windows
+	size := m.priceHistory.Len()
+	m.historyMu.Unlock()
+
+	if needed := historySizeFor(windows, m.shortestInterval()); needed > size {
+		m.resizeHistory(needed)
+	}
+}
+
+// SetBuiltinAlerts 设置是否启用内置的单币价格/价值变化报警，只使用自定义规则时可关闭
+func (m *TokenMonitor) SetBuiltinAlerts(enabled bool) {
+	m.rules.mu.Lock()
+	defer m.rules.mu.Unlock()
+	m.rules.builtinEnabled = enabled
+}
+
+// builtinAlertsEnabled 返回是否启用内置的价格/价值变化报警
+func (m *TokenMonitor) builtinAlertsEnabled() bool {
+	m.rules.mu.Lock()
+	defer m.rules.mu.Unlock()
+	return m.rules.builtinEnabled
+}
+
+// checkRules 对当前快照求值所有自定义规则
+func (m *TokenMonitor) checkRules(snapshot *PriceSnapshot) {
+	m.rules.mu.Lock()
+	defer m.rules.mu.Unlock()
+	if len(m.rules.rules) == 0 {
+		return
+	}
+	if m.rules.active == nil {
+		m.rules.active = make(map[string]bool)
+	}
+
+	past := make(map[time.Duration]*PriceSnapshot)
+	for _, rule := range m.rules.rules {
+		if !rule.perToken {
+			m.evalRule(rule, &ruleEnv{m: m, snapshot: snapshot, past: past})
+			continue
+		}
+		for _, token := range snapshot.TokenData {
+			m.evalRule(rule, &ruleEnv{m: m, snapshot: snapshot, token: token, past: past})
+		}
+	}
+}
+
+// evalRule 对单个代币（或组合）求值规则，条件由不满足变为满足时报警；调用方需持有 m.rules.mu
+func (m *TokenMonitor) evalRule(rule *AlertRule, env *ruleEnv) {
+	var mintAddr string
+	if env.token != nil {
+		mintAddr = env.token.MintAddr
+	}
+	key := rule.Name + "|" + rule.When.String() + "|" + mintAddr
+
+	matched, err := rule.When.evalBool(env)
+	if err != nil && !errors.Is(err, errExprMissing) {
+		if !m.rules.failed[rule.Name] {
+			m.rules.failed[rule.Name] = true
+			monitorLog.Warn("报警规则求值失败", "rule", rule.Name, "when", rule.When.String(), "error", err)
+		}
+		return
+	}
+	if !matched {
+		delete(m.rules.active, key)
+		return
+	}
+	if m.rules.active[key] {
+		return
+	}
+	m.rules.active[key] = true
+
+	alert := Alert{
+		Type:      AlertTypeRule,
+		Rule:      rule.Name,
+		Notify:    rule.Notify,
+		Severity:  rule.Severity,
+		Timestamp: env.snapshot.Timestamp,
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T("规则报警 [") + rule.Name + "]")
+	if env.token != nil {
+		alert.Wallet = soleHolder(env.token)
+		alert.MintAddr = env.token.MintAddr
+		alert.Symbol = env.token.Symbol
+		alert.NewValue = env.token.Value
+		sb.WriteString(fmt.Sprintf(" - %s (%s)", displaySymbol(env.token), env.token.MintAddr))
+	} else {
+		alert.NewValue = env.snapshot.Value
+		sb.WriteString(i18n.T(" - 组合"))
+	}
+	if rule.Message != "" {
+		sb.WriteString(": " + rule.Message)
+	}
+	sb.WriteString(i18n.T("\n条件: ") + rule.When.String())
+	if env.token != nil {
+		sb.WriteString(i18n.Sprintf("\n当前价格: $%.8f  当前价值: $%.2f  持有钱包: %s",
+			env.token.Price, env.token.Value, holderLabels(env.token)))
+	} else {
+		sb.WriteString(i18n.Sprintf("\n组合价值: $%.2f", env.snapshot.Value))
+	}
+	alert.Message = sb.String()
+	m.emitAlert(alert)
+}
+
+// ruleEnv 规则求值时的变量来源：当前快照、当前代币和按窗口查找的历史快照
+type ruleEnv struct {
+	m        *TokenMonitor
+	snapshot *PriceSnapshot
+	token    *TokenData                       // 组合规则为nil
+	past     map[time.Duration]*PriceSnapshot // 同一次检查中共享的历史快照查找结果
+}
+
+// lookup 返回变量的值
+func (e *ruleEnv) lookup(name string) (interface{}, error) {
+	if name == "portfolio.value" {
+		return e.snapshot.Value, nil
+	}
+	if w, ok := strings.CutPrefix(name, "portfolio.change_"); ok {
+		old := e.pastSnapshot(w)
+		if old == nil || old.Value <= 0 {
+			return nil, errExprMissing
+		}
+		return (e.snapshot.Value - old.Value) / old.Value * 100, nil
+	}
+
+	t := e.token
+	if t == nil {
+		return nil, errExprMissing
+	}
+	switch name {
+	case "token.symbol":
+		return t.Symbol, nil
+	case "token.mint":
+		return t.MintAddr, nil
+	case "token.name":
+		return t.Name, nil
+	case "price":
+		return t.Price, nil
+	case "value":
+		return t.Value, nil
+	case "amount":
+		return t.Amount, nil
+	case "confidence":
+		return t.ConfidenceLevel, nil
+	case "liquidity":
+		if t.Liquidity <= 0 {
+			return nil, errExprMissing
+		}
+		return t.Liquidity, nil
+	case "risk_score":
+		if t.Safety == nil {
+			return nil, errExprMissing
+		}
+		return float64(t.Safety.Score), nil
+	case "pnl", "pnl_pct":
+		if !t.HasBasis {
+			return nil, errExprMissing
+		}
+		if name == "pnl" {
+			return t.PnL, nil
+		}
+		return t.PnLPct, nil
+	}
+
+	valueChange := strings.HasPrefix(name, "value_change_")
+	w := strings.TrimPrefix(strings.TrimPrefix(name, "value_"), "change_")
+	old := e.pastSnapshot(w)
+	if old == nil {
+		return nil, errExprMissing
+	}
+	oldToken, ok := old.TokenData[t.MintAddr]
+	if !ok {
+		return nil, errExprMissing
+	}
+	if valueChange {
+		if oldToken.Value <= 0 {
+			return nil, errExprMissing
+		}
+		return (t.Value - oldToken.Value) / oldToken.Value * 100, nil
+	}
+	if oldToken.Price <= 0 {
+		return nil, errExprMissing
+	}
+	return (t.Price - oldToken.Price) / oldToken.Price * 100, nil
+}
+
+// pastSnapshot 查找窗口起点的历史快照，容差为一个监控间隔
+func (e *ruleEnv) pastSnapshot(w string) *PriceSnapshot {
+	window, err := parseRuleWindow(w)
+	if err != nil {
+		return nil
+	}
+	old, ok := e.past[window]
+	if !ok {
+		old = e.m.findSnapshotAt(e.snapshot.Timestamp.Add(-window), e.m.longestInterval())
+		if old == e.snapshot {
+			old = nil
+		}
+		e.past[window] = old
+	}
+	return old
+}
@@ -0,0 +1,446 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+)
+
+const (
+	rugCheckAPIEndpoint = "https://api.rugcheck.xyz/v1/tokens"
+
+	defaultSafetyRecheck   = 24 * time.Hour // 代币安全检查结果的默认有效期
+	defaultRiskThreshold   = 50             // 风险分达到该值的代币视为高风险
+	lpUnlockedThresholdPct = 50             // LP 锁定比例低于该值视为未锁定
+)
+
+// 各风险项计入的风险分
+const (
+	riskScoreMintAuthority   = 35
+	riskScoreFreezeAuthority = 25
+	riskScoreLPUnlocked      = 25
+	riskScoreDanger          = 10 // RugCheck 报告的每个 danger 级风险
+)
+
+// defaultTrustedMints 按设计保留增发/冻结权限的主流代币，不做安全检查
+var defaultTrustedMints = map[string]bool{
+	"So11111111111111111111111111111111111111111":  true, // SOL（原生余额）
+	"So11111111111111111111111111111111111111112":  true, // wSOL
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": true, // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": true, // USDT
+	"2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo": true, // PYUSD
+}
+
+// TokenSafety 代币安全检查结果
+type TokenSafety struct {
+	MintAuthority   bool     // 是否保留增发权限
+	FreezeAuthority bool     // 是否保留冻结权限
+	LPLockedPct     float64  // 流动性池LP的锁定比例（%），未知时为-1
+	Score           int      // 风险分（0-100），越高越危险
+	Risks           []string // 风险项说明
+	CheckedAt       time.Time
+}
+
+// Risky 返回风险分是否达到阈值
+func (s *TokenSafety) Risky(threshold int) bool {
+	return s != nil && s.Score >= threshold
+}
+
+// SafetyService 检查代币的增发权限、冻结权限和LP锁定情况并打分，
+// 权限从 Helius RPC 读取 mint 账户，LP锁定和其他风险项来自 RugCheck
+type SafetyService struct {
+	helius      *HeliusService // 为nil时不检查链上权限
+	client      *http.Client
+	rugCheckURL string
+	recheck     time.Duration
+	threshold   int
+	trusted     map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]*TokenSafety
+}
+
+// NewSafetyService 创建代币安全检查服务，Helius 配置缺失时只使用 RugCheck
+func NewSafetyService(cfg config.TokenSafety) *SafetyService {
+	helius, err := NewHeliusService()
+	if err != nil {
+		priceLog.Warn("缺少 Helius 配置，代币安全检查不读取链上权限", "error", err)
+		helius = nil
+	}
+	return NewSafetyServiceWithConfig(helius, rugCheckAPIEndpoint, cfg, nil)
+}
+
+// NewSafetyServiceWithConfig 使用指定的 Helius 服务、RugCheck 端点和HTTP客户端创建代币安全检查服务，client 为nil时使用共享客户端
+func NewSafetyServiceWithConfig(helius *HeliusService, rugCheckURL string, cfg config.TokenSafety, client *http.Client) *SafetyService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	recheck := cfg.RecheckInterval
+	if recheck <= 0 {
+		recheck = defaultSafetyRecheck
+	}
+	threshold := cfg.RiskThreshold
+	if threshold <= 0 {
+		threshold = defaultRiskThreshold
+	}
+	trusted := make(map[string]bool, len(defaultTrustedMints)+len(cfg.Trusted))
+	for mint := range defaultTrustedMints {
+		trusted[mint] = true
+	}
+	for _, mint := range cfg.Trusted {
+		trusted[mint] = true
+	}
+	return &SafetyService{
+		helius:      helius,
+		client:      client,
+		rugCheckURL: rugCheckURL,
+		recheck:     recheck,
+		threshold:   threshold,
+		trusted:     trusted,
+		cache:       make(map[string]*TokenSafety),
+	}
+}
+
+var (
+	safetyServiceMu sync.RWMutex
+	safetyService   *SafetyService
+)
+
+// SetSafetyService 设置 UpdateTokenPrices 使用的代币安全检查服务，nil 表示不检查
+func SetSafetyService(service *SafetyService) {
+	safetyServiceMu.Lock()
+	defer safetyServiceMu.Unlock()
+	safetyService = service
+}
+
+func currentSafetyService() *SafetyService {
+	safetyServiceMu.RLock()
+	defer safetyServiceMu.RUnlock()
+	return safetyService
+}
+
+// Threshold 返回高风险代币的风险分阈值
+func (s *SafetyService) Threshold() int {
+	return s.threshold
+}
+
+// Check 返回 Solana 代币的安全检查结果，只检查新出现或结果已过期的代币；受信任代币和EVM代币不检查
+func (s *SafetyService) Check(ctx context.Context, mintAddrs []string) map[string]*TokenSafety {
+	result := make(map[string]*TokenSafety, len(mintAddrs))
+	var missing []string
+
+	now := time.Now()
+	s.mu.Lock()
+	for _, mintAddr := range mintAddrs {
+		if s.trusted[mintAddr] || config.DetectChain(mintAddr) != config.ChainSolana {
+			continue
+		}
+		if safety, ok := s.cache[mintAddr]; ok && now.Sub(safety.CheckedAt) < s.recheck {
+			result[mintAddr] = safety
+			continue
+		}
+		missing = append(missing, mintAddr)
+	}
+	s.mu.Unlock()
+
+	if len(missing) == 0 {
+		return result
+	}
+
+	// 读取链上权限，失败时本轮不缓存，下次重试
+	authorities := make(map[string]mintAuthorities)
+	if s.helius != nil {
+		for i := 0; i < len(missing); i += multipleAccountsLimit {
+			end := i + multipleAccountsLimit
+			if end > len(missing) {
+				end = len(missing)
+			}
+			batch, err := s.helius.fetchMintAuthorities(ctx, missing[i:end])
+			if err != nil {
+				if ctx.Err() == nil {
+					priceLog.Warn("读取代币权限失败", "error", err)
+				}
+				return result
+			}
+			for mint, auth := range batch {
+				authorities[mint] = auth
+			}
+		}
+	}
+
+	for _, mintAddr := range missing {
+		report, err := s.fetchRugCheck(ctx, mintAddr)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result
+			}
+			priceLog.Debug("获取 RugCheck 报告失败", "mint", mintAddr, "error", err)
+		}
+		safety := scoreSafety(authorities[mintAddr], report)
+
+		s.mu.Lock()
+		s.cache[mintAddr] = safety
+		s.mu.Unlock()
+		result[mintAddr] = safety
+
+		if safety.Risky(s.threshold) {
+			priceLog.Info("发现高风险代币", "mint", mintAddr, "score", safety.Score, "risks", strings.Join(safety.Risks, ", "))
+		}
+	}
+	return result
+}
+
+// mintAuthorities mint 账户中的权限设置
+type mintAuthorities struct {
+	mint   bool
+	freeze bool
+}
+
+// fetchMintAuthorities 使用 getMultipleAccounts 读取 mint 账户的增发和冻结权限
+func (s *HeliusService) fetchMintAuthorities(ctx context.Context, mints []string) (map[string]mintAuthorities, error) {
+	var result struct {
+		Result struct {
+			Value []*struct {
+				Data struct {
+					Parsed struct {
+						Type string `json:"type"`
+						Info struct {
+							MintAuthority   *string `json:"mintAuthority"`
+							FreezeAuthority *string `json:"freezeAuthority"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"value"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	url := fmt.Sprintf("%s/?api-key=%s", s.endpoint, s.apiKey)
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      fmt.Sprintf("rpc-query-%d", rand.Int()),
+		"method":  "getMultipleAccounts",
+		"params": []interface{}{
+			mints,
+			map[string]interface{}{
+				"encoding": "jsonParsed",
+			},
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := heliusBreaker.Do(s.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("RPC错误: %s", result.Error.Message)
+	}
+
+	authorities := make(map[string]mintAuthorities)
+	for i, account := range result.Result.Value {
+		if account == nil || i >= len(mints) || account.Data.Parsed.Type != "mint" {
+			continue
+		}
+		info := account.Data.Parsed.Info
+		authorities[mints[i]] = mintAuthorities{
+			mint:   info.MintAuthority != nil && *info.MintAuthority != "",
+			freeze: info.FreezeAuthority != nil && *info.FreezeAuthority != "",
+		}
+	}
+	return authorities, nil
+}
+
+// rugCheckReport RugCheck 报告摘要中需要的字段
+type rugCheckReport struct {
+	LPLockedPct *float64 `json:"lpLockedPct"`
+	Risks       []struct {
+		Name  string `json:"name"`
+		Level string `json:"level"`
+	} `json:"risks"`
+}
+
+// fetchRugCheck 获取代币的 RugCheck 报告摘要
+func (s *SafetyService) fetchRugCheck(ctx context.Context, mintAddr string) (*rugCheckReport, error) {
+	url := fmt.Sprintf("%s/%s/report/summary", s.rugCheckURL, mintAddr)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RugCheck 返回状态码 %d", resp.StatusCode)
+	}
+
+	var report rugCheckReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return &report, nil
+}
+
+// scoreSafety 根据链上权限和 RugCheck 报告计算风险分
+func scoreSafety(auth mintAuthorities, report *rugCheckReport) *TokenSafety {
+	safety := &TokenSafety{
+		MintAuthority:   auth.mint,
+		FreezeAuthority: auth.freeze,
+		LPLockedPct:     -1,
+		CheckedAt:       time.Now(),
+	}
+	if auth.mint {
+		safety.Score += riskScoreMintAuthority
+		safety.Risks = append(safety.Risks, "可增发")
+	}
+	if auth.freeze {
+		safety.Score += riskScoreFreezeAuthority
+		safety.Risks = append(safety.Risks, "可冻结")
+	}
+
+	if report != nil {
+		if report.LPLockedPct != nil {
+			safety.LPLockedPct = *report.LPLockedPct
+			if safety.LPLockedPct < lpUnlockedThresholdPct {
+				safety.Score += riskScoreLPUnlocked
+				safety.Risks = append(safety.Risks, fmt.Sprintf("LP锁定%.0f%%", safety.LPLockedPct))
+			}
+		}
+		for _, risk := range report.Risks {
+			if risk.Level != "danger" {
+				continue
+			}
+			// 权限相关的风险已经由链上数据计入
+			name := strings.ToLower(risk.Name)
+			if strings.Contains(name, "authority") || strings.Contains(name, "lp unlocked") {
+				continue
+			}
+			safety.Score += riskScoreDanger
+			safety.Risks = append(safety.Risks, risk.Name)
+		}
+	}
+
+	if safety.Score > 100 {
+		safety.Score = 100
+	}
+	return safety
+}
+
+// enrichSafety 为代币填充安全检查结果
+func enrichSafety(ctx context.Context, service *SafetyService, tokens []*TokenData) {
+	mintAddrs := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		mintAddrs = append(mintAddrs, token.MintAddr)
+	}
+	results := service.Check(ctx, mintAddrs)
+	for _, token := range tokens {
+		if safety, ok := results[token.MintAddr]; ok {
+			token.Safety = safety
+		}
+	}
+}
+
+// safetyWatch 记录已报警的高风险代币，每个代币只报警一次
+type safetyWatch struct {
+	mu      sync.Mutex
+	alerted map[string]bool
+}
+
+// checkSafety 对首次发现的高风险持仓代币发出报警
+func (m *TokenMonitor) checkSafety(tokens []*TokenData, threshold int) {
+	m.safety.mu.Lock()
+	defer m.safety.mu.Unlock()
+
+	if m.safety.alerted == nil {
+		m.safety.alerted = make(map[string]bool)
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		if !token.Safety.Risky(threshold) || m.safety.alerted[token.MintAddr] {
+			continue
+		}
+		m.safety.alerted[token.MintAddr] = true
+
+		m.emitAlert(Alert{
+			Type:     AlertTypeRisk,
+			MintAddr: token.MintAddr,
+			Symbol:   token.Symbol,
+			NewValue: float64(token.Safety.Score),
+			Message: i18n.Sprintf("高风险代币 - %s (%s) 风险分 %d: %s，持仓价值 $%.2f",
+				displaySymbol(token), token.MintAddr, token.Safety.Score, strings.Join(displayRisks(token.Safety.Risks), ", "), token.Value),
+			Timestamp: now,
+		})
+	}
+}
+
+// displayRisks 按当前语言显示风险项，RugCheck 返回的风险项原样显示
+func displayRisks(risks []string) []string {
+	translated := make([]string, len(risks))
+	for i, risk := range risks {
+		if pct, ok := strings.CutPrefix(risk, "LP锁定"); ok {
+			translated[i] = i18n.T("LP锁定") + pct
+			continue
+		}
+		translated[i] = i18n.T(risk)
+	}
+	return translated
+}
+
+// riskLabel 返回高风险代币的风险分和风险项，未达到阈值时返回空字符串
+func riskLabel(token *TokenData) string {
+	service := currentSafetyService()
+	if service == nil || !token.Safety.Risky(service.Threshold()) {
+		return ""
+	}
+	return i18n.Sprintf("风险分 %d: %s", token.Safety.Score, strings.Join(displayRisks(token.Safety.Risks), ", "))
+}
+
+// generateRiskSection 生成高风险代币部分，没有高风险代币时返回空字符串
+func generateRiskSection(tokens []*TokenData) string {
+	service := currentSafetyService()
+	if service == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, token := range tokens {
+		if !token.Safety.Risky(service.Threshold()) {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString(i18n.Sprintf("\n⚠ 高风险代币\n%-16s %8s %14s  %s\n", i18n.T("代币"), i18n.T("风险分"), i18n.T("价值"), i18n.T("风险项")))
+			sb.WriteString(strings.Repeat("-", 80) + "\n")
+		}
+		symbol := displaySymbol(token)
+		if len(symbol) > 16 {
+			symbol = symbol[:16]
+		}
+		sb.WriteString(fmt.Sprintf("%-16s %8d %14.2f  %s\n",
+			symbol, token.Safety.Score, token.Value, strings.Join(displayRisks(token.Safety.Risks), ", ")))
+	}
+	return sb.String()
+}
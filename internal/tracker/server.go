@@ -0,0 +1,314 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// HoldingResponse 持仓查询接口返回的单个代币数据
+type HoldingResponse struct {
+	Symbol          string        `json:"symbol"`
+	Mint            string        `json:"mint"`
+	Amount          float64       `json:"amount"`
+	Price           float64       `json:"price"`
+	Value           float64       `json:"value"`
+	ConfidenceLevel string        `json:"confidence_level"`
+	StaleSeconds    float64       `json:"stale_seconds,omitempty"` // 沿用上次价格的时长（秒），价格为最新时省略
+	Liquidity       float64       `json:"liquidity,omitempty"`     // 所有交易对的流动性合计（美元）
+	BuyDepth        float64       `json:"buy_depth,omitempty"`     // 价格上涨2%所需的买入金额（美元）
+	SellDepth       float64       `json:"sell_depth,omitempty"`    // 价格下跌2%所需的卖出金额（美元）
+	RiskScore       *int          `json:"risk_score,omitempty"`    // 代币安全检查的风险分，未检查时省略
+	RiskFlags       []string      `json:"risk_flags,omitempty"`    // 风险项说明
+	Change          float64       `json:"change"`                  // 价值变化率 (%/s)
+	Holders         []TokenHolder `json:"holders,omitempty"`       // 持有该代币的各钱包，按数量降序排列
+}
+
+// TotalResponse 总价值查询接口的返回数据
+type TotalResponse struct {
+	TotalValue float64   `json:"total_value"`
+	TokenCount int       `json:"token_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// HistoryResponse 组合价值K线查询接口的返回数据
+type HistoryResponse struct {
+	Interval string    `json:"interval"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Candles  []Candle  `json:"candles"`
+}
+
+const (
+	defaultHistoryInterval = "5m"           // K线查询的默认周期
+	defaultHistoryRange    = 24 * time.Hour // K线查询的默认时间范围
+)
+
+// Server 提供持仓查询的HTTP服务
+type Server struct {
+	monitor *TokenMonitor
+	mux     *http.ServeMux
+	server  *http.Server
+	done    chan struct{} // 关闭时通知长连接退出
+}
+
+// wsUpgrader WebSocket 升级配置，允许跨域的看板页面连接
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// NewServer 创建HTTP查询服务
+func NewServer(addr string, monitor *TokenMonitor) *Server {
+	mux := http.NewServeMux()
+	s := &Server{monitor: monitor, mux: mux, done: make(chan struct{})}
+
+	mux.HandleFunc("/holdings", s.handleHoldings)
+	mux.HandleFunc("/total", s.handleTotal)
+	mux.HandleFunc("/allocation", s.handleAllocation)
+	mux.HandleFunc("/history", s.handleHistory)
+	mux.HandleFunc("/sources", s.handleSources)
+	mux.HandleFunc("/wallets", s.handleWallets)
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/report", s.handleReport)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	s.registerDebugHandlers()
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handle 注册额外的路由，如 webhook 接收端点
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start 在后台启动HTTP服务
+func (s *Server) Start() {
+	go func() {
+		serverLog.Info("HTTP服务已启动", "addr", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverLog.Error("HTTP服务异常退出", "error", err)
+		}
+	}()
+}
+
+// Shutdown 优雅关闭HTTP服务
+func (s *Server) Shutdown(ctx context.Context) error {
+	close(s.done)
+	return s.server.Shutdown(ctx)
+}
+
+// handleHoldings 返回当前持仓列表
+func (s *Server) handleHoldings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, newHoldingResponses(s.monitor.Tokens()))
+}
+
+// handleReport 返回实时生成的HTML报告页面，带 interval/from/to 参数时走势图使用对应范围的K线
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var page string
+	var err error
+	if query := r.URL.Query(); query.Has("interval") || query.Has("from") || query.Has("to") {
+		interval, since, until, qerr := parseHistoryQuery(r)
+		if qerr != nil {
+			http.Error(w, qerr.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err = s.monitor.HTMLReportRange(r.Context(), interval, since, until)
+	} else {
+		page, err = s.monitor.HTMLReport(r.Context())
+	}
+	if err != nil {
+		serverLog.Error("生成HTML报告失败", "error", err)
+		http.Error(w, "生成报告失败", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(page)); err != nil {
+		serverLog.Error("写入HTTP响应失败", "error", err)
+	}
+}
+
+// handleTotal 返回当前总价值
+func (s *Server) handleTotal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	total, updatedAt := s.monitor.TotalValue()
+	writeJSON(w, TotalResponse{
+		TotalValue: total,
+		TokenCount: len(s.monitor.Tokens()),
+		UpdatedAt:  updatedAt,
+	})
+}
+
+// handleAllocation 返回当前持仓的配置和集中度分析
+func (s *Server) handleAllocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, AnalyzeAllocation(s.monitor.Tokens()))
+}
+
+// handleSources 返回各数据源的请求、重试、错误和限流统计
+func (s *Server) handleSources(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, AllSourceStats())
+}
+
+// handleWallets 返回各钱包最近一次获取代币的结果
+func (s *Server) handleWallets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, WalletStatuses())
+}
+
+// handleHistory 返回组合价值的 OHLC K线，参数 interval（1m/5m/1h/1d，默认5m）、from 和 to（RFC3339 或 Unix 秒，默认最近24小时）
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	interval, since, until, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	candles, err := s.monitor.ValueCandles(r.Context(), interval, since, until)
+	if err != nil {
+		serverLog.Error("查询组合K线失败", "error", err)
+		http.Error(w, "查询失败", http.StatusInternalServerError)
+		return
+	}
+	if candles == nil {
+		candles = []Candle{}
+	}
+	writeJSON(w, HistoryResponse{
+		Interval: interval.String(),
+		From:     since,
+		To:       until,
+		Candles:  candles,
+	})
+}
+
+// parseHistoryQuery 解析K线查询参数
+func parseHistoryQuery(r *http.Request) (time.Duration, time.Time, time.Time, error) {
+	query := r.URL.Query()
+	name := query.Get("interval")
+	if name == "" {
+		name = defaultHistoryInterval
+	}
+	interval, err := ParseCandleInterval(name)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+
+	until := time.Now()
+	if v := query.Get("to"); v != "" {
+		if until, err = parseQueryTime(v); err != nil {
+			return 0, time.Time{}, time.Time{}, fmt.Errorf("to 参数无效: %v", err)
+		}
+	}
+	since := until.Add(-defaultHistoryRange)
+	if v := query.Get("from"); v != "" {
+		if since, err = parseQueryTime(v); err != nil {
+			return 0, time.Time{}, time.Time{}, fmt.Errorf("from 参数无效: %v", err)
+		}
+	}
+	if err := checkCandleRange(interval, since, until); err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	return interval, since, until, nil
+}
+
+// parseQueryTime 解析 RFC3339 时间或 Unix 秒
+func parseQueryTime(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// handleWebSocket 通过 WebSocket 推送每个快照
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		serverLog.Warn("WebSocket 握手失败", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.monitor.SubscribeSnapshots()
+	defer unsubscribe()
+
+	// 读取客户端消息以处理关闭帧，客户端断开时结束推送
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown"),
+				time.Now().Add(time.Second))
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(event); err != nil {
+				serverLog.Warn("WebSocket 推送失败", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		serverLog.Error("写入HTTP响应失败", "error", err)
+	}
+}
@@ -0,0 +1,206 @@
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// AlertSeverity 报警级别
+type AlertSeverity string
+
+const (
+	SeverityInfo     AlertSeverity = "info"
+	SeverityWarn     AlertSeverity = "warn"
+	SeverityCritical AlertSeverity = "critical"
+)
+
+// maxDigestEntries 汇总中最多列出的报警数量
+const maxDigestEntries = 50
+
+// severityRank 报警级别的高低顺序
+var severityRank = map[AlertSeverity]int{
+	SeverityInfo:     0,
+	SeverityWarn:     1,
+	SeverityCritical: 2,
+}
+
+// ParseSeverity 解析报警级别 info/warn/critical
+func ParseSeverity(s string) (AlertSeverity, error) {
+	severity := AlertSeverity(strings.ToLower(s))
+	if _, ok := severityRank[severity]; !ok {
+		return "", fmt.Errorf("未知的报警级别: %s（可选 info/warn/critical）", s)
+	}
+	return severity, nil
+}
+
+// fixedSeverities 不按变化幅度划分级别的报警类型
+var fixedSeverities = map[AlertType]AlertSeverity{
+	AlertTypeRisk:            SeverityCritical,
+	AlertTypeDepeg:           SeverityCritical,
+	AlertTypeLiquidation:     SeverityCritical,
+	AlertTypeDivergence:      SeverityWarn,
+	AlertTypePositionReduced: SeverityWarn,
+	AlertTypePriceTarget:     SeverityWarn,
+	AlertTypeRule:            SeverityWarn,
+	AlertTypeDegraded:        SeverityWarn,
+	AlertTypeNewToken:        SeverityInfo,
+	AlertTypeAirdrop:         SeverityInfo,
+	AlertTypeActivity:        SeverityInfo,
+	AlertTypeSummary:         SeverityInfo,
+	AlertTypePegRestored:     SeverityInfo,
+	AlertTypeDigest:          SeverityInfo,
+}
+
+// minSeverities 按变化幅度划分级别的报警类型的最低级别
+var minSeverities = map[AlertType]AlertSeverity{
+	AlertTypeLiquidity:       SeverityWarn,
+	AlertTypeTrailingStop:    SeverityWarn,
+	AlertTypeImpermanentLoss: SeverityWarn,
+}
+
+// SeverityConfig 报警级别划分、按级别路由和静默时段设置
+type SeverityConfig struct {
+	WarnPct     float64                    // 变化幅度达到该比例（%）时为 warn
+	CriticalPct float64                    // 变化幅度达到该比例（%）时为 critical
+	Routes      map[AlertSeverity][]string // 各级别发送到的通知渠道名称，未配置的级别发送到所有渠道
+	QuietStart  string                     // 静默时段开始（HH:MM，本地时间），为空表示不静默
+	QuietEnd    string                     // 静默时段结束（HH:MM，本地时间）
+}
+
+// severityState 报警级别设置和静默期间排队的报警
+type severityState struct {
+	mu         sync.Mutex
+	cfg        SeverityConfig
+	quietStart int // 静默开始，距零点的分钟数
+	quietEnd   int
+	queued     []Alert
+}
+
+// SetSeverityConfig 设置报警级别划分、按级别路由和静默时段，运行中可重复调用
+func (m *TokenMonitor) SetSeverityConfig(cfg SeverityConfig) error {
+	var start, end int
+	if cfg.QuietStart != "" {
+		var err error
+		if start, err = parseClockMinutes(cfg.QuietStart); err != nil {
+			return err
+		}
+		if end, err = parseClockMinutes(cfg.QuietEnd); err != nil {
+			return err
+		}
+	}
+
+	m.severity.mu.Lock()
+	defer m.severity.mu.Unlock()
+	m.severity.cfg = cfg
+	m.severity.quietStart = start
+	m.severity.quietEnd = end
+	return nil
+}
+
+// parseClockMinutes 解析 HH:MM 为距零点的分钟数
+func parseClockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("时间格式无效（应为 HH:MM）: %s", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// severityFor 根据报警类型和变化幅度确定报警级别，已指定级别的报警保持不变
+func (s *severityState) severityFor(alert Alert) AlertSeverity {
+	if alert.Severity != "" {
+		return alert.Severity
+	}
+	if severity, ok := fixedSeverities[alert.Type]; ok {
+		return severity
+	}
+
+	severity := SeverityInfo
+	change := math.Abs(alert.ChangePct)
+	switch {
+	case s.cfg.CriticalPct > 0 && change >= s.cfg.CriticalPct:
+		severity = SeverityCritical
+	case s.cfg.WarnPct > 0 && change >= s.cfg.WarnPct:
+		severity = SeverityWarn
+	}
+	if floor, ok := minSeverities[alert.Type]; ok && severityRank[floor] > severityRank[severity] {
+		severity = floor
+	}
+	return severity
+}
+
+// quietAt 判断时间是否处于静默时段，支持跨零点的时段（如 23:00-07:00）
+func (s *severityState) quietAt(t time.Time) bool {
+	if s.cfg.QuietStart == "" || s.quietStart == s.quietEnd {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if s.quietStart < s.quietEnd {
+		return minute >= s.quietStart && minute < s.quietEnd
+	}
+	return minute >= s.quietStart || minute < s.quietEnd
+}
+
+// route 确定报警的级别和发送渠道；静默时段内的非 critical 报警加入队列并返回 false
+func (m *TokenMonitor) route(alert *Alert) bool {
+	m.severity.mu.Lock()
+	defer m.severity.mu.Unlock()
+
+	alert.Severity = m.severity.severityFor(*alert)
+	if len(alert.Notify) == 0 {
+		alert.Notify = m.severity.cfg.Routes[alert.Severity]
+	}
+	if alert.Severity != SeverityCritical && m.severity.quietAt(time.Now()) {
+		m.severity.queued = append(m.severity.queued, *alert)
+		return false
+	}
+	return true
+}
+
+// flushDigest 静默时段结束后将排队的报警合并为一条汇总发送
+func (m *TokenMonitor) flushDigest() {
+	m.severity.mu.Lock()
+	if len(m.severity.queued) == 0 || m.severity.quietAt(time.Now()) {
+		m.severity.mu.Unlock()
+		return
+	}
+	queued := m.severity.queued
+	m.severity.queued = nil
+	routes := m.severity.cfg.Routes
+	m.severity.mu.Unlock()
+
+	severity := SeverityInfo
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf("静默时段内共有 %d 条报警:", len(queued)))
+	for i, alert := range queued {
+		if severityRank[alert.Severity] > severityRank[severity] {
+			severity = alert.Severity
+		}
+		if i >= maxDigestEntries {
+			continue
+		}
+		summary, _, _ := strings.Cut(alert.Message, "\n")
+		sb.WriteString(fmt.Sprintf("\n[%s] %s %s", alert.Severity, alert.Timestamp.Format("15:04"), summary))
+	}
+	if len(queued) > maxDigestEntries {
+		sb.WriteString(i18n.Sprintf("\n... 另有 %d 条", len(queued)-maxDigestEntries))
+	}
+
+	// 汇总按其中最高的级别路由，只发送通知，不再写入报警日志
+	digest := Alert{
+		Type:      AlertTypeDigest,
+		Severity:  severity,
+		Notify:    routes[severity],
+		Message:   sb.String(),
+		Timestamp: time.Now(),
+	}
+	if m.notifiers != nil {
+		m.notifiers.Dispatch(m.ctx, digest)
+	}
+	notifyLog.Info("已发送静默时段报警汇总", "alerts", len(queued))
+}
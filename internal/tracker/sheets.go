@@ -0,0 +1,461 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// Google API 地址
+const (
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	sheetsAPIURL   = "https://sheets.googleapis.com/v4/spreadsheets/"
+	sheetsScope    = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// sheetsFinalSync 退出时同步持仓表的超时
+const sheetsFinalSync = 10 * time.Second
+
+// googleServiceAccount 服务账号密钥文件中用到的字段
+type googleServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// SheetsClient 通过 Google Sheets API v4 读写表格，使用服务账号签发的 JWT 换取访问令牌
+type SheetsClient struct {
+	client        *http.Client
+	baseURL       string
+	spreadsheetID string
+	email         string
+	tokenURL      string
+	key           *rsa.PrivateKey
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewSheetsClient 读取服务账号密钥文件并创建客户端，表格需共享给该服务账号（编辑权限）
+func NewSheetsClient(spreadsheetID, credentialsFile string) (*SheetsClient, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取服务账号密钥失败: %v", err)
+	}
+	var account googleServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("解析服务账号密钥失败: %v", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("服务账号密钥缺少 client_email 或 private_key")
+	}
+	key, err := parseRSAPrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	return &SheetsClient{
+		client:        apiHTTPClient(),
+		baseURL:       sheetsAPIURL,
+		spreadsheetID: spreadsheetID,
+		email:         account.ClientEmail,
+		tokenURL:      tokenURL,
+		key:           key,
+	}, nil
+}
+
+// parseRSAPrivateKey 解析 PEM 格式的 RSA 私钥（PKCS#8 或 PKCS#1）
+func parseRSAPrivateKey(text string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(text))
+	if block == nil {
+		return nil, fmt.Errorf("服务账号私钥不是有效的PEM格式")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析服务账号私钥失败: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("服务账号私钥不是RSA密钥")
+	}
+	return key, nil
+}
+
+// accessToken 返回访问令牌，过期前一分钟重新获取
+func (c *SheetsClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expiry) > time.Minute {
+		return c.token, nil
+	}
+
+	assertion, err := c.signJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取访问令牌失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("获取访问令牌失败，状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("解析访问令牌失败: %v", err)
+	}
+	c.token = result.AccessToken
+	c.expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+// signJWT 签发用于换取访问令牌的 JWT（RS256），有效期一小时
+func (c *SheetsClient) signJWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   c.email,
+		"scope": sheetsScope,
+		"aud":   c.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("签名JWT失败: %v", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// do 发送 Sheets API 请求，body 和 result 为nil时分别表示没有请求体和忽略返回内容
+func (c *SheetsClient) do(ctx context.Context, method, path string, body, result interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("编码请求失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+url.PathEscape(c.spreadsheetID)+path, reader)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Google Sheets返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// sheetRange 返回工作表中某个范围的 A1 表示法路径片段，工作表名加引号以支持空格和中文
+func sheetRange(sheet, cells string) string {
+	name := "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	if cells != "" {
+		name += "!" + cells
+	}
+	return "/values/" + url.PathEscape(name)
+}
+
+// EnsureSheets 创建表格中尚不存在的工作表
+func (c *SheetsClient) EnsureSheets(ctx context.Context, titles ...string) error {
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.do(ctx, "GET", "?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		existing[sheet.Properties.Title] = true
+	}
+
+	var requests []interface{}
+	for _, title := range titles {
+		if existing[title] {
+			continue
+		}
+		existing[title] = true
+		requests = append(requests, map[string]interface{}{
+			"addSheet": map[string]interface{}{
+				"properties": map[string]string{"title": title},
+			},
+		})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	return c.do(ctx, "POST", ":batchUpdate", map[string]interface{}{"requests": requests}, nil)
+}
+
+// ReplaceValues 清空工作表后从 A1 开始写入各行
+func (c *SheetsClient) ReplaceValues(ctx context.Context, sheet string, rows [][]interface{}) error {
+	if err := c.do(ctx, "POST", sheetRange(sheet, "")+":clear", map[string]interface{}{}, nil); err != nil {
+		return err
+	}
+	// 使用 RAW 写入：代币符号由发行者决定，以 = 开头时不能被当作公式执行
+	return c.do(ctx, "PUT", sheetRange(sheet, "A1")+"?valueInputOption=RAW", map[string]interface{}{"values": rows}, nil)
+}
+
+// AppendValues 在工作表已有数据之后追加各行
+func (c *SheetsClient) AppendValues(ctx context.Context, sheet string, rows [][]interface{}) error {
+	path := sheetRange(sheet, "A1") + ":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS"
+	return c.do(ctx, "POST", path, map[string]interface{}{"values": rows}, nil)
+}
+
+// IsEmpty 判断工作表的 A1 单元格是否为空
+func (c *SheetsClient) IsEmpty(ctx context.Context, sheet string) (bool, error) {
+	var result struct {
+		Values [][]interface{} `json:"values"`
+	}
+	if err := c.do(ctx, "GET", sheetRange(sheet, "A1"), nil, &result); err != nil {
+		return false, err
+	}
+	return len(result.Values) == 0, nil
+}
+
+// sheetsDay 一天内快照的组合总值统计
+type sheetsDay struct {
+	date      time.Time // 本地时间零点
+	open      float64
+	close     float64
+	high      float64
+	low       float64
+	snapshots int
+}
+
+// row 返回该天的汇总行
+func (d *sheetsDay) row() []interface{} {
+	var changePct float64
+	if d.open > 0 {
+		changePct = (d.close - d.open) / d.open * 100
+	}
+	return []interface{}{d.date.Format("2006-01-02"), d.open, d.close, d.high, d.low, changePct, d.snapshots}
+}
+
+// SheetsSync 订阅监控器的快照，定时将当前持仓表写入一个工作表，每天结束时向另一个工作表追加一行当天的汇总（开盘、收盘、最高、最低）。
+// 汇总只统计本进程运行期间的快照，当天中途启动时开盘价为启动后的第一个快照
+type SheetsSync struct {
+	client        *SheetsClient
+	holdingsSheet string
+	summarySheet  string
+	interval      time.Duration
+
+	// 以下字段只由同步goroutine访问
+	latest  *SnapshotEvent
+	dirty   bool            // 持仓表有尚未同步的快照
+	day     *sheetsDay      // 当天的统计
+	pending [][]interface{} // 尚未成功追加的汇总行
+	ready   bool            // 工作表和表头已创建
+	done    chan struct{}
+}
+
+// NewSheetsSync 创建 Google Sheets 同步
+func NewSheetsSync(client *SheetsClient, holdingsSheet, summarySheet string, interval time.Duration) *SheetsSync {
+	return &SheetsSync{
+		client:        client,
+		holdingsSheet: holdingsSheet,
+		summarySheet:  summarySheet,
+		interval:      interval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Run 订阅快照并在后台同步，直到 ctx 结束；结束时同步最后的持仓表
+func (s *SheetsSync) Run(ctx context.Context, monitor *TokenMonitor) {
+	events, unsubscribe := monitor.SubscribeSnapshots()
+	go func() {
+		defer close(s.done)
+		defer unsubscribe()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				syncCtx, cancel := context.WithTimeout(context.Background(), sheetsFinalSync)
+				s.sync(syncCtx)
+				cancel()
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				s.record(event)
+			case <-ticker.C:
+				s.sync(ctx)
+			}
+		}
+	}()
+}
+
+// Close 等待最后一次同步完成，需在 Run 的 ctx 结束后调用
+func (s *SheetsSync) Close() {
+	<-s.done
+	if len(s.pending) > 0 {
+		sheetsLog.Warn("退出时仍有汇总行未写入Google Sheets", "rows", len(s.pending))
+	}
+}
+
+// record 记录快照并更新当天的统计，日期变化时将前一天的汇总加入待写入列表
+func (s *SheetsSync) record(event SnapshotEvent) {
+	s.latest = &event
+	s.dirty = true
+
+	local := event.Timestamp.Local()
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if s.day != nil && !date.Equal(s.day.date) {
+		s.pending = append(s.pending, s.day.row())
+		s.day = nil
+	}
+	if s.day == nil {
+		s.day = &sheetsDay{date: date, open: event.TotalValue, high: event.TotalValue, low: event.TotalValue}
+	}
+	s.day.close = event.TotalValue
+	if event.TotalValue > s.day.high {
+		s.day.high = event.TotalValue
+	}
+	if event.TotalValue < s.day.low {
+		s.day.low = event.TotalValue
+	}
+	s.day.snapshots++
+}
+
+// sync 写入有变化的持仓表和待写入的汇总行，失败时保留到下次同步
+func (s *SheetsSync) sync(ctx context.Context) {
+	if !s.dirty && len(s.pending) == 0 {
+		return
+	}
+	if !s.ready {
+		if err := s.prepare(ctx); err != nil {
+			sheetsLog.Error("准备Google Sheets工作表失败，稍后重试", "error", err)
+			return
+		}
+		s.ready = true
+	}
+
+	if s.dirty {
+		if err := s.client.ReplaceValues(ctx, s.holdingsSheet, holdingsRows(*s.latest)); err != nil {
+			sheetsLog.Error("同步持仓表到Google Sheets失败，稍后重试", "sheet", s.holdingsSheet, "error", err)
+		} else {
+			s.dirty = false
+			sheetsLog.Debug("已同步持仓表到Google Sheets", "sheet", s.holdingsSheet, "tokens", len(s.latest.Tokens))
+		}
+	}
+	if len(s.pending) > 0 {
+		if err := s.client.AppendValues(ctx, s.summarySheet, s.pending); err != nil {
+			sheetsLog.Error("追加每日汇总到Google Sheets失败，稍后重试", "sheet", s.summarySheet, "error", err)
+		} else {
+			sheetsLog.Info("已追加每日汇总到Google Sheets", "sheet", s.summarySheet, "rows", len(s.pending))
+			s.pending = nil
+		}
+	}
+}
+
+// prepare 创建缺少的工作表，汇总工作表为空时写入表头
+func (s *SheetsSync) prepare(ctx context.Context) error {
+	if err := s.client.EnsureSheets(ctx, s.holdingsSheet, s.summarySheet); err != nil {
+		return err
+	}
+	empty, err := s.client.IsEmpty(ctx, s.summarySheet)
+	if err != nil || !empty {
+		return err
+	}
+	header := []interface{}{i18n.T("日期"), i18n.T("开盘"), i18n.T("收盘"), i18n.T("最高"), i18n.T("最低"), i18n.T("变化率(%)"), i18n.T("快照数")}
+	return s.client.AppendValues(ctx, s.summarySheet, [][]interface{}{header})
+}
+
+// holdingsRows 生成持仓表：表头、各代币一行，最后是总价值和更新时间
+func holdingsRows(event SnapshotEvent) [][]interface{} {
+	rows := make([][]interface{}, 0, len(event.Tokens)+4)
+	rows = append(rows, []interface{}{i18n.T("代币"), "Mint", i18n.T("数量"), i18n.T("价格"), i18n.T("价值"), i18n.T("占比(%)"), i18n.T("可信度"), i18n.T("钱包")})
+	for _, token := range event.Tokens {
+		var share float64
+		if event.TotalValue > 0 {
+			share = token.Value / event.TotalValue * 100
+		}
+		rows = append(rows, []interface{}{token.Symbol, token.Mint, token.Amount, token.Price, token.Value, share, token.ConfidenceLevel, holdersText(token.Holders)})
+	}
+	rows = append(rows,
+		[]interface{}{},
+		[]interface{}{i18n.T("总价值"), "", "", "", event.TotalValue},
+		[]interface{}{i18n.T("更新时间"), event.Timestamp.Local().Format("2006-01-02 15:04:05")},
+	)
+	return rows
+}
+
+// holdersText 将持有钱包格式化为一个单元格，如 "main: 1200, cold: 800"
+func holdersText(holders []TokenHolder) string {
+	parts := make([]string, len(holders))
+	for i, holder := range holders {
+		parts[i] = fmt.Sprintf("%s: %g", holder.Label, holder.Amount)
+	}
+	return strings.Join(parts, ", ")
+}
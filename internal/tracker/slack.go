@@ -0,0 +1,190 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// slackSummaryTopN 每日汇总中列出的代币数量
+const slackSummaryTopN = 10
+
+// SlackNotifier 通过 Slack incoming webhook 发送报警和每日汇总
+type SlackNotifier struct {
+	client     *http.Client
+	webhookURL string
+}
+
+// NewSlackNotifier 创建 Slack webhook 通知渠道
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return NewSlackNotifierWithConfig(webhookURL, nil)
+}
+
+// NewSlackNotifierWithConfig 使用指定的HTTP客户端创建 Slack 通知渠道，client 为nil时使用共享客户端
+func NewSlackNotifierWithConfig(webhookURL string, client *http.Client) *SlackNotifier {
+	if client == nil {
+		client = notifyHTTPClient()
+	}
+	return &SlackNotifier{
+		client:     client,
+		webhookURL: webhookURL,
+	}
+}
+
+// slackBlock Block Kit 中的一个块
+type slackBlock map[string]interface{}
+
+func slackText(text string) slackBlock {
+	return slackBlock{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": text},
+	}
+}
+
+func slackHeader(text string) slackBlock {
+	return slackBlock{
+		"type": "header",
+		"text": map[string]string{"type": "plain_text", "text": text},
+	}
+}
+
+func slackContext(text string) slackBlock {
+	return slackBlock{
+		"type":     "context",
+		"elements": []map[string]string{{"type": "mrkdwn", "text": text}},
+	}
+}
+
+// Notify 将报警格式化为 Block Kit 消息发送到 Slack
+func (s *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	icon := ":large_yellow_circle:"
+	if alert.ChangePct > 0 {
+		icon = ":chart_with_upwards_trend:"
+	} else if alert.ChangePct < 0 {
+		icon = ":chart_with_downwards_trend:"
+	}
+
+	var fields []map[string]string
+	addField := func(name, value string) {
+		fields = append(fields, map[string]string{"type": "mrkdwn", "text": fmt.Sprintf("*%s*\n%s", name, value)})
+	}
+	if alert.Severity != "" {
+		addField(i18n.T("级别"), string(alert.Severity))
+	}
+	if alert.Symbol != "" {
+		addField(i18n.T("代币"), alert.Symbol)
+	}
+	if alert.ChangePct != 0 {
+		addField(i18n.T("变化"), fmt.Sprintf("%+.2f%%", alert.ChangePct))
+	}
+	if alert.Window > 0 {
+		addField(i18n.T("窗口"), alert.Window.String())
+	}
+	if alert.Wallet != "" {
+		addField(i18n.T("钱包"), WalletLabel(alert.Wallet))
+	}
+
+	blocks := []slackBlock{
+		slackHeader(icon + " " + alertTitle(alert)),
+		slackText(alert.Message),
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, slackBlock{"type": "section", "fields": fields})
+	}
+	if url := tokenURL(alert.Chain, alert.MintAddr); url != "" {
+		blocks = append(blocks, slackContext(fmt.Sprintf("<%s|%s>", url, alert.MintAddr)))
+	}
+
+	return s.post(ctx, alertTitle(alert), blocks)
+}
+
+// SendSummary 发送组合汇总消息：总价值和价值最高的代币
+func (s *SlackNotifier) SendSummary(ctx context.Context, tokens []*TokenData) error {
+	sorted := make([]*TokenData, len(tokens))
+	copy(sorted, tokens)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Value > sorted[j].Value
+	})
+
+	var total float64
+	for _, token := range sorted {
+		total += token.Value
+	}
+
+	var lines []string
+	for i, token := range sorted {
+		if i >= slackSummaryTopN {
+			break
+		}
+		var pct float64
+		if total > 0 {
+			pct = token.Value / total * 100
+		}
+		lines = append(lines, fmt.Sprintf("%d. *%s* $%.2f (%.1f%%) %s",
+			i+1, displaySymbol(token), token.Value, pct, formatPnL(token)))
+	}
+
+	blocks := []slackBlock{
+		slackHeader(":bar_chart: " + i18n.T("每日组合汇总")),
+		slackText(i18n.Sprintf("*总价值:* $%.2f\n*代币数:* %d", total, len(sorted))),
+	}
+	if len(lines) > 0 {
+		blocks = append(blocks, slackText(strings.Join(lines, "\n")))
+	}
+	if pnl, pnlPct, ok := summarizePnL(sorted); ok {
+		blocks = append(blocks, slackContext(i18n.Sprintf("未实现盈亏: $%+.2f (%+.2f%%)", pnl, pnlPct)))
+	}
+
+	return s.post(ctx, i18n.Sprintf("每日组合汇总: $%.2f", total), blocks)
+}
+
+// RunDailySummary 每天在 at（HH:MM，本地时间）发送一次组合汇总，直到 ctx 结束
+func (s *SlackNotifier) RunDailySummary(ctx context.Context, monitor *TokenMonitor, at string) error {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("汇总时间格式无效（应为 HH:MM）: %v", err)
+	}
+
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			sendCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			if err := s.SendSummary(sendCtx, monitor.Tokens()); err != nil {
+				notifyLog.Error("发送Slack每日汇总失败", "error", err)
+			}
+			cancel()
+		}
+	}()
+	return nil
+}
+
+// post 发送 Block Kit 消息，text 为不支持 blocks 的客户端显示的摘要
+func (s *SlackNotifier) post(ctx context.Context, text string, blocks []slackBlock) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":   text,
+		"blocks": blocks,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %v", err)
+	}
+	return postJSON(ctx, s.client, s.webhookURL, payload, nil)
+}
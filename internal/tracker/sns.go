@@ -0,0 +1,215 @@
+package tracker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// Bonfida SNS（Solana Name Service）的程序和 .sol 顶级域名账户
+var (
+	snsNameProgramID = common.PublicKeyFromString("namesLPneVptA9Z5rqUDD9tMTWEJwofgaYwp8cawRkX")
+	snsSolRoot       = common.PublicKeyFromString("58PwtjSDuFHuUkYjH9BYnnQKHfwo9reZhC2zMJv9JPkx")
+)
+
+// snsHashPrefix 计算域名账户地址前加在名称前的固定前缀
+const snsHashPrefix = "SPL Name Service"
+
+// snsRegistryHeader 域名账户数据的头部：parent(32) + owner(32) + class(32)
+const snsRegistryHeader = 96
+
+// IsSNSDomain 判断钱包地址是否为 .sol 域名（如 bonfida.sol、sub.bonfida.sol）
+func IsSNSDomain(address string) bool {
+	return strings.HasSuffix(strings.ToLower(address), ".sol")
+}
+
+// snsEntry 一个域名的解析结果
+type snsEntry struct {
+	Address    string    `json:"address"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// snsResolver 解析 .sol 域名并缓存结果，设置了文件路径时保存到磁盘，重启后无需重新解析
+type snsResolver struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration // 超过该时长的解析结果重新解析，以发现域名转让
+	entries map[string]snsEntry
+}
+
+var sns = &snsResolver{
+	ttl:     time.Hour,
+	entries: make(map[string]snsEntry),
+}
+
+// SetSNSCache 设置域名解析缓存的文件路径和有效期，并加载已缓存的解析结果；path 为空时只缓存在内存中
+func SetSNSCache(path string, ttl time.Duration) error {
+	sns.mu.Lock()
+	defer sns.mu.Unlock()
+	if ttl > 0 {
+		sns.ttl = ttl
+	}
+	if path == sns.path {
+		return nil
+	}
+	sns.path = path
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取域名解析缓存失败: %v", err)
+	}
+	var cached map[string]snsEntry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return fmt.Errorf("解析域名解析缓存失败: %v", err)
+	}
+	for domain, entry := range cached {
+		if _, ok := sns.entries[domain]; !ok {
+			sns.entries[domain] = entry
+		}
+	}
+	return nil
+}
+
+// ExpireSNSCache 使所有缓存的解析结果过期，下次解析时重新查询（查询失败时仍沿用缓存的地址）
+func ExpireSNSCache() {
+	sns.mu.Lock()
+	defer sns.mu.Unlock()
+	for domain, entry := range sns.entries {
+		entry.ResolvedAt = time.Time{}
+		sns.entries[domain] = entry
+	}
+}
+
+// ResolveSNSDomain 将 .sol 域名解析为所有者的钱包地址；缓存未过期时直接返回，
+// 重新解析失败时沿用缓存中的地址，从未解析成功时返回错误
+func ResolveSNSDomain(ctx context.Context, domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	sns.mu.Lock()
+	entry, cached := sns.entries[domain]
+	fresh := cached && time.Since(entry.ResolvedAt) < sns.ttl
+	sns.mu.Unlock()
+	if fresh {
+		return entry.Address, nil
+	}
+
+	address, err := lookupSNSOwner(ctx, domain)
+	if err != nil {
+		if cached {
+			walletLog.Warn("重新解析域名失败，沿用上次的解析结果", "domain", domain, "address", entry.Address, "error", err)
+			return entry.Address, nil
+		}
+		return "", err
+	}
+	if cached && entry.Address != address {
+		walletLog.Warn("域名所有者已变化", "domain", domain, "old", entry.Address, "new", address)
+	} else if !cached {
+		walletLog.Info("已解析钱包域名", "domain", domain, "address", address)
+	}
+
+	sns.mu.Lock()
+	defer sns.mu.Unlock()
+	sns.entries[domain] = snsEntry{Address: address, ResolvedAt: time.Now()}
+	if err := sns.save(); err != nil {
+		walletLog.Warn("保存域名解析缓存失败", "error", err)
+	}
+	return address, nil
+}
+
+// save 将解析结果写入缓存文件，调用方需持有锁
+func (r *snsResolver) save() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化域名解析缓存失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// snsDomainKey 计算域名账户地址：一级域名的父账户为 .sol 根账户，子域名（sub.name.sol）的父账户为一级域名账户，名称前加 \x00
+func snsDomainKey(domain string) (common.PublicKey, error) {
+	name := strings.TrimSuffix(domain, ".sol")
+	labels := strings.Split(name, ".")
+	if name == "" || len(labels) > 2 {
+		return common.PublicKey{}, fmt.Errorf("无效的域名: %s", domain)
+	}
+
+	key, err := snsNameKey(labels[len(labels)-1], snsSolRoot)
+	if err != nil || len(labels) == 1 {
+		return key, err
+	}
+	return snsNameKey("\x00"+labels[0], key)
+}
+
+// snsNameKey 计算名称账户地址：种子为 sha256(前缀+名称)、空的 class 和父账户
+func snsNameKey(name string, parent common.PublicKey) (common.PublicKey, error) {
+	hashed := sha256.Sum256([]byte(snsHashPrefix + name))
+	key, _, err := common.FindProgramAddress([][]byte{
+		hashed[:],
+		make([]byte, 32),
+		parent.Bytes(),
+	}, snsNameProgramID)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("计算域名账户地址失败: %v", err)
+	}
+	return key, nil
+}
+
+// lookupSNSOwner 读取域名账户，返回其中记录的所有者地址，可使用备用RPC端点
+func lookupSNSOwner(ctx context.Context, domain string) (string, error) {
+	key, err := snsDomainKey(domain)
+	if err != nil {
+		return "", err
+	}
+
+	helius, _ := NewHeliusService()
+	client := apiHTTPClient()
+	if helius != nil {
+		client = helius.client
+	}
+	var result struct {
+		Value *struct {
+			Data []string `json:"data"`
+		} `json:"value"`
+	}
+	err = withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+		params := []interface{}{key.ToBase58(), map[string]interface{}{"encoding": "base64"}}
+		return endpoint.call(ctx, client, "getAccountInfo", params, &result)
+	})
+	if err != nil {
+		return "", fmt.Errorf("读取域名账户失败: %v", err)
+	}
+	if result.Value == nil || len(result.Value.Data) == 0 {
+		return "", fmt.Errorf("域名未注册: %s", domain)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Value.Data[0])
+	if err != nil || len(data) < snsRegistryHeader {
+		return "", fmt.Errorf("域名账户数据无效: %s", domain)
+	}
+	return common.PublicKeyFromBytes(data[32:64]).ToBase58(), nil
+}
@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+)
+
+const (
+	stakeProgramID = "Stake11111111111111111111111111111111111111"
+
+	// stakeWithdrawerOffset 质押账户数据中提款权限公钥的偏移量
+	stakeWithdrawerOffset = 44
+	// stakeAccountSize 质押账户的数据长度
+	stakeAccountSize = 200
+)
+
+// liquidStakingTokens 流动性质押代币（mint地址 -> 符号），其持仓计入质押
+var liquidStakingTokens = map[string]string{
+	"mSoLzYCxHdYgdzU16g5QSh3i5K3z3KZK7ytfqcJm7So":  "mSOL",
+	"J1toso1uCk3RLmjorhTtrVwY9HJ7X8V9yYac6Y7kGCPn": "JitoSOL",
+	"bSo13r4TkiE4KumL71LsHTPpL2euBYLFx6h9HP3piy1":  "bSOL",
+	"7dHbWXmci3dT8UFYWYZweBLXgycu7Y3iL6trKn1Y7ARj": "stSOL",
+	"jupSoLaHXQiZZTSfEWMTRRgpnyFm8f6sZdosWBjx93v":  "JupSOL",
+}
+
+// fetchStakedBalance 获取以钱包为提款权限的原生质押账户的 lamports 总额
+func (s *HeliusService) fetchStakedBalance(ctx context.Context, walletAddr string) (uint64, int, error) {
+	var result struct {
+		Result []struct {
+			Pubkey  string `json:"pubkey"`
+			Account struct {
+				Lamports uint64 `json:"lamports"`
+			} `json:"account"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	url := fmt.Sprintf("%s/?api-key=%s", s.endpoint, s.apiKey)
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      fmt.Sprintf("rpc-query-%d", rand.Int()),
+		"method":  "getProgramAccounts",
+		"params": []interface{}{
+			stakeProgramID,
+			map[string]interface{}{
+				"encoding": "base64",
+				// 只需要余额，不返回账户数据
+				"dataSlice": map[string]int{"offset": 0, "length": 0},
+				"filters": []interface{}{
+					map[string]interface{}{"dataSize": stakeAccountSize},
+					map[string]interface{}{
+						"memcmp": map[string]interface{}{
+							"offset": stakeWithdrawerOffset,
+							"bytes":  walletAddr,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, 0, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := heliusBreaker.Do(s.client, req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, 0, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if result.Error != nil {
+		return 0, 0, fmt.Errorf("RPC错误: %s", result.Error.Message)
+	}
+
+	var total uint64
+	for _, account := range result.Result {
+		total += account.Account.Lamports
+	}
+	return total, len(result.Result), nil
+}
+
+// markLiquidStaking 将流动性质押代币的持仓标记为质押
+func markLiquidStaking(tokens []*TokenData) {
+	for _, token := range tokens {
+		if _, ok := liquidStakingTokens[token.MintAddr]; ok {
+			token.Staked = token.Amount
+		}
+	}
+}
+
+// stakedValue 返回代币中质押部分的价值
+func stakedValue(token *TokenData) float64 {
+	if token.Staked <= 0 || token.Amount <= 0 {
+		return 0
+	}
+	return token.Value * token.Staked / token.Amount
+}
+
+// formatStaked 格式化报告中的质押列，没有质押时显示 "-"
+func formatStaked(token *TokenData) string {
+	if token.Staked <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", stakedValue(token))
+}
+
+// lstExchangeRate 根据市场价格计算流动性质押代币相对SOL的兑换率
+func lstExchangeRate(token *TokenData, tokens []*TokenData) (float64, bool) {
+	if _, ok := liquidStakingTokens[token.MintAddr]; !ok || token.Price <= 0 {
+		return 0, false
+	}
+	for _, t := range tokens {
+		if t.MintAddr == nativeSOLMint && t.Price > 0 {
+			return token.Price / t.Price, true
+		}
+	}
+	return 0, false
+}
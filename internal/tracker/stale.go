@@ -0,0 +1,77 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// defaultStalePriceMaxAge 数据源缺失价格时沿用上次价格的默认最长时间
+const defaultStalePriceMaxAge = 30 * time.Minute
+
+// lastKnownPrice 代币最近一次从数据源获取到的有效价格
+type lastKnownPrice struct {
+	price     TokenPrice
+	fetchedAt time.Time
+}
+
+var (
+	stalePriceMu     sync.RWMutex
+	stalePriceMaxAge = defaultStalePriceMaxAge
+	lastKnownPrices  = make(map[string]*lastKnownPrice) // mint地址 -> 最近一次的有效价格
+)
+
+// SetStalePriceMaxAge 设置数据源缺失价格时沿用上次价格的最长时间，<=0 表示不沿用，缺失价格的代币直接隐藏
+func SetStalePriceMaxAge(maxAge time.Duration) {
+	stalePriceMu.Lock()
+	defer stalePriceMu.Unlock()
+	stalePriceMaxAge = maxAge
+}
+
+// rememberPrice 记录代币最近一次的有效价格
+func rememberPrice(mintAddr string, price *TokenPrice, at time.Time) {
+	stalePriceMu.Lock()
+	defer stalePriceMu.Unlock()
+	lastKnownPrices[mintAddr] = &lastKnownPrice{price: *price, fetchedAt: at}
+}
+
+// stalePrice 返回代币的上次价格及其年龄，没有记录或超过最长时间时返回 false
+func stalePrice(mintAddr string, now time.Time) (*TokenPrice, time.Duration, bool) {
+	stalePriceMu.RLock()
+	defer stalePriceMu.RUnlock()
+	last, ok := lastKnownPrices[mintAddr]
+	if !ok || stalePriceMaxAge <= 0 {
+		return nil, 0, false
+	}
+	age := now.Sub(last.fetchedAt)
+	if age > stalePriceMaxAge {
+		return nil, age, false
+	}
+	price := last.price
+	return &price, age, true
+}
+
+// formatStaleAge 显示价格过期时长，如 "过期 5m"
+func formatStaleAge(token *TokenData) string {
+	if token.StaleFor <= 0 {
+		return ""
+	}
+	return i18n.T("过期 ") + token.StaleFor.Round(time.Second).String()
+}
+
+// staleFootnote 列出沿用上次价格的代币，没有时返回空字符串
+func staleFootnote(tokens []*TokenData) string {
+	var stale []string
+	for _, token := range tokens {
+		if token.StaleFor > 0 {
+			stale = append(stale, fmt.Sprintf("%s %s", displaySymbol(token), token.StaleFor.Round(time.Second)))
+		}
+	}
+	if len(stale) == 0 {
+		return ""
+	}
+	return i18n.T("* 价格过期（数据源未返回，沿用上次价格）: ") + strings.Join(stale, ", ") + "\n"
+}
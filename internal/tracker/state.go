@@ -0,0 +1,236 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateVersion 状态文件格式版本，格式不兼容时忽略旧文件
+const stateVersion = 1
+
+// stateToken 状态文件中单个代币的数据
+type stateToken struct {
+	MintAddr        string             `json:"mint"`
+	Chain           string             `json:"chain,omitempty"`
+	Symbol          string             `json:"symbol,omitempty"`
+	Name            string             `json:"name,omitempty"`
+	Decimals        uint8              `json:"decimals,omitempty"`
+	Amount          float64            `json:"amount"`
+	Price           float64            `json:"price"`
+	MarketPrice     float64            `json:"market_price,omitempty"`
+	Value           float64            `json:"value"`
+	ConfidenceLevel string             `json:"confidence,omitempty"`
+	Liquidity       float64            `json:"liquidity,omitempty"`
+	Staked          float64            `json:"staked,omitempty"`
+	DeFi            float64            `json:"defi,omitempty"`
+	WalletAmounts   map[string]float64 `json:"wallet_amounts,omitempty"`
+}
+
+// stateSnapshot 状态文件中的一个历史快照
+type stateSnapshot struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Value     float64       `json:"value"`
+	Tokens    []*stateToken `json:"tokens"`
+}
+
+// monitorState 监控器退出时保存的状态，重启后恢复以免报警窗口和持仓变化检测从零开始
+type monitorState struct {
+	Version    int                           `json:"version"`
+	SavedAt    time.Time                     `json:"saved_at"`
+	TotalValue float64                       `json:"total_value"`
+	LastUpdate time.Time                     `json:"last_update"`
+	Tokens     []*stateToken                 `json:"tokens"`
+	History    []*stateSnapshot              `json:"history"`  // 按时间升序
+	Holdings   map[string]map[string]float64 `json:"holdings"` // 钱包地址 -> mint地址 -> 数量
+	Symbols    map[string]string             `json:"symbols"`  // mint地址 -> 符号，用于恢复持仓
+	LastPrices map[string]float64            `json:"last_prices"`
+}
+
+func newStateToken(t *TokenData) *stateToken {
+	return &stateToken{
+		MintAddr:        t.MintAddr,
+		Chain:           t.Chain,
+		Symbol:          t.Symbol,
+		Name:            t.Name,
+		Decimals:        t.Decimals,
+		Amount:          t.Amount,
+		Price:           t.Price,
+		MarketPrice:     t.MarketPrice,
+		Value:           t.Value,
+		ConfidenceLevel: t.ConfidenceLevel,
+		Liquidity:       t.Liquidity,
+		Staked:          t.Staked,
+		DeFi:            t.DeFi,
+		WalletAmounts:   t.WalletAmounts,
+	}
+}
+
+func (s *stateToken) tokenData() *TokenData {
+	return &TokenData{
+		MintAddr:        s.MintAddr,
+		Chain:           s.Chain,
+		Symbol:          s.Symbol,
+		Name:            s.Name,
+		Decimals:        s.Decimals,
+		Amount:          s.Amount,
+		Price:           s.Price,
+		MarketPrice:     s.MarketPrice,
+		Value:           s.Value,
+		ConfidenceLevel: s.ConfidenceLevel,
+		Liquidity:       s.Liquidity,
+		Staked:          s.Staked,
+		DeFi:            s.DeFi,
+		WalletAmounts:   s.WalletAmounts,
+	}
+}
+
+// SaveState 将历史快照缓冲区、当前代币列表、总价值和各钱包持仓保存到JSON文件
+func (m *TokenMonitor) SaveState(path string) error {
+	state := &monitorState{
+		Version:    stateVersion,
+		SavedAt:    time.Now(),
+		Holdings:   make(map[string]map[string]float64),
+		Symbols:    make(map[string]string),
+		LastPrices: make(map[string]float64),
+	}
+
+	m.mu.RLock()
+	state.TotalValue = m.lastTotalValue
+	state.LastUpdate = m.lastUpdateTime
+	for _, token := range m.tokens {
+		state.Tokens = append(state.Tokens, newStateToken(token))
+	}
+	m.mu.RUnlock()
+
+	for _, snapshot := range m.snapshotsSince(time.Time{}) {
+		s := &stateSnapshot{Timestamp: snapshot.Timestamp, Value: snapshot.Value}
+		for _, token := range snapshot.TokenData {
+			s.Tokens = append(s.Tokens, newStateToken(token))
+		}
+		state.History = append(state.History, s)
+	}
+
+	m.holdings.mu.Lock()
+	for wallet, holdings := range m.holdings.holdings {
+		amounts := make(map[string]float64, len(holdings))
+		for mintAddr, token := range holdings {
+			amounts[mintAddr] = token.Amount
+			if token.Symbol != "" {
+				state.Symbols[mintAddr] = token.Symbol
+			}
+		}
+		state.Holdings[wallet] = amounts
+	}
+	for mintAddr, price := range m.holdings.lastPrices {
+		state.LastPrices[mintAddr] = price
+	}
+	m.holdings.mu.Unlock()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("序列化监控状态失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建状态目录失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免退出时写入一半
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存状态文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("保存状态文件失败: %v", err)
+	}
+	monitorLog.Info("已保存监控状态", "path", path, "snapshots", len(state.History), "tokens", len(state.Tokens))
+	return nil
+}
+
+// LoadState 从JSON文件恢复历史快照、代币列表、总价值和各钱包持仓；
+// 保存时间超过 maxAge 的状态被忽略（maxAge<=0 表示不限制），文件不存在时返回的错误满足 os.IsNotExist
+func (m *TokenMonitor) LoadState(path string, maxAge time.Duration) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var state monitorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("解析状态文件失败: %v", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("状态文件版本不兼容: %d", state.Version)
+	}
+	if age := time.Since(state.SavedAt); maxAge > 0 && age > maxAge {
+		monitorLog.Info("监控状态已过期，忽略", "saved_at", state.SavedAt, "age", age.Round(time.Second))
+		return nil
+	}
+
+	// 恢复的价格可作为数据源缺失时沿用的上次价格
+	for _, token := range state.Tokens {
+		price := token.Price
+		if token.MarketPrice > 0 {
+			price = token.MarketPrice
+		}
+		if price > 0 {
+			rememberPrice(token.MintAddr, &TokenPrice{
+				Price:           price,
+				Timestamp:       state.LastUpdate,
+				ConfidenceLevel: token.ConfidenceLevel,
+				Liquidity:       token.Liquidity,
+			}, state.LastUpdate)
+		}
+	}
+
+	m.mu.Lock()
+	m.lastTotalValue = state.TotalValue
+	m.lastUpdateTime = state.LastUpdate
+	if len(m.tokens) == 0 {
+		for _, token := range state.Tokens {
+			m.tokens = append(m.tokens, token.tokenData())
+		}
+	}
+	m.mu.Unlock()
+
+	// 缓冲区容量不足时只保留最近的快照
+	m.historyMu.Lock()
+	history := state.History
+	if size := m.priceHistory.Len(); len(history) > size {
+		history = history[len(history)-size:]
+	}
+	for _, s := range history {
+		snapshot := &PriceSnapshot{
+			Timestamp: s.Timestamp,
+			Value:     s.Value,
+			TokenData: make(map[string]*TokenData, len(s.Tokens)),
+		}
+		for _, token := range s.Tokens {
+			snapshot.TokenData[token.MintAddr] = token.tokenData()
+		}
+		m.priceHistory = m.priceHistory.Next()
+		m.priceHistory.Value = snapshot
+	}
+	m.historyMu.Unlock()
+
+	m.holdings.mu.Lock()
+	if m.holdings.holdings == nil {
+		m.holdings.holdings = make(map[string]map[string]*TokenData, len(state.Holdings))
+		m.holdings.lastPrices = make(map[string]float64, len(state.LastPrices))
+		for wallet, amounts := range state.Holdings {
+			holdings := make(map[string]*TokenData, len(amounts))
+			for mintAddr, amount := range amounts {
+				holdings[mintAddr] = &TokenData{MintAddr: mintAddr, Amount: amount, Symbol: state.Symbols[mintAddr]}
+			}
+			m.holdings.holdings[wallet] = holdings
+		}
+		for mintAddr, price := range state.LastPrices {
+			m.holdings.lastPrices[mintAddr] = price
+		}
+	}
+	m.holdings.mu.Unlock()
+
+	monitorLog.Info("已恢复监控状态", "saved_at", state.SavedAt, "snapshots", len(history),
+		"tokens", len(state.Tokens), "wallets", len(state.Holdings), "value", state.TotalValue)
+	return nil
+}
@@ -0,0 +1,230 @@
+package tracker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TokenRecord 持久化的单个代币快照
+type TokenRecord struct {
+	Timestamp       time.Time
+	MintAddr        string
+	Symbol          string
+	Price           float64
+	Amount          float64
+	Value           float64
+	ConfidenceLevel string
+}
+
+// PortfolioRecord 持久化的组合总价值快照
+type PortfolioRecord struct {
+	Timestamp  time.Time
+	TotalValue float64
+	TokenCount int
+}
+
+// SnapshotStore 快照持久化存储
+type SnapshotStore interface {
+	// SaveSnapshot 保存一个快照
+	SaveSnapshot(ctx context.Context, snapshot *PriceSnapshot) error
+	// SnapshotNear 返回时间最接近 target 且误差不超过 tolerance 的快照，不存在时返回nil
+	SnapshotNear(ctx context.Context, target time.Time, tolerance time.Duration) (*PriceSnapshot, error)
+	// TokenHistory 返回代币在 [since, until] 内的快照，按时间升序
+	TokenHistory(ctx context.Context, mintAddr string, since, until time.Time) ([]TokenRecord, error)
+	// PortfolioHistory 返回组合总价值在 [since, until] 内的快照，按时间升序
+	PortfolioHistory(ctx context.Context, since, until time.Time) ([]PortfolioRecord, error)
+	// Close 关闭存储
+	Close() error
+}
+
+// SQLiteStore 基于 SQLite 的快照存储
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts          INTEGER NOT NULL,
+	total_value REAL    NOT NULL,
+	token_count INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_snapshots_ts ON snapshots(ts);
+CREATE TABLE IF NOT EXISTS token_snapshots (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+	ts          INTEGER NOT NULL,
+	mint        TEXT    NOT NULL,
+	symbol      TEXT    NOT NULL,
+	price       REAL    NOT NULL,
+	amount      REAL    NOT NULL,
+	value       REAL    NOT NULL,
+	confidence  TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_token_snapshots_mint_ts ON token_snapshots(mint, ts);
+CREATE INDEX IF NOT EXISTS idx_token_snapshots_snapshot ON token_snapshots(snapshot_id);
+`
+
+// NewSQLiteStore 打开（或创建）SQLite 快照数据库
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化数据库失败: %v", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// SaveSnapshot 在一个事务中保存快照及其所有代币数据
+func (s *SQLiteStore) SaveSnapshot(ctx context.Context, snapshot *PriceSnapshot) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	ts := snapshot.Timestamp.UnixMilli()
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO snapshots (ts, total_value, token_count) VALUES (?, ?, ?)",
+		ts, snapshot.Value, len(snapshot.TokenData))
+	if err != nil {
+		return fmt.Errorf("写入快照失败: %v", err)
+	}
+	snapshotID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取快照ID失败: %v", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO token_snapshots (snapshot_id, ts, mint, symbol, price, amount, value, confidence) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+
+	for mintAddr, token := range snapshot.TokenData {
+		if _, err := stmt.ExecContext(ctx, snapshotID, ts, mintAddr, token.Symbol,
+			token.Price, token.Amount, token.Value, token.ConfidenceLevel); err != nil {
+			return fmt.Errorf("写入代币快照失败: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// SnapshotNear 返回时间最接近 target 的快照
+func (s *SQLiteStore) SnapshotNear(ctx context.Context, target time.Time, tolerance time.Duration) (*PriceSnapshot, error) {
+	targetMs := target.UnixMilli()
+	var id, ts int64
+	var total float64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, ts, total_value FROM snapshots
+		 WHERE ts BETWEEN ? AND ?
+		 ORDER BY ABS(ts - ?) ASC, ts ASC LIMIT 1`,
+		targetMs-tolerance.Milliseconds(), targetMs+tolerance.Milliseconds(), targetMs).
+		Scan(&id, &ts, &total)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询快照失败: %v", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT mint, symbol, price, amount, value, confidence FROM token_snapshots WHERE snapshot_id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询代币快照失败: %v", err)
+	}
+	defer rows.Close()
+
+	snapshot := &PriceSnapshot{
+		Timestamp: time.UnixMilli(ts),
+		Value:     total,
+		TokenData: make(map[string]*TokenData),
+	}
+	for rows.Next() {
+		token := &TokenData{}
+		if err := rows.Scan(&token.MintAddr, &token.Symbol, &token.Price,
+			&token.Amount, &token.Value, &token.ConfidenceLevel); err != nil {
+			return nil, fmt.Errorf("读取代币快照失败: %v", err)
+		}
+		snapshot.TokenData[token.MintAddr] = token
+	}
+	return snapshot, rows.Err()
+}
+
+// TokenHistory 返回代币的历史快照
+func (s *SQLiteStore) TokenHistory(ctx context.Context, mintAddr string, since, until time.Time) ([]TokenRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT ts, mint, symbol, price, amount, value, confidence FROM token_snapshots
+		 WHERE mint = ? AND ts BETWEEN ? AND ? ORDER BY ts ASC`,
+		mintAddr, since.UnixMilli(), until.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("查询代币历史失败: %v", err)
+	}
+	defer rows.Close()
+
+	var records []TokenRecord
+	for rows.Next() {
+		var r TokenRecord
+		var ts int64
+		if err := rows.Scan(&ts, &r.MintAddr, &r.Symbol, &r.Price, &r.Amount, &r.Value, &r.ConfidenceLevel); err != nil {
+			return nil, fmt.Errorf("读取代币历史失败: %v", err)
+		}
+		r.Timestamp = time.UnixMilli(ts)
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// PortfolioHistory 返回组合总价值的历史快照
+func (s *SQLiteStore) PortfolioHistory(ctx context.Context, since, until time.Time) ([]PortfolioRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT ts, total_value, token_count FROM snapshots WHERE ts BETWEEN ? AND ? ORDER BY ts ASC",
+		since.UnixMilli(), until.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("查询组合历史失败: %v", err)
+	}
+	defer rows.Close()
+
+	var records []PortfolioRecord
+	for rows.Next() {
+		var r PortfolioRecord
+		var ts int64
+		if err := rows.Scan(&ts, &r.TotalValue, &r.TokenCount); err != nil {
+			return nil, fmt.Errorf("读取组合历史失败: %v", err)
+		}
+		r.Timestamp = time.UnixMilli(ts)
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// Close 关闭数据库
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// PriceChange 计算代币在 [since, until] 内的价格变化率（%），数据不足时 ok 为 false
+func PriceChange(ctx context.Context, store SnapshotStore, mintAddr string, since, until time.Time) (float64, bool, error) {
+	records, err := store.TokenHistory(ctx, mintAddr, since, until)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(records) < 2 || records[0].Price <= 0 {
+		return 0, false, nil
+	}
+	first, last := records[0], records[len(records)-1]
+	return (last.Price - first.Price) / first.Price * 100, true, nil
+}
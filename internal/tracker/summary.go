@@ -0,0 +1,200 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// SummaryPeriod 汇总周期
+type SummaryPeriod string
+
+const (
+	SummaryDaily  SummaryPeriod = "daily"
+	SummaryWeekly SummaryPeriod = "weekly"
+)
+
+// summaryPeriodNames 各汇总周期的显示名称
+var summaryPeriodNames = map[SummaryPeriod]string{
+	SummaryDaily:  "每日汇总",
+	SummaryWeekly: "每周汇总",
+}
+
+// TokenMove 代币在汇总周期内的价格变化
+type TokenMove struct {
+	Symbol    string
+	MintAddr  string
+	OldPrice  float64
+	NewPrice  float64
+	ChangePct float64
+}
+
+// PeriodSummary 组合在一个周期内的汇总
+type PeriodSummary struct {
+	Period    SummaryPeriod
+	Start     time.Time
+	End       time.Time
+	Open      float64 // 周期内第一个快照的总价值
+	Close     float64 // 周期内最后一个快照的总价值
+	High      float64
+	Low       float64
+	ChangePct float64
+	Snapshots int
+	Gainer    *TokenMove // 涨幅最大的代币，数据不足时为nil
+	Loser     *TokenMove // 跌幅最大的代币，数据不足时为nil
+}
+
+// BuildSummary 根据持久化快照生成 [start, end] 内的汇总，没有快照时返回nil
+func BuildSummary(ctx context.Context, store SnapshotStore, period SummaryPeriod, start, end time.Time) (*PeriodSummary, error) {
+	records, err := store.PortfolioHistory(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	first, last := records[0], records[len(records)-1]
+	summary := &PeriodSummary{
+		Period:    period,
+		Start:     start,
+		End:       end,
+		Open:      first.TotalValue,
+		Close:     last.TotalValue,
+		High:      first.TotalValue,
+		Low:       first.TotalValue,
+		Snapshots: len(records),
+	}
+	for _, r := range records {
+		if r.TotalValue > summary.High {
+			summary.High = r.TotalValue
+		}
+		if r.TotalValue < summary.Low {
+			summary.Low = r.TotalValue
+		}
+	}
+	if summary.Open > 0 {
+		summary.ChangePct = (summary.Close - summary.Open) / summary.Open * 100
+	}
+
+	// 比较首尾快照中的代币价格
+	opening, err := store.SnapshotNear(ctx, first.Timestamp, 0)
+	if err != nil {
+		return nil, err
+	}
+	closing, err := store.SnapshotNear(ctx, last.Timestamp, 0)
+	if err != nil {
+		return nil, err
+	}
+	if opening != nil && closing != nil {
+		summary.Gainer, summary.Loser = biggestMoves(opening.TokenData, closing.TokenData)
+	}
+	return summary, nil
+}
+
+// biggestMoves 返回首尾都有价格的代币中涨幅最大和跌幅最大的代币
+func biggestMoves(opening, closing map[string]*TokenData) (*TokenMove, *TokenMove) {
+	var gainer, loser *TokenMove
+	for mintAddr, token := range closing {
+		old, ok := opening[mintAddr]
+		if !ok || old.Price <= 0 || token.Price <= 0 {
+			continue
+		}
+		move := &TokenMove{
+			Symbol:    displaySymbol(token),
+			MintAddr:  mintAddr,
+			OldPrice:  old.Price,
+			NewPrice:  token.Price,
+			ChangePct: (token.Price - old.Price) / old.Price * 100,
+		}
+		if move.ChangePct > 0 && (gainer == nil || move.ChangePct > gainer.ChangePct) {
+			gainer = move
+		}
+		if move.ChangePct < 0 && (loser == nil || move.ChangePct < loser.ChangePct) {
+			loser = move
+		}
+	}
+	return gainer, loser
+}
+
+// Message 生成汇总的通知文本
+func (s *PeriodSummary) Message() string {
+	var sb strings.Builder
+	layout := "2006-01-02 15:04"
+	sb.WriteString(fmt.Sprintf("%s (%s ~ %s)\n", i18n.T(summaryPeriodNames[s.Period]), s.Start.Format(layout), s.End.Format(layout)))
+	sb.WriteString(i18n.Sprintf("开盘: $%.2f  收盘: $%.2f  变化: %+.2f%%\n", s.Open, s.Close, s.ChangePct))
+	sb.WriteString(i18n.Sprintf("最高: $%.2f  最低: $%.2f  快照数: %d", s.High, s.Low, s.Snapshots))
+	if s.Gainer != nil {
+		sb.WriteString(i18n.Sprintf("\n涨幅最大: %s %+.2f%% ($%.6f -> $%.6f)",
+			s.Gainer.Symbol, s.Gainer.ChangePct, s.Gainer.OldPrice, s.Gainer.NewPrice))
+	}
+	if s.Loser != nil {
+		sb.WriteString(i18n.Sprintf("\n跌幅最大: %s %+.2f%% ($%.6f -> $%.6f)",
+			s.Loser.Symbol, s.Loser.ChangePct, s.Loser.OldPrice, s.Loser.NewPrice))
+	}
+	return sb.String()
+}
+
+// RunSummaries 每天在 at（HH:MM，本地时间）发送前一天的汇总，每周 weekday 的同一时间发送前七天的汇总，直到 ctx 结束；
+// 汇总基于持久化快照，需先调用 SetStore
+func (m *TokenMonitor) RunSummaries(ctx context.Context, at string, weekday time.Weekday, daily, weekly bool) error {
+	if m.store == nil {
+		return fmt.Errorf("汇总报告需要配置快照数据库")
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("汇总时间格式无效（应为 HH:MM）: %v", err)
+	}
+
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if daily {
+				m.sendSummary(ctx, SummaryDaily, next.AddDate(0, 0, -1), next)
+			}
+			if weekly && next.Weekday() == weekday {
+				m.sendSummary(ctx, SummaryWeekly, next.AddDate(0, 0, -7), next)
+			}
+		}
+	}()
+	return nil
+}
+
+// sendSummary 生成一个周期的汇总并通过通知渠道发送
+func (m *TokenMonitor) sendSummary(ctx context.Context, period SummaryPeriod, start, end time.Time) {
+	summary, err := BuildSummary(ctx, m.store, period, start, end)
+	if err != nil {
+		monitorLog.Error("生成汇总报告失败", "period", period, "error", err)
+		return
+	}
+	if summary == nil {
+		monitorLog.Info("汇总周期内没有快照，跳过", "period", period)
+		return
+	}
+
+	m.emitAlert(Alert{
+		Type:      AlertTypeSummary,
+		Window:    end.Sub(start),
+		ChangePct: summary.ChangePct,
+		OldValue:  summary.Open,
+		NewValue:  summary.Close,
+		Message:   summary.Message(),
+		Timestamp: end,
+	})
+}
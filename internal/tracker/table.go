@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// ANSI 颜色
+const (
+	ansiGreen = "\x1b[32m"
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+// reportColor 文本报告是否使用ANSI颜色标记涨跌
+var reportColor atomic.Bool
+
+// SetReportColor 设置文本报告是否使用ANSI颜色标记涨跌，输出不是终端时应关闭
+func SetReportColor(enabled bool) {
+	reportColor.Store(enabled)
+}
+
+// colorChange 启用颜色时上涨标绿、下跌标红
+func colorChange(text string, change float64) string {
+	if !reportColor.Load() {
+		return text
+	}
+	switch {
+	case change > 0:
+		return ansiGreen + text + ansiReset
+	case change < 0:
+		return ansiRed + text + ansiReset
+	}
+	return text
+}
+
+// reportUnicode 文本报告是否使用 Unicode 制表符绘制表格边框，输出到终端时启用
+var reportUnicode atomic.Bool
+
+// SetReportUnicode 设置文本报告是否使用 Unicode 制表符绘制表格，输出被重定向到文件或管道时应关闭
+func SetReportUnicode(enabled bool) {
+	reportUnicode.Store(enabled)
+}
+
+// tableColumn 报告表格的列
+type tableColumn struct {
+	header string
+	width  int  // 最小显示宽度，内容更宽时自动加宽
+	left   bool // 是否左对齐，默认右对齐
+}
+
+// reportTable 按显示宽度对齐的文本表格，单元格中的ANSI颜色码和中文等宽字符都能正确对齐
+type reportTable struct {
+	columns []tableColumn
+	rows    [][]string
+}
+
+// addRow 添加一行，单元格数量需与列数相同
+func (t *reportTable) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// widths 计算各列的实际显示宽度
+func (t *reportTable) widths() []int {
+	widths := make([]int, len(t.columns))
+	for i, column := range t.columns {
+		widths[i] = column.width
+		if w := displayWidth(column.header); w > widths[i] {
+			widths[i] = w
+		}
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if w := displayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+// render 输出表格：启用 Unicode 时带边框，否则为空格分隔的纯文本，适合重定向到文件
+func (t *reportTable) render() string {
+	if reportUnicode.Load() {
+		return t.renderBox()
+	}
+	return t.renderPlain()
+}
+
+// renderPlain 输出空格分隔、表头下方带分隔线的纯文本表格
+func (t *reportTable) renderPlain() string {
+	widths := t.widths()
+	total := len(widths) - 1
+	for _, w := range widths {
+		total += w
+	}
+
+	// 最后一列左对齐时去掉行尾的填充空格
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(t.line(t.headers(), widths, "", " ", ""), " ") + "\n")
+	sb.WriteString(strings.Repeat("-", total) + "\n")
+	for _, row := range t.rows {
+		sb.WriteString(strings.TrimRight(t.line(row, widths, "", " ", ""), " ") + "\n")
+	}
+	return sb.String()
+}
+
+// renderBox 输出带 Unicode 边框的表格
+func (t *reportTable) renderBox() string {
+	widths := t.widths()
+	border := func(left, middle, right string) string {
+		parts := make([]string, len(widths))
+		for i, w := range widths {
+			parts[i] = strings.Repeat("─", w+2)
+		}
+		return left + strings.Join(parts, middle) + right + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(border("┌", "┬", "┐"))
+	sb.WriteString(t.line(t.headers(), widths, "│ ", " │ ", " │") + "\n")
+	sb.WriteString(border("├", "┼", "┤"))
+	for _, row := range t.rows {
+		sb.WriteString(t.line(row, widths, "│ ", " │ ", " │") + "\n")
+	}
+	sb.WriteString(border("└", "┴", "┘"))
+	return sb.String()
+}
+
+// headers 返回各列的表头
+func (t *reportTable) headers() []string {
+	headers := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		headers[i] = column.header
+	}
+	return headers
+}
+
+// line 按列宽和对齐方式拼接一行
+func (t *reportTable) line(cells []string, widths []int, left, separator, right string) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		padding := strings.Repeat(" ", widths[i]-displayWidth(cell))
+		if t.columns[i].left {
+			parts[i] = cell + padding
+		} else {
+			parts[i] = padding + cell
+		}
+	}
+	return left + strings.Join(parts, separator) + right
+}
+
+// displayWidth 返回字符串在终端中的显示宽度：ANSI 转义序列不占宽度，中日韩文字和全角符号占两列
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		// 跳过 ESC [ ... 字母 形式的控制序列
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			i += 2
+			for i < len(s) && !(s[i] >= 0x40 && s[i] <= 0x7e) {
+				i++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if isWideRune(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// isWideRune 判断字符在终端中是否占两列
+func isWideRune(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, // 谚文字母
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, // 中日韩部首、标点、假名、汉字、彝文
+		r >= 0xac00 && r <= 0xd7a3,                // 谚文音节
+		r >= 0xf900 && r <= 0xfaff,                // 兼容汉字
+		r >= 0xfe30 && r <= 0xfe4f,                // 兼容形式
+		r >= 0xff00 && r <= 0xff60,                // 全角符号
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, // 表情符号
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd: // 扩展汉字
+		return true
+	}
+	return false
+}
@@ -0,0 +1,409 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"wallet-tracker/config"
+
+	_ "github.com/lib/pq"
+)
+
+// timeSeriesFinalFlush 退出时写入剩余数据点的超时
+const timeSeriesFinalFlush = 10 * time.Second
+
+// TimeSeriesPoint 写入时序数据库的一个数据点
+type TimeSeriesPoint struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]float64
+	Timestamp   time.Time
+}
+
+// TimeSeriesSink 时序数据库输出
+type TimeSeriesSink interface {
+	// WritePoints 写入一批数据点
+	WritePoints(ctx context.Context, points []TimeSeriesPoint) error
+	// Close 关闭连接
+	Close() error
+}
+
+// timeSeriesRejected 数据库拒绝了写入的数据（如格式错误、权限不足），重试也不会成功
+type timeSeriesRejected struct {
+	message string
+}
+
+func (e *timeSeriesRejected) Error() string {
+	return "写入被拒绝: " + e.message
+}
+
+// NewTimeSeriesSink 根据配置创建时序数据库输出，地址和令牌中的 ${ENV} 替换为环境变量
+func NewTimeSeriesSink(cfg config.TimeSeries) (TimeSeriesSink, error) {
+	switch cfg.Driver {
+	case config.TimeSeriesInfluxDB:
+		return NewInfluxSink(os.ExpandEnv(cfg.URL), cfg.Database, cfg.Org, cfg.Bucket, os.ExpandEnv(cfg.Token)), nil
+	case config.TimeSeriesTimescale:
+		return NewTimescaleSink(os.ExpandEnv(cfg.URL))
+	default:
+		return nil, fmt.Errorf("未知的时序数据库: %s", cfg.Driver)
+	}
+}
+
+// snapshotPoints 将快照转换为数据点：portfolio（组合总值、代币数、变化率）和 token（按 mint、symbol 标记的价格、数量、价值）
+func snapshotPoints(event SnapshotEvent) []TimeSeriesPoint {
+	points := make([]TimeSeriesPoint, 0, len(event.Tokens)+1)
+	points = append(points, TimeSeriesPoint{
+		Measurement: "portfolio",
+		Fields: map[string]float64{
+			"total_value": event.TotalValue,
+			"token_count": float64(len(event.Tokens)),
+			"change_pct":  event.ChangePct,
+		},
+		Timestamp: event.Timestamp,
+	})
+	for _, token := range event.Tokens {
+		points = append(points, TimeSeriesPoint{
+			Measurement: "token",
+			Tags:        map[string]string{"mint": token.Mint, "symbol": token.Symbol},
+			Fields: map[string]float64{
+				"price":  token.Price,
+				"amount": token.Amount,
+				"value":  token.Value,
+			},
+			Timestamp: event.Timestamp,
+		})
+	}
+	return points
+}
+
+// TimeSeriesWriter 订阅监控器的快照，批量写入时序数据库；写入失败时退避重试，仍失败的数据点缓存到下次写入
+type TimeSeriesWriter struct {
+	sink          TimeSeriesSink
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	maxBuffer     int
+	pending       []TimeSeriesPoint // 只由写入goroutine访问
+	done          chan struct{}
+}
+
+// NewTimeSeriesWriter 创建时序数据库写入器
+func NewTimeSeriesWriter(sink TimeSeriesSink, cfg config.TimeSeries) *TimeSeriesWriter {
+	maxRetries := cfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &TimeSeriesWriter{
+		sink:          sink,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		maxRetries:    maxRetries,
+		maxBuffer:     cfg.MaxBuffer,
+		done:          make(chan struct{}),
+	}
+}
+
+// Run 订阅快照并在后台写入，直到 ctx 结束；结束时写入剩余的数据点
+func (w *TimeSeriesWriter) Run(ctx context.Context, monitor *TokenMonitor) {
+	events, unsubscribe := monitor.SubscribeSnapshots()
+	go func() {
+		defer close(w.done)
+		defer unsubscribe()
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), timeSeriesFinalFlush)
+				w.flush(flushCtx)
+				cancel()
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				w.pending = append(w.pending, snapshotPoints(event)...)
+				if len(w.pending) >= w.batchSize {
+					w.flush(ctx)
+				}
+			case <-ticker.C:
+				w.flush(ctx)
+			}
+		}
+	}()
+}
+
+// Close 等待剩余数据点写入后关闭连接，需在 Run 的 ctx 结束后调用
+func (w *TimeSeriesWriter) Close() error {
+	<-w.done
+	if len(w.pending) > 0 {
+		seriesLog.Warn("退出时仍有数据点未写入", "points", len(w.pending))
+	}
+	return w.sink.Close()
+}
+
+// flush 按批量大小写入缓存的数据点，某一批重试后仍失败时保留剩余数据点等待下次写入
+func (w *TimeSeriesWriter) flush(ctx context.Context) {
+	for len(w.pending) > 0 {
+		n := len(w.pending)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		err := w.write(ctx, w.pending[:n])
+		var rejected *timeSeriesRejected
+		if err != nil && !errors.As(err, &rejected) {
+			seriesLog.Error("写入时序数据库失败，稍后重试", "points", len(w.pending), "error", err)
+			w.trim()
+			return
+		}
+		if err != nil {
+			seriesLog.Error("时序数据库拒绝写入，丢弃该批数据点", "points", n, "error", err)
+		}
+		w.pending = w.pending[n:]
+	}
+	w.pending = nil
+}
+
+// write 写入一批数据点，失败时按指数退避重试，被拒绝时不重试
+func (w *TimeSeriesWriter) write(ctx context.Context, points []TimeSeriesPoint) error {
+	var err error
+	for attempt := 0; attempt <= w.maxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(retryBackoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		err = w.sink.WritePoints(ctx, points)
+		var rejected *timeSeriesRejected
+		if err == nil || errors.As(err, &rejected) || ctx.Err() != nil {
+			return err
+		}
+		seriesLog.Debug("写入时序数据库失败", "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+// trim 缓存超过上限时丢弃最旧的数据点
+func (w *TimeSeriesWriter) trim() {
+	if w.maxBuffer <= 0 || len(w.pending) <= w.maxBuffer {
+		return
+	}
+	dropped := len(w.pending) - w.maxBuffer
+	w.pending = append([]TimeSeriesPoint(nil), w.pending[dropped:]...)
+	seriesLog.Warn("时序数据库缓存已满，丢弃最旧的数据点", "dropped", dropped)
+}
+
+// InfluxSink 以 line protocol 写入 InfluxDB，设置 bucket 时使用 2.x 接口，否则使用 1.x 接口
+type InfluxSink struct {
+	client   *http.Client
+	writeURL string
+	token    string
+}
+
+// NewInfluxSink 创建 InfluxDB 输出
+func NewInfluxSink(baseURL, database, org, bucket, token string) *InfluxSink {
+	return NewInfluxSinkWithConfig(baseURL, database, org, bucket, token, nil)
+}
+
+// NewInfluxSinkWithConfig 使用指定的HTTP客户端创建 InfluxDB 输出，client 为nil时使用共享客户端
+func NewInfluxSinkWithConfig(baseURL, database, org, bucket, token string, client *http.Client) *InfluxSink {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+	query := url.Values{"precision": {"ms"}}
+	path := "/write"
+	if bucket != "" {
+		path = "/api/v2/write"
+		query.Set("org", org)
+		query.Set("bucket", bucket)
+	} else {
+		query.Set("db", database)
+	}
+	return &InfluxSink{
+		client:   client,
+		writeURL: strings.TrimRight(baseURL, "/") + path + "?" + query.Encode(),
+		token:    token,
+	}
+}
+
+// WritePoints 写入一批数据点
+func (s *InfluxSink) WritePoints(ctx context.Context, points []TimeSeriesPoint) error {
+	var body bytes.Buffer
+	for _, point := range points {
+		writeLineProtocol(&body, point)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.writeURL, &body)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	text := fmt.Sprintf("InfluxDB返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &timeSeriesRejected{message: text}
+	}
+	return fmt.Errorf("%s", text)
+}
+
+// Close 关闭连接
+func (s *InfluxSink) Close() error {
+	return nil
+}
+
+// writeLineProtocol 将数据点编码为一行 line protocol，标签和字段按名称排序
+func writeLineProtocol(buf *bytes.Buffer, point TimeSeriesPoint) {
+	buf.WriteString(lineEscaper.Replace(point.Measurement))
+
+	keys := make([]string, 0, len(point.Tags))
+	for key, value := range point.Tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		buf.WriteString("," + tagEscaper.Replace(key) + "=" + tagEscaper.Replace(point.Tags[key]))
+	}
+
+	keys = keys[:0]
+	for key := range point.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			buf.WriteByte(' ')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(tagEscaper.Replace(key) + "=" + strconv.FormatFloat(point.Fields[key], 'f', -1, 64))
+	}
+
+	buf.WriteString(" " + strconv.FormatInt(point.Timestamp.UnixMilli(), 10) + "\n")
+}
+
+// line protocol 中 measurement 需要转义逗号和空格，标签和字段名还需要转义等号
+var (
+	lineEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	tagEscaper  = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`, "\n", `\n`)
+)
+
+// TimescaleSink 写入 TimescaleDB（PostgreSQL），组合总值和代币数据分别写入 portfolio_values 和 token_values 表
+type TimescaleSink struct {
+	db *sql.DB
+}
+
+const timescaleSchema = `
+CREATE TABLE IF NOT EXISTS portfolio_values (
+	time        TIMESTAMPTZ      NOT NULL,
+	total_value DOUBLE PRECISION NOT NULL,
+	token_count INTEGER          NOT NULL,
+	change_pct  DOUBLE PRECISION NOT NULL
+);
+CREATE TABLE IF NOT EXISTS token_values (
+	time   TIMESTAMPTZ      NOT NULL,
+	mint   TEXT             NOT NULL,
+	symbol TEXT             NOT NULL,
+	price  DOUBLE PRECISION NOT NULL,
+	amount DOUBLE PRECISION NOT NULL,
+	value  DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_token_values_mint_time ON token_values (mint, time DESC);
+`
+
+// NewTimescaleSink 连接数据库并创建表，安装了 timescaledb 扩展时转换为 hypertable
+func NewTimescaleSink(dsn string) (*TimescaleSink, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %v", err)
+	}
+	db.SetMaxOpenConns(2)
+
+	if _, err := db.Exec(timescaleSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("创建数据表失败: %v", err)
+	}
+	for _, table := range []string{"portfolio_values", "token_values"} {
+		if _, err := db.Exec("SELECT create_hypertable($1, 'time', if_not_exists => TRUE)", table); err != nil {
+			seriesLog.Warn("创建hypertable失败，按普通表写入", "table", table, "error", err)
+		}
+	}
+	return &TimescaleSink{db: db}, nil
+}
+
+// WritePoints 在一个事务中写入一批数据点，未知的 measurement 忽略
+func (s *TimescaleSink) WritePoints(ctx context.Context, points []TimeSeriesPoint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	portfolioStmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO portfolio_values (time, total_value, token_count, change_pct) VALUES ($1, $2, $3, $4)")
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer portfolioStmt.Close()
+	tokenStmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO token_values (time, mint, symbol, price, amount, value) VALUES ($1, $2, $3, $4, $5, $6)")
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer tokenStmt.Close()
+
+	for _, point := range points {
+		switch point.Measurement {
+		case "portfolio":
+			_, err = portfolioStmt.ExecContext(ctx, point.Timestamp,
+				point.Fields["total_value"], int(point.Fields["token_count"]), point.Fields["change_pct"])
+		case "token":
+			_, err = tokenStmt.ExecContext(ctx, point.Timestamp, point.Tags["mint"], point.Tags["symbol"],
+				point.Fields["price"], point.Fields["amount"], point.Fields["value"])
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("写入数据点失败: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
+	}
+	return nil
+}
+
+// Close 关闭数据库连接
+func (s *TimescaleSink) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,158 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+)
+
+const (
+	tokenProgramID     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	token2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+
+	// getMultipleAccounts 单次最多查询的账户数
+	multipleAccountsLimit = 100
+)
+
+// transferFeeConfig Token-2022 转账手续费扩展
+type transferFeeConfig struct {
+	basisPoints uint16
+	maximumFee  uint64
+}
+
+// applyTransferFees 查询 Token-2022 代币的 mint 账户，为带转账手续费扩展的代币账户填充手续费
+func (s *HeliusService) applyTransferFees(ctx context.Context, accounts []*TokenAccount) {
+	seen := make(map[string]bool)
+	var mints []string
+	for _, account := range accounts {
+		if !seen[account.Mint] {
+			seen[account.Mint] = true
+			mints = append(mints, account.Mint)
+		}
+	}
+
+	fees := make(map[string]transferFeeConfig)
+	for i := 0; i < len(mints); i += multipleAccountsLimit {
+		end := i + multipleAccountsLimit
+		if end > len(mints) {
+			end = len(mints)
+		}
+		batchFees, err := s.fetchTransferFees(ctx, mints[i:end])
+		if err != nil {
+			walletLog.Warn("获取Token-2022转账手续费失败", "error", err)
+			return
+		}
+		for mint, fee := range batchFees {
+			fees[mint] = fee
+		}
+	}
+
+	for _, account := range accounts {
+		if fee, ok := fees[account.Mint]; ok {
+			account.TransferFeeBps = fee.basisPoints
+			account.MaxTransferFee = fee.maximumFee
+		}
+	}
+}
+
+// fetchTransferFees 使用 getMultipleAccounts 读取 mint 账户中的 transferFeeConfig 扩展
+func (s *HeliusService) fetchTransferFees(ctx context.Context, mints []string) (map[string]transferFeeConfig, error) {
+	var result struct {
+		Result struct {
+			Value []*struct {
+				Data struct {
+					Parsed struct {
+						Info struct {
+							Extensions []struct {
+								Extension string `json:"extension"`
+								State     struct {
+									NewerTransferFee struct {
+										MaximumFee             json.Number `json:"maximumFee"`
+										TransferFeeBasisPoints uint16      `json:"transferFeeBasisPoints"`
+									} `json:"newerTransferFee"`
+								} `json:"state"`
+							} `json:"extensions"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"value"`
+		} `json:"result"`
+	}
+
+	url := fmt.Sprintf("%s/?api-key=%s", s.endpoint, s.apiKey)
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      fmt.Sprintf("rpc-query-%d", rand.Int()),
+		"method":  "getMultipleAccounts",
+		"params": []interface{}{
+			mints,
+			map[string]interface{}{
+				"encoding": "jsonParsed",
+			},
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := heliusBreaker.Do(s.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	fees := make(map[string]transferFeeConfig)
+	for i, account := range result.Result.Value {
+		if account == nil || i >= len(mints) {
+			continue
+		}
+		for _, ext := range account.Data.Parsed.Info.Extensions {
+			if ext.Extension != "transferFeeConfig" {
+				continue
+			}
+			fee := ext.State.NewerTransferFee
+			if fee.TransferFeeBasisPoints == 0 {
+				continue
+			}
+			maximumFee, _ := strconv.ParseUint(fee.MaximumFee.String(), 10, 64)
+			fees[mints[i]] = transferFeeConfig{
+				basisPoints: fee.TransferFeeBasisPoints,
+				maximumFee:  maximumFee,
+			}
+		}
+	}
+	return fees, nil
+}
+
+// applyTransferFee 将代币账户的转账手续费复制到代币数据
+func applyTransferFee(token *TokenData, account *TokenAccount) {
+	if account.TransferFeeBps == 0 {
+		return
+	}
+	token.TransferFeeBps = account.TransferFeeBps
+	token.MaxTransferFee = float64(account.MaxTransferFee) / math.Pow10(int(account.Decimals))
+}
+
+// netAmount 返回扣除转出手续费后的可变现数量
+func netAmount(token *TokenData) float64 {
+	if token.TransferFeeBps == 0 {
+		return token.Amount
+	}
+	fee := token.Amount * float64(token.TransferFeeBps) / 10000
+	if token.MaxTransferFee > 0 && fee > token.MaxTransferFee {
+		fee = token.MaxTransferFee
+	}
+	return token.Amount - fee
+}
@@ -0,0 +1,65 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+
+	"wallet-tracker/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 记录钱包获取、DAS/RPC 请求和价格批次的 span，未调用 InitTracing 时为空实现
+var tracer = otel.Tracer("wallet-tracker/internal/tracker")
+
+// InitTracing 创建 OTLP/HTTP 导出器并设置为全局 TracerProvider，返回的函数在退出时发送剩余的 span
+func InitTracing(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("创建OTLP导出器失败: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("创建资源信息失败: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		httpLog.Debug("导出追踪数据失败", "error", err)
+	}))
+	return provider.Shutdown, nil
+}
+
+// StartSpan 开始一个 span，供调用方把一次刷新中的获取和价格更新归到同一个 trace 下
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan 记录错误（如有）并结束 span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
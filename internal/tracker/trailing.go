@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// trailingWatch 记录持仓代币开始监控以来的最高价，用于回撤报警
+type trailingWatch struct {
+	mu      sync.Mutex
+	pct     float64            // 从最高价回撤超过该比例（%）时报警，0表示关闭
+	peak    map[string]float64 // mint地址 -> 开始监控以来的最高价
+	alerted map[string]bool    // 当前最高价已报警过的代币，创新高后重新生效
+}
+
+// SetTrailingStop 设置回撤报警：持仓代币价格从开始监控以来的最高价回撤超过 pct(%) 时报警，0表示关闭
+func (m *TokenMonitor) SetTrailingStop(pct float64) {
+	m.trailing.mu.Lock()
+	defer m.trailing.mu.Unlock()
+	m.trailing.pct = pct
+}
+
+// checkTrailingStop 更新持仓代币的最高价，回撤超过阈值时报警；稳定币和低可信度价格不参与
+func (m *TokenMonitor) checkTrailingStop(tokens []*TokenData) {
+	m.trailing.mu.Lock()
+	defer m.trailing.mu.Unlock()
+
+	if m.trailing.pct <= 0 {
+		return
+	}
+	if m.trailing.peak == nil {
+		m.trailing.peak = make(map[string]float64)
+		m.trailing.alerted = make(map[string]bool)
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		if token.Price <= 0 || token.ConfidenceLevel == "low" || isStablecoin(token) {
+			continue
+		}
+		peak := m.trailing.peak[token.MintAddr]
+		if token.Price > peak {
+			m.trailing.peak[token.MintAddr] = token.Price
+			delete(m.trailing.alerted, token.MintAddr)
+			continue
+		}
+
+		drawdown := (token.Price - peak) / peak * 100
+		if -drawdown < m.trailing.pct || m.trailing.alerted[token.MintAddr] {
+			continue
+		}
+		m.trailing.alerted[token.MintAddr] = true
+
+		m.emitAlert(Alert{
+			Type:      AlertTypeTrailingStop,
+			MintAddr:  token.MintAddr,
+			Symbol:    token.Symbol,
+			ChangePct: drawdown,
+			OldValue:  peak,
+			NewValue:  token.Price,
+			Message: i18n.Sprintf("回撤报警 - %s (%s) 从最高价 $%.6f 回撤 %.2f%% 至 $%.6f，超过 %.2f%%，持仓价值 $%.2f",
+				displaySymbol(token), token.MintAddr, peak, -drawdown, token.Price, m.trailing.pct, token.Value),
+			Timestamp: now,
+		})
+	}
+}
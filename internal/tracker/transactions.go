@@ -0,0 +1,240 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultHeliusAPIURL = "https://api.helius.xyz/v0"
+	heliusTxPageLimit   = 100 // 增强交易接口单页上限
+	nativeSOLMint       = "So11111111111111111111111111111111111111111"
+	lamportsPerSOL      = 1e9
+)
+
+// TokenTransfer 交易中的一笔代币转账（原生SOL使用 nativeSOLMint）
+type TokenTransfer struct {
+	MintAddr string
+	From     string
+	To       string
+	Amount   float64
+}
+
+// SwapEvent 钱包视角的兑换：付出 Input，得到 Output
+type SwapEvent struct {
+	InputMint    string
+	InputAmount  float64
+	OutputMint   string
+	OutputAmount float64
+}
+
+// WalletTransaction 解析后的钱包交易
+type WalletTransaction struct {
+	Signature   string
+	Timestamp   time.Time
+	Type        string // Helius 交易类型，如 SWAP、TRANSFER
+	Source      string // 交易来源，如 JUPITER、RAYDIUM
+	Description string
+	Fee         uint64
+	Transfers   []TokenTransfer
+	Swap        *SwapEvent // 非兑换交易为nil
+}
+
+// TransactionQuery 交易查询参数
+type TransactionQuery struct {
+	Limit  int    // 最多返回的交易数量，<=0 时只取一页
+	Before string // 从该签名之前开始查询
+	Type   string // 只返回指定类型的交易，为空时返回所有类型
+}
+
+// heliusTransaction Helius 增强交易接口的响应结构
+type heliusTransaction struct {
+	Signature       string `json:"signature"`
+	Timestamp       int64  `json:"timestamp"`
+	Type            string `json:"type"`
+	Source          string `json:"source"`
+	Description     string `json:"description"`
+	Fee             uint64 `json:"fee"`
+	NativeTransfers []struct {
+		FromUserAccount string `json:"fromUserAccount"`
+		ToUserAccount   string `json:"toUserAccount"`
+		Amount          uint64 `json:"amount"`
+	} `json:"nativeTransfers"`
+	TokenTransfers []struct {
+		FromUserAccount string  `json:"fromUserAccount"`
+		ToUserAccount   string  `json:"toUserAccount"`
+		TokenAmount     float64 `json:"tokenAmount"`
+		Mint            string  `json:"mint"`
+	} `json:"tokenTransfers"`
+	Events struct {
+		Swap *heliusSwapEvent `json:"swap"`
+	} `json:"events"`
+}
+
+type heliusNativeAmount struct {
+	Account string `json:"account"`
+	Amount  string `json:"amount"`
+}
+
+type heliusTokenAmount struct {
+	UserAccount    string `json:"userAccount"`
+	Mint           string `json:"mint"`
+	RawTokenAmount struct {
+		TokenAmount string `json:"tokenAmount"`
+		Decimals    int    `json:"decimals"`
+	} `json:"rawTokenAmount"`
+}
+
+type heliusSwapEvent struct {
+	NativeInput  *heliusNativeAmount `json:"nativeInput"`
+	NativeOutput *heliusNativeAmount `json:"nativeOutput"`
+	TokenInputs  []heliusTokenAmount `json:"tokenInputs"`
+	TokenOutputs []heliusTokenAmount `json:"tokenOutputs"`
+}
+
+// FetchWalletTransactions 使用 Helius 增强交易接口获取钱包的交易记录，按时间倒序返回
+func (s *HeliusService) FetchWalletTransactions(ctx context.Context, walletAddr string, query TransactionQuery) ([]*WalletTransaction, error) {
+	var transactions []*WalletTransaction
+	before := query.Before
+
+	for {
+		pageLimit := heliusTxPageLimit
+		if query.Limit > 0 && query.Limit-len(transactions) < pageLimit {
+			pageLimit = query.Limit - len(transactions)
+		}
+
+		page, err := s.fetchTransactionsPage(ctx, walletAddr, before, query.Type, pageLimit)
+		if err != nil {
+			if len(transactions) > 0 {
+				return transactions, fmt.Errorf("获取交易记录中断（已获取 %d 笔）: %v", len(transactions), err)
+			}
+			return nil, err
+		}
+
+		for _, tx := range page {
+			transactions = append(transactions, parseHeliusTransaction(tx))
+		}
+
+		if query.Limit <= 0 || len(page) < pageLimit || len(transactions) >= query.Limit {
+			break
+		}
+		before = page[len(page)-1].Signature
+	}
+
+	return transactions, nil
+}
+
+// fetchTransactionsPage 请求增强交易接口的单页数据
+func (s *HeliusService) fetchTransactionsPage(ctx context.Context, walletAddr, before, txType string, limit int) ([]heliusTransaction, error) {
+	params := url.Values{}
+	params.Set("api-key", s.apiKey)
+	params.Set("limit", strconv.Itoa(limit))
+	if before != "" {
+		params.Set("before", before)
+	}
+	if txType != "" {
+		params.Set("type", txType)
+	}
+	reqURL := fmt.Sprintf("%s/addresses/%s/transactions?%s", s.apiURL, walletAddr, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := heliusBreaker.Do(s.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("Helius 返回状态码 %d: %s", resp.StatusCode, string(body))
+	}
+
+	var page []heliusTransaction
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return page, nil
+}
+
+// parseHeliusTransaction 将 Helius 响应转换为 WalletTransaction
+func parseHeliusTransaction(tx heliusTransaction) *WalletTransaction {
+	parsed := &WalletTransaction{
+		Signature:   tx.Signature,
+		Timestamp:   time.Unix(tx.Timestamp, 0),
+		Type:        tx.Type,
+		Source:      tx.Source,
+		Description: tx.Description,
+		Fee:         tx.Fee,
+	}
+
+	for _, t := range tx.NativeTransfers {
+		parsed.Transfers = append(parsed.Transfers, TokenTransfer{
+			MintAddr: nativeSOLMint,
+			From:     t.FromUserAccount,
+			To:       t.ToUserAccount,
+			Amount:   float64(t.Amount) / lamportsPerSOL,
+		})
+	}
+	for _, t := range tx.TokenTransfers {
+		parsed.Transfers = append(parsed.Transfers, TokenTransfer{
+			MintAddr: t.Mint,
+			From:     t.FromUserAccount,
+			To:       t.ToUserAccount,
+			Amount:   t.TokenAmount,
+		})
+	}
+
+	if swap := tx.Events.Swap; swap != nil {
+		event := &SwapEvent{}
+		if swap.NativeInput != nil {
+			event.InputMint = nativeSOLMint
+			event.InputAmount = parseLamports(swap.NativeInput.Amount)
+		} else if len(swap.TokenInputs) > 0 {
+			event.InputMint = swap.TokenInputs[0].Mint
+			event.InputAmount = parseRawTokenAmount(swap.TokenInputs[0])
+		}
+		if swap.NativeOutput != nil {
+			event.OutputMint = nativeSOLMint
+			event.OutputAmount = parseLamports(swap.NativeOutput.Amount)
+		} else if len(swap.TokenOutputs) > 0 {
+			event.OutputMint = swap.TokenOutputs[0].Mint
+			event.OutputAmount = parseRawTokenAmount(swap.TokenOutputs[0])
+		}
+		if event.InputMint != "" && event.OutputMint != "" {
+			parsed.Swap = event
+		}
+	}
+
+	return parsed
+}
+
+// parseLamports 将 lamports 字符串转换为 SOL 数量
+func parseLamports(amount string) float64 {
+	lamports, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return lamports / lamportsPerSOL
+}
+
+// parseRawTokenAmount 按精度将原始代币数量转换为实际数量
+func parseRawTokenAmount(amount heliusTokenAmount) float64 {
+	raw, err := strconv.ParseFloat(amount.RawTokenAmount.TokenAmount, 64)
+	if err != nil {
+		return 0
+	}
+	for i := 0; i < amount.RawTokenAmount.Decimals; i++ {
+		raw /= 10
+	}
+	return raw
+}
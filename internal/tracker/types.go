@@ -2,6 +2,7 @@ package tracker
 
 import (
 	"context"
+	"time"
 
 	"github.com/portto/solana-go-sdk/program/token"
 )
@@ -9,6 +10,7 @@ import (
 // TokenData 保存单个 token 数据
 type TokenData struct {
 	MintAddr        string // mint address (唯一标识)
+	Chain           string // 代币所在的链，为空时根据地址推断（Solana 代币不设置）
 	Symbol          string
 	Amount          float64
 	Value           float64
@@ -17,8 +19,22 @@ type TokenData struct {
 	Name            string
 	Raw             *token.TokenAccount
 	Price           float64
-	Liquidity       float64 // 代币流动性（美元）
-	ConfidenceLevel string  // 价格可信度: high/medium/low
+	MarketPrice     float64            // 稳定币的实际市场价格（按 $1 估值时Price为1），非稳定币为0
+	Liquidity       float64            // 代币流动性（美元）
+	BuyDepth        float64            // 价格上涨2%所需的买入金额（美元）
+	SellDepth       float64            // 价格下跌2%所需的卖出金额（美元）
+	ConfidenceLevel string             // 价格可信度: high/medium/low
+	StaleFor        time.Duration      // 数据源本次未返回价格、沿用上次价格的时长，0表示价格为最新
+	SecondaryPrice  float64            // 交叉验证数据源的价格（未配置时为0）
+	PnL             float64            // 相对基准的未实现盈亏（美元）
+	PnLPct          float64            // 相对基准的未实现盈亏（%）
+	HasBasis        bool               // 是否存在基准成本
+	WalletAmounts   map[string]float64 // 各钱包持有的数量（钱包地址 -> 数量）
+	TransferFeeBps  uint16             // Token-2022 转账手续费（基点）
+	MaxTransferFee  float64            // Token-2022 单笔转账手续费上限（代币数量）
+	Staked          float64            // 其中处于质押状态的数量（原生质押SOL或流动性质押代币）
+	DeFi            float64            // 其中存放在DeFi协议中的数量（借贷存款或LP底层资产）
+	Safety          *TokenSafety       // 代币安全检查结果，未检查时为nil
 }
 
 // TokenMap 用于存储 mint address 到 TokenData 的映射
@@ -4,27 +4,47 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"wallet-tracker/config"
 
 	"github.com/portto/solana-go-sdk/client"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+const (
+	dasPageLimit = 1000 // searchAssets 每页请求的资产数量（Helius 上限为1000）
+	rpcPageLimit = 1000 // getTokenAccountsByOwnerV2 每页请求的账户数量
+)
+
+// maxTokenAccounts 单个钱包最多获取的代币账户/资产数量
+var maxTokenAccounts = config.DefaultMaxTokenAccounts
+
+// SetMaxTokenAccounts 设置单个钱包分页获取的代币数量上限，<=0 时使用默认值
+func SetMaxTokenAccounts(limit int) {
+	if limit <= 0 {
+		limit = config.DefaultMaxTokenAccounts
+	}
+	maxTokenAccounts = limit
+}
+
 // HeliusService Helius API服务
 type HeliusService struct {
 	client   *http.Client
 	endpoint string
 	apiKey   string
+	apiURL   string // 增强交易等 REST 接口地址
 }
 
+// NewHeliusService 使用环境变量中的配置创建 Helius 服务
 func NewHeliusService() (*HeliusService, error) {
 	endpoint := os.Getenv("HELIUS_RPC_ENDPOINT")
 	apiKey := os.Getenv("HELIUS_API_KEY")
@@ -32,20 +52,35 @@ func NewHeliusService() (*HeliusService, error) {
 		return nil, fmt.Errorf("缺少 Helius API 配置")
 	}
 
+	service := NewHeliusServiceWithConfig(endpoint, apiKey, nil)
+	if apiURL := os.Getenv("HELIUS_API_ENDPOINT"); apiURL != "" {
+		service.apiURL = apiURL
+	}
+	return service, nil
+}
+
+// NewHeliusServiceWithConfig 使用指定的端点、API密钥和HTTP客户端创建 Helius 服务，client 为nil时使用共享客户端
+func NewHeliusServiceWithConfig(endpoint, apiKey string, client *http.Client) *HeliusService {
+	if client == nil {
+		client = apiHTTPClient()
+	}
+
 	return &HeliusService{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:   client,
 		endpoint: endpoint,
 		apiKey:   apiKey,
-	}, nil
+		apiURL:   defaultHeliusAPIURL,
+	}
 }
 
 // TokenAccount 代表一个代币账户
 type TokenAccount struct {
-	Mint     string
-	Balance  uint64
-	Decimals uint8
+	Mint           string
+	Balance        uint64
+	Decimals       uint8
+	Program        string // 所属的代币程序ID
+	TransferFeeBps uint16 // Token-2022 转账手续费（基点）
+	MaxTransferFee uint64 // Token-2022 单笔转账手续费上限（原始单位）
 }
 
 // TokenResult 代表一个数据源的结果
@@ -55,15 +90,18 @@ type TokenResult struct {
 }
 
 // FetchWalletTokens 获取钱包下所有 token 列表
-func FetchWalletTokens(walletAddr string, rpcClient *client.Client, cfg *config.Config) ([]*TokenData, error) {
-	log.Printf("开始获取钱包 %s 的代币列表...", walletAddr)
-	log.Println("----------------------------------------")
-
+func FetchWalletTokens(ctx context.Context, walletAddr string, rpcClient *client.Client, cfg *config.Config) ([]*TokenData, error) {
 	// 创建 Helius 服务实例
 	helius, err := NewHeliusService()
 	if err != nil {
 		return nil, err
 	}
+	return FetchWalletTokensWithService(ctx, walletAddr, helius, cfg)
+}
+
+// FetchWalletTokensWithService 使用指定的 Helius 服务获取钱包下所有 token 列表
+func FetchWalletTokensWithService(ctx context.Context, walletAddr string, helius *HeliusService, cfg *config.Config) ([]*TokenData, error) {
+	walletLog.Info("开始获取钱包代币列表", "wallet", walletAddr)
 
 	// 创建通道用于接收结果
 	rpcChan := make(chan []*TokenAccount)
@@ -74,14 +112,16 @@ func FetchWalletTokens(walletAddr string, rpcClient *client.Client, cfg *config.
 	})
 
 	// 并发获取 RPC 和 DAS 数据
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// 启动 RPC 获取 goroutine
+	var rpcErr error
 	go func() {
 		accounts, err := fetchTokenAccountsByRPC(ctx, walletAddr, helius)
 		if err != nil {
-			log.Printf("RPC获取失败: %v", err)
+			walletLog.Error("RPC获取失败", "wallet", walletAddr, "error", err)
+			rpcErr = err
 			rpcChan <- nil
 			return
 		}
@@ -106,147 +146,345 @@ func FetchWalletTokens(walletAddr string, rpcClient *client.Client, cfg *config.
 	// 使用 select 处理超时
 	select {
 	case rpcTokens = <-rpcChan:
-		log.Printf("RPC获取到 %d 个代币账户", len(rpcTokens))
+		walletLog.Debug("RPC获取代币账户完成", "wallet", walletAddr, "accounts", len(rpcTokens))
 	case <-ctx.Done():
 		return nil, fmt.Errorf("RPC获取超时")
 	}
+	// Helius 熔断期间 DAS 同样不可用，直接返回错误，避免把钱包当作空仓
+	if errors.Is(rpcErr, ErrCircuitOpen) {
+		return nil, rpcErr
+	}
 
 	select {
 	case dasResult := <-dasChan:
 		if dasResult.err != nil {
-			log.Printf("警告: DAS API获取失败: %v, 将使用RPC数据作为备选", dasResult.err)
+			walletLog.Warn("DAS API获取失败，将使用RPC数据作为备选", "wallet", walletAddr, "error", dasResult.err)
+			nativeBalance = fetchNativeBalanceFallback(ctx, walletAddr, helius)
 		} else {
 			dasTokens = dasResult.tokens
 			nativeBalance = dasResult.balance
-			log.Printf("DAS API获取到 %d 个代币", len(dasTokens))
+			walletLog.Debug("DAS API获取代币完成", "wallet", walletAddr, "tokens", len(dasTokens))
 		}
 	case <-ctx.Done():
-		log.Printf("警告: DAS API获取超时，将使用RPC数据作为备选")
+		walletLog.Warn("DAS API获取超时，将使用RPC数据作为备选", "wallet", walletAddr)
 	}
 
+	// DAS 没有返回的代币只有RPC数据，通过 getAsset/Metaplex 查询元数据
+	resolveUnknownMetadata(ctx, helius, cfg, rpcOnlyMints(rpcTokens, dasTokens))
+
 	// 合并数据
-	log.Println("合并RPC和DAS API数据")
+	walletLog.Debug("合并RPC和DAS API数据", "wallet", walletAddr)
 	mergedTokens := mergeTokenData(rpcTokens, dasTokens)
 
-	// 添加原生 SOL 余额
-	if nativeBalance > 0 {
-		solAmount := float64(nativeBalance) / 1e9
-		log.Printf("添加SOL余额: %.0f SOL", solAmount)
+	// 缓存DAS返回的元数据，并为未知代币回填配置中的元数据
+	cacheTokenMetadata(dasTokens, cfg)
+	fillTokenMetadata(mergedTokens, cfg)
+
+	// 流动性质押代币计入质押
+	markLiquidStaking(mergedTokens)
+
+	// 获取原生质押账户余额，计入SOL持仓
+	stakedLamports, stakeAccounts, err := helius.fetchStakedBalance(ctx, walletAddr)
+	if err != nil {
+		walletLog.Warn("获取质押账户失败", "wallet", walletAddr, "error", err)
+	} else if stakeAccounts > 0 {
+		walletLog.Info("获取质押账户完成", "wallet", walletAddr, "accounts", stakeAccounts, "sol", float64(stakedLamports)/lamportsPerSOL)
+	}
+
+	// 添加原生 SOL 余额（含质押）
+	if nativeBalance > 0 || stakedLamports > 0 {
+		solAmount := float64(nativeBalance) / lamportsPerSOL
+		stakedAmount := float64(stakedLamports) / lamportsPerSOL
+		walletLog.Debug("添加SOL余额", "wallet", walletAddr, "amount", solAmount)
 		mergedTokens = append(mergedTokens, &TokenData{
-			MintAddr: "So11111111111111111111111111111111111111111",
-			Amount:   solAmount,
+			MintAddr: nativeSOLMint,
+			Amount:   solAmount + stakedAmount,
+			Staked:   stakedAmount,
 			Decimals: 9,
 			Symbol:   "SOL",
 			Name:     "Solana",
 		})
 	}
 
+	// 识别DeFi仓位：LP代币和仓位NFT拆分为底层资产，借贷存款计入持仓
+	if cfg != nil && cfg.Settings.IncludeDeFi {
+		positions := helius.FetchDeFiPositions(ctx, walletAddr, mergedTokens)
+		var added []string
+		mergedTokens, added = applyDeFiPositions(mergedTokens, positions)
+		if len(added) > 0 {
+			resolveUnknownMetadata(ctx, helius, cfg, added)
+			fillTokenMetadata(mergedTokens, cfg)
+		}
+		storeDeFiPositions(walletAddr, positions)
+		if len(positions) > 0 {
+			walletLog.Info("获取DeFi仓位完成", "wallet", walletAddr, "positions", len(positions))
+		}
+	} else {
+		storeDeFiPositions(walletAddr, nil)
+	}
+
 	// 移除过滤规则相关代码，让价格更新后再过滤
 	return mergedTokens, nil
 }
 
-// fetchTokenAccountsByRPC 使用RPC获取代币账户列表
-func fetchTokenAccountsByRPC(ctx context.Context, walletAddr string, helius *HeliusService) ([]*TokenAccount, error) {
-	// 发送 RPC 请求并获取响应
-	var result struct {
-		Result struct {
-			Value []struct {
-				Account struct {
-					Data struct {
-						Parsed struct {
-							Info struct {
-								Mint        string `json:"mint"`
-								TokenAmount struct {
-									Amount   string `json:"amount"`
-									Decimals int    `json:"decimals"`
-								} `json:"tokenAmount"`
-							} `json:"info"`
-						} `json:"parsed"`
-					} `json:"data"`
-				} `json:"account"`
-			} `json:"value"`
-		} `json:"result"`
-	}
-
-	url := fmt.Sprintf("%s/?api-key=%s", helius.endpoint, helius.apiKey)
-	jsonData, _ := json.Marshal(map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      fmt.Sprintf("rpc-query-%d", rand.Int()),
-		"method":  "getTokenAccountsByOwner",
-		"params": []interface{}{
-			walletAddr,
-			map[string]interface{}{
-				"programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
-			},
-			map[string]interface{}{
-				"encoding": "jsonParsed",
-			},
-		},
-	})
+// fetchTokenAccountsByRPC 使用RPC获取 SPL Token 和 Token-2022 程序下的代币账户列表，
+// 端点不可用时切换到配置的备用端点
+func fetchTokenAccountsByRPC(ctx context.Context, walletAddr string, helius *HeliusService) (all []*TokenAccount, err error) {
+	ctx, span := StartSpan(ctx, "fetchTokenAccountsByRPC")
+	defer func() {
+		span.SetAttributes(attribute.Int("token.accounts", len(all)))
+		EndSpan(span, err)
+	}()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	var tokenAccounts, token2022Accounts []*TokenAccount
+	err = withRPCFailover(ctx, helius, func(endpoint *rpcEndpoint) error {
+		accounts, err := fetchTokenAccountsByProgram(ctx, walletAddr, endpoint, helius.client, tokenProgramID)
+		if err != nil {
+			return err
+		}
+		tokenAccounts = accounts
 
-	resp, err := helius.client.Do(req)
+		// Token-2022 账户获取失败不影响 SPL Token 的结果
+		token2022Accounts, err = fetchTokenAccountsByProgram(ctx, walletAddr, endpoint, helius.client, token2022ProgramID)
+		if err != nil {
+			walletLog.Warn("获取Token-2022代币账户失败", "endpoint", endpoint.name, "error", err)
+			token2022Accounts = nil
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("发送请求失败: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+	if len(token2022Accounts) > 0 {
+		helius.applyTransferFees(ctx, token2022Accounts)
 	}
 
+	return append(tokenAccounts, token2022Accounts...), nil
+}
+
+// fetchTokenAccountsByProgram 获取钱包在指定代币程序下的代币账户（支持分页的端点按页循环直到取完或达到上限）
+func fetchTokenAccountsByProgram(ctx context.Context, walletAddr string, endpoint *rpcEndpoint, client *http.Client, programID string) ([]*TokenAccount, error) {
 	var tokenAccounts []*TokenAccount
-	for _, acc := range result.Result.Value {
-		info := acc.Account.Data.Parsed.Info
-		amount, err := strconv.ParseUint(info.TokenAmount.Amount, 10, 64)
+	paginationKey := ""
+
+	for page := 1; ; page++ {
+		result, err := endpoint.tokenAccountsPage(ctx, client, walletAddr, programID, paginationKey)
 		if err != nil {
-			log.Printf("警告: 无法解析代币数量 %s: %v", info.TokenAmount.Amount, err)
-			continue
+			if page == 1 {
+				return nil, err
+			}
+			walletLog.Warn("RPC获取代币账户分页失败，返回已获取的账户", "page", page, "fetched", len(tokenAccounts), "error", err)
+			return tokenAccounts, nil
 		}
 
-		log.Printf("RPC代币数据: Mint=%s, Amount=%s, Decimals=%d",
-			info.Mint, info.TokenAmount.Amount, info.TokenAmount.Decimals)
+		for _, acc := range result.Value {
+			info := acc.Account.Data.Parsed.Info
+			amount, err := strconv.ParseUint(info.TokenAmount.Amount, 10, 64)
+			if err != nil {
+				walletLog.Warn("无法解析代币数量", "amount", info.TokenAmount.Amount, "error", err)
+				continue
+			}
+
+			walletLog.Debug("RPC代币数据", "mint", info.Mint, "amount", info.TokenAmount.Amount, "decimals", info.TokenAmount.Decimals)
 
-		tokenAccounts = append(tokenAccounts, &TokenAccount{
-			Mint:     info.Mint,
-			Balance:  amount,
-			Decimals: uint8(info.TokenAmount.Decimals),
-		})
+			tokenAccounts = append(tokenAccounts, &TokenAccount{
+				Mint:     info.Mint,
+				Balance:  amount,
+				Decimals: uint8(info.TokenAmount.Decimals),
+				Program:  programID,
+			})
+		}
+
+		if len(tokenAccounts) >= maxTokenAccounts {
+			walletLog.Warn("代币账户数量达到上限，停止分页", "limit", maxTokenAccounts)
+			return tokenAccounts[:maxTokenAccounts], nil
+		}
+		if result.PaginationKey == "" || len(result.Value) == 0 {
+			break
+		}
+		paginationKey = result.PaginationKey
 	}
 
 	return tokenAccounts, nil
 }
 
-// fetchTokensWithDAS 使用DAS API获取代币列表
-func (s *HeliusService) fetchTokensWithDAS(ctx context.Context, walletAddr string) ([]*TokenData, uint64, error) {
+// rpcTokenAccountsResult getTokenAccountsByOwner(V2) 单页响应中需要的字段
+type rpcTokenAccountsResult struct {
+	Value []struct {
+		Account struct {
+			Data struct {
+				Parsed struct {
+					Info struct {
+						Mint        string `json:"mint"`
+						TokenAmount struct {
+							Amount   string `json:"amount"`
+							Decimals int    `json:"decimals"`
+						} `json:"tokenAmount"`
+					} `json:"info"`
+				} `json:"parsed"`
+			} `json:"data"`
+		} `json:"account"`
+	} `json:"value"`
+	PaginationKey string `json:"paginationKey"`
+}
+
+// tokenAccountsPage 请求单页代币账户：Helius 使用支持分页的 getTokenAccountsByOwnerV2，
+// 其他端点使用标准的 getTokenAccountsByOwner 一次返回全部账户
+func (e *rpcEndpoint) tokenAccountsPage(ctx context.Context, client *http.Client, walletAddr, programID, paginationKey string) (*rpcTokenAccountsResult, error) {
+	method := "getTokenAccountsByOwner"
+	options := map[string]interface{}{
+		"encoding": "jsonParsed",
+	}
+	if e.paginated {
+		method = "getTokenAccountsByOwnerV2"
+		options["limit"] = rpcPageLimit
+		if paginationKey != "" {
+			options["paginationKey"] = paginationKey
+		}
+	}
+
+	var result rpcTokenAccountsResult
+	params := []interface{}{
+		walletAddr,
+		map[string]interface{}{
+			"programId": programID,
+		},
+		options,
+	}
+	if err := e.call(ctx, client, method, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// dasSearchResult searchAssets 单页响应中需要的字段
+type dasSearchResult struct {
+	Total         int `json:"total"`
+	NativeBalance struct {
+		Lamports uint64 `json:"lamports"`
+	} `json:"nativeBalance"`
+	Items []struct {
+		Interface string `json:"interface"`
+		ID        string `json:"id"`
+		Content   struct {
+			Metadata struct {
+				Symbol string `json:"symbol"`
+				Name   string `json:"name"`
+			} `json:"metadata"`
+		} `json:"content"`
+		TokenInfo struct {
+			Balance  string `json:"balance"`
+			Decimals int    `json:"decimals"`
+			Symbol   string `json:"symbol"`
+			Name     string `json:"name"`
+		} `json:"token_info"`
+		Grouping []struct {
+			GroupKey   string `json:"group_key"`
+			GroupValue string `json:"group_value"`
+		} `json:"grouping"`
+		Compression struct {
+			Compressed bool `json:"compressed"`
+		} `json:"compression"`
+	} `json:"items"`
+}
+
+// fetchTokensWithDAS 使用DAS API获取代币列表（按页循环直到取完或达到上限）
+func (s *HeliusService) fetchTokensWithDAS(ctx context.Context, walletAddr string) (tokens []*TokenData, nativeBalance uint64, err error) {
+	ctx, span := StartSpan(ctx, "fetchTokensWithDAS")
+	defer func() {
+		span.SetAttributes(attribute.Int("token.count", len(tokens)))
+		EndSpan(span, err)
+	}()
+
+	fetched := 0
+
+	for page := 1; ; page++ {
+		// 每一页之前检查上下文，保证超时对整个分页过程生效
+		if err := ctx.Err(); err != nil {
+			if page == 1 {
+				return nil, 0, err
+			}
+			walletLog.Warn("DAS API分页中断，返回已获取的代币", "page", page, "fetched", len(tokens), "error", err)
+			return tokens, nativeBalance, nil
+		}
+
+		result, err := s.searchAssetsPage(ctx, walletAddr, "fungible", page)
+		if err != nil {
+			if page == 1 {
+				return nil, 0, err
+			}
+			walletLog.Warn("DAS API获取分页失败，返回已获取的代币", "page", page, "fetched", len(tokens), "error", err)
+			return tokens, nativeBalance, nil
+		}
+
+		// 原生余额只从第一页读取
+		if page == 1 {
+			nativeBalance = result.NativeBalance.Lamports
+		}
+
+		for _, item := range result.Items {
+			symbol := item.TokenInfo.Symbol
+			name := item.TokenInfo.Name
+			if symbol == "" {
+				symbol = item.Content.Metadata.Symbol
+			}
+			if name == "" {
+				name = item.Content.Metadata.Name
+			}
+
+			// 直接解析为float64，因为DAS API返回的balance可能包含小数点
+			balance, err := strconv.ParseFloat(item.TokenInfo.Balance, 64)
+			if err != nil {
+				walletLog.Warn("无法解析代币余额", "balance", item.TokenInfo.Balance, "error", err)
+				continue
+			}
+
+			walletLog.Debug("处理DAS代币数据", "mint", item.ID, "raw_balance", item.TokenInfo.Balance)
+
+			td := &TokenData{
+				MintAddr: item.ID,
+				Amount:   balance, // 直接使用解析后的float64值
+				Decimals: uint8(item.TokenInfo.Decimals),
+				Symbol:   symbol,
+				Name:     name,
+			}
+			tokens = append(tokens, td)
+		}
+
+		fetched += len(result.Items)
+		walletLog.Debug("DAS API分页获取完成", "page", page, "items", len(result.Items))
+
+		if len(tokens) >= maxTokenAccounts {
+			walletLog.Warn("DAS资产数量达到上限，停止分页", "limit", maxTokenAccounts)
+			tokens = tokens[:maxTokenAccounts]
+			break
+		}
+
+		// 最后一页：条目数不足一页，或已达到总数
+		// （Helius 的 total 通常是本页条目数，超过一页时才视为总数）
+		if len(result.Items) < dasPageLimit || result.Total < dasPageLimit {
+			break
+		}
+		if result.Total > dasPageLimit && fetched >= result.Total {
+			break
+		}
+	}
+
+	return tokens, nativeBalance, nil
+}
+
+// searchAssetsPage 请求 searchAssets 的单页数据，tokenType 为 fungible 或 nonFungible
+func (s *HeliusService) searchAssetsPage(ctx context.Context, walletAddr, tokenType string, page int) (result *dasSearchResult, err error) {
+	ctx, span := StartSpan(ctx, "das.searchAssets", attribute.String("das.token_type", tokenType), attribute.Int("das.page", page))
+	defer func() {
+		if result != nil {
+			span.SetAttributes(attribute.Int("das.items", len(result.Items)))
+		}
+		EndSpan(span, err)
+	}()
+
 	var dasResponse struct {
-		Result struct {
-			Total         int `json:"total"`
-			NativeBalance struct {
-				Lamports uint64 `json:"lamports"`
-			} `json:"nativeBalance"`
-			Items []struct {
-				Interface string `json:"interface"`
-				ID        string `json:"id"`
-				Content   struct {
-					Metadata struct {
-						Symbol string `json:"symbol"`
-						Name   string `json:"name"`
-					} `json:"metadata"`
-				} `json:"content"`
-				TokenInfo struct {
-					Balance  string `json:"balance"`
-					Decimals int    `json:"decimals"`
-					Symbol   string `json:"symbol"`
-					Name     string `json:"name"`
-				} `json:"token_info"`
-			} `json:"items"`
-		} `json:"result"`
+		Result dasSearchResult `json:"result"`
 	}
 
 	url := fmt.Sprintf("%s/?api-key=%s", s.endpoint, s.apiKey)
@@ -256,7 +494,9 @@ func (s *HeliusService) fetchTokensWithDAS(ctx context.Context, walletAddr strin
 		"method":  "searchAssets",
 		"params": map[string]interface{}{
 			"ownerAddress": walletAddr,
-			"tokenType":    "fungible",
+			"tokenType":    tokenType,
+			"page":         page,
+			"limit":        dasPageLimit,
 			"displayOptions": map[string]interface{}{
 				"showNativeBalance": true,
 			},
@@ -265,51 +505,51 @@ func (s *HeliusService) fetchTokensWithDAS(ctx context.Context, walletAddr strin
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, 0, fmt.Errorf("创建请求失败: %v", err)
+		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := heliusBreaker.Do(s.client, req)
+	if !errors.Is(err, ErrCircuitOpen) {
+		observeSourceResponse("helius-das", resp, err)
+	}
 	if err != nil {
-		return nil, 0, fmt.Errorf("发送请求失败: %v", err)
+		return nil, fmt.Errorf("发送请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if err := json.NewDecoder(resp.Body).Decode(&dasResponse); err != nil {
-		return nil, 0, fmt.Errorf("解析响应失败: %v", err)
+		return nil, fmt.Errorf("解析响应失败: %v", err)
 	}
 
-	var tokens []*TokenData
-	for _, item := range dasResponse.Result.Items {
-		symbol := item.TokenInfo.Symbol
-		name := item.TokenInfo.Name
-		if symbol == "" {
-			symbol = item.Content.Metadata.Symbol
-		}
-		if name == "" {
-			name = item.Content.Metadata.Name
-		}
-
-		// 直接解析为float64，因为DAS API返回的balance可能包含小数点
-		balance, err := strconv.ParseFloat(item.TokenInfo.Balance, 64)
-		if err != nil {
-			log.Printf("警告: 无法解析代币余额 %s: %v", item.TokenInfo.Balance, err)
-			continue
-		}
+	return &dasResponse.Result, nil
+}
 
-		log.Printf("处理DAS代币数据: Mint=%s, RawBalance=%s", item.ID, item.TokenInfo.Balance)
+// fetchNativeBalanceFallback DAS 不可用时通过RPC获取 SOL 余额，避免 SOL 持仓被当作清仓
+func fetchNativeBalanceFallback(ctx context.Context, walletAddr string, helius *HeliusService) uint64 {
+	balance, err := fetchNativeBalanceByRPC(ctx, walletAddr, helius)
+	if err != nil {
+		walletLog.Warn("RPC获取SOL余额失败", "wallet", walletAddr, "error", err)
+		return 0
+	}
+	return balance
+}
 
-		td := &TokenData{
-			MintAddr: item.ID,
-			Amount:   balance, // 直接使用解析后的float64值
-			Decimals: uint8(item.TokenInfo.Decimals),
-			Symbol:   symbol,
-			Name:     name,
+// rpcOnlyMints 返回只在RPC结果中出现、DAS没有返回的代币 mint 地址
+func rpcOnlyMints(rpcTokens []*TokenAccount, dasTokens []*TokenData) []string {
+	dasMints := make(map[string]bool, len(dasTokens))
+	for _, token := range dasTokens {
+		if !isUnknownSymbol(token.Symbol) {
+			dasMints[token.MintAddr] = true
 		}
-		tokens = append(tokens, td)
 	}
-
-	return tokens, dasResponse.Result.NativeBalance.Lamports, nil
+	var mints []string
+	for _, account := range rpcTokens {
+		if !dasMints[account.Mint] {
+			mints = append(mints, account.Mint)
+		}
+	}
+	return mints
 }
 
 // mergeTokenData 合并RPC和DAS API的数据
@@ -328,26 +568,27 @@ func mergeTokenData(rpcTokens []*TokenAccount, dasTokens []*TokenData) []*TokenD
 	for _, rpcToken := range rpcTokens {
 		if dasToken, ok := dasTokenMap[rpcToken.Mint]; ok {
 			// 如果DAS API中有对应的token，使用DAS的数据
+			applyTransferFee(dasToken, rpcToken)
 			mergedTokens = append(mergedTokens, dasToken)
 		} else {
 			// 如果DAS API中没有，从RPC数据创建token数据
-			log.Printf("处理RPC代币数据: Mint=%s, Balance=%d, Decimals=%d",
-				rpcToken.Mint, rpcToken.Balance, rpcToken.Decimals)
+			walletLog.Debug("处理RPC代币数据", "mint", rpcToken.Mint, "balance", rpcToken.Balance, "decimals", rpcToken.Decimals)
 
 			actualBalance := float64(rpcToken.Balance)
 			if rpcToken.Decimals > 0 {
 				actualBalance = actualBalance / math.Pow10(int(rpcToken.Decimals))
 			}
 
-			mergedTokens = append(mergedTokens, &TokenData{
+			token := &TokenData{
 				MintAddr: rpcToken.Mint,
 				Amount:   actualBalance,
 				Decimals: rpcToken.Decimals,
 				Symbol:   "UNKNOWN",
 				Name:     "Unknown Token",
-			})
-			log.Printf("创建RPC代币数据: Mint=%s, ActualBalance=%.8f, Decimals=%d",
-				rpcToken.Mint, actualBalance, rpcToken.Decimals)
+			}
+			applyTransferFee(token, rpcToken)
+			mergedTokens = append(mergedTokens, token)
+			walletLog.Debug("创建RPC代币数据", "mint", rpcToken.Mint, "balance", actualBalance, "decimals", rpcToken.Decimals)
 		}
 		processedMints[rpcToken.Mint] = true
 	}
@@ -362,77 +603,223 @@ func mergeTokenData(rpcTokens []*TokenAccount, dasTokens []*TokenData) []*TokenD
 	return mergedTokens
 }
 
+// isUnknownSymbol 判断代币符号是否缺失
+func isUnknownSymbol(symbol string) bool {
+	return symbol == "" || symbol == "UNKNOWN"
+}
+
+// cacheTokenMetadata 将DAS获取到的代币元数据写入缓存
+func cacheTokenMetadata(tokens []*TokenData, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	for _, token := range tokens {
+		if isUnknownSymbol(token.Symbol) {
+			continue
+		}
+		cfg.SetTokenMetadata(token.MintAddr, &config.TokenMetadata{
+			Symbol:   token.Symbol,
+			Name:     token.Name,
+			Decimals: int(token.Decimals),
+		})
+	}
+}
+
+// fillTokenMetadata 使用缓存或配置中的元数据回填未知代币的符号、名称和精度
+func fillTokenMetadata(tokens []*TokenData, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	for _, token := range tokens {
+		if !isUnknownSymbol(token.Symbol) && token.Name != "" && token.Name != "Unknown Token" {
+			continue
+		}
+		metadata := cfg.GetTokenMetadata(token.MintAddr)
+		if metadata == nil {
+			continue
+		}
+		if isUnknownSymbol(token.Symbol) && metadata.Symbol != "" {
+			token.Symbol = metadata.Symbol
+		}
+		if (token.Name == "" || token.Name == "Unknown Token") && metadata.Name != "" {
+			token.Name = metadata.Name
+		}
+		if token.Decimals == 0 && metadata.Decimals > 0 {
+			token.Decimals = uint8(metadata.Decimals)
+		}
+		walletLog.Debug("使用元数据回填代币", "mint", token.MintAddr, "symbol", token.Symbol, "name", token.Name)
+	}
+}
+
+var (
+	walletTokensMu    sync.Mutex
+	walletTokensCache = make(map[string][]*TokenData)
+)
+
+// rememberWalletTokens 记录钱包最近一次成功获取的代币列表
+func rememberWalletTokens(walletAddr string, tokens []*TokenData) {
+	walletTokensMu.Lock()
+	defer walletTokensMu.Unlock()
+	walletTokensCache[walletAddr] = copyTokens(tokens)
+}
+
+// lastWalletTokens 返回钱包最近一次成功获取的代币列表的副本
+func lastWalletTokens(walletAddr string) ([]*TokenData, bool) {
+	walletTokensMu.Lock()
+	defer walletTokensMu.Unlock()
+	tokens, ok := walletTokensCache[walletAddr]
+	if !ok {
+		return nil, false
+	}
+	return copyTokens(tokens), true
+}
+
+// copyTokens 复制代币列表，避免价格更新修改缓存中的数据
+func copyTokens(tokens []*TokenData) []*TokenData {
+	copied := make([]*TokenData, len(tokens))
+	for i, token := range tokens {
+		t := *token
+		copied[i] = &t
+	}
+	return copied
+}
+
 // FetchMultipleWalletsTokens 并发获取多个钱包的代币信息
-func FetchMultipleWalletsTokens(ctx context.Context, walletAddrs []string, c *client.Client, cfg *config.Config) (map[string][]*TokenData, error) {
-	log.Printf("开始并发获取 %d 个钱包的代币信息...", len(walletAddrs))
+// 返回每个钱包的获取结果（成功的代币列表、失败的错误和时间）；只有全部失败时才返回 error
+func FetchMultipleWalletsTokens(ctx context.Context, walletAddrs []string, c *client.Client, cfg *config.Config) (*WalletFetchResult, error) {
+	defer recordTiming("fetch_wallets", time.Now())
+	ctx, span := StartSpan(ctx, "FetchMultipleWalletsTokens", attribute.Int("wallet.count", len(walletAddrs)))
+	result, err := fetchMultipleWalletsTokens(ctx, walletAddrs, c, cfg)
+	span.SetAttributes(attribute.Int("wallet.failed", len(result.Errors)), attribute.Int("wallet.degraded", len(result.Degraded())))
+	EndSpan(span, err)
+	return result, err
+}
+
+// fetchMultipleWalletsTokens 并发获取多个钱包的代币信息
+func fetchMultipleWalletsTokens(ctx context.Context, walletAddrs []string, c *client.Client, cfg *config.Config) (*WalletFetchResult, error) {
+	walletLog.Info("开始并发获取钱包代币信息", "wallets", len(walletAddrs))
+	fetch := &WalletFetchResult{
+		Tokens:    make(map[string][]*TokenData),
+		Errors:    make(map[string]error),
+		Statuses:  make(map[string]*WalletFetchStatus),
+		StartedAt: time.Now(),
+	}
 
 	// 创建结果通道
 	type walletResult struct {
-		address string
-		tokens  []*TokenData
-		err     error
+		address  string
+		tokens   []*TokenData
+		err      error
+		cacheErr error // 获取失败但沿用了上一次的代币列表时的原始错误
 	}
 	resultChan := make(chan walletResult, len(walletAddrs))
 
 	// 创建信号量来限制并发请求数
-	const maxConcurrent = 3
+	maxConcurrent := config.DefaultMaxConcurrentWallets
+	if cfg != nil && cfg.Settings.MaxConcurrentWallets > 0 {
+		maxConcurrent = cfg.Settings.MaxConcurrentWallets
+	}
 	sem := make(chan struct{}, maxConcurrent)
 
 	for _, addr := range walletAddrs {
 		select {
 		case sem <- struct{}{}: // 获取信号量
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return fetch, ctx.Err()
 		}
 
 		go func(walletAddr string) {
+			ctx, span := StartSpan(ctx, "fetchWallet", attribute.String("wallet.address", walletAddr))
+			result := walletResult{address: walletAddr}
 			defer func() {
 				<-sem // 释放信号量
 				if r := recover(); r != nil {
-					log.Printf("处理钱包 %s 时发生panic: %v", walletAddr, r)
-					resultChan <- walletResult{
-						address: walletAddr,
-						err:     fmt.Errorf("panic: %v", r),
-					}
+					walletLog.Error("处理钱包时发生panic", "wallet", walletAddr, "panic", r)
+					result = walletResult{address: walletAddr, err: fmt.Errorf("panic: %v", r)}
 				}
+				span.SetAttributes(attribute.Int("wallet.tokens", len(result.tokens)), attribute.Bool("wallet.cached", result.cacheErr != nil))
+				EndSpan(span, result.err)
+				resultChan <- result
 			}()
 
-			// 添加随机延迟，避免同时发起请求
-			time.Sleep(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
+			// 按配置的间隔依次开始获取，避免同时发起请求
+			waitCtx, waitSpan := StartSpan(ctx, "walletFetchLimiter.Wait")
+			result.err = currentWalletFetchLimiter().Wait(waitCtx)
+			EndSpan(waitSpan, result.err)
+			if result.err != nil {
+				return
+			}
 
-			tokens, err := FetchWalletTokens(walletAddr, c, cfg)
-			resultChan <- walletResult{
-				address: walletAddr,
-				tokens:  tokens,
-				err:     err,
+			chain := config.DetectChain(walletAddr)
+			if cfg != nil {
+				chain = cfg.WalletChain(walletAddr)
+			}
+			span.SetAttributes(attribute.String("wallet.chain", chain))
+			chainClient, err := newChainClient(chain, c, cfg)
+			if err != nil {
+				result.err = err
+				return
+			}
+
+			result.tokens, result.err = chainClient.FetchTokens(ctx, walletAddr)
+			if errors.Is(result.err, ErrCircuitOpen) {
+				// 熔断期间使用上一次成功获取的代币列表
+				if cached, ok := lastWalletTokens(walletAddr); ok {
+					walletLog.Warn("API熔断中，使用上一次获取的代币列表", "wallet", walletAddr)
+					result.tokens, result.err, result.cacheErr = cached, nil, result.err
+				}
+			} else if result.err == nil {
+				rememberWalletTokens(walletAddr, result.tokens)
 			}
 		}(addr)
 	}
 
 	// 收集结果
-	results := make(map[string][]*TokenData)
 	var firstErr error
 	for i := 0; i < len(walletAddrs); i++ {
 		select {
 		case <-ctx.Done():
-			return results, ctx.Err()
+			fetch.FinishedAt = time.Now()
+			return fetch, ctx.Err()
 		case result := <-resultChan:
+			status := &WalletFetchStatus{Wallet: result.address, FetchedAt: time.Now()}
+			fetch.Statuses[result.address] = status
 			if result.err != nil {
-				log.Printf("获取钱包 %s 代币失败: %v", result.address, result.err)
+				walletLog.Error("获取钱包代币失败", "wallet", result.address, "error", result.err)
+				fetch.Errors[result.address] = result.err
+				status.Error = result.err.Error()
 				if firstErr == nil {
 					firstErr = result.err
 				}
 				continue
 			}
-			results[result.address] = result.tokens
+			fetch.Tokens[result.address] = result.tokens
+			status.OK = true
+			status.Tokens = len(result.tokens)
+			if result.cacheErr != nil {
+				status.Cached = true
+				status.Error = result.cacheErr.Error()
+			} else {
+				status.LastSuccess = status.FetchedAt
+			}
+		}
+	}
+	fetch.FinishedAt = time.Now()
+	recordWalletStatuses(fetch.Statuses)
+
+	// 保存本次获取到的代币元数据
+	if cfg != nil {
+		if err := cfg.SaveTokenMetadata(); err != nil {
+			walletLog.Warn("保存代币元数据缓存失败", "error", err)
 		}
 	}
 
 	// 如果所有钱包都失败了，返回错误
-	if len(results) == 0 && firstErr != nil {
-		return nil, fmt.Errorf("所有钱包处理失败: %v", firstErr)
+	if len(fetch.Tokens) == 0 && firstErr != nil {
+		return fetch, fmt.Errorf("所有钱包处理失败: %v", firstErr)
 	}
 
-	log.Printf("完成处理 %d 个钱包的代币信息", len(results))
-	return results, nil
+	walletLog.Info("完成处理钱包代币信息", "wallets", len(fetch.Tokens), "failed", len(fetch.Errors),
+		"degraded", len(fetch.Degraded()), "elapsed", fetch.FinishedAt.Sub(fetch.StartedAt).Round(time.Millisecond))
+	return fetch, nil
 }
@@ -0,0 +1,508 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"wallet-tracker/config"
+
+	"github.com/portto/solana-go-sdk/client"
+)
+
+const testWallet = "WaLLet1111111111111111111111111111111111111"
+
+// rpcRequest 测试服务器收到的 JSON-RPC 请求
+type rpcRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// newRPCServer 启动按 method 返回预设 result 的 JSON-RPC 测试服务器，未登记的方法返回RPC错误
+func newRPCServer(t *testing.T, handlers map[string]func(params json.RawMessage) string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		handler, ok := handlers[req.Method]
+		if !ok {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":"1","error":{"message":"method not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":"1","result":` + handler(req.Params) + `}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// tokenAccountJSON 生成 getTokenAccountsByOwner 响应中的单个账户
+func tokenAccountJSON(mint, amount string, decimals int) string {
+	b, _ := json.Marshal(map[string]interface{}{
+		"account": map[string]interface{}{
+			"data": map[string]interface{}{
+				"parsed": map[string]interface{}{
+					"info": map[string]interface{}{
+						"mint": mint,
+						"tokenAmount": map[string]interface{}{
+							"amount":   amount,
+							"decimals": decimals,
+						},
+					},
+				},
+			},
+		},
+	})
+	return string(b)
+}
+
+// programID 返回 getTokenAccountsByOwner 请求中的代币程序ID和分页键
+func programID(t *testing.T, params json.RawMessage) (string, string) {
+	t.Helper()
+	var raw []json.RawMessage
+	if err := json.Unmarshal(params, &raw); err != nil || len(raw) < 3 {
+		t.Fatalf("无法解析请求参数: %s", params)
+	}
+	var filter struct {
+		ProgramID string `json:"programId"`
+	}
+	var options struct {
+		PaginationKey string `json:"paginationKey"`
+	}
+	json.Unmarshal(raw[1], &filter)
+	json.Unmarshal(raw[2], &options)
+	return filter.ProgramID, options.PaginationKey
+}
+
+func tokensByMint(tokens []*TokenData) map[string]*TokenData {
+	byMint := make(map[string]*TokenData, len(tokens))
+	for _, token := range tokens {
+		byMint[token.MintAddr] = token
+	}
+	return byMint
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFetchTokenAccountsByRPC(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages map[string]string // paginationKey -> result
+		want  map[string]float64
+	}{
+		{
+			name: "单页",
+			pages: map[string]string{
+				"": `{"value":[` + tokenAccountJSON("MintA", "1500000", 6) + `,` + tokenAccountJSON("MintB", "42", 0) + `]}`,
+			},
+			want: map[string]float64{"MintA": 1500000, "MintB": 42},
+		},
+		{
+			name: "按paginationKey分页",
+			pages: map[string]string{
+				"":      `{"value":[` + tokenAccountJSON("MintA", "1", 9) + `],"paginationKey":"next"}`,
+				"next":  `{"value":[` + tokenAccountJSON("MintB", "2", 9) + `],"paginationKey":"last"}`,
+				"last":  `{"value":[]}`,
+				"other": `{"value":[` + tokenAccountJSON("MintC", "3", 9) + `]}`,
+			},
+			want: map[string]float64{"MintA": 1, "MintB": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRPCServer(t, map[string]func(json.RawMessage) string{
+				"getTokenAccountsByOwnerV2": func(params json.RawMessage) string {
+					program, key := programID(t, params)
+					if program != tokenProgramID {
+						return `{"value":[]}`
+					}
+					return tt.pages[key]
+				},
+			})
+			helius := NewHeliusServiceWithConfig(srv.URL, "test", srv.Client())
+
+			accounts, err := fetchTokenAccountsByRPC(context.Background(), testWallet, helius)
+			if err != nil {
+				t.Fatalf("fetchTokenAccountsByRPC 返回错误: %v", err)
+			}
+			if len(accounts) != len(tt.want) {
+				t.Fatalf("账户数量 = %d, 期望 %d", len(accounts), len(tt.want))
+			}
+			for _, account := range accounts {
+				want, ok := tt.want[account.Mint]
+				if !ok {
+					t.Errorf("意外的账户 %s", account.Mint)
+					continue
+				}
+				if float64(account.Balance) != want {
+					t.Errorf("%s 余额 = %d, 期望 %.0f", account.Mint, account.Balance, want)
+				}
+				if account.Program != tokenProgramID {
+					t.Errorf("%s 程序 = %s, 期望 %s", account.Mint, account.Program, tokenProgramID)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchTokenAccountsByProgramStandardRPC(t *testing.T) {
+	srv := newRPCServer(t, map[string]func(json.RawMessage) string{
+		"getTokenAccountsByOwner": func(params json.RawMessage) string {
+			return `{"value":[` + tokenAccountJSON("MintA", "250000000", 8) + `,` + tokenAccountJSON("MintBad", "not-a-number", 6) + `]}`
+		},
+	})
+	endpoint := &rpcEndpoint{name: "test", url: srv.URL, breaker: NewCircuitBreaker("rpc:test", rpcFailureThreshold, rpcFailureCooldown)}
+
+	accounts, err := fetchTokenAccountsByProgram(context.Background(), testWallet, endpoint, srv.Client(), tokenProgramID)
+	if err != nil {
+		t.Fatalf("fetchTokenAccountsByProgram 返回错误: %v", err)
+	}
+	if len(accounts) != 1 {
+		t.Fatalf("账户数量 = %d, 期望 1（无法解析的数量应被跳过）", len(accounts))
+	}
+	if got := accounts[0]; got.Mint != "MintA" || got.Balance != 250000000 || got.Decimals != 8 {
+		t.Errorf("账户 = %+v", got)
+	}
+}
+
+func TestSearchAssetsPage(t *testing.T) {
+	srv := newRPCServer(t, map[string]func(json.RawMessage) string{
+		"searchAssets": func(params json.RawMessage) string {
+			return `{
+				"total": 2,
+				"nativeBalance": {"lamports": 2500000000},
+				"items": [
+					{"id": "MintA", "token_info": {"balance": "1.5", "decimals": 6, "symbol": "AAA", "name": "Token A"}},
+					{"id": "MintB", "token_info": {"balance": "3", "decimals": 9}, "content": {"metadata": {"symbol": "BBB", "name": "Token B"}}}
+				]
+			}`
+		},
+	})
+	helius := NewHeliusServiceWithConfig(srv.URL, "test", srv.Client())
+
+	result, err := helius.searchAssetsPage(context.Background(), testWallet, "fungible", 1)
+	if err != nil {
+		t.Fatalf("searchAssetsPage 返回错误: %v", err)
+	}
+	if result.NativeBalance.Lamports != 2500000000 || result.Total != 2 || len(result.Items) != 2 {
+		t.Fatalf("解析结果 = %+v", result)
+	}
+	if item := result.Items[0]; item.ID != "MintA" || item.TokenInfo.Balance != "1.5" || item.TokenInfo.Decimals != 6 {
+		t.Errorf("第一个条目 = %+v", item)
+	}
+
+	tokens, lamports, err := helius.fetchTokensWithDAS(context.Background(), testWallet)
+	if err != nil {
+		t.Fatalf("fetchTokensWithDAS 返回错误: %v", err)
+	}
+	if lamports != 2500000000 {
+		t.Errorf("原生余额 = %d, 期望 2500000000", lamports)
+	}
+	byMint := tokensByMint(tokens)
+	if token := byMint["MintA"]; token == nil || token.Symbol != "AAA" || !almostEqual(token.Amount, 1.5) {
+		t.Errorf("MintA = %+v", token)
+	}
+	// token_info 中没有符号时使用 content.metadata
+	if token := byMint["MintB"]; token == nil || token.Symbol != "BBB" || token.Name != "Token B" || !almostEqual(token.Amount, 3) {
+		t.Errorf("MintB = %+v", token)
+	}
+}
+
+func TestMergeTokenData(t *testing.T) {
+	tests := []struct {
+		name string
+		rpc  []*TokenAccount
+		das  []*TokenData
+		want []TokenData
+	}{
+		{
+			name: "RPC代币按精度换算",
+			rpc: []*TokenAccount{
+				{Mint: "MintA", Balance: 1500000, Decimals: 6},
+				{Mint: "MintB", Balance: 42, Decimals: 0},
+				{Mint: "MintC", Balance: 123456789, Decimals: 9},
+			},
+			want: []TokenData{
+				{MintAddr: "MintA", Amount: 1.5, Decimals: 6, Symbol: "UNKNOWN", Name: "Unknown Token"},
+				{MintAddr: "MintB", Amount: 42, Decimals: 0, Symbol: "UNKNOWN", Name: "Unknown Token"},
+				{MintAddr: "MintC", Amount: 0.123456789, Decimals: 9, Symbol: "UNKNOWN", Name: "Unknown Token"},
+			},
+		},
+		{
+			name: "两边都有时使用DAS数据",
+			rpc:  []*TokenAccount{{Mint: "MintA", Balance: 1500000, Decimals: 6}},
+			das:  []*TokenData{{MintAddr: "MintA", Amount: 1.5, Decimals: 6, Symbol: "AAA", Name: "Token A"}},
+			want: []TokenData{{MintAddr: "MintA", Amount: 1.5, Decimals: 6, Symbol: "AAA", Name: "Token A"}},
+		},
+		{
+			name: "只在DAS中的代币被追加",
+			rpc:  []*TokenAccount{{Mint: "MintA", Balance: 2000, Decimals: 3}},
+			das:  []*TokenData{{MintAddr: "MintD", Amount: 7, Decimals: 2, Symbol: "DDD", Name: "Token D"}},
+			want: []TokenData{
+				{MintAddr: "MintA", Amount: 2, Decimals: 3, Symbol: "UNKNOWN", Name: "Unknown Token"},
+				{MintAddr: "MintD", Amount: 7, Decimals: 2, Symbol: "DDD", Name: "Token D"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeTokenData(tt.rpc, tt.das)
+			if len(got) != len(tt.want) {
+				t.Fatalf("代币数量 = %d, 期望 %d", len(got), len(tt.want))
+			}
+			byMint := tokensByMint(got)
+			for _, want := range tt.want {
+				token := byMint[want.MintAddr]
+				if token == nil {
+					t.Errorf("缺少代币 %s", want.MintAddr)
+					continue
+				}
+				if !almostEqual(token.Amount, want.Amount) || token.Decimals != want.Decimals ||
+					token.Symbol != want.Symbol || token.Name != want.Name {
+					t.Errorf("%s = %+v, 期望 %+v", want.MintAddr, *token, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchWalletTokensWithService(t *testing.T) {
+	srv := newRPCServer(t, map[string]func(json.RawMessage) string{
+		"getTokenAccountsByOwnerV2": func(params json.RawMessage) string {
+			if program, _ := programID(t, params); program != tokenProgramID {
+				return `{"value":[]}`
+			}
+			return `{"value":[` + tokenAccountJSON("MintA", "1500000", 6) + `,` + tokenAccountJSON("MintRPC", "2500", 2) + `]}`
+		},
+		"searchAssets": func(params json.RawMessage) string {
+			return `{"total":1,"nativeBalance":{"lamports":1500000000},"items":[
+				{"id":"MintA","token_info":{"balance":"1.5","decimals":6,"symbol":"AAA","name":"Token A"}}
+			]}`
+		},
+		"getProgramAccounts": func(params json.RawMessage) string {
+			return `[]`
+		},
+	})
+	helius := NewHeliusServiceWithConfig(srv.URL, "test", srv.Client())
+
+	tokens, err := FetchWalletTokensWithService(context.Background(), testWallet, helius, nil)
+	if err != nil {
+		t.Fatalf("FetchWalletTokensWithService 返回错误: %v", err)
+	}
+
+	var mints []string
+	for _, token := range tokens {
+		mints = append(mints, token.MintAddr)
+	}
+	sort.Strings(mints)
+	if want := []string{"MintA", "MintRPC", nativeSOLMint}; len(mints) != len(want) {
+		t.Fatalf("代币 = %v, 期望 %v", mints, want)
+	}
+
+	byMint := tokensByMint(tokens)
+	if token := byMint["MintA"]; token.Symbol != "AAA" || !almostEqual(token.Amount, 1.5) {
+		t.Errorf("MintA = %+v", token)
+	}
+	if token := byMint["MintRPC"]; token.Symbol != "UNKNOWN" || !almostEqual(token.Amount, 25) || token.Decimals != 2 {
+		t.Errorf("MintRPC = %+v", token)
+	}
+	if token := byMint[nativeSOLMint]; token.Symbol != "SOL" || !almostEqual(token.Amount, 1.5) {
+		t.Errorf("SOL = %+v", token)
+	}
+}
+
+// fakeChainClient 按钱包返回预设结果的链客户端
+type fakeChainClient struct {
+	fetch func(walletAddr string) ([]*TokenData, error)
+}
+
+func (f *fakeChainClient) Chain() string { return config.ChainSolana }
+
+func (f *fakeChainClient) FetchTokens(ctx context.Context, walletAddr string) ([]*TokenData, error) {
+	return f.fetch(walletAddr)
+}
+
+func TestFetchMultipleWalletsTokensPartialFailure(t *testing.T) {
+	const (
+		okWallet     = "WalletOK1111111111111111111111111111111111"
+		errWallet    = "WalletErr111111111111111111111111111111111"
+		panicWallet  = "WalletPanic1111111111111111111111111111111"
+		cachedWallet = "WalletCached111111111111111111111111111111"
+	)
+	rememberWalletTokens(cachedWallet, []*TokenData{{MintAddr: "MintCached", Amount: 3}})
+
+	previous := newChainClient
+	newChainClient = func(chain string, rpc *client.Client, cfg *config.Config) (ChainClient, error) {
+		return &fakeChainClient{fetch: func(walletAddr string) ([]*TokenData, error) {
+			switch walletAddr {
+			case okWallet:
+				return []*TokenData{{MintAddr: "MintA", Amount: 1}, {MintAddr: "MintB", Amount: 2}}, nil
+			case errWallet:
+				return nil, errors.New("rpc unavailable")
+			case panicWallet:
+				panic("unexpected nil account")
+			case cachedWallet:
+				return nil, fmt.Errorf("所有RPC端点均不可用: %w", ErrCircuitOpen)
+			}
+			return nil, nil
+		}}, nil
+	}
+	defer func() { newChainClient = previous }()
+
+	result, err := fetchMultipleWalletsTokens(context.Background(),
+		[]string{okWallet, errWallet, panicWallet, cachedWallet}, nil, nil)
+	if err != nil {
+		t.Fatalf("部分钱包成功时不应返回错误: %v", err)
+	}
+
+	if tokens := result.Tokens[okWallet]; len(tokens) != 2 {
+		t.Errorf("%s 代币 = %v, 期望 2 个", okWallet, tokens)
+	}
+	if tokens := result.Tokens[cachedWallet]; len(tokens) != 1 || tokens[0].MintAddr != "MintCached" {
+		t.Errorf("熔断时应沿用上一次的代币列表, 得到 %v", tokens)
+	}
+	if !result.Statuses[cachedWallet].Cached {
+		t.Errorf("%s 状态应标记为使用缓存", cachedWallet)
+	}
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("错误 = %v, 期望 2 个", result.Errors)
+	}
+	if err := result.Errors[errWallet]; err == nil || err.Error() != "rpc unavailable" {
+		t.Errorf("%s 错误 = %v", errWallet, err)
+	}
+	if err := result.Errors[panicWallet]; err == nil || !strings.Contains(err.Error(), "panic: unexpected nil account") {
+		t.Errorf("%s 的panic应被恢复为错误, 得到 %v", panicWallet, err)
+	}
+	for _, wallet := range []string{errWallet, panicWallet} {
+		if _, ok := result.Tokens[wallet]; ok {
+			t.Errorf("失败的钱包 %s 不应有代币", wallet)
+		}
+		if status := result.Statuses[wallet]; status == nil || status.OK {
+			t.Errorf("%s 状态 = %+v, 期望失败", wallet, status)
+		}
+	}
+}
+
+func TestFetchMultipleWalletsTokensAllFailed(t *testing.T) {
+	previous := newChainClient
+	newChainClient = func(chain string, rpc *client.Client, cfg *config.Config) (ChainClient, error) {
+		return &fakeChainClient{fetch: func(walletAddr string) ([]*TokenData, error) {
+			panic("boom")
+		}}, nil
+	}
+	defer func() { newChainClient = previous }()
+
+	result, err := fetchMultipleWalletsTokens(context.Background(), []string{"WalletA", "WalletB"}, nil, nil)
+	if err == nil {
+		t.Fatal("全部钱包失败时应返回错误")
+	}
+	if len(result.Errors) != 2 || len(result.Tokens) != 0 {
+		t.Errorf("结果 = tokens %v, errors %v", result.Tokens, result.Errors)
+	}
+}
+
+// newMetadataConfig 创建带有 tokens 配置和内存元数据缓存的配置
+func newMetadataConfig(tokens ...config.TokenConfig) *config.Config {
+	cfg := &config.Config{Tokens: tokens}
+	cfg.SetMetadataCache(config.NewTokenMetadataCache())
+	return cfg
+}
+
+func TestFillTokenMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []config.TokenConfig
+		cached map[string]*config.TokenMetadata
+		das    []*TokenData // 本次DAS返回的代币，写入缓存
+		token  TokenData
+		want   TokenData
+	}{
+		{
+			name:   "配置条目回填未知代币",
+			tokens: []config.TokenConfig{{Address: "MintCfg", Symbol: "CFG", Name: "Configured", Decimal: 6}},
+			token:  TokenData{MintAddr: "MintCfg", Symbol: "UNKNOWN", Name: "Unknown Token"},
+			want:   TokenData{MintAddr: "MintCfg", Symbol: "CFG", Name: "Configured", Decimals: 6},
+		},
+		{
+			name:   "缓存的DAS元数据不覆盖配置条目",
+			tokens: []config.TokenConfig{{Address: "MintCfg", Symbol: "MINE", Name: "My Label"}},
+			das:    []*TokenData{{MintAddr: "MintCfg", Symbol: "DAS", Name: "From DAS", Decimals: 9}},
+			token:  TokenData{MintAddr: "MintCfg", Symbol: "UNKNOWN", Name: "Unknown Token"},
+			want:   TokenData{MintAddr: "MintCfg", Symbol: "MINE", Name: "My Label", Decimals: 9},
+		},
+		{
+			name:   "配置条目缺少的字段使用缓存",
+			tokens: []config.TokenConfig{{Address: "MintCfg", Decimal: 6}},
+			cached: map[string]*config.TokenMetadata{"MintCfg": {Symbol: "META", Name: "Metaplex Name", Decimals: 9}},
+			token:  TokenData{MintAddr: "MintCfg", Symbol: "UNKNOWN", Name: "Unknown Token"},
+			want:   TokenData{MintAddr: "MintCfg", Symbol: "META", Name: "Metaplex Name", Decimals: 6},
+		},
+		{
+			name:   "未配置的代币使用缓存",
+			cached: map[string]*config.TokenMetadata{"MintCached": {Symbol: "CCH", Name: "Cached", Decimals: 8}},
+			token:  TokenData{MintAddr: "MintCached", Symbol: "UNKNOWN", Name: "Unknown Token"},
+			want:   TokenData{MintAddr: "MintCached", Symbol: "CCH", Name: "Cached", Decimals: 8},
+		},
+		{
+			name:   "已知符号的代币不被配置覆盖",
+			tokens: []config.TokenConfig{{Address: "MintKnown", Symbol: "CFG", Name: "Configured"}},
+			token:  TokenData{MintAddr: "MintKnown", Symbol: "KNOWN", Name: "Known Token", Decimals: 6},
+			want:   TokenData{MintAddr: "MintKnown", Symbol: "KNOWN", Name: "Known Token", Decimals: 6},
+		},
+		{
+			name:  "没有元数据时保持未知",
+			token: TokenData{MintAddr: "MintNone", Symbol: "UNKNOWN", Name: "Unknown Token", Decimals: 4},
+			want:  TokenData{MintAddr: "MintNone", Symbol: "UNKNOWN", Name: "Unknown Token", Decimals: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newMetadataConfig(tt.tokens...)
+			for mint, metadata := range tt.cached {
+				cfg.SetTokenMetadata(mint, metadata)
+			}
+			cacheTokenMetadata(tt.das, cfg)
+
+			token := tt.token
+			fillTokenMetadata([]*TokenData{&token}, cfg)
+			if token.Symbol != tt.want.Symbol || token.Name != tt.want.Name || token.Decimals != tt.want.Decimals {
+				t.Errorf("回填结果 = %s/%s/%d, 期望 %s/%s/%d",
+					token.Symbol, token.Name, token.Decimals, tt.want.Symbol, tt.want.Name, tt.want.Decimals)
+			}
+		})
+	}
+}
+
+func TestCacheTokenMetadataSkipsUnknown(t *testing.T) {
+	cfg := newMetadataConfig()
+	cacheTokenMetadata([]*TokenData{
+		{MintAddr: "MintKnown", Symbol: "KNOWN", Name: "Known", Decimals: 6},
+		{MintAddr: "MintUnknown", Symbol: "UNKNOWN", Name: "Unknown Token"},
+		{MintAddr: "MintEmpty"},
+	}, cfg)
+
+	if metadata := cfg.GetTokenMetadata("MintKnown"); metadata == nil || metadata.Symbol != "KNOWN" || metadata.Decimals != 6 {
+		t.Errorf("MintKnown 缓存 = %+v", metadata)
+	}
+	for _, mint := range []string{"MintUnknown", "MintEmpty"} {
+		if metadata := cfg.GetTokenMetadata(mint); metadata != nil {
+			t.Errorf("%s 不应写入缓存, 得到 %+v", mint, metadata)
+		}
+	}
+}
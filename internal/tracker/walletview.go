@@ -0,0 +1,300 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"wallet-tracker/internal/i18n"
+)
+
+// walletSectionLimit 每个钱包分区显示的代币数量
+const walletSectionLimit = 10
+
+var (
+	walletLabelsMu sync.RWMutex
+	walletLabels   = make(map[string]string)
+	walletGroups   = make(map[string]string)
+)
+
+// SetWalletLabels 设置钱包地址到标签的映射，用于报告和报警
+func SetWalletLabels(labels map[string]string) {
+	walletLabelsMu.Lock()
+	defer walletLabelsMu.Unlock()
+	walletLabels = make(map[string]string, len(labels))
+	for addr, label := range labels {
+		walletLabels[addr] = label
+	}
+}
+
+// SetWalletGroups 设置钱包地址到分组的映射，用于按分组汇总报告
+func SetWalletGroups(groups map[string]string) {
+	walletLabelsMu.Lock()
+	defer walletLabelsMu.Unlock()
+	walletGroups = make(map[string]string, len(groups))
+	for addr, group := range groups {
+		walletGroups[addr] = group
+	}
+}
+
+// WalletGroup 返回钱包所属的分组，未分组时返回空字符串
+func WalletGroup(addr string) string {
+	walletLabelsMu.RLock()
+	defer walletLabelsMu.RUnlock()
+	return walletGroups[addr]
+}
+
+// WalletLabel 返回钱包的标签，未配置标签时返回缩写地址
+func WalletLabel(addr string) string {
+	walletLabelsMu.RLock()
+	label := walletLabels[addr]
+	walletLabelsMu.RUnlock()
+	if label != "" {
+		return label
+	}
+	if len(addr) > 12 {
+		return addr[:4] + "..." + addr[len(addr)-4:]
+	}
+	return addr
+}
+
+// WalletView 单个钱包的持仓视图
+type WalletView struct {
+	Wallet     string
+	Label      string
+	Tokens     []*TokenData // 按价值降序排列，数量和价值为该钱包的部分
+	TotalValue float64
+}
+
+// WalletBreakdown 根据聚合代币中的各钱包数量生成每个钱包的持仓视图，按总价值降序排列
+func WalletBreakdown(tokens []*TokenData) []*WalletView {
+	views := make(map[string]*WalletView)
+	for _, token := range tokens {
+		for wallet, amount := range token.WalletAmounts {
+			view, ok := views[wallet]
+			if !ok {
+				view = &WalletView{Wallet: wallet, Label: WalletLabel(wallet)}
+				views[wallet] = view
+			}
+			walletToken := *token
+			walletToken.Amount = amount
+			walletToken.Staked = proportionalStaked(token, amount)
+			walletToken.DeFi = proportionalDeFi(token, amount)
+			walletToken.Value = netAmount(&walletToken) * token.Price
+			walletToken.WalletAmounts = nil
+			view.Tokens = append(view.Tokens, &walletToken)
+			view.TotalValue += walletToken.Value
+		}
+	}
+
+	result := make([]*WalletView, 0, len(views))
+	for _, view := range views {
+		sort.Slice(view.Tokens, func(i, j int) bool {
+			return view.Tokens[i].Value > view.Tokens[j].Value
+		})
+		result = append(result, view)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TotalValue > result[j].TotalValue
+	})
+	return result
+}
+
+// splitByWallet 将聚合代币按钱包拆分，供重新计算价格时保留各钱包持仓
+func splitByWallet(tokens []*TokenData) map[string][]*TokenData {
+	walletTokens := make(map[string][]*TokenData)
+	for _, token := range tokens {
+		if len(token.WalletAmounts) == 0 {
+			walletTokens["default"] = append(walletTokens["default"], token)
+			continue
+		}
+		for wallet, amount := range token.WalletAmounts {
+			walletToken := *token
+			walletToken.Amount = amount
+			walletToken.Staked = proportionalStaked(token, amount)
+			walletToken.DeFi = proportionalDeFi(token, amount)
+			walletToken.WalletAmounts = nil
+			walletTokens[wallet] = append(walletTokens[wallet], &walletToken)
+		}
+	}
+	return walletTokens
+}
+
+// TokenHolder 某个钱包持有的一种代币
+type TokenHolder struct {
+	Wallet string  `json:"wallet"`
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+	Value  float64 `json:"value"` // 按当前价格计算的价值
+}
+
+// Holders 返回持有该代币的各钱包，按数量降序排列；没有钱包明细时返回nil
+func Holders(token *TokenData) []TokenHolder {
+	if len(token.WalletAmounts) == 0 {
+		return nil
+	}
+	holders := make([]TokenHolder, 0, len(token.WalletAmounts))
+	for wallet, amount := range token.WalletAmounts {
+		holders = append(holders, TokenHolder{
+			Wallet: wallet,
+			Label:  WalletLabel(wallet),
+			Amount: amount,
+			Value:  amount * token.Price,
+		})
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Amount != holders[j].Amount {
+			return holders[i].Amount > holders[j].Amount
+		}
+		return holders[i].Wallet < holders[j].Wallet
+	})
+	return holders
+}
+
+// soleHolder 返回唯一持有该代币的钱包地址，由多个钱包持有或没有钱包明细时返回空字符串
+func soleHolder(token *TokenData) string {
+	if len(token.WalletAmounts) != 1 {
+		return ""
+	}
+	for wallet := range token.WalletAmounts {
+		return wallet
+	}
+	return ""
+}
+
+// holderLabels 返回持有该代币的钱包标签列表，按数量降序排列；多个钱包持有时附带各自的数量占比
+func holderLabels(token *TokenData) string {
+	holders := Holders(token)
+	if len(holders) == 0 {
+		return "-"
+	}
+	if len(holders) == 1 {
+		return holders[0].Label
+	}
+	labels := make([]string, len(holders))
+	for i, holder := range holders {
+		var share float64
+		if token.Amount > 0 {
+			share = holder.Amount / token.Amount * 100
+		}
+		labels[i] = fmt.Sprintf("%s (%.0f%%)", holder.Label, share)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// walletCount 返回持有这些代币的钱包数量
+func walletCount(tokens []*TokenData) int {
+	wallets := make(map[string]struct{})
+	for _, token := range tokens {
+		for wallet := range token.WalletAmounts {
+			wallets[wallet] = struct{}{}
+		}
+	}
+	return len(wallets)
+}
+
+// generateWalletSections 生成每个钱包的持仓分区，只有一个钱包时不生成
+func generateWalletSections(tokens []*TokenData) string {
+	views := WalletBreakdown(tokens)
+	if len(views) < 2 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, view := range views {
+		sb.WriteString(i18n.Sprintf("\n钱包 %s (%s) 总值: $%.2f\n", view.Label, view.Wallet, view.TotalValue))
+		sb.WriteString(fmt.Sprintf("%-4s %-16s %16s %16s %10s\n", "#", i18n.T("代币"), i18n.T("数量"), i18n.T("价值"), i18n.T("占比")))
+		sb.WriteString(strings.Repeat("-", 66) + "\n")
+
+		limit := walletSectionLimit
+		if len(view.Tokens) < limit {
+			limit = len(view.Tokens)
+		}
+		for i, token := range view.Tokens[:limit] {
+			var percentage float64
+			if view.TotalValue > 0 {
+				percentage = token.Value / view.TotalValue * 100
+			}
+			sb.WriteString(fmt.Sprintf("%-4d %-16s %16.4f %16.2f %9.2f%%\n",
+				i+1,
+				truncateSymbol(displaySymbol(token)),
+				token.Amount,
+				token.Value,
+				percentage))
+		}
+		if rest := len(view.Tokens) - limit; rest > 0 {
+			sb.WriteString(i18n.Sprintf("... 其余 %d 个代币\n", rest))
+		}
+	}
+	return sb.String()
+}
+
+// generateGroupSections 按钱包分组汇总总价值，没有配置分组时不生成
+func generateGroupSections(tokens []*TokenData) string {
+	views := WalletBreakdown(tokens)
+
+	type groupTotal struct {
+		name    string
+		wallets int
+		value   float64
+	}
+	totals := make(map[string]*groupTotal)
+	var grandTotal float64
+	hasGroup := false
+	for _, view := range views {
+		name := WalletGroup(view.Wallet)
+		if name != "" {
+			hasGroup = true
+		} else {
+			name = i18n.T("(未分组)")
+		}
+		total, ok := totals[name]
+		if !ok {
+			total = &groupTotal{name: name}
+			totals[name] = total
+		}
+		total.wallets++
+		total.value += view.TotalValue
+		grandTotal += view.TotalValue
+	}
+	if !hasGroup {
+		return ""
+	}
+
+	sorted := make([]*groupTotal, 0, len(totals))
+	for _, total := range totals {
+		sorted = append(sorted, total)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].value > sorted[j].value
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n%-20s %8s %16s %10s\n", i18n.T("分组"), i18n.T("钱包数"), i18n.T("总值"), i18n.T("占比")))
+	sb.WriteString(strings.Repeat("-", 57) + "\n")
+	for _, total := range sorted {
+		var percentage float64
+		if grandTotal > 0 {
+			percentage = total.value / grandTotal * 100
+		}
+		sb.WriteString(fmt.Sprintf("%-20s %8d %16.2f %9.2f%%\n", total.name, total.wallets, total.value, percentage))
+	}
+	return sb.String()
+}
+
+// truncateSymbol 截断过长的代币名称以适应表格宽度
+func truncateSymbol(symbol string) string {
+	if len(symbol) > 16 {
+		return symbol[:16]
+	}
+	return symbol
+}
+
+// proportionalStaked 按钱包持有数量的比例分摊聚合代币的质押数量
+func proportionalStaked(token *TokenData, amount float64) float64 {
+	if token.Staked <= 0 || token.Amount <= 0 {
+		return 0
+	}
+	return token.Staked * amount / token.Amount
+}
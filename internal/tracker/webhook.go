@@ -0,0 +1,159 @@
+package tracker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// webhookSignatureHeader 请求体 HMAC-SHA256 签名所在的请求头
+const webhookSignatureHeader = "X-Tracker-Signature"
+
+// WebhookEvent 发送到外部 webhook 的事件
+type WebhookEvent struct {
+	Event     string      `json:"event"` // alert / snapshot / trade_signal
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// AlertPayload 报警事件的JSON格式
+type AlertPayload struct {
+	Type        AlertType `json:"type"`
+	Severity    string    `json:"severity,omitempty"`
+	Wallet      string    `json:"wallet,omitempty"`
+	WalletLabel string    `json:"wallet_label,omitempty"`
+	WalletGroup string    `json:"wallet_group,omitempty"`
+	Mint        string    `json:"mint,omitempty"`
+	Symbol      string    `json:"symbol,omitempty"`
+	Signature   string    `json:"signature,omitempty"`
+	Window      string    `json:"window,omitempty"`
+	ChangePct   float64   `json:"change_pct"`
+	OldValue    float64   `json:"old_value"`
+	NewValue    float64   `json:"new_value"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// newAlertPayload 将报警转换为JSON格式
+func newAlertPayload(alert Alert) AlertPayload {
+	payload := AlertPayload{
+		Type:      alert.Type,
+		Severity:  string(alert.Severity),
+		Wallet:    alert.Wallet,
+		Mint:      alert.MintAddr,
+		Symbol:    alert.Symbol,
+		Signature: alert.Signature,
+		ChangePct: alert.ChangePct,
+		OldValue:  alert.OldValue,
+		NewValue:  alert.NewValue,
+		Message:   alert.Message,
+		Timestamp: alert.Timestamp,
+	}
+	if alert.Wallet != "" {
+		payload.WalletLabel = WalletLabel(alert.Wallet)
+		payload.WalletGroup = WalletGroup(alert.Wallet)
+	}
+	if alert.Window > 0 {
+		payload.Window = alert.Window.String()
+	}
+	return payload
+}
+
+// WebhookNotifier 将报警（以及可选的快照）以JSON POST 到用户配置的地址，secret 非空时附带 HMAC 签名
+type WebhookNotifier struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewWebhookNotifier 创建通用 webhook 通知渠道
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return NewWebhookNotifierWithConfig(url, secret, nil)
+}
+
+// NewWebhookNotifierWithConfig 使用指定的HTTP客户端创建 webhook 通知渠道，client 为nil时使用共享客户端
+func NewWebhookNotifierWithConfig(url, secret string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = notifyHTTPClient()
+	}
+	return &WebhookNotifier{
+		client: client,
+		url:    url,
+		secret: secret,
+	}
+}
+
+// Notify 发送报警事件
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return w.send(ctx, WebhookEvent{
+		Event:     "alert",
+		Timestamp: alert.Timestamp,
+		Data:      newAlertPayload(alert),
+	})
+}
+
+// PublishSignal 发送跟单信号
+func (w *WebhookNotifier) PublishSignal(ctx context.Context, signal TradeSignal) error {
+	return w.send(ctx, WebhookEvent{
+		Event:     "trade_signal",
+		Timestamp: signal.Timestamp,
+		Data:      signal,
+	})
+}
+
+// ForwardSnapshots 订阅监控器的快照并逐个发送，直到 ctx 结束
+func (w *WebhookNotifier) ForwardSnapshots(ctx context.Context, monitor *TokenMonitor) {
+	events, unsubscribe := monitor.SubscribeSnapshots()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				sendCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+				if err := w.send(sendCtx, WebhookEvent{
+					Event:     "snapshot",
+					Timestamp: event.Timestamp,
+					Data:      event,
+				}); err != nil {
+					notifyLog.Error("发送快照到webhook失败", "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// send 序列化事件并签名发送
+func (w *WebhookNotifier) send(ctx context.Context, event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %v", err)
+	}
+
+	headers := map[string]string{
+		"X-Tracker-Event":     event.Event,
+		"X-Tracker-Timestamp": strconv.FormatInt(event.Timestamp.Unix(), 10),
+	}
+	if w.secret != "" {
+		headers[webhookSignatureHeader] = "sha256=" + signPayload(w.secret, body)
+	}
+	return postJSON(ctx, w.client, w.url, body, headers)
+}
+
+// signPayload 计算请求体的 HMAC-SHA256 签名（十六进制）
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -2,209 +2,367 @@ package main
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
 	"wallet-tracker/internal/tracker"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/term"
 )
 
+var logger = logging.For("main")
+
+// snsResolveTimeout 解析配置中所有 .sol 域名的超时
+const snsResolveTimeout = 30 * time.Second
+
 func main() {
-	// 解析命令行参数
-	var (
-		walletAddr string
-		configFile string
-		processAll bool
-	)
-	flag.StringVar(&walletAddr, "wallet", "", "要分析的钱包地址")
-	flag.StringVar(&configFile, "config", "config/wallets.yaml", "钱包配置文件路径")
-	flag.BoolVar(&processAll, "all", false, "是否处理配置文件中的所有钱包")
-	flag.Parse()
-
-	// 配置日志输出到文件
-	logFile, err := os.OpenFile("wallet-tracker.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatal("无法创建日志文件:", err)
-	}
-	defer logFile.Close()
+	// 用法、参数说明和不加载配置的子命令（wallet、ctl、config）按语言环境变量选择输出语言，加载配置后按 settings.language 重新设置
+	i18n.SetLanguage(i18n.Detect(""))
 
-	// 设置日志输出格式
-	log.SetOutput(logFile)
-	log.SetFlags(log.Ltime) // 只显示时间，不显示日期
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		// 兼容旧的单命令用法：tracker -all 等同于 tracker watch -all
+		runWatch(os.Args[1:])
+		return
+	}
 
-	// 检查日志级别
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "INFO" // 默认日志级别
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "watch":
+		runWatch(args)
+	case "snapshot":
+		runSnapshot(args)
+	case "report":
+		runReport(args)
+	case "wallet":
+		runWallet(args)
+	case "ctl":
+		runCtl(args)
+	case "config":
+		runConfig(args)
+	case "counterparties":
+		runCounterparties(args)
+	case "help":
+		printUsage()
+	default:
+		fmt.Fprint(os.Stderr, i18n.Sprintf("未知的子命令: %s\n\n", command))
+		printUsage()
+		os.Exit(2)
 	}
+}
+
+// printUsage 打印子命令列表
+func printUsage() {
+	fmt.Fprint(os.Stderr, i18n.T(`用法: tracker <子命令> [参数]
+
+子命令:
+  watch      持续监控钱包、报警并定时刷新（默认）
+  snapshot   获取一次当前持仓后退出，-json 输出JSON
+  report     根据存储的快照生成区间报告，如 -since 24h
+  wallet     管理配置文件中的钱包: add / remove / list / label
+  config     检查配置文件: validate
+  ctl        控制运行中的 watch: status / pause / resume / refresh / threshold
+  counterparties
+             分析钱包最近的交易，列出往来频繁的地址和资金来源，如 -all -limit 500
+
+使用 tracker <子命令> -h 查看各子命令的参数
+`))
+}
 
-	// 移除不必要的日志输出
-	if logLevel == "DEBUG" {
-		log.Printf("日志级别: %s", logLevel)
-		log.Println("----------------------------------------")
-		log.Println("开始执行程序...")
-		log.Println("----------------------------------------")
+// initEnv 加载当前目录下的 .env 文件，文件不存在时（如在容器中直接传入环境变量）跳过
+func initEnv() error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("加载环境变量失败: %v", err)
 	}
+	return nil
+}
 
-	if err := initEnv(); err != nil {
-		log.Fatal(err)
+func fetchTokens(ctx context.Context, walletAddrs []string, cfg *config.Config, strict bool) (map[string][]*tracker.TokenData, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		logger.Info("开始处理钱包地址", "count", len(walletAddrs))
+		result, err := tracker.FetchMultipleWalletsTokens(ctx, walletAddrs, nil, cfg)
+		reportWalletErrors(result.Errors, strict)
+		return result.Tokens, err
 	}
+}
 
-	// 加载配置文件
-	cfg, err := config.LoadConfig(configFile)
-	if err != nil {
-		log.Fatal("加载配置文件失败:", err)
+// reportWalletErrors 记录获取失败的钱包，strict 模式下以非零状态退出
+func reportWalletErrors(walletErrs map[string]error, strict bool) {
+	if len(walletErrs) == 0 {
+		return
 	}
 
-	var walletAddrs []string
-	if processAll {
-		// 使用配置文件中的所有钱包
-		walletAddrs = cfg.GetWalletAddresses()
-		if logLevel == "DEBUG" {
-			log.Printf("从配置文件加载了 %d 个钱包地址", len(walletAddrs))
-		}
-	} else if walletAddr != "" {
-		// 使用命令行指定的钱包
-		walletAddrs = []string{walletAddr}
-		if logLevel == "DEBUG" {
-			log.Printf("使用命令行指定的钱包地址: %s", walletAddr)
-		}
-	} else {
-		log.Fatal("请使用 -wallet 指定钱包地址或使用 -all 处理所有配置的钱包")
+	for addr, err := range walletErrs {
+		logger.Warn("已跳过钱包", "wallet", addr, "error", err)
 	}
 
-	// 创建上下文以便优雅退出
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	if strict {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("%d 个钱包获取失败，strict 模式下退出\n", len(walletErrs)))
+		fatal("钱包获取失败，strict 模式下退出", "failed", len(walletErrs))
+	}
+}
 
-	// 获取最新数据
-	tokens, err := fetchTokens(ctx, walletAddrs, cfg)
+func updateTokenPrices(ctx context.Context, tokens map[string][]*tracker.TokenData, monitor *tracker.TokenMonitor) ([]*tracker.TokenData, error) {
+	validTokens, err := tracker.UpdateTokenPrices(ctx, tokens, monitor)
 	if err != nil {
-		log.Fatal("获取代币数据失败:", err)
+		return nil, fmt.Errorf("更新价格信息失败: %v", err)
 	}
+	return validTokens, nil
+}
 
-	// 更新价格
-	validTokens, err := updateTokenPrices(tokens, nil)
-	if err != nil {
-		log.Fatal("更新价格失败:", err)
+func printReport(tokens []*tracker.TokenData, diff *tracker.DiffReporter) {
+	// 警告和报警级别：不输出报告
+	if logging.Level() >= slog.LevelWarn {
+		return
 	}
 
-	// 生成初始报告
-	printReport(validTokens)
+	// 生成报告，diff 不为nil时只输出与上次相比的变化
+	var report string
+	if diff != nil {
+		report = diff.Report(tokens)
+	} else {
+		report = tracker.GenerateReport(tokens)
+	}
+	fmt.Println(report)
 
-	// 创建中断信号通道
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Debug级别：同时将完整报告写入日志
+	logger.Debug("代币报告", "report", report)
+}
 
-	// 创建并启动监控器
-	monitor := tracker.NewTokenMonitor(20*time.Second, func(tokens []*tracker.TokenData) {
-		printReport(tokens)
-	})
+// configureReportStyle 根据标准输出是否为终端设置报告的表格边框和涨跌颜色，重定向到文件或管道时输出纯文本
+func configureReportStyle(colorMode string) {
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	tracker.SetReportUnicode(tty)
+
+	color := tty
+	switch colorMode {
+	case config.ReportColorAlways:
+		color = true
+	case config.ReportColorNever:
+		color = false
+	default:
+		if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+			color = false
+		}
+	}
+	tracker.SetReportColor(color)
+}
 
-	// 更新监控器数据
-	monitor.UpdateTokens(validTokens)
+// configureLogging 配置日志级别、格式和语言：命令行参数优先，其次是配置文件，最后是 LOG_LEVEL 环境变量
+func configureLogging(out io.Writer, flagLevel, flagFormat string, settings config.Settings) error {
+	levelName := flagLevel
+	if levelName == "" {
+		levelName = settings.LogLevel
+	}
+	if levelName == "" {
+		levelName = os.Getenv("LOG_LEVEL")
+	}
+	level, err := logging.ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
 
-	// 启动监控
-	monitor.Start()
+	format := flagFormat
+	if format == "" {
+		format = settings.LogFormat
+	}
+	i18n.SetLanguage(i18n.Detect(settings.Language))
+	return logging.Setup(logging.Options{Level: level, Format: format, Output: out})
+}
 
-	// 创建定时更新代币列表的goroutine
-	go func() {
-		// 等待一段时间后再开始定时更新
-		time.Sleep(5 * time.Minute)
-		log.Println("开始定时更新代币列表...")
+// fatal 记录错误日志并同时输出到标准错误，然后退出
+func fatal(msg string, args ...any) {
+	logger.Error(msg, args...)
+	fmt.Fprintln(os.Stderr, append([]any{i18n.T(msg)}, args...)...)
+	os.Exit(1)
+}
+
+// tokenFilterFromConfig 将配置中的过滤规则转换为代币过滤器
+func tokenFilterFromConfig(f config.Filters) tracker.TokenFilter {
+	filter := tracker.TokenFilter{
+		MinValue:          f.MinValue,
+		MinLiquidity:      f.MinLiquidity,
+		Blacklist:         make(map[string]bool, len(f.Blacklist)),
+		Whitelist:         make(map[string]bool, len(f.Whitelist)),
+		HideLowConfidence: f.HideLowConfidence == nil || *f.HideLowConfidence,
+		MaxTokens:         f.MaxTokens,
+		HideSpam:          f.HideSpam == nil || *f.HideSpam,
+	}
+	for _, mint := range f.Blacklist {
+		filter.Blacklist[mint] = true
+	}
+	for _, mint := range f.Whitelist {
+		filter.Whitelist[mint] = true
+	}
+	return filter
+}
 
-		ticker := time.NewTicker(5 * time.Minute)
-		defer ticker.Stop()
+// tradesFromConfig 将配置中的交易记录转换为账本交易
+func tradesFromConfig(configs []config.TradeConfig) []tracker.Trade {
+	trades := make([]tracker.Trade, 0, len(configs))
+	for _, t := range configs {
+		trades = append(trades, tracker.Trade{
+			MintAddr:  t.Mint,
+			Side:      tracker.TradeSide(t.Side),
+			Amount:    t.Amount,
+			Price:     t.Price,
+			Timestamp: t.Time,
+			Wallet:    t.Wallet,
+		})
+	}
+	return trades
+}
 
-		updateData := func() {
-			log.Println("执行定时更新...")
-			// 获取最新数据
-			tokens, err := fetchTokens(ctx, walletAddrs, cfg)
-			if err != nil {
-				log.Printf("更新代币数据失败: %v", err)
-				return
-			}
+// priceTargetsFromConfig 将配置中的价格目标转换为 tracker.PriceTarget
+func priceTargetsFromConfig(configs []config.PriceTargetConfig) []tracker.PriceTarget {
+	targets := make([]tracker.PriceTarget, 0, len(configs))
+	for _, t := range configs {
+		targets = append(targets, tracker.PriceTarget{
+			MintAddr: t.Mint,
+			Above:    t.AlertAbove,
+			Below:    t.AlertBelow,
+			Repeat:   t.Repeat,
+		})
+	}
+	return targets
+}
 
-			validTokens, err := updateTokenPrices(tokens, monitor)
-			if err != nil {
-				log.Printf("更新价格失败: %v", err)
-				return
-			}
+// severityFromConfig 将配置中的报警级别设置转换为 tracker.SeverityConfig
+func severityFromConfig(cfg config.Severity) tracker.SeverityConfig {
+	routes := make(map[tracker.AlertSeverity][]string, len(cfg.Routes))
+	for level, names := range cfg.Routes {
+		routes[tracker.AlertSeverity(level)] = names
+	}
+	return tracker.SeverityConfig{
+		WarnPct:     cfg.WarnPct,
+		CriticalPct: cfg.CriticalPct,
+		Routes:      routes,
+		QuietStart:  cfg.QuietHours.Start,
+		QuietEnd:    cfg.QuietHours.End,
+	}
+}
 
-			// 更新监控器数据
-			monitor.UpdateTokens(validTokens)
-			log.Println("定时更新完成")
+// rulesFromConfig 编译配置中的报警规则
+func rulesFromConfig(configs []config.AlertRule) ([]*tracker.AlertRule, error) {
+	rules := make([]*tracker.AlertRule, 0, len(configs))
+	for _, r := range configs {
+		rule, err := tracker.CompileRule(r.Name, r.When, r.Message, r.Notify, r.Severity)
+		if err != nil {
+			return nil, err
 		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				log.Println("停止定时更新")
-				return
-			case <-ticker.C:
-				updateData()
-			}
+// applyRuntimeConfig 应用运行中可以热更新的配置：HTTP限流、熔断、备用RPC端点、过滤规则、价格目标、交易记录和钱包标签；
+// 以 .sol 域名填写的钱包在这里解析为地址
+func applyRuntimeConfig(cfg *config.Config) error {
+	tracker.SetHTTPConfig(tracker.HTTPConfig{
+		Timeout:             cfg.Settings.HTTP.Timeout,
+		NotifyTimeout:       cfg.Settings.HTTP.NotifyTimeout,
+		MaxIdleConnsPerHost: cfg.Settings.HTTP.MaxIdleConnsPerHost,
+		RateLimits:          cfg.Settings.HTTP.RateLimits,
+		RateLimitBursts:     cfg.Settings.HTTP.RateLimitBursts,
+	})
+	tracker.SetWalletFetchDelay(cfg.Settings.WalletFetchDelay)
+	tracker.SetCircuitBreakerConfig(cfg.Settings.CircuitBreaker.FailureThreshold, cfg.Settings.CircuitBreaker.Cooldown)
+	tracker.SetRPCEndpoints(cfg.Settings.RPC.Endpoints, cfg.Settings.RPC.Strategy == config.RPCStrategyRoundRobin)
+	tracker.SetTokenFilter(tokenFilterFromConfig(cfg.Filters))
+	tracker.SetStablecoinConfig(tracker.StablecoinConfig{
+		Mints:           cfg.Settings.Stablecoins.Mints,
+		PegBand:         cfg.Settings.Stablecoins.PegBand,
+		MarketValuation: cfg.Settings.Stablecoins.MarketValuation,
+	})
+	tracker.SetPriceTargets(priceTargetsFromConfig(cfg.PriceTargets))
+	tracker.SetStalePriceMaxAge(cfg.Settings.StalePriceMaxAge)
+	configureReportStyle(cfg.Settings.ReportColor)
+
+	// 加载手动录入的交易记录
+	var ledger *tracker.PositionLedger
+	if len(cfg.Trades) > 0 {
+		ledger = tracker.NewPositionLedger()
+		if err := ledger.AddTrades(tradesFromConfig(cfg.Trades)); err != nil {
+			return fmt.Errorf("加载交易记录失败: %v", err)
 		}
-	}()
-
-	// 等待中断信号
-	<-sigChan
-
-	// 优雅退出
-	monitor.Stop()
+	}
+	tracker.SetPositionLedger(ledger)
 
-	log.Println("----------------------------------------")
-	log.Println("程序执行完成")
-}
+	// 解析 .sol 域名需要已设置的RPC端点，钱包标签和分组使用解析后的地址
+	if err := tracker.SetSNSCache(cfg.Settings.SNS.CachePath, cfg.Settings.SNS.RefreshInterval); err != nil {
+		logger.Warn("加载域名解析缓存失败", "error", err)
+	}
+	if _, err := resolveWalletDomains(cfg); err != nil {
+		return err
+	}
 
-func initEnv() error {
-	if err := godotenv.Load(); err != nil {
-		return fmt.Errorf("加载环境变量失败: %v", err)
+	// 报告和报警中使用钱包标签和分组
+	labels := make(map[string]string, len(cfg.Wallets))
+	groups := make(map[string]string, len(cfg.Wallets))
+	for _, w := range cfg.Wallets {
+		labels[w.Address] = w.Label
+		groups[w.Address] = w.Group
 	}
+	tracker.SetWalletLabels(labels)
+	tracker.SetWalletGroups(groups)
 	return nil
 }
 
-func fetchTokens(ctx context.Context, walletAddrs []string, cfg *config.Config) (map[string][]*tracker.TokenData, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		log.Printf("开始处理 %d 个钱包地址...", len(walletAddrs))
-		return tracker.FetchMultipleWalletsTokens(ctx, walletAddrs, nil, cfg)
-	}
-}
+// resolveWalletDomains 将以 .sol 域名填写的钱包解析为所有者地址，未设置标签的钱包以域名作为标签；
+// 已解析过的钱包按原域名重新解析，返回是否有钱包地址变化
+func resolveWalletDomains(cfg *config.Config) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), snsResolveTimeout)
+	defer cancel()
 
-func updateTokenPrices(tokens map[string][]*tracker.TokenData, monitor *tracker.TokenMonitor) ([]*tracker.TokenData, error) {
-	validTokens, err := tracker.UpdateTokenPrices(tokens, monitor)
-	if err != nil {
-		return nil, fmt.Errorf("更新价格信息失败: %v", err)
+	changed := false
+	for i, w := range cfg.Wallets {
+		domain := w.Domain
+		if domain == "" {
+			if !tracker.IsSNSDomain(w.Address) {
+				continue
+			}
+			domain = w.Address
+		}
+		address, err := tracker.ResolveSNSDomain(ctx, domain)
+		if err != nil {
+			return false, fmt.Errorf("解析钱包域名 %s 失败: %v", domain, err)
+		}
+		if address != w.Address {
+			changed = true
+		}
+		cfg.Wallets[i].Domain = domain
+		cfg.Wallets[i].Address = address
+		if w.Label == "" {
+			cfg.Wallets[i].Label = domain
+		}
 	}
-	return validTokens, nil
+	return changed, nil
 }
 
-func printReport(tokens []*tracker.TokenData) {
-	logLevel := os.Getenv("LOG_LEVEL")
-
-	// 生成报告
-	report := tracker.GenerateReport(tokens)
-
-	// 根据日志级别决定输出内容
-	switch logLevel {
-	case "DEBUG":
-		// Debug模式：输出完整报告
-		fmt.Println(report)
-		log.Println(report)
-	case "WARN", "ALERT":
-		// 警告和报警模式：不输出报告
-	default:
-		// INFO模式：只输出到控制台
-		fmt.Println(report)
+// sameWallets 判断两个钱包列表是否包含相同的地址
+func sameWallets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, addr := range a {
+		set[addr] = true
+	}
+	for _, addr := range b {
+		if !set[addr] {
+			return false
+		}
 	}
+	return true
 }
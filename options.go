@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"wallet-tracker/config"
+	"wallet-tracker/internal/i18n"
+	"wallet-tracker/internal/logging"
+	"wallet-tracker/internal/tracker"
+)
+
+// globalOptions 各子命令共用的参数：配置文件和日志设置
+type globalOptions struct {
+	configFile string
+	logLevel   string
+	logFormat  string
+	noColor    bool
+	logFile    *logging.RotatingFile
+}
+
+func (o *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configFile, "config", defaultConfigFile(), i18n.Sprintf("钱包配置文件路径，为空表示只从 %s* 环境变量读取配置", config.EnvPrefix))
+	fs.StringVar(&o.logLevel, "log-level", "", i18n.T("日志级别（debug/info/warn/alert/error），覆盖配置文件中的 log_level"))
+	fs.StringVar(&o.logFormat, "log-format", "", i18n.T("日志格式（text/json），覆盖配置文件中的 log_format"))
+	fs.BoolVar(&o.noColor, "no-color", false, i18n.T("报告不使用颜色（适合CI和日志），覆盖配置文件中的 report_color"))
+}
+
+// defaultConfigFile 默认的配置文件路径，可通过 WALLET_TRACKER_CONFIG 环境变量指定（设为空表示不使用配置文件）
+func defaultConfigFile() string {
+	if path, ok := os.LookupEnv(config.EnvPrefix + "CONFIG"); ok {
+		return path
+	}
+	return "config/wallets.yaml"
+}
+
+// setupLogging 应用日志级别、格式和轮转设置；未打开日志文件时输出到标准错误
+func (o *globalOptions) setupLogging(settings config.Settings) error {
+	var out io.Writer = os.Stderr
+	if o.logFile != nil {
+		if settings.LogRotation.MaxSizeMB > 0 {
+			o.logFile.SetConfig(settings.LogRotation.RotateConfig())
+		}
+		out = o.logFile
+	}
+	return configureLogging(out, o.logLevel, o.logFormat, settings)
+}
+
+// openLogFile 打开配置的日志文件，log_file 为 "-" 时只输出到标准错误
+func (o *globalOptions) openLogFile(settings config.Settings) {
+	if settings.LogFile == "-" {
+		return
+	}
+	rotation := settings.LogRotation
+	if rotation.MaxSizeMB <= 0 {
+		rotation = config.LogRotation{MaxSizeMB: config.DefaultLogMaxSizeMB, MaxBackups: config.DefaultLogMaxBackups}
+	}
+	logFile, err := logging.OpenRotatingFile(settings.LogFile, rotation.RotateConfig(), "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("无法创建日志文件:"), err)
+		os.Exit(1)
+	}
+	o.logFile = logFile
+}
+
+// load 初始化日志和环境变量并加载配置文件，失败时退出
+func (o *globalOptions) load() *config.Config {
+	// 先按命令行参数和环境变量初始化日志并输出到标准错误，加载配置后再切换到配置的日志文件
+	if err := o.setupLogging(config.Settings{}); err != nil {
+		fatal("日志配置无效", "error", err)
+	}
+
+	if err := initEnv(); err != nil {
+		fatal("初始化环境失败", "error", err)
+	}
+
+	cfg, err := config.LoadConfig(o.configFile)
+	if err != nil {
+		fatal("加载配置文件失败", "error", err)
+	}
+	o.override(&cfg.Settings)
+	o.openLogFile(cfg.Settings)
+	if err := o.setupLogging(cfg.Settings); err != nil {
+		fatal("日志配置无效", "error", err)
+	}
+	logger.Debug("开始执行程序", "level", logging.Level().String())
+	return cfg
+}
+
+// override 用命令行参数覆盖配置文件中的设置，重新加载配置后也需调用
+func (o *globalOptions) override(settings *config.Settings) {
+	if o.noColor {
+		settings.ReportColor = config.ReportColorNever
+	}
+}
+
+// close 关闭日志文件
+func (o *globalOptions) close() {
+	if o.logFile != nil {
+		o.logFile.Close()
+	}
+}
+
+// walletOptions 选择要处理的钱包
+type walletOptions struct {
+	walletAddr string
+	processAll bool
+	group      string
+}
+
+func (o *walletOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.walletAddr, "wallet", "", i18n.T("要分析的钱包地址或 .sol 域名"))
+	fs.BoolVar(&o.processAll, "all", false, i18n.T("是否处理配置文件中的所有钱包"))
+	fs.StringVar(&o.group, "group", "", i18n.T("只处理属于该分组或带有该标签的钱包"))
+}
+
+// resolve 根据参数返回要处理的钱包地址
+func (o *walletOptions) resolve(cfg *config.Config) ([]string, error) {
+	switch {
+	case o.group != "":
+		// 使用配置文件中指定分组的钱包
+		walletAddrs := cfg.GroupWalletAddresses(o.group)
+		if len(walletAddrs) == 0 {
+			return nil, fmt.Errorf("分组 %s 中没有钱包", o.group)
+		}
+		logger.Debug("从分组加载钱包地址", "group", o.group, "count", len(walletAddrs))
+		return walletAddrs, nil
+	case o.processAll:
+		// 使用配置文件中的所有钱包
+		walletAddrs := cfg.GetWalletAddresses()
+		logger.Debug("从配置文件加载钱包地址", "count", len(walletAddrs))
+		return walletAddrs, nil
+	case o.walletAddr != "":
+		// 使用命令行指定的钱包
+		logger.Debug("使用命令行指定的钱包地址", "wallet", o.walletAddr)
+		address, err := o.resolveDomain()
+		if err != nil {
+			return nil, err
+		}
+		return []string{address}, nil
+	}
+	return nil, fmt.Errorf("请使用 -wallet 指定钱包地址，或使用 -all/-group 处理配置中的钱包")
+}
+
+// reload 配置文件重新加载或重新解析域名后返回新的钱包列表；命令行指定的单个钱包保持不变，为 .sol 域名时重新解析
+func (o *walletOptions) reload(cfg *config.Config, current []string) []string {
+	if o.group != "" {
+		return cfg.GroupWalletAddresses(o.group)
+	}
+	if o.processAll {
+		return cfg.GetWalletAddresses()
+	}
+	if address, err := o.resolveDomain(); err == nil {
+		return []string{address}
+	}
+	return current
+}
+
+// resolveDomain 返回命令行指定的钱包地址，.sol 域名解析为所有者地址
+func (o *walletOptions) resolveDomain() (string, error) {
+	if !tracker.IsSNSDomain(o.walletAddr) {
+		return o.walletAddr, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snsResolveTimeout)
+	defer cancel()
+	address, err := tracker.ResolveSNSDomain(ctx, o.walletAddr)
+	if err != nil {
+		return "", fmt.Errorf("解析钱包域名 %s 失败: %v", o.walletAddr, err)
+	}
+	return address, nil
+}
+
+// overrideOptions 覆盖配置文件中运行参数的命令行参数，零值表示不覆盖
+type overrideOptions struct {
+	minValue        float64
+	monitorInterval time.Duration
+	refreshInterval time.Duration
+	maxConcurrent   int
+	priceBatchSize  int
+	dbPath          string
+}
+
+// registerFetch 注册获取代币和价格相关的参数
+func (o *overrideOptions) registerFetch(fs *flag.FlagSet) {
+	fs.Float64Var(&o.minValue, "min-value", -1, i18n.T("报告中显示代币的最小价值（美元），覆盖配置文件中的 filters.min_value"))
+	fs.IntVar(&o.maxConcurrent, "max-concurrent", 0, i18n.T("并发获取的钱包数量，覆盖配置文件中的 max_concurrent_wallets"))
+	fs.IntVar(&o.priceBatchSize, "batch-size", 0, i18n.T("价格查询的批量大小，覆盖配置文件中的 price_batch_size"))
+}
+
+// registerMonitor 注册持续监控相关的参数
+func (o *overrideOptions) registerMonitor(fs *flag.FlagSet) {
+	fs.DurationVar(&o.monitorInterval, "interval", 0, i18n.T("监控快照间隔（如 20s），覆盖配置文件中的 monitor_interval"))
+	fs.DurationVar(&o.refreshInterval, "refresh-interval", 0, i18n.T("代币列表刷新间隔（如 5m），覆盖配置文件中的 refresh_interval"))
+}
+
+// registerStore 注册快照存储相关的参数
+func (o *overrideOptions) registerStore(fs *flag.FlagSet) {
+	fs.StringVar(&o.dbPath, "db", "", i18n.T("快照SQLite数据库路径，覆盖配置文件中的 sqlite_path"))
+}
+
+// apply 命令行参数覆盖配置文件中的运行参数（重新加载配置时同样适用）
+func (o *overrideOptions) apply(c *config.Config) error {
+	if o.monitorInterval != 0 {
+		c.Settings.MonitorInterval = o.monitorInterval
+	}
+	if o.refreshInterval != 0 {
+		c.Settings.RefreshInterval = o.refreshInterval
+	}
+	if o.maxConcurrent != 0 {
+		c.Settings.MaxConcurrentWallets = o.maxConcurrent
+	}
+	if o.priceBatchSize != 0 {
+		c.Settings.PriceBatchSize = o.priceBatchSize
+	}
+	if o.dbPath != "" {
+		c.Settings.SQLitePath = o.dbPath
+	}
+	// 小额代币过滤阈值（命令行参数优先）
+	if o.minValue >= 0 {
+		c.Filters.MinValue = o.minValue
+	}
+	return c.Settings.Validate()
+}
+
+// initTracker 应用运行参数并创建价格服务、盈亏基准和过滤规则，失败时退出；配置了 Redis 时返回其连接
+func initTracker(cfg *config.Config, resetBaseline bool) *tracker.RedisClient {
+	tracker.SetDataDir(cfg.Settings.DataDir)
+	tracker.SetPriceBatchSize(cfg.Settings.PriceBatchSize)
+	tracker.SetMaxTokenAccounts(cfg.Settings.MaxTokenAccounts)
+
+	// 从磁盘加载代币元数据缓存，避免重启后重新获取符号和精度
+	cache, err := config.LoadTokenMetadataCache(cfg.Settings.MetadataCachePath, cfg.Settings.MetadataCacheTTL)
+	if err != nil {
+		logger.Warn("加载代币元数据缓存失败", "error", err)
+	}
+	cfg.SetMetadataCache(cache)
+	logger.Debug("已加载代币元数据缓存", "path", cfg.Settings.MetadataCachePath, "tokens", cache.Len())
+
+	// 应用HTTP设置、过滤阈值、交易记录和钱包标签，需在创建价格服务之前
+	if err := applyRuntimeConfig(cfg); err != nil {
+		fatal("应用配置失败", "error", err)
+	}
+
+	// 创建价格聚合服务
+	aggregator, err := tracker.NewPriceAggregatorFromConfig(cfg.Settings)
+	if err != nil {
+		fatal("创建价格服务失败", "error", err)
+	}
+	var redisClient *tracker.RedisClient
+	if cfg.Settings.Redis.URL != "" {
+		redisClient, err = tracker.NewRedisClient(cfg.Settings.Redis)
+		if err != nil {
+			fatal("连接Redis失败", "error", err)
+		}
+	}
+
+	var priceService tracker.QuotePriceService = aggregator
+	if cache := cfg.Settings.PriceCache; cache.TTL > 0 {
+		cacheCfg := tracker.PriceCacheConfig{
+			TTL:               cache.TTL,
+			LongTailTTL:       cache.LongTailTTL,
+			StaleTTL:          cache.StaleTTL,
+			LongTailLiquidity: cache.LongTailLiquidity,
+		}
+		// 本地缓存过期后先读取其他实例写入 Redis 的价格
+		if cfg.Settings.Redis.PriceCache {
+			priceService = tracker.NewRedisPriceService(priceService, redisClient, cacheCfg)
+		}
+		priceService = tracker.NewCachedPriceService(priceService, cacheCfg)
+	}
+	tracker.SetPriceService(priceService)
+
+	// 创建流动性服务
+	if interval := cfg.Settings.Liquidity.RefreshInterval; interval > 0 {
+		tracker.SetLiquidityService(tracker.NewLiquidityService(interval))
+	}
+
+	// 创建代币安全检查服务
+	if cfg.Settings.TokenSafety.Enabled {
+		tracker.SetSafetyService(tracker.NewSafetyService(cfg.Settings.TokenSafety))
+	}
+
+	// 加载盈亏基准（运行中可发送 SIGHUP 重新锚定）
+	tracker.InitBaseline(filepath.Join(cfg.Settings.DataDir, "baseline.json"), resetBaseline)
+	return redisClient
+}
+
+// initTracing 启用追踪时设置 OTLP 导出，返回退出时调用的函数（发送剩余的 span）
+func initTracing(cfg *config.Config) func() {
+	if !cfg.Settings.Tracing.Enabled {
+		return func() {}
+	}
+	shutdown, err := tracker.InitTracing(context.Background(), cfg.Settings.Tracing)
+	if err != nil {
+		fatal("初始化追踪失败", "error", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Warn("发送追踪数据失败", "error", err)
+		}
+	}
+}
+
+// newFlagSet 创建子命令的参数集，出错时打印用法并退出；usage 应已经过翻译
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), i18n.Sprintf("用法: tracker %s\n\n参数:\n", usage))
+		fs.PrintDefaults()
+	}
+	return fs
+}